@@ -0,0 +1,58 @@
+package sysprims
+
+import "strconv"
+
+// GetCPUAffinity returns the logical CPU indices pid may run on, ascending.
+//
+// On Linux this is the affinity of the process's main thread
+// (sched_getaffinity); on Windows it is the process affinity mask within the
+// process's processor group.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to query this process
+//   - [ErrNotSupported]: macOS, which has no CPU affinity API
+func GetCPUAffinity(pid uint32) ([]int, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+	return getCPUAffinity(pid)
+}
+
+// SetCPUAffinity restricts pid to the given logical CPU indices.
+//
+// On Linux the mask is applied to every thread of the process, like
+// `taskset -a -p`; threads created afterwards inherit it from their creator.
+// On Windows this calls SetProcessAffinityMask, which only addresses CPUs in
+// the process's processor group (at most 64).
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is invalid, cpus is empty, an index is outside
+//     the logical CPU count, or none of cpus is usable by the process
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to change this process
+//   - [ErrNotSupported]: macOS, which has no CPU affinity API
+func SetCPUAffinity(pid uint32, cpus []int) error {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return err
+	}
+	if len(cpus) == 0 {
+		return &Error{Code: ErrInvalidArgument, Message: "cpus must not be empty"}
+	}
+	n, err := logicalCPUCount()
+	if err != nil {
+		return err
+	}
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= n {
+			return &Error{
+				Code:    ErrInvalidArgument,
+				Message: "cpu index " + strconv.Itoa(cpu) + " out of range (logical CPUs: " + strconv.Itoa(n) + ")",
+			}
+		}
+	}
+	return setCPUAffinity(pid, cpus)
+}
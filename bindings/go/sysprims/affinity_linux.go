@@ -0,0 +1,97 @@
+//go:build linux
+
+package sysprims
+
+/*
+#include <unistd.h>
+*/
+import "C"
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// affinityMaskWords is the initial mask size: 1024 CPUs, matching glibc's
+// cpu_set_t. Larger kernels are handled by retrying on EINVAL.
+const affinityMaskWords = 1024 / 64
+
+// logicalCPUCount returns the number of configured logical CPUs.
+func logicalCPUCount() (int, error) {
+	n := int(C.sysconf(C._SC_NPROCESSORS_CONF))
+	if n < 1 {
+		n = runtime.NumCPU()
+	}
+	return n, nil
+}
+
+// getCPUAffinity reads the main thread's mask with sched_getaffinity.
+func getCPUAffinity(pid uint32) ([]int, error) {
+	for words := affinityMaskWords; ; words *= 2 {
+		mask := make([]uint64, words)
+		_, _, e := syscall.Syscall(syscall.SYS_SCHED_GETAFFINITY,
+			uintptr(pid), uintptr(words*8), uintptr(unsafe.Pointer(&mask[0])))
+		if e == syscall.EINVAL && words < 1<<12 {
+			continue
+		}
+		if e != 0 {
+			return nil, errnoError(pid, e)
+		}
+
+		var cpus []int
+		for i, w := range mask {
+			for b := 0; b < 64; b++ {
+				if w&(1<<uint(b)) != 0 {
+					cpus = append(cpus, i*64+b)
+				}
+			}
+		}
+		return cpus, nil
+	}
+}
+
+// setCPUAffinity applies the mask to pid and then to its other threads.
+func setCPUAffinity(pid uint32, cpus []int) error {
+	words := affinityMaskWords
+	for _, cpu := range cpus {
+		if cpu/64 >= words {
+			words = cpu/64 + 1
+		}
+	}
+	mask := make([]uint64, words)
+	for _, cpu := range cpus {
+		mask[cpu/64] |= 1 << uint(cpu%64)
+	}
+
+	if e := schedSetAffinity(pid, mask); e != 0 {
+		if e == syscall.EINVAL {
+			return (&Error{Code: ErrInvalidArgument, Message: "cpus contains no CPU usable by the process"}).withErrno(e)
+		}
+		return errnoError(pid, e)
+	}
+
+	entries, err := os.ReadDir("/proc/" + strconv.FormatUint(uint64(pid), 10) + "/task")
+	if err != nil {
+		return procReadError(pid, err)
+	}
+	for _, entry := range entries {
+		tid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil || uint32(tid) == pid {
+			continue
+		}
+		// Threads that exit mid-walk are not an error.
+		if e := schedSetAffinity(uint32(tid), mask); e != 0 && e != syscall.ESRCH {
+			return errnoError(pid, e)
+		}
+	}
+	return nil
+}
+
+func schedSetAffinity(tid uint32, mask []uint64) syscall.Errno {
+	_, _, e := syscall.Syscall(syscall.SYS_SCHED_SETAFFINITY,
+		uintptr(tid), uintptr(len(mask)*8), uintptr(unsafe.Pointer(&mask[0])))
+	return e
+}
@@ -0,0 +1,21 @@
+//go:build !linux && !windows
+
+package sysprims
+
+import "runtime"
+
+func logicalCPUCount() (int, error) {
+	return 0, affinityNotSupported()
+}
+
+func getCPUAffinity(pid uint32) ([]int, error) {
+	return nil, affinityNotSupported()
+}
+
+func setCPUAffinity(pid uint32, cpus []int) error {
+	return affinityNotSupported()
+}
+
+func affinityNotSupported() error {
+	return &Error{Code: ErrNotSupported, Message: "cpu affinity is not supported on " + runtime.GOOS}
+}
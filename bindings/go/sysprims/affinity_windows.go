@@ -0,0 +1,81 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"math/bits"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	processSetInformation = 0x0200
+	allProcessorGroups    = 0xFFFF
+)
+
+var (
+	procGetProcessAffinityMask  = modKernel32.NewProc("GetProcessAffinityMask")
+	procSetProcessAffinityMask  = modKernel32.NewProc("SetProcessAffinityMask")
+	procGetActiveProcessorCount = modKernel32.NewProc("GetActiveProcessorCount")
+)
+
+// logicalCPUCount returns the number of CPUs addressable by an affinity mask:
+// the active processor count, capped at the mask width.
+func logicalCPUCount() (int, error) {
+	r, _, e := procGetActiveProcessorCount.Call(allProcessorGroups)
+	if r == 0 {
+		return 0, systemError(e)
+	}
+	n := int(r)
+	if n > bits.UintSize {
+		n = bits.UintSize
+	}
+	return n, nil
+}
+
+// getCPUAffinity reads the process affinity mask.
+func getCPUAffinity(pid uint32) ([]int, error) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return nil, winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var processMask, systemMask uintptr
+	r, _, e := procGetProcessAffinityMask.Call(uintptr(h),
+		uintptr(unsafe.Pointer(&processMask)), uintptr(unsafe.Pointer(&systemMask)))
+	if r == 0 {
+		return nil, systemError(e)
+	}
+
+	var cpus []int
+	for b := 0; b < bits.UintSize; b++ {
+		if processMask&(1<<uint(b)) != 0 {
+			cpus = append(cpus, b)
+		}
+	}
+	return cpus, nil
+}
+
+// setCPUAffinity sets the process affinity mask.
+func setCPUAffinity(pid uint32, cpus []int) error {
+	var mask uintptr
+	for _, cpu := range cpus {
+		mask |= 1 << uint(cpu)
+	}
+
+	h, err := syscall.OpenProcess(processSetInformation, false, pid)
+	if err != nil {
+		return winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	r, _, e := procSetProcessAffinityMask.Call(uintptr(h), mask)
+	if r == 0 {
+		if e == syscall.Errno(87) { // ERROR_INVALID_PARAMETER: not a subset of the system mask
+			return (&Error{Code: ErrInvalidArgument, Message: "cpus contains no CPU usable by the process"}).withErrno(syscall.Errno(87))
+		}
+		return systemError(e)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package sysprims
+
+// CanSignal reports whether the caller may signal pid, without sending
+// anything.
+//
+// On Unix this is kill(pid, 0): true when it succeeds, false when the
+// process exists but EPERM denies it. A zombie still reports true. On
+// Windows it opens pid with PROCESS_TERMINATE access, the right [Kill]
+// needs, and reports false when that access is denied.
+//
+// It is a single system call, much cheaper than [ProcessGet], but the answer
+// can change before a following Kill.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+func CanSignal(pid uint32) (bool, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return false, err
+	}
+	return canSignal(pid)
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package sysprims
+
+import "syscall"
+
+func canSignal(pid uint32) (bool, error) {
+	switch err := syscall.Kill(int(pid), 0); err {
+	case nil:
+		return true, nil
+	case syscall.EPERM:
+		return false, nil
+	default:
+		return false, errnoError(pid, err.(syscall.Errno))
+	}
+}
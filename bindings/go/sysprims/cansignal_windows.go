@@ -0,0 +1,19 @@
+//go:build windows
+
+package sysprims
+
+import "syscall"
+
+const processTerminate = 0x0001
+
+func canSignal(pid uint32) (bool, error) {
+	h, err := syscall.OpenProcess(processTerminate, false, pid)
+	if err == syscall.ERROR_ACCESS_DENIED {
+		return false, nil
+	}
+	if err != nil {
+		return false, winProcessError(pid, err)
+	}
+	_ = syscall.CloseHandle(h)
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package sysprims
+
+import "strings"
+
+// containerIDLen is the length of the hex container IDs used by Docker,
+// containerd, CRI-O, and Podman.
+const containerIDLen = 64
+
+// parseCgroupFile picks the process's cgroup path from the contents of
+// /proc/<pid>/cgroup.
+//
+// The unified (cgroup v2) entry is preferred. On hybrid hosts it is often
+// just "/" while a v1 controller holds the real placement, so the first v1
+// path other than "/" is used instead in that case.
+func parseCgroupFile(data string) (string, bool) {
+	var unified, v1 string
+	haveUnified := false
+	for _, line := range strings.Split(data, "\n") {
+		// hierarchy-ID:controller-list:cgroup-path; the path may contain ':'.
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			unified, haveUnified = parts[2], true
+		case v1 == "" && parts[2] != "/":
+			v1 = parts[2]
+		}
+	}
+	switch {
+	case haveUnified && (unified != "/" || v1 == ""):
+		return unified, true
+	case v1 != "":
+		return v1, true
+	case haveUnified:
+		return unified, true
+	}
+	return "", false
+}
+
+// containerIDFromCgroup extracts a container ID from a cgroup path, or
+// returns "" when none is recognized.
+//
+// It looks for the innermost path segment that is a 64-character hex ID,
+// optionally wrapped by a runtime prefix and systemd suffix. That covers
+// the common layouts:
+//
+//	/docker/<id>
+//	/system.slice/docker-<id>.scope
+//	/kubepods/burstable/pod<uid>/<id>
+//	/kubepods.slice/.../cri-containerd-<id>.scope
+//	/kubepods.slice/.../crio-<id>.scope
+//	/machine.slice/libpod-<id>.scope
+func containerIDFromCgroup(path string) string {
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		s := strings.TrimSuffix(segments[i], ".scope")
+		if j := strings.LastIndexByte(s, '-'); j >= 0 {
+			s = s[j+1:]
+		}
+		if isContainerID(s) {
+			return s
+		}
+	}
+	return ""
+}
+
+func isContainerID(s string) bool {
+	if len(s) != containerIDLen {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// readCgroup fills CgroupPath and ContainerID for p. Fields that cannot be
+// read are left nil.
+func readCgroup(p *ProcessInfo) {
+	path, err := readCgroupPath(p.PID)
+	if err != nil {
+		return
+	}
+	p.CgroupPath = &path
+	if id := containerIDFromCgroup(path); id != "" {
+		p.ContainerID = &id
+	}
+}
+
+// cgroupPathOf returns p.CgroupPath, reading it when the listing did not
+// request it.
+func cgroupPathOf(p *ProcessInfo) (string, bool) {
+	if p.CgroupPath != nil {
+		return *p.CgroupPath, true
+	}
+	path, err := readCgroupPath(p.PID)
+	return path, err == nil
+}
@@ -0,0 +1,21 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"os"
+	"strconv"
+)
+
+// readCgroupPath reads the cgroup path of pid from /proc/<pid>/cgroup.
+func readCgroupPath(pid uint32) (string, error) {
+	data, err := os.ReadFile("/proc/" + strconv.FormatUint(uint64(pid), 10) + "/cgroup")
+	if err != nil {
+		return "", procReadError(pid, err)
+	}
+	path, ok := parseCgroupFile(string(data))
+	if !ok {
+		return "", &Error{Code: ErrSystem, Message: "no cgroup entry for pid " + strconv.FormatUint(uint64(pid), 10)}
+	}
+	return path, nil
+}
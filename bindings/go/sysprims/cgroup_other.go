@@ -0,0 +1,11 @@
+//go:build !linux
+
+package sysprims
+
+import "runtime"
+
+// readCgroupPath is not implemented on this platform; cgroups are
+// Linux-only.
+func readCgroupPath(pid uint32) (string, error) {
+	return "", &Error{Code: ErrNotSupported, Message: "cgroups are not supported on " + runtime.GOOS}
+}
@@ -0,0 +1,142 @@
+package sysprims
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ChildHandle is a child spawned by [SpawnInGroupHandle].
+//
+// The handle owns the child: it reaps it as soon as it exits (so no zombie
+// lingers on Unix) and keeps its exit status for Wait. Callers must not wait
+// on or reap the PID by other means.
+type ChildHandle struct {
+	// SpawnInGroupResult describes the spawn.
+	SpawnInGroupResult
+
+	proc   *os.Process
+	done   chan struct{}
+	result *WaitPidResult
+	err    error
+	// classify fills result.WarningDetails on the first Wait that sees the
+	// exit, so the warnings are logged once, under Wait.
+	classify sync.Once
+}
+
+// SpawnInGroupHandle is like [SpawnInGroup] but returns a handle for
+// managing the child's lifecycle.
+//
+// On Unix the child is a direct child of the calling process, so Wait
+// reports its real exit status rather than polling. On Windows the handle
+// opens the process right after the spawn and reads the exit code from it;
+// the Job Object stays with the library, which TerminateTree uses.
+//
+// # Errors
+//
+//   - Errors from [SpawnInGroup]
+//   - [ErrSystem]: The child could not be opened after spawning
+func SpawnInGroupHandle(config SpawnInGroupConfig) (*ChildHandle, error) {
+	res, err := SpawnInGroup(config)
+	if err != nil {
+		return nil, err
+	}
+	proc, err := os.FindProcess(int(res.PID))
+	if err != nil {
+		return nil, &Error{Code: ErrSystem, Message: "failed to open spawned process " + strconv.FormatUint(uint64(res.PID), 10) + ": " + err.Error()}
+	}
+
+	h := &ChildHandle{SpawnInGroupResult: *res, proc: proc, done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		state, err := proc.Wait()
+		releaseGroupJob(res.PID)
+		if err != nil {
+			h.err = &Error{Code: ErrSystem, Message: "failed to wait for process " + strconv.FormatUint(uint64(res.PID), 10) + ": " + err.Error()}
+			return
+		}
+		h.result = childExitResult(res.PID, state)
+	}()
+	return h, nil
+}
+
+// Done returns a channel that is closed once the child has exited and been
+// reaped.
+func (h *ChildHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait waits up to timeout for the child to exit. A timeout of 0 checks
+// without blocking.
+//
+// If the child is still running, the result has Exited=false and
+// TimedOut=true. Otherwise it carries the exit code; a child killed by a
+// signal has no ExitCode but Signaled, ExitSignal, and a warning naming the
+// signal. Wait may be called any number of times and returns the same exit
+// result each time.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: timeout is negative
+//   - [ErrSystem]: The exit status could not be collected
+func (h *ChildHandle) Wait(timeout time.Duration) (*WaitPidResult, error) {
+	if timeout < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "timeout must be >= 0"}
+	}
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-h.done:
+		case <-timer.C:
+		}
+	}
+	select {
+	case <-h.done:
+		if h.err != nil {
+			return nil, h.err
+		}
+		h.classify.Do(func() {
+			h.result.WarningDetails = warningDetails("ChildHandle.Wait", h.PID, h.result.Warnings)
+		})
+		result := *h.result
+		return &result, nil
+	default:
+		return newWaitPidResult(h.PID, false, true, nil, nil), nil
+	}
+}
+
+// Kill sends signal to the child. Unlike [Kill] it cannot hit an unrelated
+// process that reused the PID: once the child has been reaped it reports
+// ErrNotFound.
+//
+// # Errors
+//
+//   - [ErrNotFound]: The child has exited
+//   - [ErrInvalidArgument]: Invalid signal
+//   - [ErrPermissionDenied]: Not permitted to signal the child
+//   - [ErrNotSupported]: Signal not supported on this platform (see [Kill])
+func (h *ChildHandle) Kill(signal int) error {
+	select {
+	case <-h.done:
+		return h.exitedError()
+	default:
+	}
+	return signalChild(h, signal)
+}
+
+// TerminateTree terminates the child's process group (Unix) or Job Object
+// (Windows) with [TerminateTree], including descendants that outlive the
+// child itself.
+//
+// # Errors
+//
+//   - Errors from [TerminateTree]
+func (h *ChildHandle) TerminateTree(config TerminateTreeConfig) (*TerminateTreeResult, error) {
+	return TerminateTree(h.PID, config)
+}
+
+func (h *ChildHandle) exitedError() error {
+	return &Error{Code: ErrNotFound, Message: "process " + strconv.FormatUint(uint64(h.PID), 10) + " has exited"}
+}
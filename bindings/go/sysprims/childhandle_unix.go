@@ -0,0 +1,37 @@
+//go:build !windows
+
+package sysprims
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// childExitResult converts the state of a reaped child.
+func childExitResult(pid uint32, state *os.ProcessState) *WaitPidResult {
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok {
+		return exitResult(pid, ws)
+	}
+	code := int32(state.ExitCode())
+	return newWaitPidResult(pid, true, false, &code, nil)
+}
+
+// signalChild signals h through os.Process, which refuses once the child
+// has been reaped.
+func signalChild(h *ChildHandle, signal int) error {
+	err := h.proc.Signal(syscall.Signal(signal))
+	var errno syscall.Errno
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrProcessDone):
+		return h.exitedError()
+	case errors.As(err, &errno) && errno == syscall.EINVAL:
+		return &Error{Code: ErrInvalidArgument, Message: "invalid signal"}
+	case errors.As(err, &errno):
+		return errnoError(h.PID, errno)
+	default:
+		return &Error{Code: ErrSystem, Message: err.Error()}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package sysprims
+
+import "os"
+
+// childExitResult converts the state of an exited child.
+func childExitResult(pid uint32, state *os.ProcessState) *WaitPidResult {
+	code := int32(state.ExitCode())
+	return newWaitPidResult(pid, true, false, &code, nil)
+}
+
+// signalChild signals h with [Kill]. The open process handle keeps the PID
+// from being reused while the child runs.
+func signalChild(h *ChildHandle, signal int) error {
+	return Kill(h.PID, signal)
+}
@@ -0,0 +1,261 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listConnections reads the /proc/net tcp and udp tables, keeps the
+// connections accepted by keep, and attributes them to PIDs.
+func listConnections(keep func(c *Connection) bool) ([]Connection, []string, error) {
+	var conns []Connection
+	warnings, err := scanProcNetSockets(func(protocol Protocol, fields []string, inode uint64) bool {
+		info, err := parseProcNetFields(fields, protocol)
+		if err != nil {
+			return false
+		}
+		c := Connection{
+			Protocol:   info.Protocol,
+			LocalAddr:  info.LocalAddr,
+			LocalPort:  info.LocalPort,
+			RemoteAddr: info.RemoteAddr,
+			RemotePort: info.RemotePort,
+			State:      info.State,
+			Inode:      inode,
+		}
+		if keep(&c) {
+			conns = append(conns, c)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	wanted := make(map[uint64]*uint32)
+	for i := range conns {
+		if conns[i].Inode != 0 {
+			wanted[conns[i].Inode] = nil
+		}
+	}
+	if len(wanted) > 0 {
+		warnings = append(warnings, mapSocketOwners(wanted)...)
+		for i := range conns {
+			conns[i].PID = wanted[conns[i].Inode]
+		}
+	}
+	return conns, warnings, nil
+}
+
+// scanProcNetSockets calls visit with the fields and inode of every line of
+// the /proc/net tcp and udp tables. visit returns false for a line it could
+// not parse, which is counted in a warning.
+func scanProcNetSockets(visit func(protocol Protocol, fields []string, inode uint64) bool) ([]string, error) {
+	var warnings []string
+	readable := 0
+
+	for _, t := range procNetTables {
+		path := "/proc/net/" + t.name
+		f, err := os.Open(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				warnings = append(warnings, fmt.Sprintf("failed to read %s: %v", path, err))
+			}
+			continue
+		}
+		readable++
+		malformed := 0
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // header
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 {
+				continue
+			}
+			inode, err := strconv.ParseUint(fields[9], 10, 64)
+			if err != nil || !visit(t.protocol, fields, inode) {
+				malformed++
+			}
+		}
+		_ = f.Close()
+		if malformed > 0 {
+			warnings = append(warnings, fmt.Sprintf("skipped %d malformed entries in %s", malformed, path))
+		}
+		if err := scanner.Err(); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to read %s: %v", path, err))
+		}
+	}
+	if readable == 0 {
+		return warnings, &Error{Code: ErrSystem, Message: "no /proc/net socket table is readable"}
+	}
+	return warnings, nil
+}
+
+// summarizeConnections counts the sockets in the /proc/net tables toward
+// total: every socket when all is set, otherwise only those whose inode is
+// in owners. Owned sockets are also counted toward their owner's entry in
+// byPID, when byPID is not nil. Lines are never parsed beyond protocol and
+// state.
+func summarizeConnections(owners map[uint64]uint32, all bool, total *ConnCounts, byPID map[uint32]*ConnCounts) ([]string, error) {
+	return scanProcNetSockets(func(protocol Protocol, fields []string, inode uint64) bool {
+		pid, owned := owners[inode]
+		owned = owned && inode != 0
+		if !owned && !all {
+			return true
+		}
+		state := ""
+		if protocol == ProtocolTCP {
+			state = tcpStates[fields[3]]
+		}
+		total.add(protocol, state)
+		if owned && byPID != nil {
+			counts := byPID[pid]
+			if counts == nil {
+				counts = newConnCounts()
+				byPID[pid] = counts
+			}
+			counts.add(protocol, state)
+		}
+		return true
+	})
+}
+
+// socketInodesOf returns the socket inodes open in pid, mapped to pid.
+func socketInodesOf(pid uint32, into map[uint64]uint32) error {
+	dir := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/fd/"
+	return walkFdDir(pid, func(names []string) {
+		for _, name := range names {
+			target, err := os.Readlink(dir + name)
+			if err != nil {
+				continue
+			}
+			if inode, ok := parseSocketInode(target); ok {
+				if owner, seen := into[inode]; !seen || pid < owner {
+					into[inode] = pid
+				}
+			}
+		}
+	})
+}
+
+// connectionSummary implements ConnectionSummary.
+func connectionSummary(pid uint32) (*ConnSummary, error) {
+	owners := make(map[uint64]uint32)
+	if err := socketInodesOf(pid, owners); err != nil {
+		return nil, err
+	}
+	summary := newConnSummary()
+	warnings, err := summarizeConnections(owners, false, &summary.ConnCounts, nil)
+	if err != nil {
+		return nil, err
+	}
+	summary.Warnings = append(summary.Warnings, warnings...)
+	return summary, nil
+}
+
+// connectionSummaryAll implements ConnectionSummaryAll for the sockets of
+// pids, or for every socket when all is set.
+func connectionSummaryAll(pids []uint32, all bool) (*ConnSummary, error) {
+	summary := newConnSummary()
+	summary.ByPID = make(map[uint32]*ConnCounts)
+
+	owners := make(map[uint64]uint32)
+	var permissionDenied, readErrors int
+	for _, pid := range pids {
+		err := socketInodesOf(pid, owners)
+		var sErr *Error
+		if errors.As(err, &sErr) {
+			switch sErr.Code {
+			case ErrNotFound:
+			case ErrPermissionDenied:
+				permissionDenied++
+			default:
+				readErrors++
+			}
+		}
+	}
+	if permissionDenied > 0 {
+		summary.Warnings = append(summary.Warnings, fmt.Sprintf("Skipped %d pid entries due to permission errors", permissionDenied))
+	}
+	if readErrors > 0 {
+		summary.Warnings = append(summary.Warnings, fmt.Sprintf("Skipped %d pid entries due to read errors", readErrors))
+	}
+
+	warnings, err := summarizeConnections(owners, all, &summary.ConnCounts, summary.ByPID)
+	if err != nil {
+		return nil, err
+	}
+	summary.Warnings = append(summary.Warnings, warnings...)
+	return summary, nil
+}
+
+// mapSocketOwners fills wanted with the PID holding each socket inode by
+// scanning /proc/<pid>/fd, and returns warnings for processes that could
+// not be read. A socket shared by several processes gets the lowest PID.
+func mapSocketOwners(wanted map[uint64]*uint32) []string {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return []string{fmt.Sprintf("failed to map socket inodes to PIDs: %v", err)}
+	}
+
+	var permissionDenied, readErrors int
+	remaining := len(wanted)
+	// ReadDir sorts by name, not number, so keep the lowest PID explicitly.
+	for _, e := range entries {
+		n, err := strconv.ParseUint(e.Name(), 10, 32)
+		if err != nil || n == 0 {
+			continue
+		}
+		pid := uint32(n)
+		dir := "/proc/" + e.Name() + "/fd/"
+		err = walkFdDir(pid, func(names []string) {
+			for _, name := range names {
+				target, err := os.Readlink(dir + name)
+				if err != nil {
+					continue
+				}
+				inode, ok := parseSocketInode(target)
+				if !ok {
+					continue
+				}
+				owner, want := wanted[inode]
+				if !want {
+					continue
+				}
+				if owner == nil {
+					remaining--
+					p := pid
+					wanted[inode] = &p
+				} else if pid < *owner {
+					*owner = pid
+				}
+			}
+		})
+		var sErr *Error
+		if errors.As(err, &sErr) {
+			switch sErr.Code {
+			case ErrNotFound:
+			case ErrPermissionDenied:
+				permissionDenied++
+			default:
+				readErrors++
+			}
+		}
+	}
+
+	var warnings []string
+	if permissionDenied > 0 && remaining > 0 {
+		warnings = append(warnings, fmt.Sprintf("Skipped %d pid entries due to permission errors", permissionDenied))
+	}
+	if readErrors > 0 && remaining > 0 {
+		warnings = append(warnings, fmt.Sprintf("Skipped %d pid entries due to read errors", readErrors))
+	}
+	return warnings
+}
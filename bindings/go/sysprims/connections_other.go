@@ -0,0 +1,17 @@
+//go:build !linux
+
+package sysprims
+
+import "runtime"
+
+func listConnections(keep func(c *Connection) bool) ([]Connection, []string, error) {
+	return nil, nil, &Error{Code: ErrNotSupported, Message: "connection listing is not supported on " + runtime.GOOS}
+}
+
+func connectionSummary(pid uint32) (*ConnSummary, error) {
+	return nil, &Error{Code: ErrNotSupported, Message: "connection listing is not supported on " + runtime.GOOS}
+}
+
+func connectionSummaryAll(pids []uint32, all bool) (*ConnSummary, error) {
+	return nil, &Error{Code: ErrNotSupported, Message: "connection listing is not supported on " + runtime.GOOS}
+}
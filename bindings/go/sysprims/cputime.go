@@ -0,0 +1,62 @@
+package sysprims
+
+import "time"
+
+// cpuTimeNS returns the cumulative user+system CPU time of pid in nanoseconds.
+func cpuTimeNS(pid uint32) (uint64, error) {
+	user, system, err := cpuTimesNS(pid)
+	return user + system, err
+}
+
+// readCPUTimes fills CPUUserTimeMS and CPUSystemTimeMS for p. They are left
+// nil when the times cannot be read.
+func readCPUTimes(p *ProcessInfo) {
+	user, system, err := cpuTimesNS(p.PID)
+	if err != nil {
+		return
+	}
+	userMS, systemMS := user/1_000_000, system/1_000_000
+	p.CPUUserTimeMS = &userMS
+	p.CPUSystemTimeMS = &systemMS
+}
+
+// CPUUsage measures the CPU usage of pid over sample and returns it as a
+// percentage of the elapsed wall time; as with [CpuModeMonitor], it may
+// exceed 100 on multi-core. It is [MonitorCPU] reduced to the percentage,
+// with the process's identity checked around the sample so that an exit or
+// PID reuse is an error rather than a misleading 0.
+//
+// Unlike the lifetime CPUPercent of [ProcessGet], the result reflects only
+// the sample window. The call blocks for sample.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or sample is not positive
+//   - [ErrNotFound]: Process doesn't exist, or exited (or its PID was
+//     reused) during the sample
+//   - [ErrPermissionDenied]: Not permitted to read this process
+func CPUUsage(pid uint32, sample time.Duration) (float64, error) {
+	before, err := ProcessGet(pid)
+	if err != nil {
+		return 0, err
+	}
+	result, err := MonitorCPU(pid, sample)
+	if err != nil {
+		return 0, err
+	}
+	exited := &Error{Code: ErrNotFound, Message: "process exited during sample"}
+	if result.ExitedDuringSample {
+		return 0, exited
+	}
+	after, err := ProcessGet(pid)
+	if err != nil {
+		if asError(err).Code == ErrNotFound {
+			return 0, exited
+		}
+		return 0, err
+	}
+	if after.IsZombie() || !sameStart(before.StartTimeUnixMS, after.StartTimeUnixMS) {
+		return 0, exited
+	}
+	return result.CPUPercent, nil
+}
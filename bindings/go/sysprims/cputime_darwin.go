@@ -0,0 +1,50 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <mach/mach_time.h>
+#include <sys/proc_info.h>
+
+static int sysprims_go_task_times(int pid, uint64_t *user, uint64_t *system) {
+	struct proc_taskinfo ti;
+	int n = proc_pidinfo(pid, PROC_PIDTASKINFO, 0, &ti, sizeof(ti));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	if (n < (int)sizeof(ti)) {
+		return EIO;
+	}
+	*user = ti.pti_total_user;
+	*system = ti.pti_total_system;
+	return 0;
+}
+
+static void sysprims_go_timebase(uint32_t *numer, uint32_t *denom) {
+	mach_timebase_info_data_t tb;
+	mach_timebase_info(&tb);
+	*numer = tb.numer;
+	*denom = tb.denom;
+}
+*/
+import "C"
+
+import "syscall"
+
+// cpuTimesNS returns the cumulative user and system CPU time of pid in
+// nanoseconds. Task info reports mach absolute time units.
+func cpuTimesNS(pid uint32) (user, system uint64, err error) {
+	var u, s C.uint64_t
+	if rc := C.sysprims_go_task_times(C.int(pid), &u, &s); rc != 0 {
+		return 0, 0, errnoError(pid, syscall.Errno(rc))
+	}
+
+	var numer, denom C.uint32_t
+	C.sysprims_go_timebase(&numer, &denom)
+	if denom == 0 {
+		return uint64(u), uint64(s), nil
+	}
+	return uint64(u) * uint64(numer) / uint64(denom), uint64(s) * uint64(numer) / uint64(denom), nil
+}
@@ -0,0 +1,101 @@
+//go:build linux
+
+package sysprims
+
+/*
+#include <unistd.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// clockTicks is the kernel USER_HZ used for /proc/<pid>/stat time fields.
+var clockTicks = uint64(C.sysconf(C._SC_CLK_TCK))
+
+// cpuTimesNS returns the cumulative user and system CPU time of pid in
+// nanoseconds, from utime and stime in /proc/<pid>/stat.
+func cpuTimesNS(pid uint32) (user, system uint64, err error) {
+	path := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/stat"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, procReadError(pid, err)
+	}
+
+	_, fields, err := parseProcStat(path, data)
+	if err != nil {
+		return 0, 0, err
+	}
+	utime, stime, err := statCPUTimes(path, fields)
+	if err != nil {
+		return 0, 0, err
+	}
+	nsPerTick := 1_000_000_000 / userHZ()
+	return utime * nsPerTick, stime * nsPerTick, nil
+}
+
+// parseProcStat splits a /proc/<pid>/stat (or task stat) line into comm and
+// the fields after it; fields[0] is the state character.
+func parseProcStat(path string, data []byte) (comm string, fields []string, err error) {
+	// comm may contain spaces and parentheses; fields start after the last ')'.
+	stat := string(data)
+	start := strings.IndexByte(stat, '(')
+	end := strings.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return "", nil, &Error{Code: ErrSystem, Message: "malformed " + path}
+	}
+	fields = strings.Fields(stat[end+1:])
+	if len(fields) < 20 {
+		return "", nil, &Error{Code: ErrSystem, Message: "malformed " + path}
+	}
+	return stat[start+1 : end], fields, nil
+}
+
+// statCPUTicks returns utime+stime from parsed stat fields, in clock ticks.
+func statCPUTicks(path string, fields []string) (uint64, error) {
+	utime, stime, err := statCPUTimes(path, fields)
+	return utime + stime, err
+}
+
+// statCPUTimes returns utime and stime from parsed stat fields, in clock
+// ticks.
+func statCPUTimes(path string, fields []string) (utime, stime uint64, err error) {
+	utime, err = strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, 0, &Error{Code: ErrSystem, Message: "malformed utime in " + path}
+	}
+	stime, err = strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, 0, &Error{Code: ErrSystem, Message: "malformed stime in " + path}
+	}
+	return utime, stime, nil
+}
+
+// userHZ returns clockTicks, defaulting to the common value of 100.
+func userHZ() uint64 {
+	if clockTicks == 0 {
+		return 100
+	}
+	return clockTicks
+}
+
+// procReadError maps a /proc read failure to a sysprims error.
+func procReadError(pid uint32, err error) error {
+	var errno syscall.Errno
+	errors.As(err, &errno)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return &Error{Code: ErrNotFound, Message: "process " + strconv.FormatUint(uint64(pid), 10) + " not found"}
+	case errors.Is(err, fs.ErrPermission):
+		return (&Error{Code: ErrPermissionDenied, Message: err.Error()}).withErrno(errno)
+	default:
+		return (&Error{Code: ErrSystem, Message: err.Error()}).withErrno(errno)
+	}
+}
@@ -0,0 +1,53 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"strconv"
+	"syscall"
+)
+
+const processQueryLimitedInformation = 0x1000
+
+// cpuTimesNS returns the cumulative user and kernel CPU time of pid in
+// nanoseconds.
+func cpuTimesNS(pid uint32) (user, system uint64, err error) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return 0, 0, winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var creation, exit, kernel, userTime syscall.Filetime
+	if err := syscall.GetProcessTimes(h, &creation, &exit, &kernel, &userTime); err != nil {
+		return 0, 0, systemError(err)
+	}
+
+	// FILETIME durations are in 100ns units.
+	return filetimeTicks(userTime) * 100, filetimeTicks(kernel) * 100, nil
+}
+
+func filetimeTicks(ft syscall.Filetime) uint64 {
+	return uint64(ft.HighDateTime)<<32 | uint64(ft.LowDateTime)
+}
+
+// winProcessError maps an OpenProcess failure to a sysprims error.
+func winProcessError(pid uint32, err error) error {
+	switch err {
+	case syscall.ERROR_ACCESS_DENIED:
+		return (&Error{Code: ErrPermissionDenied, Message: err.Error()}).withErrno(syscall.ERROR_ACCESS_DENIED)
+	case syscall.Errno(87): // ERROR_INVALID_PARAMETER: no such process
+		return &Error{Code: ErrNotFound, Message: "process " + strconv.FormatUint(uint64(pid), 10) + " not found"}
+	default:
+		return systemError(err)
+	}
+}
+
+// systemError maps a Win32 API failure to ErrSystem, keeping the error code.
+func systemError(err error) error {
+	sErr := &Error{Code: ErrSystem, Message: err.Error()}
+	if errno, ok := err.(syscall.Errno); ok {
+		sErr.withErrno(errno)
+	}
+	return sErr
+}
@@ -0,0 +1,147 @@
+package sysprims
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultCSVColumns is the column set WriteCSV uses when columns is nil.
+var DefaultCSVColumns = []string{"pid", "ppid", "name", "user", "state", "cpu_percent", "memory_kb", "elapsed_seconds"}
+
+// csvColumns maps column names, which match the JSON field names, to cell
+// formatters. Unset optional fields format as "".
+var csvColumns = map[string]func(p *ProcessInfo) string{
+	"pid":                func(p *ProcessInfo) string { return strconv.FormatUint(uint64(p.PID), 10) },
+	"ppid":               func(p *ProcessInfo) string { return strconv.FormatUint(uint64(p.PPID), 10) },
+	"name":               func(p *ProcessInfo) string { return p.Name },
+	"user":               func(p *ProcessInfo) string { return csvString(p.User) },
+	"cpu_percent":        func(p *ProcessInfo) string { return strconv.FormatFloat(p.CPUPercent, 'f', -1, 64) },
+	"memory_kb":          func(p *ProcessInfo) string { return strconv.FormatUint(p.MemoryKB, 10) },
+	"elapsed_seconds":    func(p *ProcessInfo) string { return csvUint64(p.ElapsedSeconds) },
+	"start_time_unix_ms": func(p *ProcessInfo) string { return csvUint64(p.StartTimeUnixMS) },
+	"exe_path":           func(p *ProcessInfo) string { return csvString(p.ExePath) },
+	"state":              func(p *ProcessInfo) string { return csvString(p.State) },
+	"raw_state":          func(p *ProcessInfo) string { return csvString(p.RawState) },
+	"cmdline":            func(p *ProcessInfo) string { return strings.Join(p.Cmdline, " ") },
+	"thread_count":       func(p *ProcessInfo) string { return csvUint32(p.ThreadCount) },
+	"open_fd_count":      func(p *ProcessInfo) string { return csvUint32(p.OpenFdCount) },
+	"oom_score":          func(p *ProcessInfo) string { return csvInt32(p.OomScore) },
+	"oom_score_adj":      func(p *ProcessInfo) string { return csvInt32(p.OomScoreAdj) },
+	"nice": func(p *ProcessInfo) string {
+		if p.Nice == nil {
+			return ""
+		}
+		return strconv.Itoa(*p.Nice)
+	},
+	"cpu_user_time_ms":         func(p *ProcessInfo) string { return csvUint64(p.CPUUserTimeMS) },
+	"cpu_system_time_ms":       func(p *ProcessInfo) string { return csvUint64(p.CPUSystemTimeMS) },
+	"cgroup_path":              func(p *ProcessInfo) string { return csvString(p.CgroupPath) },
+	"container_id":             func(p *ProcessInfo) string { return csvString(p.ContainerID) },
+	"tty":                      func(p *ProcessInfo) string { return csvString(p.TTY) },
+	"uid":                      func(p *ProcessInfo) string { return csvUint32(p.UID) },
+	"euid":                     func(p *ProcessInfo) string { return csvUint32(p.EUID) },
+	"gid":                      func(p *ProcessInfo) string { return csvUint32(p.GID) },
+	"egid":                     func(p *ProcessInfo) string { return csvUint32(p.EGID) },
+	"voluntary_ctx_switches":   func(p *ProcessInfo) string { return csvUint64(p.VoluntaryCtxSwitches) },
+	"involuntary_ctx_switches": func(p *ProcessInfo) string { return csvUint64(p.InvoluntaryCtxSwitches) },
+	"minor_faults":             func(p *ProcessInfo) string { return csvUint64(p.MinorFaults) },
+	"major_faults":             func(p *ProcessInfo) string { return csvUint64(p.MajorFaults) },
+	"memory.rss_kb": func(p *ProcessInfo) string {
+		if p.Memory == nil {
+			return ""
+		}
+		return strconv.FormatUint(p.Memory.RSSKB, 10)
+	},
+	"memory.vsz_kb": func(p *ProcessInfo) string {
+		if p.Memory == nil {
+			return ""
+		}
+		return strconv.FormatUint(p.Memory.VSZKB, 10)
+	},
+	"memory.shared_kb": func(p *ProcessInfo) string {
+		if p.Memory == nil {
+			return ""
+		}
+		return csvUint64(p.Memory.SharedKB)
+	},
+	"memory.swap_kb": func(p *ProcessInfo) string {
+		if p.Memory == nil {
+			return ""
+		}
+		return csvUint64(p.Memory.SwapKB)
+	},
+}
+
+// WriteCSV writes s to w as CSV: a header row of column names, then one row
+// per process.
+//
+// Column names match the JSON field names of [ProcessInfo]; every field but
+// env is supported. The fields of memory are selected individually with
+// dotted names: memory.rss_kb, memory.vsz_kb, memory.shared_kb, and
+// memory.swap_kb. Nil columns selects [DefaultCSVColumns]. Unset optional
+// fields are written as empty cells, and cmdline is joined with spaces.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: columns is empty or names an unknown column
+//   - Any error returned by w
+func (s *ProcessSnapshot) WriteCSV(w io.Writer, columns []string) error {
+	if columns == nil {
+		columns = DefaultCSVColumns
+	}
+	if len(columns) == 0 {
+		return &Error{Code: ErrInvalidArgument, Message: "no CSV columns selected"}
+	}
+	cells := make([]func(p *ProcessInfo) string, len(columns))
+	for i, name := range columns {
+		cell, ok := csvColumns[name]
+		if !ok {
+			return &Error{Code: ErrInvalidArgument, Message: "unknown CSV column: " + name}
+		}
+		cells[i] = cell
+	}
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(columns)
+	row := make([]string, len(columns))
+	for i := range s.Processes {
+		for j, cell := range cells {
+			row[j] = cell(&s.Processes[i])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func csvUint64(v *uint64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatUint(*v, 10)
+}
+
+func csvUint32(v *uint32) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*v), 10)
+}
+
+func csvInt32(v *int32) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(*v), 10)
+}
@@ -0,0 +1,77 @@
+package sysprims
+
+import (
+	"sort"
+	"strings"
+)
+
+// deletedSuffix is appended by the Linux kernel to /proc/<pid>/fd link
+// targets whose file has been unlinked.
+const deletedSuffix = " (deleted)"
+
+// DeletedFileHolder is a process holding an unlinked file open. The file's
+// disk space is not reclaimed until every such fd is closed.
+type DeletedFileHolder struct {
+	PID  uint32 `json:"pid"`
+	Name string `json:"name"`
+	// Path is the file's former path, without the " (deleted)" suffix.
+	Path string `json:"path"`
+	// Fds lists the holder's fds open on the file, ascending.
+	Fds []uint32 `json:"fds"`
+	// SizeBytes is the file size, best-effort.
+	SizeBytes *uint64 `json:"size_bytes,omitempty"`
+}
+
+// DeletedOpenFiles scans the processes matching filter for fds open on
+// unlinked files, returning one entry per process and path, sorted by PID
+// then path.
+//
+// Processes whose fds cannot be listed (permission denied, exited during the
+// scan) are skipped. Detection is supported on Linux and, for same-user
+// processes, macOS; elsewhere the result is empty.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Invalid filter
+//   - [ErrSystem]: System error listing processes
+func DeletedOpenFiles(filter *ProcessFilter) ([]DeletedFileHolder, error) {
+	snapshot, err := ProcessList(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := "file"
+	fdFilter := &FdFilter{Kind: &kind, OnlyDeleted: true, IncludeOffsets: true}
+	var holders []DeletedFileHolder
+	for _, p := range snapshot.Processes {
+		fds, err := listFdsResolved(p.PID, fdFilter)
+		if err != nil {
+			continue
+		}
+		byPath := make(map[string]int)
+		for _, fd := range fds.Fds {
+			if fd.Path == nil {
+				continue
+			}
+			path := strings.TrimSuffix(*fd.Path, deletedSuffix)
+			i, ok := byPath[path]
+			if !ok {
+				i = len(holders)
+				byPath[path] = i
+				holders = append(holders, DeletedFileHolder{PID: p.PID, Name: p.Name, Path: path})
+			}
+			holders[i].Fds = append(holders[i].Fds, fd.Fd)
+			if holders[i].SizeBytes == nil && fd.FileSizeBytes != nil {
+				holders[i].SizeBytes = fd.FileSizeBytes
+			}
+		}
+	}
+
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].PID != holders[j].PID {
+			return holders[i].PID < holders[j].PID
+		}
+		return holders[i].Path < holders[j].Path
+	})
+	return holders, nil
+}
@@ -0,0 +1,290 @@
+package sysprims
+
+import (
+	"fmt"
+	"time"
+)
+
+// DescendantsOf returns the union of the process subtrees rooted at pids.
+//
+// A process under more than one root (because one root descends from
+// another) is reported once, at its depth below the nearest root, and
+// TotalFound counts it once. RootPIDs lists the deduplicated roots and
+// RootsByPID records, for each reported process, every root whose subtree
+// (within MaxLevels) contains it, nearest first. A root that descends from
+// another root is itself reported. RootPID is set only for a single root.
+// With IncludeRoot, level 0 holds every root, and a root that descends from
+// another is reported there rather than at its depth.
+//
+// Traversal runs in the Go bindings over one process snapshot, and honors
+// every DescendantsOptions field. opts may be nil.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pids is empty or holds 0, filter/config is
+//     invalid, MaxTotal is 0, or Timeout is negative
+//   - [ErrNotFound]: a root process doesn't exist
+func DescendantsOf(pids []uint32, opts *DescendantsOptions) (*DescendantsResult, error) {
+	if err := validatePidList(pids); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &DescendantsOptions{}
+	}
+	roots := make([]uint32, 0, len(pids))
+	seen := make(map[uint32]bool, len(pids))
+	for _, pid := range pids {
+		if !seen[pid] {
+			seen[pid] = true
+			roots = append(roots, pid)
+		}
+	}
+	return descendantsWalk(roots, opts, true)
+}
+
+// descendantsBounded implements DescendantsWithOptions when MaxTotal,
+// Timeout, or IncludeRoot is set, which the library cannot provide.
+func descendantsBounded(pid uint32, opts *DescendantsOptions) (*DescendantsResult, error) {
+	return descendantsWalk([]uint32{pid}, opts, false)
+}
+
+// descendantsWalk mirrors the library: verify the roots, take one snapshot,
+// walk it breadth-first from all roots at once, then filter. The deadline
+// starts before the roots are verified; if it passes before the snapshot is
+// ready, the result is empty with TimedOut set. The walk checks the cap and
+// the deadline per process and stops with what it has collected. With
+// tagRoots set it also fills RootPIDs and RootsByPID.
+func descendantsWalk(roots []uint32, opts *DescendantsOptions, tagRoots bool) (*DescendantsResult, error) {
+	if opts.MaxTotal != nil && *opts.MaxTotal == 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "max_total must be > 0"}
+	}
+	if opts.Timeout < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "timeout must be >= 0"}
+	}
+	mode, err := normalizeCpuMode(opts.CpuMode)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Filter.validateLibrary(); err != nil {
+		return nil, err
+	}
+	if err := opts.Filter.validateGo(); err != nil {
+		return nil, err
+	}
+	maxLevels := ^uint32(0)
+	if opts.MaxLevels != nil && *opts.MaxLevels != 0 {
+		maxLevels = *opts.MaxLevels
+	}
+
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+	expired := func() bool { return !deadline.IsZero() && time.Now().After(deadline) }
+
+	var rootPID uint32
+	if len(roots) == 1 {
+		rootPID = roots[0]
+	}
+	op := "Descendants"
+	if tagRoots {
+		op = "DescendantsOf"
+	}
+	result := &DescendantsResult{
+		SchemaID:  SchemaDescendantsResultV1,
+		RootPID:   rootPID,
+		MaxLevels: maxLevels,
+		Levels:    []DescendantsLevel{},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Platform:  Platform(),
+	}
+	if mode == CpuModeMonitor {
+		result.SchemaID = SchemaDescendantsResultSampledV1
+	}
+	// timedOut returns result empty when the deadline passes before the
+	// traversal starts.
+	timedOut := func() (*DescendantsResult, error) {
+		result.TimedOut = true
+		result.Warnings = append(result.Warnings, fmt.Sprintf("traversal timed out after %s before the process snapshot completed", opts.Timeout))
+		if tagRoots {
+			result.RootPIDs = roots
+			result.RootsByPID = map[uint32][]uint32{}
+		}
+		result.WarningDetails = warningDetails(op, rootPID, result.Warnings)
+		return result, nil
+	}
+
+	for _, pid := range roots {
+		if _, err := ProcessGet(pid); err != nil {
+			return nil, err
+		}
+	}
+	// A monitor-mode sample that cannot finish before the deadline is not
+	// started.
+	sample := opts.SampleDuration
+	if sample == 0 {
+		sample = defaultSampleDuration
+	}
+	if expired() || (mode == CpuModeMonitor && !deadline.IsZero() && time.Until(deadline) < sample) {
+		return timedOut()
+	}
+	snapshot, err := ProcessListWithOptions(nil, &ProcessOptions{CpuMode: mode, SampleDuration: opts.SampleDuration})
+	if err != nil {
+		return nil, err
+	}
+	if expired() {
+		return timedOut()
+	}
+
+	children := make(map[uint32][]int)
+	for i := range snapshot.Processes {
+		p := &snapshot.Processes[i]
+		children[p.PPID] = append(children[p.PPID], i)
+	}
+
+	// A process is reported once; a parent is expanded once. Roots start
+	// expanded but can still be reported under another root, unless
+	// IncludeRoot reports them at level 0.
+	reported := make(map[uint32]bool)
+	expanded := make(map[uint32]bool, len(roots))
+	for _, pid := range roots {
+		expanded[pid] = true
+	}
+	var rootLevel DescendantsLevel
+	if opts.IncludeRoot {
+		index := make(map[uint32]int, len(snapshot.Processes))
+		for i := range snapshot.Processes {
+			index[snapshot.Processes[i].PID] = i
+		}
+		for _, pid := range roots {
+			i, ok := index[pid]
+			if !ok {
+				// Exited between ProcessGet and the snapshot.
+				return nil, &Error{Code: ErrNotFound, Message: fmt.Sprintf("process %d not found", pid)}
+			}
+			reported[pid] = true
+			rootLevel.Processes = append(rootLevel.Processes, snapshot.Processes[i])
+		}
+	}
+	current := roots
+walk:
+	for depth := uint32(1); depth <= maxLevels && len(current) > 0; depth++ {
+		level := DescendantsLevel{Level: depth}
+		var next []uint32
+		for _, parent := range current {
+			for _, i := range children[parent] {
+				child := snapshot.Processes[i].PID
+				if reported[child] {
+					continue
+				}
+				if expired() {
+					result.TimedOut = true
+				} else if opts.MaxTotal != nil && uint32(result.TotalFound) >= *opts.MaxTotal {
+					result.Truncated = true
+				}
+				if result.TimedOut || result.Truncated {
+					if len(level.Processes) > 0 {
+						result.Levels = append(result.Levels, level)
+					}
+					break walk
+				}
+				reported[child] = true
+				level.Processes = append(level.Processes, snapshot.Processes[i])
+				if !expanded[child] {
+					expanded[child] = true
+					next = append(next, child)
+				}
+				result.TotalFound++
+			}
+		}
+		if len(level.Processes) == 0 {
+			break
+		}
+		result.Levels = append(result.Levels, level)
+		current = next
+		if depth == ^uint32(0) {
+			break
+		}
+	}
+
+	if result.Truncated {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("traversal truncated at max_total %d descendants", *opts.MaxTotal))
+	}
+	if result.TimedOut {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("traversal timed out after %s with %d descendants collected", opts.Timeout, result.TotalFound))
+	}
+
+	if opts.IncludeRoot {
+		result.Levels = append([]DescendantsLevel{rootLevel}, result.Levels...)
+		result.TotalFound += len(rootLevel.Processes)
+	}
+
+	if tagRoots {
+		result.RootPIDs = roots
+		result.RootsByPID = rootsByPID(snapshot.Processes, roots, result.Levels, maxLevels)
+	}
+
+	result.MatchedByFilter = result.TotalFound
+	if opts.Filter != nil {
+		matched := 0
+		levels := result.Levels[:0]
+		for _, level := range result.Levels {
+			if opts.IncludeRoot && level.Level == 0 {
+				matched += len(level.Processes)
+				levels = append(levels, level)
+				continue
+			}
+			kept := level.Processes[:0]
+			for _, p := range level.Processes {
+				if opts.Filter.matchesLibrary(&p) && opts.Filter.matchesGo(&p) {
+					kept = append(kept, p)
+				} else if result.RootsByPID != nil {
+					delete(result.RootsByPID, p.PID)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			level.Processes = kept
+			matched += len(kept)
+			levels = append(levels, level)
+		}
+		result.Levels = levels
+		result.MatchedByFilter = matched
+	}
+	result.WarningDetails = warningDetails(op, rootPID, result.Warnings)
+
+	return result, nil
+}
+
+// rootsByPID maps each process in levels to the roots within maxLevels
+// above it, nearest first, by following PPID links through processes.
+func rootsByPID(processes []ProcessInfo, roots []uint32, levels []DescendantsLevel, maxLevels uint32) map[uint32][]uint32 {
+	isRoot := make(map[uint32]bool, len(roots))
+	for _, pid := range roots {
+		isRoot[pid] = true
+	}
+	parent := make(map[uint32]uint32, len(processes))
+	for i := range processes {
+		parent[processes[i].PID] = processes[i].PPID
+	}
+
+	out := make(map[uint32][]uint32)
+	for _, level := range levels {
+		for _, p := range level.Processes {
+			// The step bound guards against PPID cycles in a racy snapshot.
+			ancestor := p.PPID
+			for dist := uint32(1); dist <= maxLevels && dist <= uint32(len(processes)); dist++ {
+				if isRoot[ancestor] {
+					out[p.PID] = append(out[p.PID], ancestor)
+				}
+				next, ok := parent[ancestor]
+				if !ok || next == ancestor {
+					break
+				}
+				ancestor = next
+			}
+		}
+	}
+	return out
+}
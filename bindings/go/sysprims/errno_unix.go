@@ -0,0 +1,32 @@
+//go:build !windows
+
+package sysprims
+
+import (
+	"strconv"
+	"syscall"
+)
+
+// isTransientErrno reports whether errno typically clears on retry
+// (resource exhaustion or interruption rather than a hard failure).
+func isTransientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case syscall.EAGAIN, syscall.EINTR, syscall.EMFILE, syscall.ENFILE,
+		syscall.ENOMEM, syscall.ENOBUFS, syscall.EBUSY:
+		return true
+	default:
+		return false
+	}
+}
+
+// errnoError maps an errno from a per-PID query to a sysprims error.
+func errnoError(pid uint32, errno syscall.Errno) error {
+	switch errno {
+	case syscall.ESRCH:
+		return &Error{Code: ErrNotFound, Message: "process " + strconv.FormatUint(uint64(pid), 10) + " not found"}
+	case syscall.EPERM, syscall.EACCES:
+		return (&Error{Code: ErrPermissionDenied, Message: errno.Error()}).withErrno(errno)
+	default:
+		return (&Error{Code: ErrSystem, Message: errno.Error()}).withErrno(errno)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package sysprims
+
+import "syscall"
+
+// isTransientErrno reports whether a Win32 error code typically clears on
+// retry (resource exhaustion or a busy resource rather than a hard failure).
+func isTransientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case syscall.Errno(4), // ERROR_TOO_MANY_OPEN_FILES
+		syscall.Errno(8),    // ERROR_NOT_ENOUGH_MEMORY
+		syscall.Errno(14),   // ERROR_OUTOFMEMORY
+		syscall.Errno(170),  // ERROR_BUSY
+		syscall.Errno(1450): // ERROR_NO_SYSTEM_RESOURCES
+		return true
+	default:
+		return false
+	}
+}
@@ -4,7 +4,12 @@ package sysprims
 #include "sysprims.h"
 */
 import "C"
-import "runtime"
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
 
 // ErrorCode represents sysprims FFI error codes.
 //
@@ -78,6 +83,12 @@ type Error struct {
 	Code ErrorCode
 	// Message is a detailed error message from the library.
 	Message string
+	// Errno is the OS error number (errno on Unix, GetLastError on Windows)
+	// behind an [ErrSystem] or [ErrPermissionDenied] error, when known.
+	// Zero when not applicable.
+	Errno int
+	// OSError is the platform description of Errno. Empty when Errno is zero.
+	OSError string
 }
 
 // Error implements the error interface.
@@ -88,12 +99,52 @@ func (e *Error) Error() string {
 	return e.Code.String()
 }
 
+// IsTransient reports whether the underlying OS error is one that commonly
+// clears on retry, such as EAGAIN, EINTR, or EMFILE.
+//
+// Returns false when Errno is zero.
+func (e *Error) IsTransient() bool {
+	return e.Errno != 0 && isTransientErrno(syscall.Errno(e.Errno))
+}
+
+// withErrno records errno detail on e and returns e.
+func (e *Error) withErrno(errno syscall.Errno) *Error {
+	if errno != 0 {
+		e.Errno = int(errno)
+		e.OSError = errno.Error()
+	}
+	return e
+}
+
+// parseErrno extracts the trailing "(errno: N)" the library appends to
+// system error messages.
+func parseErrno(message string) (syscall.Errno, bool) {
+	const marker = "(errno: "
+	i := strings.LastIndex(message, marker)
+	if i < 0 || !strings.HasSuffix(message, ")") {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(message[i+len(marker):len(message)-1], 10, 32)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return syscall.Errno(n), true
+}
+
 // callAndCheck executes an FFI call and converts the returned code to a Go error.
 //
 // Important: sysprims stores error details in thread-local storage (TLS). Go
 // goroutines can move between OS threads between cgo calls, so we lock the OS
 // thread to ensure `sysprims_last_error()` reads the error for the same thread
 // that performed the failing call.
+//
+// The lock is taken on every call, including the success path, because the
+// outcome is unknown until the call returns, and only a lock taken before the
+// call keeps the error read on the same thread. An uncontended
+// lock/unlock pair costs a few nanoseconds, well under the cgo transition
+// itself (see BenchmarkFFICall), so neither a success-path bypass nor a pool
+// of locked worker threads, whose channel handoff costs more than the lock,
+// pays for itself.
 func callAndCheck(call func() C.SysprimsErrorCode) error {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
@@ -103,11 +154,22 @@ func callAndCheck(call func() C.SysprimsErrorCode) error {
 		return nil
 	}
 
-	msgPtr := C.sysprims_last_error()
+	return takeLastError(code, C.sysprims_last_error())
+}
+
+// takeLastError builds the Error for a failing code from its last-error
+// message, which it frees.
+func takeLastError(code C.SysprimsErrorCode, msgPtr *C.char) *Error {
 	defer C.sysprims_free_string(msgPtr)
 
-	return &Error{
+	err := &Error{
 		Code:    ErrorCode(code),
 		Message: C.GoString(msgPtr),
 	}
+	if err.Code == ErrSystem || err.Code == ErrPermissionDenied {
+		if errno, ok := parseErrno(err.Message); ok {
+			err.withErrno(errno)
+		}
+	}
+	return err
 }
@@ -0,0 +1,488 @@
+package sysprims
+
+/*
+#include "sysprims.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// FdInfo describes an open file descriptor.
+type FdInfo struct {
+	Fd   uint32  `json:"fd"`
+	Kind string  `json:"kind"`
+	Path *string `json:"path,omitempty"`
+	// Socket carries endpoint details for fds of kind "socket", best-effort.
+	//
+	// Internet (TCP/UDP over IPv4/IPv6) and unix domain sockets are resolved.
+	// Sockets of other families, or that close mid-listing, carry only Inode.
+	Socket *SocketInfo `json:"socket,omitempty"`
+	// CloseOnExec reports whether FD_CLOEXEC is set, best-effort (Linux, and
+	// same-user processes on macOS).
+	CloseOnExec *bool `json:"close_on_exec,omitempty"`
+	// AccessMode is "r", "w", or "rw", best-effort like CloseOnExec.
+	AccessMode *string `json:"access_mode,omitempty"`
+	// Flags holds the raw open flags, best-effort like CloseOnExec. On Linux
+	// these are the O_* flags from /proc/<pid>/fdinfo; on macOS the kernel
+	// fflags from proc_pidfdinfo.
+	Flags *uint32 `json:"flags,omitempty"`
+	// Offset is the current file offset of a regular-file fd (requires
+	// FdFilter.IncludeOffsets; Linux and macOS, best-effort).
+	Offset *uint64 `json:"offset,omitempty"`
+	// FileSizeBytes is the size of the file behind a regular-file fd
+	// (requires FdFilter.IncludeOffsets; Linux and macOS, best-effort).
+	FileSizeBytes *uint64 `json:"file_size_bytes,omitempty"`
+	// Deleted reports whether a fd of kind "file" refers to a file that has
+	// been unlinked but is still open, best-effort (Linux, and same-user
+	// processes on macOS). On Linux Path keeps the kernel's " (deleted)"
+	// suffix.
+	Deleted *bool `json:"deleted,omitempty"`
+}
+
+// fdDetails holds the per-fd values read by readFdDetails.
+type fdDetails struct {
+	flags     uint32
+	access    string
+	cloexec   bool
+	offset    uint64
+	hasOffset bool
+	size      uint64
+	hasSize   bool
+	deleted   bool
+	// hasDeleted is set when deleted was determined (vnodes only on macOS).
+	hasDeleted bool
+}
+
+// SocketInfo describes the endpoints of a socket file descriptor.
+//
+// For unix domain sockets LocalAddr is the bound path (nil if unbound;
+// abstract names start with "@"), ports are zero, and State is one of "listen",
+// "unconnected", "connecting", "connected", or "disconnecting".
+type SocketInfo struct {
+	// Inode is the kernel socket inode, as in the "socket:[N]" link target.
+	Inode uint64 `json:"inode"`
+	// Protocol is empty when the socket could not be resolved.
+	Protocol   Protocol `json:"protocol,omitempty"`
+	LocalAddr  *string  `json:"local_addr,omitempty"`
+	LocalPort  uint16   `json:"local_port"`
+	RemoteAddr *string  `json:"remote_addr,omitempty"`
+	RemotePort *uint16  `json:"remote_port,omitempty"`
+	State      *string  `json:"state,omitempty"`
+}
+
+// FdSnapshot represents a point-in-time listing of open file descriptors.
+type FdSnapshot struct {
+	SchemaID  string   `json:"schema_id"`
+	Timestamp string   `json:"timestamp"`
+	Platform  string   `json:"platform"`
+	Pid       uint32   `json:"pid"`
+	Fds       []FdInfo `json:"fds"`
+	Warnings  []string `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+	// TotalFds is the number of fds matching the filter before Offset and
+	// Limit are applied.
+	TotalFds int `json:"total_fds"`
+}
+
+// FdFilter specifies criteria for filtering and paging file descriptors.
+//
+// Kind is evaluated by the library. The remaining fields are evaluated by the
+// Go bindings after the listing is returned; fds without a resolved path
+// (sockets, anonymous inodes) never match the Path fields.
+type FdFilter struct {
+	Kind *string `json:"kind,omitempty"`
+	// PathContains filters by resolved path substring (case-sensitive).
+	PathContains *string `json:"-"`
+	// PathPrefix filters by resolved path prefix. Case-insensitive on macOS
+	// and Windows.
+	PathPrefix *string `json:"-"`
+	// PathGlob filters by matching the whole resolved path with
+	// [filepath.Match], so "*" does not cross separators. Case-insensitive on
+	// macOS and Windows.
+	PathGlob *string `json:"-"`
+	// FdMin and FdMax bound the fd number, inclusive.
+	FdMin *uint32 `json:"-"`
+	FdMax *uint32 `json:"-"`
+	// Offset skips this many matching fds, in ascending fd order.
+	Offset int `json:"-"`
+	// Limit caps the number of fds returned; 0 means no limit.
+	Limit int `json:"-"`
+	// CloseOnExec filters by FD_CLOEXEC; set it to false to find fds that leak
+	// into child processes. Fds whose flags are unavailable never match.
+	CloseOnExec *bool `json:"-"`
+	// IncludeOffsets requests Offset and FileSizeBytes for fds of kind
+	// "file". It is off by default because it costs an extra syscall per fd
+	// on Linux.
+	IncludeOffsets bool `json:"-"`
+	// OnlyDeleted keeps only files that are unlinked but still open.
+	OnlyDeleted bool `json:"-"`
+}
+
+// fdPathFoldCase reports whether PathPrefix and PathGlob ignore case, matching
+// the default filesystem semantics of the platform.
+const fdPathFoldCase = runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+
+// hasGoCriteria reports whether f sets any criteria evaluated by the Go
+// bindings rather than the library.
+func (f *FdFilter) hasGoCriteria() bool {
+	return f != nil && (f.hasPathCriteria() || f.FdMin != nil || f.FdMax != nil ||
+		f.Offset != 0 || f.Limit != 0 || f.CloseOnExec != nil || f.IncludeOffsets || f.OnlyDeleted)
+}
+
+func (f *FdFilter) hasPathCriteria() bool {
+	return f.PathContains != nil || f.PathPrefix != nil || f.PathGlob != nil
+}
+
+// validate rejects malformed Go-side criteria before anything is listed.
+func (f *FdFilter) validate() error {
+	if f == nil {
+		return nil
+	}
+	if f.PathGlob != nil && !validGlob(*f.PathGlob) {
+		return &Error{Code: ErrInvalidArgument, Message: "malformed path glob: " + *f.PathGlob}
+	}
+	if f.FdMin != nil && f.FdMax != nil && *f.FdMin > *f.FdMax {
+		return &Error{Code: ErrInvalidArgument, Message: "fd_min must be <= fd_max"}
+	}
+	if f.Offset < 0 || f.Limit < 0 {
+		return &Error{Code: ErrInvalidArgument, Message: "offset and limit must be >= 0"}
+	}
+	return nil
+}
+
+// matchesGo reports whether fd satisfies the Go-side criteria of f.
+func (f *FdFilter) matchesGo(fd *FdInfo) bool {
+	if f == nil {
+		return true
+	}
+	if (f.FdMin != nil && fd.Fd < *f.FdMin) || (f.FdMax != nil && fd.Fd > *f.FdMax) {
+		return false
+	}
+	if f.CloseOnExec != nil && (fd.CloseOnExec == nil || *fd.CloseOnExec != *f.CloseOnExec) {
+		return false
+	}
+	if f.OnlyDeleted && (fd.Deleted == nil || !*fd.Deleted) {
+		return false
+	}
+	if !f.hasPathCriteria() {
+		return true
+	}
+	if fd.Path == nil {
+		return false
+	}
+	if f.PathContains != nil && !strings.Contains(*fd.Path, *f.PathContains) {
+		return false
+	}
+
+	path := *fd.Path
+	fold := func(s string) string { return s }
+	if fdPathFoldCase {
+		fold = strings.ToLower
+		path = fold(path)
+	}
+	if f.PathPrefix != nil && !strings.HasPrefix(path, fold(*f.PathPrefix)) {
+		return false
+	}
+	if f.PathGlob != nil {
+		if ok, err := filepath.Match(fold(*f.PathGlob), path); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ListFds returns a snapshot of open file descriptors for the given PID.
+//
+// Fds are sorted by fd number. To page through a large table, set
+// filter.Offset and filter.Limit and advance Offset by Limit until it reaches
+// TotalFds; each page is a fresh listing, so fds opened or closed between
+// calls can shift later pages.
+//
+// Best-effort behavior:
+// - Fields may be omitted
+// - Warnings may be present
+// - Socket details are resolved on Linux only; other platforms add a warning
+// - Fd flags and requested offsets are read on Linux and macOS only
+// - Windows returns ErrNotSupported
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: filter.PathGlob is malformed, FdMin > FdMax, or
+//     Offset or Limit is negative
+func ListFds(pid uint32, filter *FdFilter) (*FdSnapshot, error) {
+	snapshot, err := listFdsResolved(pid, filter)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.WarningDetails = warningDetails("ListFds", pid, snapshot.Warnings)
+	return snapshot, nil
+}
+
+// listFdsResolved implements ListFds up to, but not including, warning
+// classification, so callers can report warnings under their own name.
+func listFdsResolved(pid uint32, filter *FdFilter) (*FdSnapshot, error) {
+	return listFdsResolvedWith(pid, filter, readSocketTable)
+}
+
+// listFdsResolvedWith is listFdsResolved with pid's socket table read by
+// sockets, which is called only if the listing has socket fds.
+func listFdsResolvedWith(pid uint32, filter *FdFilter, sockets func(pid uint32) (map[uint64]SocketInfo, []string)) (*FdSnapshot, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := listFds(pid, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Details are read per fd, so read them only for the page returned,
+	// unless the filter itself needs them.
+	offsets := filter != nil && filter.IncludeOffsets
+	early := filter != nil && (filter.CloseOnExec != nil || filter.OnlyDeleted)
+	if early {
+		snapshot.Warnings = append(snapshot.Warnings, fillFdDetails(pid, snapshot.Fds, offsets)...)
+	}
+	snapshot.Fds = filterFds(snapshot.Fds, filter)
+	sort.SliceStable(snapshot.Fds, func(i, j int) bool { return snapshot.Fds[i].Fd < snapshot.Fds[j].Fd })
+	snapshot.TotalFds = len(snapshot.Fds)
+	snapshot.Fds = pageFds(snapshot.Fds, filter)
+	if !early {
+		snapshot.Warnings = append(snapshot.Warnings, fillFdDetails(pid, snapshot.Fds, offsets)...)
+	}
+	snapshot.Warnings = append(snapshot.Warnings, resolveFdSockets(snapshot.Fds, func() (map[uint64]SocketInfo, []string) {
+		return sockets(pid)
+	})...)
+
+	return snapshot, nil
+}
+
+// listFds returns the library fd listing without Go-side filtering or
+// socket resolution.
+func listFds(pid uint32, filter *FdFilter) (*FdSnapshot, error) {
+	var filterCStr *C.char
+	if filter != nil {
+		filterJSON, err := json.Marshal(filter)
+		if err != nil {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "failed to marshal filter: " + err.Error()}
+		}
+		filterCStr = C.CString(string(filterJSON))
+		defer C.free(unsafe.Pointer(filterCStr))
+	}
+
+	var resultCStr *C.char
+	if err := callAndCheck(func() C.SysprimsErrorCode {
+		return C.sysprims_proc_list_fds(C.uint32_t(pid), filterCStr, &resultCStr)
+	}); err != nil {
+		return nil, err
+	}
+	defer C.sysprims_free_string(resultCStr)
+
+	var snapshot FdSnapshot
+	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &snapshot); err != nil {
+		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
+	}
+
+	return &snapshot, nil
+}
+
+// filterFds applies the Go-side FdFilter criteria in place.
+func filterFds(fds []FdInfo, filter *FdFilter) []FdInfo {
+	if !filter.hasGoCriteria() {
+		return fds
+	}
+
+	kept := fds[:0]
+	for i := range fds {
+		if filter.matchesGo(&fds[i]) {
+			kept = append(kept, fds[i])
+		}
+	}
+	return kept
+}
+
+// pageFds applies filter.Offset and filter.Limit.
+func pageFds(fds []FdInfo, filter *FdFilter) []FdInfo {
+	if filter == nil {
+		return fds
+	}
+	if filter.Offset >= len(fds) {
+		return fds[:0]
+	}
+	fds = fds[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(fds) {
+		fds = fds[:filter.Limit]
+	}
+	return fds
+}
+
+// validGlob reports whether pattern is well-formed for filepath.Match.
+//
+// filepath.Match stops at the first mismatch and can return false without
+// reporting a malformed class later in the pattern, so check the whole
+// pattern up front using the same rules.
+func validGlob(pattern string) bool {
+	escapes := runtime.GOOS != "windows"
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && escapes:
+			i++
+			if i >= len(pattern) {
+				return false
+			}
+		case pattern[i] == '[':
+			i++
+			if i < len(pattern) && pattern[i] == '^' {
+				i++
+			}
+			for n := 0; ; n++ {
+				if i >= len(pattern) {
+					return false
+				}
+				if pattern[i] == ']' && n > 0 {
+					break
+				}
+				var ok bool
+				if i, ok = globClassChar(pattern, i, escapes); !ok {
+					return false
+				}
+				if i < len(pattern) && pattern[i] == '-' {
+					if i, ok = globClassChar(pattern, i+1, escapes); !ok {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// globClassChar consumes one character-class character of p at i and returns
+// the index after it.
+func globClassChar(p string, i int, escapes bool) (int, bool) {
+	if i >= len(p) || p[i] == '-' || p[i] == ']' {
+		return i, false
+	}
+	if p[i] == '\\' && escapes {
+		i++
+		if i >= len(p) {
+			return i, false
+		}
+	}
+	_, w := utf8.DecodeRuneInString(p[i:])
+	return i + w, true
+}
+
+// resolveFdSockets fills FdInfo.Socket for socket fds from the table
+// returned by sockets and returns warnings describing anything that could not
+// be resolved.
+func resolveFdSockets(fds []FdInfo, sockets func() (map[uint64]SocketInfo, []string)) []string {
+	hasSockets := false
+	for _, fd := range fds {
+		if fd.Kind == "socket" {
+			hasSockets = true
+			break
+		}
+	}
+	if !hasSockets {
+		return nil
+	}
+
+	table, warnings := sockets()
+	if table == nil {
+		return warnings
+	}
+	warnings = append([]string(nil), warnings...)
+
+	unresolved := 0
+	for i := range fds {
+		if fds[i].Kind != "socket" || fds[i].Path == nil {
+			continue
+		}
+		inode, ok := parseSocketInode(*fds[i].Path)
+		if !ok {
+			continue
+		}
+		info, found := table[inode]
+		if !found {
+			unresolved++
+			info = SocketInfo{}
+		}
+		info.Inode = inode
+		fds[i].Socket = &info
+	}
+
+	if unresolved > 0 {
+		warnings = append(warnings, fmt.Sprintf("socket details unavailable for %d fds (unsupported socket family or closed during read)", unresolved))
+	}
+	return warnings
+}
+
+// fillFdDetails sets CloseOnExec, AccessMode, and Flags on fds, Deleted on
+// files, plus Offset and FileSizeBytes on files when offsets is set, and
+// returns a warning if some could not be read. Fds closed mid-listing are
+// skipped silently.
+func fillFdDetails(pid uint32, fds []FdInfo, offsets bool) []string {
+	if !fdDetailsSupported {
+		return nil
+	}
+
+	unreadable := 0
+	var firstErr error
+	for i := range fds {
+		isFile := fds[i].Kind == "file"
+		d, err := readFdDetails(pid, &fds[i], offsets && isFile)
+		if err != nil {
+			if !fdGone(err) {
+				unreadable++
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+			continue
+		}
+		fds[i].Flags = &d.flags
+		fds[i].CloseOnExec = &d.cloexec
+		if d.access != "" {
+			fds[i].AccessMode = &d.access
+		}
+		if offsets && isFile && d.hasOffset {
+			fds[i].Offset = &d.offset
+		}
+		if offsets && isFile && d.hasSize {
+			fds[i].FileSizeBytes = &d.size
+		}
+		if isFile && d.hasDeleted {
+			fds[i].Deleted = &d.deleted
+		}
+	}
+
+	if unreadable > 0 {
+		return []string{fmt.Sprintf("fd details unavailable for %d fds: %v", unreadable, firstErr)}
+	}
+	return nil
+}
+
+// parseSocketInode extracts the inode from a "socket:[12345]" link target.
+func parseSocketInode(path string) (uint64, bool) {
+	const prefix = "socket:["
+	if !strings.HasPrefix(path, prefix) || !strings.HasSuffix(path, "]") {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(path[len(prefix):len(path)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return inode, true
+}
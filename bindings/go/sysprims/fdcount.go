@@ -0,0 +1,57 @@
+package sysprims
+
+// FdCount returns the number of open file descriptors of pid (handles on
+// Windows).
+//
+// Unlike [ListFds] it resolves no paths and builds no snapshot, so it stays
+// cheap for processes with very large fd tables.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to inspect this process's fds
+//   - [ErrNotSupported]: Fd counting is unavailable on this platform
+func FdCount(pid uint32) (uint32, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return 0, err
+	}
+	return fdCount(pid)
+}
+
+// FdCountsByKind returns the number of open file descriptors of pid per
+// kind, using the kind strings of [FdInfo] ("file", "socket", "pipe",
+// "unknown"). Kinds with no fds are omitted.
+//
+// Like [FdCount] it builds no snapshot. On Linux each fd still costs one
+// readlink to classify it.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to inspect this process's fds
+//   - [ErrNotSupported]: Handle typing is not implemented (Windows)
+func FdCountsByKind(pid uint32) (map[string]uint32, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+	return fdCountsByKind(pid)
+}
+
+// readFdCount fills OpenFdCount for p. It is left nil when the fds cannot
+// be counted.
+func readFdCount(p *ProcessInfo) {
+	if n, ok := fdCountOf(p); ok {
+		p.OpenFdCount = &n
+	}
+}
+
+// fdCountOf returns the open fd count of p, reusing OpenFdCount when set.
+func fdCountOf(p *ProcessInfo) (uint32, bool) {
+	if p.OpenFdCount != nil {
+		return *p.OpenFdCount, true
+	}
+	n, err := fdCount(p.PID)
+	return n, err == nil
+}
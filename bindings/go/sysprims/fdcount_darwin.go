@@ -0,0 +1,96 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <stdlib.h>
+#include <sys/proc_info.h>
+
+// sysprims_go_fd_list fills fds with up to cap entries from
+// PROC_PIDLISTFDS and stores the number of entries returned in n.
+static int sysprims_go_fd_list(int pid, struct proc_fdinfo *fds, int cap, int *n) {
+	errno = 0;
+	int bytes = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, fds, cap * (int)sizeof(struct proc_fdinfo));
+	if (bytes < 0 || (bytes == 0 && errno != 0)) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	*n = bytes / (int)sizeof(struct proc_fdinfo);
+	return 0;
+}
+
+static int sysprims_go_fd_list_size(int pid, int *bytes) {
+	errno = 0;
+	int n = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, NULL, 0);
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	*bytes = n;
+	return 0;
+}
+*/
+import "C"
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// listFdTypes returns the proc_fdtype of each open fd via PROC_PIDLISTFDS,
+// which reports types without resolving any paths.
+func listFdTypes(pid uint32) ([]C.uint32_t, error) {
+	var bytes C.int
+	if rc := C.sysprims_go_fd_list_size(C.int(pid), &bytes); rc != 0 {
+		return nil, errnoError(pid, syscall.Errno(rc))
+	}
+
+	// Fds may be opened between the two calls; leave headroom.
+	capacity := int(bytes)/int(C.sizeof_struct_proc_fdinfo) + 32
+	buf := C.malloc(C.size_t(capacity) * C.size_t(C.sizeof_struct_proc_fdinfo))
+	if buf == nil {
+		return nil, &Error{Code: ErrSystem, Message: "out of memory listing fds"}
+	}
+	defer C.free(buf)
+
+	var n C.int
+	if rc := C.sysprims_go_fd_list(C.int(pid), (*C.struct_proc_fdinfo)(buf), C.int(capacity), &n); rc != 0 {
+		return nil, errnoError(pid, syscall.Errno(rc))
+	}
+	infos := unsafe.Slice((*C.struct_proc_fdinfo)(buf), int(n))
+	types := make([]C.uint32_t, len(infos))
+	for i := range infos {
+		types[i] = infos[i].proc_fdtype
+	}
+	return types, nil
+}
+
+func fdCount(pid uint32) (uint32, error) {
+	types, err := listFdTypes(pid)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(len(types)), nil
+}
+
+// fdCountsByKind maps proc_fdtype to FdInfo kinds as the library does.
+func fdCountsByKind(pid uint32) (map[string]uint32, error) {
+	types, err := listFdTypes(pid)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]uint32)
+	for _, t := range types {
+		switch t {
+		case C.PROX_FDTYPE_VNODE:
+			counts["file"]++
+		case C.PROX_FDTYPE_SOCKET:
+			counts["socket"]++
+		case C.PROX_FDTYPE_PIPE:
+			counts["pipe"]++
+		default:
+			counts["unknown"]++
+		}
+	}
+	return counts, nil
+}
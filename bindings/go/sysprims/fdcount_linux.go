@@ -0,0 +1,76 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// fdDirBatch is the number of /proc/<pid>/fd entries read per call.
+const fdDirBatch = 1024
+
+// fdCount counts the entries of /proc/<pid>/fd.
+func fdCount(pid uint32) (uint32, error) {
+	var n uint32
+	err := walkFdDir(pid, func(names []string) { n += uint32(len(names)) })
+	return n, err
+}
+
+// fdCountsByKind classifies each /proc/<pid>/fd entry by its link target,
+// as the library does for ListFds. Fds closed mid-walk are skipped.
+func fdCountsByKind(pid uint32) (map[string]uint32, error) {
+	dir := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/fd/"
+	counts := make(map[string]uint32)
+	err := walkFdDir(pid, func(names []string) {
+		for _, name := range names {
+			target, err := os.Readlink(dir + name)
+			if err != nil {
+				continue
+			}
+			counts[fdKindOfTarget(target)]++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// walkFdDir calls batch with successive chunks of fd names from
+// /proc/<pid>/fd without holding the whole listing in memory.
+func walkFdDir(pid uint32, batch func([]string)) error {
+	f, err := os.Open("/proc/" + strconv.FormatUint(uint64(pid), 10) + "/fd")
+	if err != nil {
+		return procReadError(pid, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	for {
+		names, err := f.Readdirnames(fdDirBatch)
+		batch(names)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return procReadError(pid, err)
+		}
+	}
+}
+
+// fdKindOfTarget maps a /proc/<pid>/fd link target to an FdInfo kind.
+func fdKindOfTarget(target string) string {
+	switch {
+	case strings.HasPrefix(target, "socket:["):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:["):
+		return "pipe"
+	case strings.HasPrefix(target, "anon_inode:"):
+		return "unknown"
+	default:
+		return "file"
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package sysprims
+
+import "runtime"
+
+func fdCount(pid uint32) (uint32, error) {
+	return 0, &Error{Code: ErrNotSupported, Message: "fd counting is not supported on " + runtime.GOOS}
+}
+
+func fdCountsByKind(pid uint32) (map[string]uint32, error) {
+	return nil, &Error{Code: ErrNotSupported, Message: "fd counting is not supported on " + runtime.GOOS}
+}
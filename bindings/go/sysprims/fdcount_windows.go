@@ -0,0 +1,32 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetProcessHandleCount = modKernel32.NewProc("GetProcessHandleCount")
+
+// fdCount returns the process handle count.
+func fdCount(pid uint32) (uint32, error) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return 0, winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var count uint32
+	r, _, e := procGetProcessHandleCount.Call(uintptr(h), uintptr(unsafe.Pointer(&count)))
+	if r == 0 {
+		return 0, systemError(e)
+	}
+	return count, nil
+}
+
+// fdCountsByKind is not implemented: classifying handles needs
+// NtQuerySystemInformation handle enumeration.
+func fdCountsByKind(pid uint32) (map[string]uint32, error) {
+	return nil, &Error{Code: ErrNotSupported, Message: "handle counts by kind are not supported on windows"}
+}
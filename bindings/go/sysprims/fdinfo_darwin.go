@@ -0,0 +1,102 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <sys/proc_info.h>
+
+// sysprims_go_fd_fileinfo reads the proc_fileinfo header shared by every
+// PROC_PIDFD* flavor, trying each flavor until one matches the fd's type.
+// For vnodes it also reports the file size and link count.
+static int sysprims_go_fd_fileinfo(int pid, int fd, uint32_t *openflags, uint32_t *status,
+                                   int64_t *offset, int64_t *size, uint32_t *nlink, int *is_vnode) {
+	union {
+		struct vnode_fdinfo vnode;
+		struct socket_fdinfo socket;
+		struct pipe_fdinfo pipe;
+		struct kqueue_fdinfo kqueue;
+	} buf;
+	static const int flavors[] = {
+		PROC_PIDFDVNODEINFO, PROC_PIDFDSOCKETINFO, PROC_PIDFDPIPEINFO, PROC_PIDFDKQUEUEINFO,
+	};
+	int rc = EBADF;
+	for (unsigned i = 0; i < sizeof(flavors) / sizeof(flavors[0]); i++) {
+		errno = 0;
+		int n = proc_pidfdinfo(pid, fd, flavors[i], &buf, sizeof(buf));
+		if (n >= (int)sizeof(struct proc_fileinfo)) {
+			struct proc_fileinfo *pfi = (struct proc_fileinfo *)&buf;
+			*openflags = pfi->fi_openflags;
+			*status = pfi->fi_status;
+			*offset = pfi->fi_offset;
+			*is_vnode = flavors[i] == PROC_PIDFDVNODEINFO && n >= (int)sizeof(struct vnode_fdinfo);
+			if (*is_vnode) {
+				*size = buf.vnode.pvi.vi_stat.vst_size;
+				*nlink = buf.vnode.pvi.vi_stat.vst_nlink;
+			}
+			return 0;
+		}
+		if (errno == EPERM || errno == EACCES || errno == ESRCH) {
+			return errno;
+		}
+		if (errno != 0) {
+			rc = errno;
+		}
+	}
+	return rc;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"syscall"
+)
+
+const fdDetailsSupported = true
+
+// Kernel fflags access bits as reported in proc_fileinfo.fi_openflags.
+const (
+	darwinFREAD  = 0x1
+	darwinFWRITE = 0x2
+)
+
+// readFdDetails reads proc_fileinfo via proc_pidfdinfo: fi_openflags, the
+// PROC_FP_CLEXEC status bit, the offset, and for vnodes the size and link
+// count; an unlinked file has no links. withSize costs nothing extra here.
+// Only same-user processes are readable.
+func readFdDetails(pid uint32, fd *FdInfo, withSize bool) (fdDetails, error) {
+	var d fdDetails
+	var openflags, status, nlink C.uint32_t
+	var offset, size C.int64_t
+	var isVnode C.int
+	if rc := C.sysprims_go_fd_fileinfo(C.int(pid), C.int(fd.Fd), &openflags, &status, &offset, &size, &nlink, &isVnode); rc != 0 {
+		return d, syscall.Errno(rc)
+	}
+	d.flags = uint32(openflags)
+	switch d.flags & (darwinFREAD | darwinFWRITE) {
+	case darwinFREAD:
+		d.access = "r"
+	case darwinFWRITE:
+		d.access = "w"
+	case darwinFREAD | darwinFWRITE:
+		d.access = "rw"
+	}
+	d.cloexec = status&C.PROC_FP_CLEXEC != 0
+	if offset >= 0 {
+		d.offset, d.hasOffset = uint64(offset), true
+	}
+	if isVnode != 0 {
+		if size >= 0 {
+			d.size, d.hasSize = uint64(size), true
+		}
+		d.deleted, d.hasDeleted = nlink == 0, true
+	}
+	return d, nil
+}
+
+// fdGone reports whether err means the fd was closed during the read.
+func fdGone(err error) bool {
+	return errors.Is(err, syscall.EBADF)
+}
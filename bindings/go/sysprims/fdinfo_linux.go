@@ -0,0 +1,94 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const fdDetailsSupported = true
+
+// readFdDetails parses /proc/<pid>/fdinfo/<fd>.
+//
+// The kernel folds the descriptor's close-on-exec bit into the octal "flags:"
+// line as O_CLOEXEC, so the file status flags and FD_CLOEXEC come from one
+// read, as does the "pos:" offset. With withSize, the target is stat'ed
+// through /proc/<pid>/fd/<fd> for its size. An unlinked file is recognized by
+// the " (deleted)" suffix the kernel appends to the link target. A vanished
+// fd reports fs.ErrNotExist.
+func readFdDetails(pid uint32, fd *FdInfo, withSize bool) (fdDetails, error) {
+	var d fdDetails
+	base := "/proc/" + strconv.FormatUint(uint64(pid), 10)
+	name := strconv.FormatUint(uint64(fd.Fd), 10)
+	path := base + "/fdinfo/" + name
+	f, err := os.Open(path)
+	if err != nil {
+		return d, err
+	}
+	defer func() { _ = f.Close() }()
+
+	haveFlags := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "pos":
+			pos, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return d, errors.New("malformed pos in " + path)
+			}
+			d.offset, d.hasOffset = pos, true
+		case "flags":
+			v, err := strconv.ParseUint(value, 8, 32)
+			if err != nil {
+				return d, errors.New("malformed flags in " + path)
+			}
+			d.flags, haveFlags = uint32(v), true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return d, err
+	}
+	if !haveFlags {
+		return d, errors.New("no flags in " + path)
+	}
+
+	switch d.flags & syscall.O_ACCMODE {
+	case syscall.O_RDONLY:
+		d.access = "r"
+	case syscall.O_WRONLY:
+		d.access = "w"
+	case syscall.O_RDWR:
+		d.access = "rw"
+	}
+	d.cloexec = d.flags&syscall.O_CLOEXEC != 0
+	if fd.Path != nil {
+		d.deleted, d.hasDeleted = strings.HasSuffix(*fd.Path, deletedSuffix), true
+	}
+
+	if withSize {
+		info, err := os.Stat(base + "/fd/" + name)
+		if err != nil {
+			return d, err
+		}
+		if info.Mode().IsRegular() {
+			d.size, d.hasSize = uint64(info.Size()), true
+		}
+	}
+	return d, nil
+}
+
+// fdGone reports whether err means the fd was closed during the read.
+func fdGone(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
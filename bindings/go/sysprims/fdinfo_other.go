@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package sysprims
+
+const fdDetailsSupported = false
+
+// readFdDetails is not implemented on this platform; fillFdDetails does not
+// call it.
+func readFdDetails(pid uint32, fd *FdInfo, withSize bool) (fdDetails, error) {
+	return fdDetails{}, &Error{Code: ErrNotSupported, Message: "fd details are not supported"}
+}
+
+func fdGone(err error) bool { return false }
@@ -0,0 +1,81 @@
+package sysprims
+
+import "strconv"
+
+// FdScan is the result of [ListFdsMany].
+type FdScan struct {
+	// Snapshots holds the listing of each PID that could be read.
+	Snapshots map[uint32]*FdSnapshot `json:"snapshots"`
+	// Errors holds the error for each PID that was skipped, such as
+	// [ErrNotFound] for a PID that exited or [ErrPermissionDenied].
+	Errors map[uint32]*Error `json:"errors"`
+	// Warnings summarizes the skipped PIDs, one per PID in input order.
+	Warnings []string `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+}
+
+// ListFdsMany lists the open fds of several PIDs in one call.
+//
+// Each snapshot matches what [ListFds] would return for that PID with the
+// same filter, and each PID is still listed separately. Only the socket
+// table is shared: it is read once per network namespace, rather than once
+// per PID. PIDs that disappear, deny access, or otherwise
+// fail are recorded in Errors and Warnings instead of failing the call;
+// duplicate PIDs are listed once.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pids is empty or holds 0, or filter is invalid
+//     (see [ListFds])
+func ListFdsMany(pids []uint32, filter *FdFilter) (*FdScan, error) {
+	if err := validatePidList(pids); err != nil {
+		return nil, err
+	}
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	type socketTable struct {
+		table    map[uint64]SocketInfo
+		warnings []string
+	}
+	tables := make(map[string]socketTable)
+	sockets := func(pid uint32) (map[uint64]SocketInfo, []string) {
+		ns, ok := netNamespace(pid)
+		if !ok {
+			return readSocketTable(pid)
+		}
+		t, loaded := tables[ns]
+		if !loaded {
+			t.table, t.warnings = readSocketTable(pid)
+			tables[ns] = t
+		}
+		return t.table, t.warnings
+	}
+
+	scan := &FdScan{
+		Snapshots: make(map[uint32]*FdSnapshot, len(pids)),
+		Errors:    make(map[uint32]*Error),
+		Warnings:  []string{},
+	}
+	for _, pid := range pids {
+		if _, done := scan.Snapshots[pid]; done {
+			continue
+		}
+		if _, done := scan.Errors[pid]; done {
+			continue
+		}
+		snapshot, err := listFdsResolvedWith(pid, filter, sockets)
+		if err != nil {
+			sErr := asError(err)
+			scan.Errors[pid] = sErr
+			scan.Warnings = append(scan.Warnings, "pid "+strconv.FormatUint(uint64(pid), 10)+" skipped: "+sErr.Message)
+			continue
+		}
+		snapshot.WarningDetails = warningDetails("ListFds", pid, snapshot.Warnings)
+		scan.Snapshots[pid] = snapshot
+	}
+	scan.WarningDetails = warningDetails("ListFdsMany", 0, scan.Warnings)
+	return scan, nil
+}
@@ -0,0 +1,355 @@
+package sysprims
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// hasGoCriteria reports whether f sets any criteria evaluated by the Go
+// bindings rather than the library.
+func (f *ProcessFilter) hasGoCriteria() bool {
+	if f == nil {
+		return false
+	}
+	return f.ExePathContains != nil || f.ExePathEquals != nil ||
+		len(f.UserIn) > 0 || len(f.PPIDIn) > 0 ||
+		f.StartedAfterUnixMS != nil || f.StartedBeforeUnixMS != nil ||
+		f.CgroupContains != nil || f.TTYEquals != nil ||
+		f.CPUBelow != nil || f.MemoryBelowKB != nil || f.NameRegex != nil ||
+		f.FdCountAbove != nil
+}
+
+// isEmpty reports whether f sets no criteria at all, so it would match
+// every process.
+func (f *ProcessFilter) isEmpty() bool {
+	if f == nil {
+		return true
+	}
+	if f.hasGoCriteria() {
+		return false
+	}
+	// Every library field is omitted from the JSON when unset.
+	b, err := json.Marshal(f)
+	return err == nil && string(b) == "{}"
+}
+
+// nameRegexps caches compiled NameRegex patterns by source, so matching a
+// listing compiles the pattern once. It is cleared when it grows past
+// maxNameRegexps.
+var nameRegexps = struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+const maxNameRegexps = 64
+
+// compileNameRegex returns the compiled form of a NameRegex pattern.
+func compileNameRegex(pattern string) (*regexp.Regexp, error) {
+	nameRegexps.Lock()
+	defer nameRegexps.Unlock()
+	if re, ok := nameRegexps.m[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "invalid name_regex: " + err.Error()}
+	}
+	if len(nameRegexps.m) >= maxNameRegexps {
+		clear(nameRegexps.m)
+	}
+	nameRegexps.m[pattern] = re
+	return re, nil
+}
+
+// validateGo checks the Go-side criteria of f that can be invalid.
+func (f *ProcessFilter) validateGo() error {
+	if f == nil || f.NameRegex == nil {
+		return nil
+	}
+	_, err := compileNameRegex(*f.NameRegex)
+	return err
+}
+
+// matchesGo reports whether p satisfies the Go-side criteria of f.
+func (f *ProcessFilter) matchesGo(p *ProcessInfo) bool {
+	if f == nil {
+		return true
+	}
+	if f.NameRegex != nil {
+		// validateGo has already rejected invalid patterns.
+		if re, err := compileNameRegex(*f.NameRegex); err != nil || !re.MatchString(p.Name) {
+			return false
+		}
+	}
+	if f.ExePathContains != nil && (p.ExePath == nil || !strings.Contains(*p.ExePath, *f.ExePathContains)) {
+		return false
+	}
+	if f.ExePathEquals != nil && (p.ExePath == nil || *p.ExePath != *f.ExePathEquals) {
+		return false
+	}
+	if len(f.UserIn) > 0 && (p.User == nil || !containsString(f.UserIn, *p.User)) {
+		return false
+	}
+	if len(f.PPIDIn) > 0 && !containsPID(f.PPIDIn, p.PPID) {
+		return false
+	}
+	if f.StartedAfterUnixMS != nil && (p.StartTimeUnixMS == nil || *p.StartTimeUnixMS < *f.StartedAfterUnixMS) {
+		return false
+	}
+	if f.StartedBeforeUnixMS != nil && (p.StartTimeUnixMS == nil || *p.StartTimeUnixMS >= *f.StartedBeforeUnixMS) {
+		return false
+	}
+	if f.CPUBelow != nil && !(p.CPUPercent < *f.CPUBelow) {
+		return false
+	}
+	if f.MemoryBelowKB != nil && p.MemoryKB >= *f.MemoryBelowKB {
+		return false
+	}
+	if f.CgroupContains != nil {
+		if path, ok := cgroupPathOf(p); !ok || !strings.Contains(path, *f.CgroupContains) {
+			return false
+		}
+	}
+	if f.TTYEquals != nil {
+		if tty := ttyOf(p); tty == "" || tty != *f.TTYEquals {
+			return false
+		}
+	}
+	if f.FdCountAbove != nil {
+		if n, ok := fdCountOf(p); !ok || n <= *f.FdCountAbove {
+			return false
+		}
+	}
+	return true
+}
+
+// validateLibrary mirrors the library's validation of the fields it
+// evaluates, for code paths that evaluate them in Go (see matchesLibrary).
+func (f *ProcessFilter) validateLibrary() error {
+	if f == nil {
+		return nil
+	}
+	if f.CPUAbove != nil && (*f.CPUAbove < 0 || *f.CPUAbove > 100) {
+		return &Error{Code: ErrInvalidArgument, Message: "cpu_above must be between 0 and 100"}
+	}
+	for _, s := range f.StateIn {
+		switch s {
+		case StateRunning, StateSleeping, StateStopped, StateZombie, StateUnknown:
+		default:
+			return &Error{Code: ErrInvalidArgument, Message: "invalid state: " + s}
+		}
+	}
+	return nil
+}
+
+// matchesLibrary reports whether p satisfies the criteria of f that the
+// library normally evaluates, with the library's semantics.
+func (f *ProcessFilter) matchesLibrary(p *ProcessInfo) bool {
+	if f == nil {
+		return true
+	}
+	if f.NameContains != nil && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(*f.NameContains)) {
+		return false
+	}
+	if f.NameEquals != nil && p.Name != *f.NameEquals {
+		return false
+	}
+	if f.UserEquals != nil && (p.User == nil || *p.User != *f.UserEquals) {
+		return false
+	}
+	if len(f.PIDIn) > 0 && !containsPID(f.PIDIn, p.PID) {
+		return false
+	}
+	if f.PPID != nil && p.PPID != *f.PPID {
+		return false
+	}
+	if len(f.StateIn) > 0 && (p.State == nil || !containsString(f.StateIn, *p.State)) {
+		return false
+	}
+	if f.CPUAbove != nil && p.CPUPercent < *f.CPUAbove {
+		return false
+	}
+	if f.MemoryAboveKB != nil && p.MemoryKB < *f.MemoryAboveKB {
+		return false
+	}
+	if f.RunningForAtLeastSecs != nil {
+		var elapsed uint64
+		if p.ElapsedSeconds != nil {
+			elapsed = *p.ElapsedSeconds
+		}
+		if elapsed < *f.RunningForAtLeastSecs {
+			return false
+		}
+	}
+	return true
+}
+
+// splitCPUBounds returns filter without CPUAbove and CPUBelow, which must
+// apply to sampled rather than lifetime values, and a func reporting whether
+// a sampled CPU percentage satisfies them. Because the library no longer
+// sees CPUAbove, its range is validated here, before any sampling.
+func splitCPUBounds(filter *ProcessFilter) (*ProcessFilter, func(cpu float64) bool, error) {
+	if filter == nil || (filter.CPUAbove == nil && filter.CPUBelow == nil) {
+		return filter, func(float64) bool { return true }, nil
+	}
+	if err := filter.validateLibrary(); err != nil {
+		return nil, nil, err
+	}
+	above, below := filter.CPUAbove, filter.CPUBelow
+	stripped := *filter
+	stripped.CPUAbove, stripped.CPUBelow = nil, nil
+	return &stripped, func(cpu float64) bool {
+		return (above == nil || cpu >= *above) && (below == nil || cpu < *below)
+	}, nil
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPID(pids []uint32, pid uint32) bool {
+	for _, p := range pids {
+		if p == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// filterProcesses applies the Go-side ProcessFilter criteria in place.
+func filterProcesses(processes []ProcessInfo, filter *ProcessFilter) []ProcessInfo {
+	if !filter.hasGoCriteria() {
+		return processes
+	}
+
+	kept := processes[:0]
+	for i := range processes {
+		if filter.matchesGo(&processes[i]) {
+			kept = append(kept, processes[i])
+		}
+	}
+	return kept
+}
+
+// filterDescendants applies the Go-side ProcessFilter criteria to a
+// descendants result, keeping MatchedByFilter and level pruning consistent
+// with the library's own filtering.
+func filterDescendants(result *DescendantsResult, filter *ProcessFilter) {
+	if !filter.hasGoCriteria() {
+		return
+	}
+
+	matched := 0
+	levels := result.Levels[:0]
+	for _, level := range result.Levels {
+		level.Processes = filterProcesses(level.Processes, filter)
+		if len(level.Processes) == 0 {
+			continue
+		}
+		matched += len(level.Processes)
+		levels = append(levels, level)
+	}
+	result.Levels = levels
+	result.MatchedByFilter = matched
+}
+
+// treeRoot carries the root of a KillTree through killDescendantsFiltered,
+// which judges it against the policy from the traversal's snapshot and
+// counts it toward MaxKillCount when it is to be signaled.
+type treeRoot struct {
+	// protected is set when the policy protects the root.
+	protected bool
+}
+
+// killDescendantsFiltered implements KillDescendantsWithOptions when the
+// filter has Go-side criteria or a safety policy applies, which the library
+// cannot evaluate.
+//
+// It mirrors the library: traverse first, apply the same safety exclusions
+// (root, self, PID 1, parent), then the policy against the traversal's
+// snapshot, then signal the remaining PIDs. With a non-nil root (set
+// opts.IncludeRoot), the root is judged too but left for the caller to
+// signal.
+func killDescendantsFiltered(pid uint32, signal int, opts *DescendantsOptions, policy *safetyCheck, root *treeRoot) (*KillDescendantsResult, error) {
+	desc, err := DescendantsWithOptions(pid, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint32]bool)
+	var candidates []*ProcessInfo
+	var rootInfo *ProcessInfo
+	for _, level := range desc.Levels {
+		for i := range level.Processes {
+			p := &level.Processes[i]
+			if p.PID == pid {
+				if level.Level == 0 {
+					rootInfo = p
+				}
+				continue
+			}
+			if seen[p.PID] {
+				continue
+			}
+			seen[p.PID] = true
+			candidates = append(candidates, p)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].PID < candidates[j].PID })
+
+	result := &KillDescendantsResult{
+		SchemaID:   SchemaBatchKillResultV1,
+		SignalSent: signal,
+		RootPID:    pid,
+		Succeeded:  []uint32{},
+		Failed:     []KillDescendantsFail{},
+	}
+	if policy != nil {
+		result.SkippedPolicy = []uint32{}
+	}
+	selfPID := uint32(os.Getpid())
+	parentPID := uint32(os.Getppid())
+	var targets []uint32
+	for _, p := range candidates {
+		switch {
+		case p.PID == selfPID || p.PID == 1 || p.PID == parentPID:
+			result.SkippedSafety++
+		case policy.protects(p.PID, p):
+			result.SkippedPolicy = append(result.SkippedPolicy, p.PID)
+		default:
+			targets = append(targets, p.PID)
+		}
+	}
+	count := len(targets)
+	if root != nil && pid != selfPID && pid != 1 && pid != parentPID {
+		root.protected = policy.protects(pid, rootInfo)
+		if !root.protected {
+			count++
+		}
+	}
+	if err := policy.checkCount(count); err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return result, nil
+	}
+
+	batch, err := KillMany(targets, signal)
+	if err != nil {
+		return nil, err
+	}
+	result.Succeeded = append(result.Succeeded, batch.Succeeded...)
+	for _, f := range batch.Failed {
+		result.Failed = append(result.Failed, KillDescendantsFail{PID: f.PID, Error: f.Error.Error()})
+	}
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package sysprims
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// DefaultFormatColumns is the column set Format uses when Columns is nil.
+var DefaultFormatColumns = []string{"pid", "user", "state", "cpu_percent", "memory_kb", "name"}
+
+// FormatOptions controls ProcessSnapshot.Format.
+type FormatOptions struct {
+	// Columns selects and orders the columns, using the same names as
+	// WriteCSV. Nil selects DefaultFormatColumns; unknown names are skipped.
+	Columns []string
+	// SortBy orders the rows; empty keeps snapshot order. The snapshot
+	// itself is not reordered.
+	SortBy SortKey
+	// Descending reverses the SortBy order.
+	Descending bool
+	// Limit caps the number of rows; 0 means no limit.
+	Limit int
+}
+
+// Format renders s as a top-style table with a header row and columns
+// aligned with spaces. Unset optional fields render as "-".
+func (s *ProcessSnapshot) Format(opts FormatOptions) string {
+	columns := opts.Columns
+	if columns == nil {
+		columns = DefaultFormatColumns
+	}
+	var names []string
+	var cells []func(p *ProcessInfo) string
+	for _, name := range columns {
+		cell, ok := csvColumns[name]
+		if !ok {
+			continue
+		}
+		if name == "cpu_percent" {
+			cell = func(p *ProcessInfo) string { return strconv.FormatFloat(p.CPUPercent, 'f', 1, 64) }
+		}
+		names = append(names, strings.ToUpper(name))
+		cells = append(cells, cell)
+	}
+	if len(cells) == 0 {
+		return ""
+	}
+
+	rows := s.Processes
+	switch opts.SortBy {
+	case SortByCPU, SortByMemory, SortByThreads, SortByFds:
+		rows = append([]ProcessInfo(nil), rows...)
+		sortProcesses(rows, opts.SortBy, opts.Descending)
+	}
+	if opts.Limit > 0 && opts.Limit < len(rows) {
+		rows = rows[:opts.Limit]
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(names, "\t"))
+	row := make([]string, len(cells))
+	for i := range rows {
+		for j, cell := range cells {
+			v := cell(&rows[i])
+			if v == "" {
+				v = "-"
+			}
+			row[j] = strings.ReplaceAll(v, "\t", " ")
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	_ = tw.Flush()
+	return b.String()
+}
+
+// String returns a one-line summary of p for debugging.
+func (p ProcessInfo) String() string {
+	state := "?"
+	if p.State != nil {
+		state = *p.State
+	}
+	return fmt.Sprintf("pid %d (%s) ppid %d %s cpu %.1f%% mem %dKB", p.PID, p.Name, p.PPID, state, p.CPUPercent, p.MemoryKB)
+}
+
+// String returns a one-line summary of b for debugging, such as
+// "tcp 127.0.0.1:8080 listen pid 42".
+func (b PortBinding) String() string {
+	addr := "*"
+	if b.LocalAddr != nil {
+		addr = *b.LocalAddr
+		if b.ScopeID != nil {
+			addr += "%" + zoneName(*b.ScopeID)
+		}
+	}
+	out := string(b.Protocol) + " " + net.JoinHostPort(addr, strconv.FormatUint(uint64(b.LocalPort), 10))
+	if b.State != nil {
+		out += " " + *b.State
+	}
+	if b.PID != nil {
+		out += " pid " + strconv.FormatUint(uint64(*b.PID), 10)
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package sysprims
+
+// SignalGraceful asks pid to shut down, giving it the chance to clean up.
+//
+// On Unix it sends SIGTERM. On Windows, where [Kill] maps SIGTERM to
+// TerminateProcess, it sends a console control event instead: the caller
+// attaches to the target's console and raises CTRL_BREAK_EVENT for the
+// process group led by pid, falling back to CTRL_C_EVENT if that fails.
+// CTRL_C_EVENT cannot be addressed to a group, so the fallback reaches every
+// process on the target's console; a target started in its own process
+// group (as [RunWithTimeout] does with ConsoleCtrl) gets CTRL_BREAK_EVENT
+// alone. While the event is raised the caller is detached from its own
+// console and ignores CTRL+C; it re-attaches to its parent's console
+// afterwards, and calls are serialized.
+//
+// SignalGraceful neither waits nor escalates; for that use
+// [GracefulStopWithOptions], [TerminateTree], or [RunWithTimeout] with
+// ConsoleCtrl set.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to signal this process
+//   - [ErrNotSupported]: On Windows, the target has no console
+func SignalGraceful(pid uint32) error {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return err
+	}
+	return signalGraceful(pid)
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package sysprims
+
+func signalGraceful(pid uint32) error {
+	return Kill(pid, SIGTERM)
+}
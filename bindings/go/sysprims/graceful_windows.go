@@ -0,0 +1,60 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	ctrlCEvent          = 0
+	ctrlBreakEvent      = 1
+	attachParentProcess = uintptr(^uint32(0)) // ATTACH_PARENT_PROCESS
+)
+
+// consoleEventSettle is how long this process keeps ignoring CTRL+C after
+// raising an event, which is delivered asynchronously.
+const consoleEventSettle = 100 * time.Millisecond
+
+var (
+	procAttachConsole            = modKernel32.NewProc("AttachConsole")
+	procFreeConsole              = modKernel32.NewProc("FreeConsole")
+	procSetConsoleCtrlHandler    = modKernel32.NewProc("SetConsoleCtrlHandler")
+	procGenerateConsoleCtrlEvent = modKernel32.NewProc("GenerateConsoleCtrlEvent")
+)
+
+// consoleMu serializes signalGraceful, which changes the console of the
+// whole process.
+var consoleMu sync.Mutex
+
+func signalGraceful(pid uint32) error {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+
+	// A process has at most one console, so leave ours to attach to the
+	// target's.
+	_, _, _ = procFreeConsole.Call()
+	defer func() { _, _, _ = procAttachConsole.Call(attachParentProcess) }()
+	if r, _, err := procAttachConsole.Call(uintptr(pid)); r == 0 {
+		if err == syscall.Errno(6) { // ERROR_INVALID_HANDLE: no console
+			return &Error{Code: ErrNotSupported, Message: "process " + strconv.FormatUint(uint64(pid), 10) + " has no console"}
+		}
+		return winProcessError(pid, err)
+	}
+
+	_, _, _ = procSetConsoleCtrlHandler.Call(0, 1)
+	r, _, err := procGenerateConsoleCtrlEvent.Call(ctrlBreakEvent, uintptr(pid))
+	if r == 0 {
+		r, _, err = procGenerateConsoleCtrlEvent.Call(ctrlCEvent, 0)
+	}
+	_, _, _ = procFreeConsole.Call()
+	time.Sleep(consoleEventSettle)
+	_, _, _ = procSetConsoleCtrlHandler.Call(0, 0)
+	if r == 0 {
+		return systemError(err)
+	}
+	return nil
+}
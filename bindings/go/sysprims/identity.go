@@ -0,0 +1,150 @@
+package sysprims
+
+import (
+	"strconv"
+	"time"
+)
+
+// identityStartTolerance absorbs platform rounding of process start times.
+//
+// Linux reports start times with one-second resolution, so any mismatch there
+// is at least 1000ms; macOS and Windows report milliseconds.
+const identityStartTolerance = 500 * time.Millisecond
+
+// ProcessIdentity identifies a process by PID and start time.
+//
+// PIDs are recycled; pairing the PID with the start time lets callers detect
+// that a remembered PID now belongs to a different process.
+type ProcessIdentity struct {
+	PID             uint32 `json:"pid"`
+	StartTimeUnixMS uint64 `json:"start_time_unix_ms"`
+}
+
+// IdentityOf reads the current identity of pid.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to read this process
+//   - [ErrNotSupported]: Start time is unavailable for this process
+func IdentityOf(pid uint32) (ProcessIdentity, error) {
+	info, err := ProcessGet(pid)
+	if err != nil {
+		return ProcessIdentity{}, err
+	}
+	id, ok := info.Identity()
+	if !ok {
+		return ProcessIdentity{}, &Error{Code: ErrNotSupported, Message: "start time unavailable for process"}
+	}
+	return id, nil
+}
+
+// Identity returns the identity of p.
+//
+// ok is false when StartTimeUnixMS is unavailable.
+func (p *ProcessInfo) Identity() (id ProcessIdentity, ok bool) {
+	if p.StartTimeUnixMS == nil {
+		return ProcessIdentity{}, false
+	}
+	return ProcessIdentity{PID: p.PID, StartTimeUnixMS: *p.StartTimeUnixMS}, true
+}
+
+// StillRunning reports whether the process identified by id is still alive.
+//
+// It re-reads the PID and compares start times. A missing PID, a zombie, or a
+// PID now owned by a process with a different start time all report false.
+//
+// # Errors
+//
+//   - [ErrPermissionDenied]: Not permitted to read this process
+//   - [ErrNotSupported]: Start time is unavailable for the current process at PID
+func (id ProcessIdentity) StillRunning() (bool, error) {
+	info, err := ProcessGet(id.PID)
+	if err != nil {
+		if sErr, ok := err.(*Error); ok && sErr.Code == ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if info.IsZombie() {
+		return false, nil
+	}
+	return id.matches(info)
+}
+
+// matches reports whether info describes the same process as id.
+func (id ProcessIdentity) matches(info *ProcessInfo) (bool, error) {
+	current, ok := info.Identity()
+	if !ok {
+		return false, &Error{Code: ErrNotSupported, Message: "start time unavailable for process"}
+	}
+	if current.PID != id.PID {
+		return false, nil
+	}
+
+	diff := int64(current.StartTimeUnixMS) - int64(id.StartTimeUnixMS)
+	if diff < 0 {
+		diff = -diff
+	}
+	return time.Duration(diff)*time.Millisecond <= identityStartTolerance, nil
+}
+
+// KillIdentity sends a signal to the process identified by id, refusing to
+// signal a PID that now belongs to a different process.
+//
+// The start time is re-read immediately before signaling. This narrows the
+// PID-reuse window to the gap between that read and the kill(2) call but
+// cannot close it entirely; treat the guard as best-effort.
+//
+// # Errors
+//
+//   - [ErrNotFound]: Process doesn't exist, or id is stale (the PID was reused);
+//     no signal is sent in either case
+//   - [ErrPermissionDenied]: Not permitted to read or signal this process
+//   - [ErrNotSupported]: Start time is unavailable, or signal not supported on this platform
+func KillIdentity(id ProcessIdentity, signal int) error {
+	if err := id.checkCurrent(); err != nil {
+		return err
+	}
+	return Kill(id.PID, signal)
+}
+
+// TerminateIdentity sends SIGTERM to the process identified by id.
+//
+// See [KillIdentity] for the stale-identity guard.
+func TerminateIdentity(id ProcessIdentity) error {
+	if err := id.checkCurrent(); err != nil {
+		return err
+	}
+	return Terminate(id.PID)
+}
+
+// ForceKillIdentity sends SIGKILL to the process identified by id.
+//
+// See [KillIdentity] for the stale-identity guard.
+func ForceKillIdentity(id ProcessIdentity) error {
+	if err := id.checkCurrent(); err != nil {
+		return err
+	}
+	return ForceKill(id.PID)
+}
+
+// checkCurrent returns nil if id still describes the process at id.PID.
+func (id ProcessIdentity) checkCurrent() error {
+	info, err := ProcessGet(id.PID)
+	if err != nil {
+		return err
+	}
+	same, err := id.matches(info)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return &Error{
+			Code:    ErrNotFound,
+			Message: "stale process identity: pid " + strconv.FormatUint(uint64(id.PID), 10) + " now belongs to a different process",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package sysprims
+
+// processIDs holds the real and effective user and group IDs of a process.
+type processIDs struct {
+	uid, euid, gid, egid uint32
+}
+
+// readIDs fills UID, EUID, GID, and EGID for p. Fields that cannot be read
+// are left nil.
+func readIDs(p *ProcessInfo) {
+	ids, err := readProcessIDs(p.PID)
+	if err != nil {
+		return
+	}
+	p.UID = &ids.uid
+	p.EUID = &ids.euid
+	p.GID = &ids.gid
+	p.EGID = &ids.egid
+}
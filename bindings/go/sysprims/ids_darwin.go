@@ -0,0 +1,38 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <sys/proc_info.h>
+
+static int sysprims_go_ids(int pid, uint32_t *ruid, uint32_t *uid, uint32_t *rgid, uint32_t *gid) {
+	struct proc_bsdinfo bi;
+	int n = proc_pidinfo(pid, PROC_PIDTBSDINFO, 0, &bi, sizeof(bi));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	if (n < (int)sizeof(bi)) {
+		return EIO;
+	}
+	*ruid = bi.pbi_ruid;
+	*uid = bi.pbi_uid;
+	*rgid = bi.pbi_rgid;
+	*gid = bi.pbi_gid;
+	return 0;
+}
+*/
+import "C"
+
+import "syscall"
+
+// readProcessIDs reads the real and effective IDs of pid from proc_pidinfo;
+// pbi_uid and pbi_gid are the effective IDs.
+func readProcessIDs(pid uint32) (processIDs, error) {
+	var ruid, uid, rgid, gid C.uint32_t
+	if rc := C.sysprims_go_ids(C.int(pid), &ruid, &uid, &rgid, &gid); rc != 0 {
+		return processIDs{}, errnoError(pid, syscall.Errno(rc))
+	}
+	return processIDs{uid: uint32(ruid), euid: uint32(uid), gid: uint32(rgid), egid: uint32(gid)}, nil
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readProcessIDs reads the Uid and Gid lines of /proc/<pid>/status, which
+// list the real, effective, saved, and filesystem IDs in that order.
+func readProcessIDs(pid uint32) (processIDs, error) {
+	path := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/status"
+	f, err := os.Open(path)
+	if err != nil {
+		return processIDs{}, procReadError(pid, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var ids processIDs
+	found := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() && found < 2 {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || (name != "Uid" && name != "Gid") {
+			continue
+		}
+		values := strings.Fields(rest)
+		if len(values) < 2 {
+			return processIDs{}, &Error{Code: ErrSystem, Message: "malformed " + name + " in " + path}
+		}
+		real, err1 := strconv.ParseUint(values[0], 10, 32)
+		effective, err2 := strconv.ParseUint(values[1], 10, 32)
+		if err1 != nil || err2 != nil {
+			return processIDs{}, &Error{Code: ErrSystem, Message: "malformed " + name + " in " + path}
+		}
+		if name == "Uid" {
+			ids.uid, ids.euid = uint32(real), uint32(effective)
+		} else {
+			ids.gid, ids.egid = uint32(real), uint32(effective)
+		}
+		found++
+	}
+	if err := scanner.Err(); err != nil {
+		return processIDs{}, procReadError(pid, err)
+	}
+	if found < 2 {
+		return processIDs{}, &Error{Code: ErrSystem, Message: "missing Uid or Gid in " + path}
+	}
+	return ids, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package sysprims
+
+import "runtime"
+
+// readProcessIDs is not implemented on this platform; Windows identifies
+// users by SID rather than numeric IDs.
+func readProcessIDs(pid uint32) (processIDs, error) {
+	return processIDs{}, &Error{Code: ErrNotSupported, Message: "numeric user and group ids are not supported on " + runtime.GOOS}
+}
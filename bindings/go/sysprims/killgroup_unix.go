@@ -0,0 +1,23 @@
+//go:build !windows
+
+package sysprims
+
+/*
+#include "sysprims.h"
+*/
+import "C"
+
+func killGroup(pgid uint32, signal int) error {
+	return callAndCheck(func() C.SysprimsErrorCode {
+		return C.sysprims_signal_send_group(C.uint32_t(pgid), C.int32_t(signal))
+	})
+}
+
+// trackGroupJob is a no-op: Unix groups are addressed by PGID directly.
+func trackGroupJob(pid uint32) {}
+
+// releaseGroupJob is a no-op, like trackGroupJob.
+func releaseGroupJob(pid uint32) {}
+
+// groupJobTerminated is a no-op, like trackGroupJob.
+func groupJobTerminated(pid uint32, warnings []string) {}
@@ -0,0 +1,95 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// jobTerminatedWarning is the TerminateTree warning with which the library
+// reports that it terminated the Job Object it holds for a PID.
+const jobTerminatedWarning = "Terminated via Job Object (spawn_in_group)"
+
+// groupJobs holds a handle to each SpawnInGroup child the library assigned
+// to a Job Object, keyed by PID. KillGroup only accepts these: any other PID
+// has no job sysprims can terminate. An entry outlives the child itself,
+// since the job's other members may not, and the handle keeps the PID from
+// being reused while KillGroup may still ask the library for its job. It is
+// released once the job is terminated or the child is waited on.
+var (
+	groupJobsMu sync.Mutex
+	groupJobs   = make(map[uint32]syscall.Handle)
+)
+
+// trackGroupJob records pid as a job leader until its job is terminated or
+// it is waited on.
+func trackGroupJob(pid uint32) {
+	h, err := syscall.OpenProcess(syscall.SYNCHRONIZE, false, pid)
+	if err != nil {
+		// Already gone, so its PID cannot be pinned; KillGroup will treat
+		// it as untracked.
+		return
+	}
+	groupJobsMu.Lock()
+	groupJobs[pid] = h
+	groupJobsMu.Unlock()
+}
+
+// releaseGroupJob forgets pid as a job leader and closes its handle, after
+// which the PID may be reused. Untracked PIDs are ignored.
+func releaseGroupJob(pid uint32) {
+	groupJobsMu.Lock()
+	h, tracked := groupJobs[pid]
+	delete(groupJobs, pid)
+	groupJobsMu.Unlock()
+	if tracked {
+		_ = syscall.CloseHandle(h)
+	}
+}
+
+// groupJobTerminated releases pid when the warnings of a TerminateTree of
+// pid report that the library terminated its job.
+func groupJobTerminated(pid uint32, warnings []string) {
+	for _, w := range warnings {
+		if w == jobTerminatedWarning {
+			releaseGroupJob(pid)
+			return
+		}
+	}
+}
+
+func killGroup(pgid uint32, signal int) error {
+	if err := validatePidList([]uint32{pgid}); err != nil {
+		return err
+	}
+	if signal != SIGTERM && signal != SIGKILL {
+		return &Error{Code: ErrNotSupported, Message: "signal " + strconv.Itoa(signal) + " cannot be sent to a job on windows"}
+	}
+	groupJobsMu.Lock()
+	h, tracked := groupJobs[pgid]
+	delete(groupJobs, pgid)
+	groupJobsMu.Unlock()
+	if !tracked {
+		return &Error{Code: ErrNotSupported, Message: "windows has no process groups; pid " + strconv.FormatUint(uint64(pgid), 10) +
+			" does not lead a Job Object created by SpawnInGroup"}
+	}
+	defer syscall.CloseHandle(h)
+
+	// The library looks the job up by the leader's PID, which the handle
+	// keeps from being reused, and terminates the whole job rather than the
+	// PID alone. Zero timeouts skip the graceful stage, which a job cannot
+	// receive anyway.
+	var zero uint64
+	res, err := TerminateTree(pgid, TerminateTreeConfig{GraceTimeoutMS: &zero, KillTimeoutMS: &zero})
+	if err != nil {
+		return err
+	}
+	for _, w := range res.Warnings {
+		if w == jobTerminatedWarning {
+			return nil
+		}
+	}
+	return &Error{Code: ErrNotFound, Message: "the library no longer holds a Job Object for pid " + strconv.FormatUint(uint64(pgid), 10)}
+}
@@ -0,0 +1,216 @@
+package sysprims
+
+import (
+	"os"
+	"sort"
+)
+
+// KillMatchingOptions configures [KillMatching].
+type KillMatchingOptions struct {
+	// Protected lists PIDs that are never signaled, in addition to the
+	// calling process, its ancestors, and PID 1.
+	Protected []uint32
+	// AllowAncestors lets the ancestors of the calling process (its parent,
+	// grandparent, and so on) be signaled. The calling process and PID 1
+	// stay protected.
+	AllowAncestors bool
+	// DryRun reports the PIDs that would be signaled in Targets without
+	// signaling anything.
+	DryRun bool
+	// Policy adds protections to the safety rules; see [SafetyPolicy].
+	Policy *SafetyPolicy
+}
+
+// KillMatchingResult is the result of [KillMatching] and [KillByName].
+type KillMatchingResult struct {
+	BatchKillResult
+	// Targets lists the PIDs that would have been signaled. Set with DryRun
+	// only.
+	Targets []uint32
+	// SkippedSafety lists matching PIDs left alone by the safety rules.
+	SkippedSafety []uint32
+	// SkippedPolicy lists matching PIDs left alone by a [SafetyPolicy].
+	SkippedPolicy []uint32
+}
+
+// KillMatching sends signal to every process matching filter, like pkill.
+//
+// It takes one [ProcessList] snapshot and evaluates filter against it in the
+// Go bindings, so a filter that matches nothing yields an empty result. The
+// calling process, its ancestors (unless opts.AllowAncestors), PID 1, and
+// any PID in opts.Protected are never signaled and are listed in
+// SkippedSafety. Matches protected by opts.Policy or the default
+// [SafetyPolicy] are listed in SkippedPolicy. The remaining PIDs are
+// signaled in ascending order, with per-PID results as in [KillMany]. A
+// process that exits after the snapshot is reported in Failed with
+// [ErrNotFound]; a PID reused in that window is not detected.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: filter is nil or sets no criteria, or is invalid,
+//     or more PIDs than the policy's MaxKillCount would be signaled (also
+//     with DryRun)
+//   - [ErrNotSupported]: The policy sets ProtectOtherUsers and the calling
+//     process's user cannot be resolved
+//   - [ErrSystem]: System error reading process information
+func KillMatching(filter *ProcessFilter, signal int, opts *KillMatchingOptions) (*KillMatchingResult, error) {
+	if filter.isEmpty() {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "kill matching requires a non-empty filter"}
+	}
+	if opts == nil {
+		opts = &KillMatchingOptions{}
+	}
+	if err := filter.validateLibrary(); err != nil {
+		return nil, err
+	}
+	if err := filter.validateGo(); err != nil {
+		return nil, err
+	}
+	check, err := resolveSafety(opts.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := ProcessList(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	self := uint32(os.Getpid())
+	protected := map[uint32]bool{self: true, 1: true}
+	for _, pid := range opts.Protected {
+		protected[pid] = true
+	}
+	if !opts.AllowAncestors {
+		for _, pid := range ancestorsOf(self, snapshot.Processes) {
+			protected[pid] = true
+		}
+	}
+
+	result := &KillMatchingResult{
+		BatchKillResult: BatchKillResult{Succeeded: []uint32{}, Failed: []BatchKillFailure{}},
+		SkippedSafety:   []uint32{},
+		SkippedPolicy:   []uint32{},
+	}
+	var targets []uint32
+	for i := range snapshot.Processes {
+		p := &snapshot.Processes[i]
+		if !filter.matchesLibrary(p) || !filter.matchesGo(p) {
+			continue
+		}
+		switch {
+		case protected[p.PID]:
+			result.SkippedSafety = append(result.SkippedSafety, p.PID)
+		case check.protects(p.PID, p):
+			result.SkippedPolicy = append(result.SkippedPolicy, p.PID)
+		default:
+			targets = append(targets, p.PID)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	sort.Slice(result.SkippedSafety, func(i, j int) bool { return result.SkippedSafety[i] < result.SkippedSafety[j] })
+	sort.Slice(result.SkippedPolicy, func(i, j int) bool { return result.SkippedPolicy[i] < result.SkippedPolicy[j] })
+	if err := check.checkCount(len(targets)); err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		result.Targets = append([]uint32{}, targets...)
+		return result, nil
+	}
+	if len(targets) > 0 {
+		batch, err := KillMany(targets, signal)
+		if err != nil {
+			return nil, err
+		}
+		result.Succeeded = append(result.Succeeded, batch.Succeeded...)
+		result.Failed = append(result.Failed, batch.Failed...)
+	}
+	return result, nil
+}
+
+// ancestorsOf returns the ancestors of pid in processes, nearest first. The
+// walk stops at a PID missing from processes or at a cycle.
+func ancestorsOf(pid uint32, processes []ProcessInfo) []uint32 {
+	parent := make(map[uint32]uint32, len(processes))
+	for i := range processes {
+		parent[processes[i].PID] = processes[i].PPID
+	}
+	var ancestors []uint32
+	seen := map[uint32]bool{pid: true}
+	for {
+		ppid, ok := parent[pid]
+		if !ok || ppid == 0 || seen[ppid] {
+			return ancestors
+		}
+		seen[ppid] = true
+		ancestors = append(ancestors, ppid)
+		pid = ppid
+	}
+}
+
+// KillByNameOptions configures [KillByNameWithOptions].
+type KillByNameOptions struct {
+	// Exact matches the whole process name (like pkill -x); otherwise name
+	// is a case-insensitive substring.
+	Exact bool
+	// AllUsers also matches processes of other users. By default only
+	// processes whose User equals the calling process's are matched.
+	AllUsers bool
+	// Protected lists PIDs that are never signaled; see [KillMatching].
+	Protected []uint32
+}
+
+// KillByName sends signal to the processes named name, like pkill (or
+// pkill -x when exact is set). It is shorthand for [KillByNameWithOptions]
+// with only Exact set, so only the calling user's processes are matched.
+func KillByName(name string, exact bool, signal int) (*KillMatchingResult, error) {
+	return KillByNameWithOptions(name, signal, &KillByNameOptions{Exact: exact})
+}
+
+// KillByNameWithOptions sends signal to the processes whose name matches
+// name, using [KillMatching] with its safety rules: the calling process, its
+// ancestors, and PID 1 are never signaled.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: name is empty
+//   - [ErrNotFound]: No process matched, so typos are noticed; processes
+//     skipped by the safety rules or policy count as matched
+//   - [ErrNotSupported]: The calling process's user cannot be resolved
+//     (unless opts.AllUsers)
+//   - Errors from [KillMatching]
+func KillByNameWithOptions(name string, signal int, opts *KillByNameOptions) (*KillMatchingResult, error) {
+	if name == "" {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "name must not be empty"}
+	}
+	if opts == nil {
+		opts = &KillByNameOptions{}
+	}
+
+	filter := &ProcessFilter{}
+	if opts.Exact {
+		filter.NameEquals = &name
+	} else {
+		filter.NameContains = &name
+	}
+	if !opts.AllUsers {
+		self, err := Self()
+		if err != nil {
+			return nil, err
+		}
+		if self.User == nil {
+			return nil, &Error{Code: ErrNotSupported, Message: "cannot resolve the calling process's user"}
+		}
+		filter.UserEquals = self.User
+	}
+
+	result, err := KillMatching(filter, signal, &KillMatchingOptions{Protected: opts.Protected})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Succeeded)+len(result.Failed)+len(result.SkippedSafety)+len(result.SkippedPolicy) == 0 {
+		return nil, &Error{Code: ErrNotFound, Message: "no process matched name: " + name}
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package sysprims
+
+import (
+	"errors"
+	"os"
+)
+
+// KillTree sends signal to the descendants of pid and then to pid itself:
+// "kill this process and everything under it".
+//
+// Descendants are signaled first, exactly as [KillDescendantsWithOptions]
+// would with opts (signal overrides opts.Signal; 0 means SIGTERM), so they
+// are not reparented mid-kill by the root exiting first. The root is then
+// signaled regardless of opts.Filter, and its outcome is appended to
+// Succeeded or Failed.
+//
+// The root's identity is captured before any signal is sent; if it has
+// exited (or its PID was reused) by the time the descendants are done, it is
+// reported in Failed rather than signaled. The safety rules apply to the
+// root too: self, PID 1, and the parent are never signaled and count toward
+// SkippedSafety. A root protected by the [SafetyPolicy] of opts (or the
+// default policy), judged from the same snapshot as its descendants, is
+// listed in SkippedPolicy instead of being signaled; the policy's
+// MaxKillCount counts the root along with the descendants when it is to be
+// signaled.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32, opts is invalid, or
+//     more processes than the policy's MaxKillCount would be signaled
+//   - [ErrNotFound]: root process doesn't exist
+func KillTree(pid uint32, signal int, opts *KillDescendantsOptions) (*KillDescendantsResult, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+	if signal == 0 {
+		signal = SIGTERM
+	}
+	id, idErr := IdentityOf(pid)
+	var sErr *Error
+	if errors.As(idErr, &sErr) && sErr.Code == ErrNotFound {
+		return nil, idErr
+	}
+
+	descOpts := KillDescendantsOptions{}
+	if opts != nil {
+		descOpts = *opts
+	}
+	descOpts.Signal = signal
+	root := &treeRoot{}
+	result, err := killDescendants(pid, &descOpts, root)
+	if err != nil {
+		return nil, err
+	}
+
+	if pid == uint32(os.Getpid()) || pid == 1 || pid == uint32(os.Getppid()) {
+		result.SkippedSafety++
+		return result, nil
+	}
+	if root.protected {
+		result.SkippedPolicy = append(result.SkippedPolicy, pid)
+		return result, nil
+	}
+	// Without a readable start time the root cannot be told apart from a
+	// process that reused its PID; signal it as Kill would.
+	if idErr == nil {
+		running, err := id.StillRunning()
+		if err == nil && !running {
+			result.Failed = append(result.Failed, KillDescendantsFail{PID: pid, Error: "process exited before it was signaled"})
+			return result, nil
+		}
+	}
+	if err := Kill(pid, signal); err != nil {
+		result.Failed = append(result.Failed, KillDescendantsFail{PID: pid, Error: err.Error()})
+	} else {
+		result.Succeeded = append(result.Succeeded, pid)
+	}
+	return result, nil
+}
@@ -0,0 +1,228 @@
+package sysprims
+
+import (
+	"errors"
+	"runtime"
+	"time"
+)
+
+// KillAndWaitResult is the result of [KillAndWait].
+type KillAndWaitResult struct {
+	PID uint32 `json:"pid"`
+	// Exited is set once the process is gone: it exited (a zombie counts),
+	// or its PID was reused by another process.
+	Exited bool `json:"exited"`
+	// TimedOut is set when the timeout elapsed with the process still
+	// running.
+	TimedOut bool `json:"timed_out"`
+	// ExitCode is set when the platform reports it. On Unix a process is
+	// only waited on, never reaped, so it is nil; use [Reap] for children.
+	ExitCode *int32 `json:"exit_code,omitempty"`
+	// ElapsedMS is the time from sending the signal until the exit was
+	// seen or the timeout elapsed.
+	ElapsedMS uint64 `json:"elapsed_ms"`
+}
+
+// KillAndWait sends signal to pid and waits up to timeout for it to exit.
+//
+// The process's identity (PID and start time) is captured before signaling,
+// and a PID that is taken over by a different process during the wait is
+// reported as exited rather than waited on. A process that is still running
+// when timeout elapses yields TimedOut rather than an error, so callers can
+// escalate (for example to SIGKILL). A timeout of 0 checks once.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32, or timeout is
+//     negative
+//   - [ErrNotFound]: Process doesn't exist when signaled
+//   - [ErrPermissionDenied]: Not permitted to read or signal this process
+//   - [ErrNotSupported]: Start time is unavailable, or signal not supported
+//     on this platform
+func KillAndWait(pid uint32, signal int, timeout time.Duration) (*KillAndWaitResult, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+	if timeout < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "timeout must be >= 0"}
+	}
+	id, err := IdentityOf(pid)
+	if err != nil {
+		return nil, err
+	}
+	return signalAndWait(id, func() error { return Kill(pid, signal) }, timeout)
+}
+
+// signalAndWait implements KillAndWait for a process whose identity has
+// already been captured, signaling it with send.
+func signalAndWait(id ProcessIdentity, send func() error, timeout time.Duration) (*KillAndWaitResult, error) {
+	pid := id.PID
+	if err := send(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	result := &KillAndWaitResult{PID: pid}
+	for {
+		step := min(DefaultWaitPollInterval, time.Until(deadline))
+		res, err := WaitPID(pid, max(step, 0))
+		var sErr *Error
+		switch {
+		case err == nil && res.Exited:
+			result.Exited = true
+			result.ExitCode = res.ExitCode
+		case err == nil:
+			// Still running at pid, unless the PID now belongs to another
+			// process.
+			running, err := id.StillRunning()
+			if err != nil {
+				return nil, err
+			}
+			result.Exited = !running
+		case errors.As(err, &sErr) && sErr.Code == ErrNotFound:
+			result.Exited = true
+		default:
+			return nil, err
+		}
+
+		if result.Exited || !time.Now().Before(deadline) {
+			result.TimedOut = !result.Exited
+			result.ElapsedMS = uint64(time.Since(start) / time.Millisecond)
+			return result, nil
+		}
+	}
+}
+
+// DefaultGracefulStopKillTimeout is how long [GracefulStop] waits after
+// escalating when GracefulStopOptions.KillTimeout is 0. It matches the
+// library's TerminateTree default.
+const DefaultGracefulStopKillTimeout = 2 * time.Second
+
+// GracefulStopOptions configures [GracefulStopWithOptions].
+type GracefulStopOptions struct {
+	// Signal is sent first. 0 means SIGTERM.
+	Signal int
+	// KillSignal is sent when the process outlives the grace period. 0
+	// means SIGKILL.
+	KillSignal int
+	// KillTimeout bounds the wait after KillSignal. 0 means
+	// DefaultGracefulStopKillTimeout.
+	KillTimeout time.Duration
+	// ConsoleCtrl sends a console control event with [SignalGraceful]
+	// instead of Signal on Windows, where Signal can only be
+	// TerminateProcess. It is ignored on Unix.
+	ConsoleCtrl bool
+}
+
+// GracefulStopResult is the result of [GracefulStop].
+type GracefulStopResult struct {
+	PID uint32 `json:"pid"`
+	// Exited is set once the process is gone (see [KillAndWaitResult]).
+	Exited bool `json:"exited"`
+	// Escalated is set when KillSignal was sent.
+	Escalated bool `json:"escalated"`
+	// EndedBy is the last signal sent before the process exited: Signal, or
+	// KillSignal when Escalated. It is 0 when the process did not exit. A
+	// console control event (ConsoleCtrl) is reported as Signal.
+	EndedBy int `json:"ended_by,omitempty"`
+	// ExitCode is set when the platform reports it (see [KillAndWaitResult]).
+	ExitCode *int32 `json:"exit_code,omitempty"`
+	// GraceMS is the time from sending Signal until the exit was seen or
+	// the grace period elapsed.
+	GraceMS uint64 `json:"grace_ms"`
+	// ElapsedMS is the time from sending Signal until GracefulStop returned.
+	ElapsedMS uint64 `json:"elapsed_ms"`
+}
+
+// GracefulStop sends SIGTERM to pid, waits up to grace for it to exit, and
+// sends SIGKILL if it is still running. It is GracefulStopWithOptions with
+// default options.
+func GracefulStop(pid uint32, grace time.Duration) (*GracefulStopResult, error) {
+	return GracefulStopWithOptions(pid, grace, GracefulStopOptions{})
+}
+
+// GracefulStopWithOptions stops a single process: it sends Signal, waits up
+// to grace, then sends KillSignal and waits up to KillTimeout.
+//
+// Unlike [TerminateTree] only pid is signaled, never its process group. The
+// process's identity is captured before the first signal, and a PID taken
+// over by a different process is reported as exited rather than escalated
+// to. A process still running after KillTimeout yields a result with
+// Exited false rather than an error.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32, or grace or
+//     KillTimeout is negative
+//   - [ErrNotFound]: Process doesn't exist when first signaled
+//   - [ErrPermissionDenied]: Not permitted to read or signal this process
+//   - [ErrNotSupported]: Start time is unavailable, or a signal is not
+//     supported on this platform
+func GracefulStopWithOptions(pid uint32, grace time.Duration, opts GracefulStopOptions) (*GracefulStopResult, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+	if grace < 0 || opts.KillTimeout < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "grace and kill timeout must be >= 0"}
+	}
+	if opts.Signal == 0 {
+		opts.Signal = SIGTERM
+	}
+	if opts.KillSignal == 0 {
+		opts.KillSignal = SIGKILL
+	}
+	if opts.KillTimeout == 0 {
+		opts.KillTimeout = DefaultGracefulStopKillTimeout
+	}
+	id, err := IdentityOf(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	send := func() error { return Kill(pid, opts.Signal) }
+	if opts.ConsoleCtrl && runtime.GOOS == "windows" {
+		send = func() error { return signalGraceful(pid) }
+	}
+	start := time.Now()
+	first, err := signalAndWait(id, send, grace)
+	if err != nil {
+		return nil, err
+	}
+	result := &GracefulStopResult{PID: pid, GraceMS: first.ElapsedMS}
+	if first.Exited {
+		result.Exited = true
+		result.EndedBy = opts.Signal
+		result.ExitCode = first.ExitCode
+		result.ElapsedMS = uint64(time.Since(start) / time.Millisecond)
+		return result, nil
+	}
+
+	// Re-check the identity right before escalating, so a process that
+	// exited and had its PID reused since the last check is not killed.
+	running, err := id.StillRunning()
+	if err != nil {
+		return nil, err
+	}
+	var final *KillAndWaitResult
+	if running {
+		result.Escalated = true
+		final, err = signalAndWait(id, func() error { return Kill(pid, opts.KillSignal) }, opts.KillTimeout)
+	}
+	var sErr *Error
+	switch {
+	case !running || errors.As(err, &sErr) && sErr.Code == ErrNotFound:
+		// Exited between the last check and the escalation.
+		result.Escalated = false
+		result.Exited = true
+		result.EndedBy = opts.Signal
+	case err != nil:
+		return nil, err
+	case final.Exited:
+		result.Exited = true
+		result.EndedBy = opts.KillSignal
+		result.ExitCode = final.ExitCode
+	}
+	result.ElapsedMS = uint64(time.Since(start) / time.Millisecond)
+	return result, nil
+}
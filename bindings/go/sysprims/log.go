@@ -0,0 +1,59 @@
+package sysprims
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// logger receives best-effort degradation reports; nil disables logging.
+var logger atomic.Pointer[slog.Logger]
+
+// SetLogger sets the logger used to report best-effort degradation, such as
+// snapshot warnings or a tree kill that could only be best-effort. Pass nil
+// to disable logging (the default).
+//
+// Records are emitted at [slog.LevelWarn] with the attributes "op" (the Go
+// function name), "pid" (when the operation targets one), and, for warnings,
+// "warning_codes" and "warnings". Logging never changes return values.
+//
+// SetLogger is safe to call concurrently with other functions in this package.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// warningDetails classifies messages and logs them on behalf of op.
+func warningDetails(op string, pid uint32, messages []string) []Warning {
+	warnings := classifyWarnings(messages)
+	if l := logger.Load(); l != nil && len(warnings) > 0 {
+		codes := make([]string, len(warnings))
+		for i, w := range warnings {
+			codes[i] = string(w.Code)
+		}
+		attrs := append(opAttrs(op, pid),
+			slog.Any("warning_codes", codes),
+			slog.Any("warnings", messages),
+		)
+		l.LogAttrs(context.Background(), slog.LevelWarn, "sysprims: best-effort warnings", attrs...)
+	}
+	return warnings
+}
+
+// logTreeKillReliability logs when op could only kill a tree best-effort.
+func logTreeKillReliability(op string, pid uint32, reliability string) {
+	if reliability != "best_effort" {
+		return
+	}
+	if l := logger.Load(); l != nil {
+		attrs := append(opAttrs(op, pid), slog.String("tree_kill_reliability", reliability))
+		l.LogAttrs(context.Background(), slog.LevelWarn, "sysprims: degraded tree kill", attrs...)
+	}
+}
+
+func opAttrs(op string, pid uint32) []slog.Attr {
+	attrs := []slog.Attr{slog.String("op", op)}
+	if pid != 0 {
+		attrs = append(attrs, slog.Uint64("pid", uint64(pid)))
+	}
+	return attrs
+}
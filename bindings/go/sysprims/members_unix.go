@@ -0,0 +1,39 @@
+//go:build !windows
+
+package sysprims
+
+/*
+#include <unistd.h>
+*/
+import "C"
+import (
+	"sort"
+	"syscall"
+)
+
+// membersOf returns, in ascending order, the PIDs in snapshot whose process
+// group (or, with session set, session) is id. Processes whose IDs cannot be
+// read, such as those that exited since the snapshot, are left out.
+func membersOf(id uint32, session bool, snapshot *ProcessSnapshot) ([]uint32, error) {
+	lookup := syscall.Getpgid
+	if session {
+		lookup = getsid
+	}
+	var members []uint32
+	for _, p := range snapshot.Processes {
+		if got, err := lookup(int(p.PID)); err == nil && got == int(id) {
+			members = append(members, p.PID)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+	return members, nil
+}
+
+// getsid calls getsid(2), which the syscall package lacks on Linux.
+func getsid(pid int) (int, error) {
+	sid, err := C.getsid(C.pid_t(pid))
+	if sid < 0 {
+		return 0, err
+	}
+	return int(sid), nil
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package sysprims
+
+// membersOf is not supported: Windows has neither process groups nor
+// sessions in the Unix sense.
+func membersOf(id uint32, session bool, snapshot *ProcessSnapshot) ([]uint32, error) {
+	return nil, &Error{Code: ErrNotSupported, Message: "process groups and sessions are not supported on windows"}
+}
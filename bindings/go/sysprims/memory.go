@@ -0,0 +1,22 @@
+package sysprims
+
+// MemoryDetail breaks down a process's memory use (requires
+// ProcessOptions.IncludeMemoryDetail). Fields a platform cannot report are
+// nil.
+//
+// Sources:
+// - Linux: /proc/<pid>/smaps_rollup when readable (same user or privileged), else /proc/<pid>/status
+// - macOS: proc_pidinfo task info; SharedKB and SwapKB are unavailable
+// - Windows: PROCESS_MEMORY_COUNTERS_EX; VSZKB is the commit charge (PrivateUsage), SharedKB and SwapKB are unavailable
+type MemoryDetail struct {
+	// RSSKB is the resident set size in kilobytes.
+	RSSKB uint64 `json:"rss_kb"`
+	// VSZKB is the virtual memory size in kilobytes.
+	VSZKB uint64 `json:"vsz_kb"`
+	// SharedKB is the resident memory that may be shared with other
+	// processes: pages actually mapped by more than one process when read
+	// from smaps_rollup, file-backed and shmem pages when read from status.
+	SharedKB *uint64 `json:"shared_kb,omitempty"`
+	// SwapKB is the memory swapped out, in kilobytes.
+	SwapKB *uint64 `json:"swap_kb,omitempty"`
+}
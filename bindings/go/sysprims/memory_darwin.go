@@ -0,0 +1,38 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <sys/proc_info.h>
+
+static int sysprims_go_task_memory(int pid, uint64_t *virt, uint64_t *resident) {
+	struct proc_taskinfo ti;
+	int n = proc_pidinfo(pid, PROC_PIDTASKINFO, 0, &ti, sizeof(ti));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	if (n < (int)sizeof(ti)) {
+		return EIO;
+	}
+	*virt = ti.pti_virtual_size;
+	*resident = ti.pti_resident_size;
+	return 0;
+}
+*/
+import "C"
+
+import "syscall"
+
+// readMemoryDetail reads the virtual and resident size of pid.
+func readMemoryDetail(pid uint32) (*MemoryDetail, error) {
+	var virt, resident C.uint64_t
+	if rc := C.sysprims_go_task_memory(C.int(pid), &virt, &resident); rc != 0 {
+		return nil, errnoError(pid, syscall.Errno(rc))
+	}
+	return &MemoryDetail{
+		RSSKB: uint64(resident) / 1024,
+		VSZKB: uint64(virt) / 1024,
+	}, nil
+}
@@ -0,0 +1,70 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readMemoryDetail reads the memory breakdown of pid. VmSize comes from
+// status; RSS, shared, and swap come from smaps_rollup when it is readable
+// and from status otherwise. Kernel threads, which have no address space,
+// return an error.
+func readMemoryDetail(pid uint32) (*MemoryDetail, error) {
+	dir := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/"
+	status, err := readKBFields(dir + "status")
+	if err != nil {
+		return nil, procReadError(pid, err)
+	}
+	vsz, ok := status["VmSize"]
+	if !ok {
+		return nil, &Error{Code: ErrNotSupported, Message: "process has no address space"}
+	}
+
+	m := &MemoryDetail{VSZKB: vsz}
+	if rollup, err := readKBFields(dir + "smaps_rollup"); err == nil {
+		shared := rollup["Shared_Clean"] + rollup["Shared_Dirty"]
+		swap := rollup["Swap"]
+		m.RSSKB = rollup["Rss"]
+		m.SharedKB = &shared
+		m.SwapKB = &swap
+		return m, nil
+	}
+
+	m.RSSKB = status["VmRSS"]
+	shared := status["RssFile"] + status["RssShmem"]
+	m.SharedKB = &shared
+	if swap, ok := status["VmSwap"]; ok {
+		m.SwapKB = &swap
+	}
+	return m, nil
+}
+
+// readKBFields parses "Name:   123 kB" lines into kilobyte values.
+func readKBFields(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	fields := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value, unit, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		if unit != "kB" {
+			continue
+		}
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			fields[name] = v
+		}
+	}
+	return fields, scanner.Err()
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package sysprims
+
+import "runtime"
+
+// readMemoryDetail is not implemented on this platform.
+func readMemoryDetail(pid uint32) (*MemoryDetail, error) {
+	return nil, &Error{Code: ErrNotSupported, Message: "memory detail is not supported on " + runtime.GOOS}
+}
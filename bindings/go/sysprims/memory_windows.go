@@ -0,0 +1,45 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetProcessMemoryInfo = modKernel32.NewProc("K32GetProcessMemoryInfo")
+
+// processMemoryCountersEx is PROCESS_MEMORY_COUNTERS_EX.
+type processMemoryCountersEx struct {
+	cb                         uint32
+	pageFaultCount             uint32
+	peakWorkingSetSize         uintptr
+	workingSetSize             uintptr
+	quotaPeakPagedPoolUsage    uintptr
+	quotaPagedPoolUsage        uintptr
+	quotaPeakNonPagedPoolUsage uintptr
+	quotaNonPagedPoolUsage     uintptr
+	pagefileUsage              uintptr
+	peakPagefileUsage          uintptr
+	privateUsage               uintptr
+}
+
+// readMemoryDetail reads the working set and commit charge of pid.
+func readMemoryDetail(pid uint32) (*MemoryDetail, error) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return nil, winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var pmc processMemoryCountersEx
+	pmc.cb = uint32(unsafe.Sizeof(pmc))
+	r, _, e := procGetProcessMemoryInfo.Call(uintptr(h), uintptr(unsafe.Pointer(&pmc)), uintptr(pmc.cb))
+	if r == 0 {
+		return nil, systemError(e)
+	}
+	return &MemoryDetail{
+		RSSKB: uint64(pmc.workingSetSize) / 1024,
+		VSZKB: uint64(pmc.privateUsage) / 1024,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package sysprims
+
+// Valid range for oom_score_adj, per proc(5).
+const (
+	minOomScoreAdj = -1000
+	maxOomScoreAdj = 1000
+)
+
+// SetOomScoreAdj sets the OOM killer adjustment for pid.
+//
+// adj ranges from -1000 (never OOM-kill) to 1000 (kill first). Lowering the
+// value below its previous setting requires CAP_SYS_RESOURCE.
+//
+// Linux only; writes /proc/<pid>/oom_score_adj.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32, or adj is out of range
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to set this value
+//   - [ErrNotSupported]: Not Linux
+func SetOomScoreAdj(pid uint32, adj int32) error {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return err
+	}
+	if adj < minOomScoreAdj || adj > maxOomScoreAdj {
+		return &Error{Code: ErrInvalidArgument, Message: "oom_score_adj must be between -1000 and 1000"}
+	}
+	return setOomScoreAdj(pid, adj)
+}
+
+// checkOOM rejects IncludeOOM on platforms without OOM scores.
+func (opts *ProcessOptions) checkOOM() error {
+	if opts != nil && opts.IncludeOOM && !oomSupported {
+		return &Error{Code: ErrNotSupported, Message: "oom scores are only supported on linux"}
+	}
+	return nil
+}
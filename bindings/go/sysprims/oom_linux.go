@@ -0,0 +1,53 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+const oomSupported = true
+
+// readOOM fills OomScore and OomScoreAdj from /proc/<pid>. Fields that cannot
+// be read (for example because the process exited) are left nil.
+func readOOM(p *ProcessInfo) {
+	dir := "/proc/" + strconv.FormatUint(uint64(p.PID), 10) + "/"
+	if v, ok := readProcInt32(dir + "oom_score"); ok {
+		p.OomScore = &v
+	}
+	if v, ok := readProcInt32(dir + "oom_score_adj"); ok {
+		p.OomScoreAdj = &v
+	}
+}
+
+// readProcInt32 reads a single-integer /proc file.
+func readProcInt32(path string) (int32, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(v), true
+}
+
+// setOomScoreAdj writes /proc/<pid>/oom_score_adj.
+func setOomScoreAdj(pid uint32, adj int32) error {
+	path := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/oom_score_adj"
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return procReadError(pid, err)
+	}
+	_, err = f.WriteString(strconv.FormatInt(int64(adj), 10))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return procReadError(pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package sysprims
+
+import "runtime"
+
+const oomSupported = false
+
+// readOOM is not implemented on this platform; checkOOM rejects IncludeOOM
+// before it could be called.
+func readOOM(p *ProcessInfo) {}
+
+// setOomScoreAdj is not implemented on this platform.
+func setOomScoreAdj(pid uint32, adj int32) error {
+	return &Error{Code: ErrNotSupported, Message: "oom_score_adj is not supported on " + runtime.GOOS}
+}
@@ -0,0 +1,93 @@
+package sysprims
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// OSProcess returns an [os.Process] for pid, verifying that the process is
+// alive and not a zombie.
+//
+// See [ProcessInfo.OSProcess] for details.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist or has exited
+//   - [ErrPermissionDenied]: Not permitted to open this process
+func OSProcess(pid uint32) (*os.Process, error) {
+	info, err := ProcessGet(pid)
+	if err != nil {
+		return nil, err
+	}
+	return info.OSProcess()
+}
+
+// OSProcess returns an [os.Process] for p.PID, refusing to return a handle to
+// a process that has exited or whose PID now belongs to a different process.
+//
+// The process is opened with [os.FindProcess] before it is re-checked. On
+// Windows the result holds a real process handle, and on Linux with Go 1.23+
+// a pidfd; either pins the PID, so a process that passes the check cannot be
+// replaced afterwards. Elsewhere the os.Process is a bare PID and the check is
+// best-effort. The start-time comparison is skipped when p has no
+// StartTimeUnixMS.
+//
+// Call Release on the result when done with it.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: p.PID is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist, has exited, or p is stale
+//   - [ErrPermissionDenied]: Not permitted to open this process
+func (p *ProcessInfo) OSProcess() (*os.Process, error) {
+	if err := validatePidList([]uint32{p.PID}); err != nil {
+		return nil, err
+	}
+
+	proc, err := os.FindProcess(int(p.PID))
+	if err != nil {
+		return nil, findProcessError(p.PID, err)
+	}
+
+	if err := p.checkAlive(); err != nil {
+		_ = proc.Release()
+		return nil, err
+	}
+	return proc, nil
+}
+
+// checkAlive returns nil if the process described by p is still running.
+func (p *ProcessInfo) checkAlive() error {
+	current, err := ProcessGet(p.PID)
+	if err != nil {
+		return err
+	}
+	if current.IsZombie() {
+		return &Error{Code: ErrNotFound, Message: "process " + strconv.FormatUint(uint64(p.PID), 10) + " has exited"}
+	}
+	if id, ok := p.Identity(); ok {
+		if same, err := id.matches(current); err == nil && !same {
+			return &Error{
+				Code:    ErrNotFound,
+				Message: "stale process info: pid " + strconv.FormatUint(uint64(p.PID), 10) + " now belongs to a different process",
+			}
+		}
+	}
+	return nil
+}
+
+// findProcessError maps an os.FindProcess failure to a sysprims error.
+func findProcessError(pid uint32, err error) error {
+	var errno syscall.Errno
+	errors.As(err, &errno)
+	if errors.Is(err, os.ErrPermission) {
+		return (&Error{Code: ErrPermissionDenied, Message: err.Error()}).withErrno(errno)
+	}
+	return (&Error{
+		Code:    ErrNotFound,
+		Message: "process " + strconv.FormatUint(uint64(pid), 10) + " not found: " + err.Error(),
+	}).withErrno(errno)
+}
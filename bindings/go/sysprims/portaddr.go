@@ -0,0 +1,182 @@
+package sysprims
+
+import (
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// AddressFamily is the address family of a [PortBinding].
+type AddressFamily string
+
+const (
+	FamilyIPv4 AddressFamily = "ipv4"
+	FamilyIPv6 AddressFamily = "ipv6"
+)
+
+// LocalAddrPort returns the local address and port of b, with an
+// IPv4-mapped address unmapped and ScopeID applied as the zone. ok is false
+// when LocalAddr is absent or not an IP address.
+func (b *PortBinding) LocalAddrPort() (netip.AddrPort, bool) {
+	return parseAddrPort(b.LocalAddr, &b.LocalPort, b.ScopeID)
+}
+
+// RemoteAddrPort is like LocalAddrPort for the peer; ok is false when b has
+// no peer.
+func (b *PortBinding) RemoteAddrPort() (netip.AddrPort, bool) {
+	return parseAddrPort(b.RemoteAddr, b.RemotePort, nil)
+}
+
+// IsLoopback reports whether b is bound to a loopback address
+// (127.0.0.0/8 or ::1).
+func (b *PortBinding) IsLoopback() bool {
+	ap, ok := b.LocalAddrPort()
+	return ok && ap.Addr().IsLoopback()
+}
+
+// IsWildcard reports whether b is bound to the unspecified address
+// (0.0.0.0 or ::).
+func (b *PortBinding) IsWildcard() bool {
+	ap, ok := b.LocalAddrPort()
+	return ok && ap.Addr().IsUnspecified()
+}
+
+// LocalAddrPort returns the local address and port of c, with an
+// IPv4-mapped address unmapped. ok is false when LocalAddr is absent or not
+// an IP address.
+func (c *Connection) LocalAddrPort() (netip.AddrPort, bool) {
+	return parseAddrPort(c.LocalAddr, &c.LocalPort, nil)
+}
+
+// RemoteAddrPort is like LocalAddrPort for the peer; ok is false when c has
+// no peer.
+func (c *Connection) RemoteAddrPort() (netip.AddrPort, bool) {
+	return parseAddrPort(c.RemoteAddr, c.RemotePort, nil)
+}
+
+// IsLoopback reports whether c's local address is a loopback address.
+func (c *Connection) IsLoopback() bool {
+	ap, ok := c.LocalAddrPort()
+	return ok && ap.Addr().IsLoopback()
+}
+
+// IsWildcard reports whether c's local address is the unspecified address.
+func (c *Connection) IsWildcard() bool {
+	ap, ok := c.LocalAddrPort()
+	return ok && ap.Addr().IsUnspecified()
+}
+
+// parseAddrPort parses an address reported by the library or the Go
+// bindings. A zone already present in addr is kept; otherwise scope, when
+// set, supplies it.
+func parseAddrPort(addr *string, port *uint16, scope *uint32) (netip.AddrPort, bool) {
+	if addr == nil || port == nil {
+		return netip.AddrPort{}, false
+	}
+	ip, err := netip.ParseAddr(*addr)
+	if err != nil {
+		return netip.AddrPort{}, false
+	}
+	ip = ip.Unmap()
+	if scope != nil && ip.Is6() && ip.Zone() == "" {
+		ip = ip.WithZone(zoneName(*scope))
+	}
+	return netip.AddrPortFrom(ip, *port), true
+}
+
+// portKey identifies a bound inet socket in a socket diagnostics dump.
+type portKey struct {
+	protocol Protocol
+	v6       bool
+	addr     [16]byte
+	port     uint16
+}
+
+// portDiag holds the socket details that the library's listing lacks.
+type portDiag struct {
+	ifindex uint32
+	v6only  *bool
+}
+
+// newPortKey returns the key of a socket bound to ip and port, where v6
+// reports whether the socket is an IPv6 one (IPv4-mapped addresses are).
+func newPortKey(protocol Protocol, v6 bool, ip net.IP, port uint16) portKey {
+	k := portKey{protocol: protocol, v6: v6, port: port}
+	if v6 {
+		copy(k.addr[:], ip.To16())
+	} else {
+		copy(k.addr[:], ip.To4())
+	}
+	return k
+}
+
+// annotatePortBindings sets Family, ScopeID, and V6Only on the bindings of
+// snapshot and rewrites IPv4-mapped local addresses to IPv4 form.
+//
+// Scope IDs and v6only flags come from readPortDiags, which is queried once
+// per protocol that has IPv6 bindings. A failed query adds a warning and
+// leaves those fields nil.
+func annotatePortBindings(snapshot *PortBindingsSnapshot) {
+	diags := make(map[Protocol]map[portKey]portDiag)
+	diagsFor := func(protocol Protocol) map[portKey]portDiag {
+		d, ok := diags[protocol]
+		if !ok {
+			var err error
+			d, err = readPortDiags(protocol)
+			if err != nil {
+				snapshot.Warnings = append(snapshot.Warnings, "IPv6 scope and v6only details unavailable for "+
+					string(protocol)+": "+err.Error())
+			}
+			diags[protocol] = d
+		}
+		return d
+	}
+
+	for i := range snapshot.Bindings {
+		b := &snapshot.Bindings[i]
+		if b.LocalAddr == nil {
+			continue
+		}
+		ip := net.ParseIP(*b.LocalAddr)
+		if ip == nil {
+			continue
+		}
+		if !strings.Contains(*b.LocalAddr, ":") {
+			b.Family = FamilyIPv4
+			continue
+		}
+		if v4 := ip.To4(); v4 != nil {
+			// An IPv4-mapped address on an IPv6 socket only carries IPv4
+			// traffic, so it is reported as the IPv4 address it maps.
+			addr := v4.String()
+			b.LocalAddr = &addr
+			b.Family = FamilyIPv4
+			continue
+		}
+		b.Family = FamilyIPv6
+
+		d := diagsFor(b.Protocol)
+		if d == nil {
+			continue
+		}
+		diag, ok := d[newPortKey(b.Protocol, true, ip, b.LocalPort)]
+		if !ok {
+			continue
+		}
+		b.V6Only = diag.v6only
+		if diag.ifindex != 0 && (ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()) {
+			scope := diag.ifindex
+			b.ScopeID = &scope
+		}
+	}
+}
+
+// zoneName returns the interface name for a scope ID, or the ID in decimal
+// when the interface is unknown.
+func zoneName(scope uint32) string {
+	if iface, err := net.InterfaceByIndex(int(scope)); err == nil {
+		return iface.Name
+	}
+	return strconv.FormatUint(uint64(scope), 10)
+}
@@ -0,0 +1,121 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// sock_diag constants from linux/sock_diag.h and linux/inet_diag.h.
+const (
+	sockDiagByFamily  = 20 // SOCK_DIAG_BY_FAMILY
+	inetDiagSkV6Only  = 11 // INET_DIAG_SKV6ONLY
+	inetDiagReqV2Len  = 56 // sizeof(struct inet_diag_req_v2)
+	inetDiagMsgLen    = 72 // sizeof(struct inet_diag_msg)
+	tcpListenStateBit = 1 << 10
+)
+
+// readPortDiags dumps the bound IPv6 sockets of protocol with sock_diag and
+// returns their interface index and IPV6_V6ONLY flag, keyed by local
+// address and port.
+//
+// TCP dumps cover listeners only, matching the listing. UDP dumps need the
+// udp_diag module.
+func readPortDiags(protocol Protocol) (map[portKey]portDiag, error) {
+	diags := make(map[portKey]portDiag)
+	err := sockDiagDump(syscall.AF_INET6, protocol, func(msg []byte) {
+		port := binary.BigEndian.Uint16(msg[4:])
+		ip := net.IP(msg[8:24])
+		diag := portDiag{ifindex: binary.NativeEndian.Uint32(msg[40:])}
+		if v6only, ok := diagAttr(msg[inetDiagMsgLen:], inetDiagSkV6Only); ok && len(v6only) > 0 {
+			flag := v6only[0] != 0
+			diag.v6only = &flag
+		}
+		diags[newPortKey(protocol, true, ip, port)] = diag
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diags, nil
+}
+
+// sockDiagDump dumps the family sockets of protocol with sock_diag and calls
+// visit with each struct inet_diag_msg and its attributes. TCP dumps cover
+// listeners only.
+func sockDiagDump(family uint8, protocol Protocol, visit func(msg []byte)) error {
+	ipproto, states := uint8(syscall.IPPROTO_TCP), uint32(tcpListenStateBit)
+	if protocol == ProtocolUDP {
+		ipproto, states = syscall.IPPROTO_UDP, ^uint32(0)
+	}
+
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.NETLINK_INET_DIAG)
+	if err != nil {
+		return fmt.Errorf("sock_diag socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	req := make([]byte, syscall.NLMSG_HDRLEN+inetDiagReqV2Len)
+	binary.NativeEndian.PutUint32(req[0:], uint32(len(req)))
+	binary.NativeEndian.PutUint16(req[4:], sockDiagByFamily)
+	binary.NativeEndian.PutUint16(req[6:], syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP)
+	body := req[syscall.NLMSG_HDRLEN:]
+	body[0] = family
+	body[1] = ipproto
+	binary.NativeEndian.PutUint32(body[4:], states)
+	if err := syscall.Sendto(fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return fmt.Errorf("sock_diag request: %w", err)
+	}
+
+	buf := make([]byte, 8*os.Getpagesize())
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return fmt.Errorf("sock_diag response: %w", err)
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return fmt.Errorf("sock_diag response: %w", err)
+		}
+		for _, m := range msgs {
+			switch m.Header.Type {
+			case syscall.NLMSG_DONE:
+				return nil
+			case syscall.NLMSG_ERROR:
+				if len(m.Data) >= 4 {
+					if errno := -int32(binary.NativeEndian.Uint32(m.Data)); errno > 0 {
+						return fmt.Errorf("sock_diag: %w", syscall.Errno(errno))
+					}
+				}
+				return fmt.Errorf("sock_diag: malformed error message")
+			}
+			if len(m.Data) >= inetDiagMsgLen {
+				visit(m.Data)
+			}
+		}
+	}
+}
+
+// diagAttr returns the payload of the first netlink attribute of type typ
+// in attrs.
+func diagAttr(attrs []byte, typ uint16) ([]byte, bool) {
+	for len(attrs) >= syscall.SizeofRtAttr {
+		l := int(binary.NativeEndian.Uint16(attrs[0:]))
+		t := binary.NativeEndian.Uint16(attrs[2:])
+		if l < syscall.SizeofRtAttr || l > len(attrs) {
+			return nil, false
+		}
+		if t == typ {
+			return attrs[syscall.SizeofRtAttr:l], true
+		}
+		aligned := (l + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+		if aligned >= len(attrs) {
+			break
+		}
+		attrs = attrs[aligned:]
+	}
+	return nil, false
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sysprims
+
+// readPortDiags reports no IPv6 socket details: scope IDs and v6only flags
+// are only read on Linux.
+func readPortDiags(Protocol) (map[portKey]portDiag, error) {
+	return nil, nil
+}
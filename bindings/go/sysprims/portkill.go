@@ -0,0 +1,160 @@
+package sysprims
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// KillByPortOptions configures [KillByPort].
+type KillByPortOptions struct {
+	// Protected lists PIDs that are never signaled, in addition to the
+	// calling process, its parent, and PID 1.
+	Protected []uint32
+	// WaitForFree, when positive, waits up to this long after signaling
+	// for the port to have no listeners, and reports the outcome in
+	// VerifiedFree.
+	WaitForFree time.Duration
+	// Policy adds protections to the safety rules; see [SafetyPolicy].
+	Policy *SafetyPolicy
+}
+
+// KillByPortResult is the result of [KillByPort].
+type KillByPortResult struct {
+	Protocol   Protocol `json:"protocol"`
+	Port       uint16   `json:"port"`
+	SignalSent int      `json:"signal_sent"`
+	// Succeeded lists the PIDs signaled.
+	Succeeded []uint32 `json:"succeeded"`
+	// Failed lists the owners that could not be signaled.
+	Failed []BatchKillFailure `json:"failed"`
+	// SkippedSafety lists owners left alone by the safety rules.
+	SkippedSafety []uint32 `json:"skipped_safety"`
+	// SkippedPolicy lists owners left alone by a [SafetyPolicy].
+	SkippedPolicy []uint32 `json:"skipped_policy"`
+	// VerifiedFree is set when WaitForFree saw the port released.
+	VerifiedFree bool `json:"verified_free"`
+	// Warnings includes the attribution warnings of the port lookup and
+	// one if the port was still bound after WaitForFree.
+	Warnings []string `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+}
+
+// KillByPort sends signal to the processes listening on proto and port, as
+// attributed by [ProcessesForPort].
+//
+// It applies the safety rules of KillDescendants: the calling process, its
+// parent, and PID 1 are never signaled, nor is any PID in opts.Protected.
+// Owners protected by opts.Policy or the default [SafetyPolicy], judged by
+// the process details of the port lookup, are listed in SkippedPolicy.
+// Only attributed owners are signaled. When some bindings on the port have
+// no owner, the attributed ones are still signaled and a warning is
+// reported; when none has an owner, nothing is signaled and ErrNotFound is
+// returned.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Unknown proto, port is 0, WaitForFree is
+//     negative, or more owners than the policy's MaxKillCount would be
+//     signaled
+//   - [ErrNotFound]: Nothing listens on the port, or no binding on it could
+//     be attributed to a process (the message carries the warnings)
+//   - [ErrNotSupported]: The policy sets ProtectOtherUsers and the calling
+//     process's user cannot be resolved
+//   - Errors from [ListeningPorts]
+func KillByPort(proto Protocol, port uint16, signal int, opts *KillByPortOptions) (*KillByPortResult, error) {
+	if opts == nil {
+		opts = &KillByPortOptions{}
+	}
+	if opts.WaitForFree < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "wait for free must be >= 0"}
+	}
+	check, err := resolveSafety(opts.Policy)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := ProcessesForPort(proto, port)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint32]bool)
+	var pids []uint32
+	for _, b := range owners.Bindings {
+		if b.PID != nil && !seen[*b.PID] {
+			seen[*b.PID] = true
+			pids = append(pids, *b.PID)
+		}
+	}
+	if len(pids) == 0 {
+		msg := portQueryName(proto, port) + " is bound but no owner could be attributed; nothing was signaled"
+		if len(owners.Warnings) > 0 {
+			msg += ": " + strings.Join(owners.Warnings, "; ")
+		}
+		return nil, &Error{Code: ErrNotFound, Message: msg}
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	protected := map[uint32]bool{uint32(os.Getpid()): true, uint32(os.Getppid()): true, 1: true}
+	for _, pid := range opts.Protected {
+		protected[pid] = true
+	}
+	result := &KillByPortResult{
+		Protocol:      proto,
+		Port:          port,
+		SignalSent:    signal,
+		Succeeded:     []uint32{},
+		Failed:        []BatchKillFailure{},
+		SkippedSafety: []uint32{},
+		SkippedPolicy: []uint32{},
+		Warnings:      owners.Warnings,
+	}
+	info := make(map[uint32]*ProcessInfo, len(owners.Processes))
+	for i := range owners.Processes {
+		info[owners.Processes[i].PID] = &owners.Processes[i]
+	}
+	var targets []uint32
+	for _, pid := range pids {
+		switch {
+		case protected[pid]:
+			result.SkippedSafety = append(result.SkippedSafety, pid)
+		case check.protects(pid, info[pid]):
+			result.SkippedPolicy = append(result.SkippedPolicy, pid)
+		default:
+			targets = append(targets, pid)
+		}
+	}
+	if err := check.checkCount(len(targets)); err != nil {
+		return nil, err
+	}
+
+	if len(targets) > 0 {
+		batch, err := KillMany(targets, signal)
+		if err != nil {
+			return nil, err
+		}
+		result.Succeeded = append(result.Succeeded, batch.Succeeded...)
+		result.Failed = append(result.Failed, batch.Failed...)
+	}
+
+	if opts.WaitForFree > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.WaitForFree)
+		err := WaitForPortFree(ctx, proto, port, nil)
+		cancel()
+		var sErr *Error
+		switch {
+		case err == nil:
+			result.VerifiedFree = true
+		case errors.As(err, &sErr) && sErr.Code == ErrTimeout:
+			result.Warnings = append(result.Warnings, sErr.Message)
+		default:
+			return nil, err
+		}
+	}
+	result.WarningDetails = warningDetails("KillByPort", 0, result.Warnings)
+	return result, nil
+}
@@ -0,0 +1,16 @@
+//go:build linux
+
+package sysprims
+
+import "encoding/binary"
+
+// libraryPort converts between real port numbers and those of the library's
+// port listing. The library decodes the /proc/net port, which is already in
+// host order, with from_be, so on little-endian hosts every listed port has
+// its bytes swapped. The swap is its own inverse: it fixes a listed port and
+// turns a real port into the value the library's LocalPort filter compares.
+func libraryPort(port uint16) uint16 {
+	var b [2]byte
+	binary.NativeEndian.PutUint16(b[:], port)
+	return binary.BigEndian.Uint16(b[:])
+}
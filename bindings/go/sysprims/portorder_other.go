@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sysprims
+
+// libraryPort returns port: outside Linux the library's port listing
+// reports real port numbers.
+func libraryPort(port uint16) uint16 {
+	return port
+}
@@ -0,0 +1,132 @@
+package sysprims
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PortOwners is the result of [ProcessesForPort].
+type PortOwners struct {
+	Protocol Protocol `json:"protocol"`
+	Port     uint16   `json:"port"`
+	// Bindings lists every socket bound to the port, such as separate IPv4
+	// and IPv6 listeners or SO_REUSEPORT sockets.
+	Bindings []PortBinding `json:"bindings"`
+	// Processes lists the distinct owners of Bindings, by PID. Owners that
+	// exited or could not be read are described by the library's
+	// attribution when it has one.
+	Processes []ProcessInfo `json:"processes"`
+	// Warnings includes the snapshot's warnings and one for bindings whose
+	// owner could not be attributed.
+	Warnings []string `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+}
+
+// ProcessForPort returns the process listening on proto and port, with full
+// details from [ProcessGet], and its binding.
+//
+// When several sockets share the port, a binding with an attributed owner is
+// chosen; use [ProcessesForPort] to see them all.
+//
+// When the port is bound but no owner can be attributed (for example on
+// macOS under SIP, or for sockets of other users without privileges), the
+// binding is returned with a nil process and an [ErrPermissionDenied] error
+// carrying the listing's warnings.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Unknown proto or port is 0
+//   - [ErrNotFound]: Nothing listens on the port
+//   - [ErrPermissionDenied]: The port is bound but its owner is unknown (the
+//     binding is still returned)
+//   - Errors from [ListeningPorts]
+func ProcessForPort(proto Protocol, port uint16) (*ProcessInfo, *PortBinding, error) {
+	owners, err := ProcessesForPort(proto, port)
+	if err != nil {
+		return nil, nil, err
+	}
+	binding := findPortBinding(owners.Bindings, proto, port)
+	if binding.PID == nil {
+		msg := portQueryName(proto, port) + " is bound but its owner could not be attributed"
+		if len(owners.Warnings) > 0 {
+			msg += ": " + strings.Join(owners.Warnings, "; ")
+		}
+		return nil, binding, &Error{Code: ErrPermissionDenied, Message: msg}
+	}
+	for i := range owners.Processes {
+		if owners.Processes[i].PID == *binding.PID {
+			return &owners.Processes[i], binding, nil
+		}
+	}
+	// The owner exited between the listing and ProcessGet, and the library
+	// had no process details for it.
+	return nil, binding, &Error{Code: ErrNotFound, Message: "owner of " + portQueryName(proto, port) + " exited"}
+}
+
+// ProcessesForPort returns every binding on proto and port and their
+// distinct owning processes, with full details from [ProcessGet].
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Unknown proto or port is 0
+//   - [ErrNotFound]: Nothing listens on the port
+//   - Errors from [ListeningPorts]
+func ProcessesForPort(proto Protocol, port uint16) (*PortOwners, error) {
+	if err := validatePortQuery(proto, port); err != nil {
+		return nil, err
+	}
+	// Filter in Go: the library reports a filter that matches nothing as an
+	// error rather than an empty snapshot.
+	snapshot, err := ListeningPorts(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := &PortOwners{
+		Protocol:  proto,
+		Port:      port,
+		Bindings:  []PortBinding{},
+		Processes: []ProcessInfo{},
+		Warnings:  append([]string{}, snapshot.Warnings...),
+	}
+	seen := make(map[uint32]bool)
+	unattributed := 0
+	for _, b := range snapshot.Bindings {
+		if b.Protocol != proto || b.LocalPort != port {
+			continue
+		}
+		owners.Bindings = append(owners.Bindings, b)
+		if b.PID == nil {
+			unattributed++
+			continue
+		}
+		if seen[*b.PID] {
+			continue
+		}
+		seen[*b.PID] = true
+		if info, err := ProcessGet(*b.PID); err == nil {
+			owners.Processes = append(owners.Processes, *info)
+		} else if b.Process != nil {
+			owners.Processes = append(owners.Processes, *b.Process)
+		}
+	}
+	if len(owners.Bindings) == 0 {
+		return nil, &Error{Code: ErrNotFound, Message: "nothing listens on " + portQueryName(proto, port)}
+	}
+	sort.Slice(owners.Processes, func(i, j int) bool {
+		return owners.Processes[i].PID < owners.Processes[j].PID
+	})
+	if unattributed > 0 {
+		owners.Warnings = append(owners.Warnings, "owner attribution is best-effort: "+
+			strconv.Itoa(unattributed)+" bindings on "+portQueryName(proto, port)+" have no process attribution")
+	}
+	owners.WarningDetails = warningDetails("ProcessesForPort", 0, owners.Warnings)
+	return owners, nil
+}
+
+// portQueryName formats proto and port for messages, e.g. "tcp port 8080".
+func portQueryName(proto Protocol, port uint16) string {
+	return string(proto) + " port " + strconv.FormatUint(uint64(port), 10)
+}
@@ -0,0 +1,176 @@
+package sysprims
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// hasGoCriteria reports whether f sets any field evaluated by the Go
+// bindings.
+func (f *PortFilter) hasGoCriteria() bool {
+	return f != nil && (f.hasAddrCriteria() || f.hasProcessCriteria())
+}
+
+func (f *PortFilter) hasAddrCriteria() bool {
+	return f.LocalAddrEquals != nil ||
+		(f.WildcardOnly != nil && *f.WildcardOnly) ||
+		(f.LoopbackOnly != nil && *f.LoopbackOnly)
+}
+
+// hasProcessCriteria reports whether f selects by owning process, which
+// depends on the library's best-effort attribution.
+func (f *PortFilter) hasProcessCriteria() bool {
+	return f != nil && (f.PID != nil || f.ProcessNameContains != nil)
+}
+
+// forLibrary returns f with LocalPort in the library's port order (see
+// libraryPort), ready to marshal for the library.
+func (f *PortFilter) forLibrary() *PortFilter {
+	if f == nil || f.LocalPort == nil {
+		return f
+	}
+	c := *f
+	port := libraryPort(*f.LocalPort)
+	c.LocalPort = &port
+	return &c
+}
+
+// validate checks the Go-side criteria and returns LocalAddrEquals parsed.
+func (f *PortFilter) validate() (net.IP, error) {
+	if f == nil {
+		return nil, nil
+	}
+	if f.WildcardOnly != nil && *f.WildcardOnly && f.LoopbackOnly != nil && *f.LoopbackOnly {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "wildcard_only and loopback_only are mutually exclusive"}
+	}
+	if f.PID != nil && *f.PID == 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "pid must be > 0"}
+	}
+	if f.LocalAddrEquals == nil {
+		return nil, nil
+	}
+	ip := net.ParseIP(*f.LocalAddrEquals)
+	if ip == nil {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "invalid local address: " + *f.LocalAddrEquals}
+	}
+	return ip, nil
+}
+
+// matchesGo reports whether b satisfies the Go-side criteria of f, with
+// localAddr from validate. names resolves the process name of an attributed
+// binding that carries no Process.
+func (f *PortFilter) matchesGo(b *PortBinding, localAddr net.IP, names func(pid uint32) string) bool {
+	if !f.hasGoCriteria() {
+		return true
+	}
+	if f.PID != nil && (b.PID == nil || *b.PID != *f.PID) {
+		return false
+	}
+	if f.ProcessNameContains != nil {
+		name := ""
+		if b.Process != nil {
+			name = b.Process.Name
+		} else if b.PID != nil {
+			name = names(*b.PID)
+		}
+		if name == "" || !strings.Contains(strings.ToLower(name), strings.ToLower(*f.ProcessNameContains)) {
+			return false
+		}
+	}
+	if !f.hasAddrCriteria() {
+		return true
+	}
+	if b.LocalAddr == nil {
+		return false
+	}
+	// net.IP.Equal and the Is methods treat IPv4-mapped IPv6 addresses as
+	// their IPv4 form.
+	ip := net.ParseIP(*b.LocalAddr)
+	if ip == nil {
+		return false
+	}
+	if localAddr != nil && !ip.Equal(localAddr) {
+		return false
+	}
+	if f.WildcardOnly != nil && *f.WildcardOnly && !ip.IsUnspecified() {
+		return false
+	}
+	if f.LoopbackOnly != nil && *f.LoopbackOnly && !ip.IsLoopback() {
+		return false
+	}
+	return true
+}
+
+// filterPortBindings applies the Go-side criteria of filter to snapshot in
+// place. When filter selects by process and some bindings have no PID, it
+// adds a warning: those bindings were dropped but might have matched.
+func filterPortBindings(snapshot *PortBindingsSnapshot, filter *PortFilter, localAddr net.IP) {
+	if !filter.hasGoCriteria() {
+		return
+	}
+
+	names := make(map[uint32]string)
+	nameOf := func(pid uint32) string {
+		name, ok := names[pid]
+		if !ok {
+			if info, err := ProcessGet(pid); err == nil {
+				name = info.Name
+			}
+			names[pid] = name
+		}
+		return name
+	}
+
+	unattributed := 0
+	kept := snapshot.Bindings[:0]
+	for i := range snapshot.Bindings {
+		b := &snapshot.Bindings[i]
+		if b.PID == nil {
+			unattributed++
+		}
+		if filter.matchesGo(b, localAddr, nameOf) {
+			kept = append(kept, *b)
+		}
+	}
+	snapshot.Bindings = kept
+
+	if filter.hasProcessCriteria() && unattributed > 0 {
+		snapshot.Warnings = append(snapshot.Warnings, "PID and process name filters are best-effort: "+
+			strconv.Itoa(unattributed)+" bindings have no process attribution and were excluded")
+	}
+}
+
+// UnattributedPID is the [PortBindingsSnapshot.ByPID] key of bindings
+// without process attribution. No process has PID 0 in a listing.
+const UnattributedPID uint32 = 0
+
+// ByPID groups the bindings of s by owning PID, keeping their order.
+// Bindings without a PID are grouped under UnattributedPID.
+func (s *PortBindingsSnapshot) ByPID() map[uint32][]PortBinding {
+	groups := make(map[uint32][]PortBinding)
+	for _, b := range s.Bindings {
+		pid := UnattributedPID
+		if b.PID != nil {
+			pid = *b.PID
+		}
+		groups[pid] = append(groups[pid], b)
+	}
+	return groups
+}
+
+// dedupeProcesses moves the Process of every attributed binding into
+// snapshot.Processes, keeping the first one seen for each PID.
+func dedupeProcesses(snapshot *PortBindingsSnapshot) {
+	snapshot.Processes = make(map[uint32]*ProcessInfo)
+	for i := range snapshot.Bindings {
+		b := &snapshot.Bindings[i]
+		if b.PID == nil {
+			continue
+		}
+		if _, ok := snapshot.Processes[*b.PID]; !ok && b.Process != nil {
+			snapshot.Processes[*b.PID] = b.Process
+		}
+		b.Process = nil
+	}
+}
@@ -0,0 +1,159 @@
+package sysprims
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// DefaultPortWaitInterval is the poll interval [WaitForPortListening] and
+// [WaitForPortFree] use when PortWaitOptions.Interval is 0.
+const DefaultPortWaitInterval = 100 * time.Millisecond
+
+// PortWaitOptions configures [WaitForPortListening] and [WaitForPortFree].
+type PortWaitOptions struct {
+	// Interval is the delay between ListeningPorts polls. 0 means
+	// DefaultPortWaitInterval.
+	Interval time.Duration
+}
+
+// WaitForPortListening polls [ListeningPorts] until a binding for proto and
+// port appears, and returns it. When several bindings match (for example
+// IPv4 and IPv6 listeners), one with PID attribution is preferred.
+//
+// Pass nil for opts to use defaults. The first poll happens immediately.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Unknown proto, port is 0, or Interval is negative
+//   - [ErrTimeout]: ctx's deadline passed first; the message describes the
+//     last poll
+//   - ctx.Err(): ctx was canceled first
+//   - Errors from [ListeningPorts]
+func WaitForPortListening(ctx context.Context, proto Protocol, port uint16, opts *PortWaitOptions) (*PortBinding, error) {
+	var found *PortBinding
+	err := waitForPort(ctx, proto, port, opts, func(b *PortBinding) (bool, string) {
+		if b == nil {
+			return false, "not listening"
+		}
+		found = b
+		return true, ""
+	}, "to listen")
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// WaitForPortFree polls [ListeningPorts] until no binding for proto and
+// port remains.
+//
+// Pass nil for opts to use defaults. The first poll happens immediately.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Unknown proto, port is 0, or Interval is negative
+//   - [ErrTimeout]: ctx's deadline passed first; the message names the
+//     binding still present at the last poll
+//   - ctx.Err(): ctx was canceled first
+//   - Errors from [ListeningPorts]
+func WaitForPortFree(ctx context.Context, proto Protocol, port uint16, opts *PortWaitOptions) error {
+	return waitForPort(ctx, proto, port, opts, func(b *PortBinding) (bool, string) {
+		if b == nil {
+			return true, ""
+		}
+		state := "still bound"
+		if b.LocalAddr != nil {
+			state += " on " + *b.LocalAddr
+		}
+		if b.PID != nil {
+			state += " by pid " + strconv.FormatUint(uint64(*b.PID), 10)
+		}
+		return false, state
+	}, "to be free")
+}
+
+// waitForPort polls until done accepts the binding for proto and port (nil
+// when there is none). done also describes a rejected binding for the
+// timeout message.
+func waitForPort(ctx context.Context, proto Protocol, port uint16, opts *PortWaitOptions, done func(*PortBinding) (bool, string), goal string) error {
+	if err := validatePortQuery(proto, port); err != nil {
+		return err
+	}
+	interval := DefaultPortWaitInterval
+	if opts != nil {
+		if opts.Interval < 0 {
+			return &Error{Code: ErrInvalidArgument, Message: "interval must be >= 0"}
+		}
+		if opts.Interval > 0 {
+			interval = opts.Interval
+		}
+	}
+
+	what := portQueryName(proto, port)
+	last := "no poll completed"
+	for {
+		if err := ctx.Err(); err != nil {
+			return portWaitError(err, what, goal, last)
+		}
+		// The library filter is not used: it reports a filter that matches
+		// nothing as an error rather than an empty snapshot.
+		snapshot, err := ListeningPorts(nil)
+		if err != nil {
+			return err
+		}
+		ok, state := done(findPortBinding(snapshot.Bindings, proto, port))
+		if ok {
+			return nil
+		}
+		last = state
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return portWaitError(ctx.Err(), what, goal, last)
+		case <-timer.C:
+		}
+	}
+}
+
+// validatePortQuery checks the protocol and port of a single-port query.
+func validatePortQuery(proto Protocol, port uint16) error {
+	if proto != ProtocolTCP && proto != ProtocolUDP {
+		return &Error{Code: ErrInvalidArgument, Message: "invalid protocol: " + string(proto)}
+	}
+	if port == 0 {
+		return &Error{Code: ErrInvalidArgument, Message: "port must be > 0"}
+	}
+	return nil
+}
+
+// findPortBinding returns a binding for proto and port, preferring one with
+// PID attribution, or nil.
+func findPortBinding(bindings []PortBinding, proto Protocol, port uint16) *PortBinding {
+	var found *PortBinding
+	for i := range bindings {
+		b := &bindings[i]
+		if b.Protocol != proto || b.LocalPort != port {
+			continue
+		}
+		if b.PID != nil {
+			return b
+		}
+		if found == nil {
+			found = b
+		}
+	}
+	return found
+}
+
+// portWaitError maps a ctx error: a passed deadline becomes ErrTimeout
+// describing the last poll, cancellation is returned as is.
+func portWaitError(err error, what, goal, last string) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Code: ErrTimeout, Message: "timed out waiting for " + what + " " + goal + " (last poll: " + last + ")"}
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+package sysprims
+
+import (
+	"context"
+	"time"
+)
+
+// PortEventKind is the type of a [PortEvent].
+type PortEventKind string
+
+const (
+	// PortOpened: a binding appeared.
+	PortOpened PortEventKind = "opened"
+	// PortClosed: a binding disappeared.
+	PortClosed PortEventKind = "closed"
+	// PortPollFailed: a poll failed; Err is set and the next poll diffs
+	// against the last successful one.
+	PortPollFailed PortEventKind = "poll_failed"
+)
+
+// PortEvent is a change in the listening ports reported by [WatchPorts].
+type PortEvent struct {
+	Kind PortEventKind `json:"kind"`
+	// Time is when the poll that observed the change completed.
+	Time time.Time `json:"time"`
+	// Binding is the binding as listed when it opened or, for PortClosed,
+	// as last listed before it closed. Its PID and Process are the
+	// library's best-effort attribution at that time.
+	Binding PortBinding `json:"binding"`
+	// Initial marks the PortOpened events for bindings present at the
+	// first poll.
+	Initial bool `json:"initial,omitempty"`
+	// Err is the poll error (PortPollFailed only).
+	Err error `json:"-"`
+}
+
+// portWatchKey identifies a binding across polls.
+type portWatchKey struct {
+	protocol Protocol
+	addr     string
+	port     uint16
+	pid      uint32
+}
+
+func watchKey(b *PortBinding) portWatchKey {
+	k := portWatchKey{protocol: b.Protocol, port: b.LocalPort}
+	if b.LocalAddr != nil {
+		k.addr = *b.LocalAddr
+	}
+	if b.PID != nil {
+		k.pid = *b.PID
+	}
+	return k
+}
+
+// WatchPorts polls [ListeningPorts] every interval until ctx is done and
+// reports bindings that open or close. Bindings are matched across polls by
+// protocol, local address, port, and owning PID, so a change in attribution
+// is reported as a close and an open. The first poll is taken before
+// WatchPorts returns; its bindings are sent as PortOpened events with
+// Initial set.
+//
+// The watcher never blocks on a slow consumer: events queue while the
+// consumer is busy, and when a binding closes before its pending PortOpened
+// is received (or reopens before its pending PortClosed is), the two events
+// cancel out. A pending PortPollFailed is replaced by a newer one. Events
+// still pending when ctx is done are discarded and the channel is closed.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: filter is invalid (see [ListeningPorts]), or
+//     interval is not positive
+//   - Errors from the first ListeningPorts poll
+func WatchPorts(ctx context.Context, filter *PortFilter, interval time.Duration) (<-chan PortEvent, error) {
+	if interval <= 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "interval must be > 0"}
+	}
+	poll := portWatchPoll(filter)
+	bindings, err := poll()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &portWatcher{pending: make(map[portWatchKey]*queuedPortEvent), seen: make(map[portWatchKey]PortBinding)}
+	now := time.Now()
+	for _, b := range bindings {
+		k := watchKey(&b)
+		if _, dup := w.seen[k]; !dup {
+			w.seen[k] = b
+			w.push(k, PortEvent{Kind: PortOpened, Time: now, Binding: b, Initial: true})
+		}
+	}
+
+	ch := make(chan PortEvent)
+	go w.run(ctx, poll, interval, ch)
+	return ch, nil
+}
+
+// portWatchPoll returns a poll of the bindings selected by filter. The
+// library criteria are evaluated in Go as well: the library reports a filter
+// that matches nothing as an error rather than an empty snapshot.
+func portWatchPoll(filter *PortFilter) func() ([]PortBinding, error) {
+	var goFilter *PortFilter
+	if filter != nil {
+		f := *filter
+		f.Protocol, f.LocalPort = nil, nil
+		goFilter = &f
+	}
+	return func() ([]PortBinding, error) {
+		snapshot, err := ListeningPorts(goFilter)
+		if err != nil {
+			return nil, err
+		}
+		if filter == nil {
+			return snapshot.Bindings, nil
+		}
+		kept := snapshot.Bindings[:0]
+		for _, b := range snapshot.Bindings {
+			if (filter.Protocol == nil || b.Protocol == *filter.Protocol) &&
+				(filter.LocalPort == nil || b.LocalPort == *filter.LocalPort) {
+				kept = append(kept, b)
+			}
+		}
+		return kept, nil
+	}
+}
+
+type portWatcher struct {
+	// queue holds the undelivered events in order.
+	queue []*queuedPortEvent
+	// pending holds the undelivered PortOpened or PortClosed of each key.
+	pending map[portWatchKey]*queuedPortEvent
+	// failed is the undelivered PortPollFailed, if any.
+	failed *queuedPortEvent
+	// seen holds the bindings of the last successful poll.
+	seen map[portWatchKey]PortBinding
+}
+
+type queuedPortEvent struct {
+	PortEvent
+	key       portWatchKey
+	cancelled bool
+}
+
+// push queues e for key k, cancelling an opposite event still pending.
+func (w *portWatcher) push(k portWatchKey, e PortEvent) {
+	if q, ok := w.pending[k]; ok {
+		q.cancelled = true
+		delete(w.pending, k)
+		return
+	}
+	q := &queuedPortEvent{PortEvent: e, key: k}
+	w.pending[k] = q
+	w.queue = append(w.queue, q)
+}
+
+// pollFailed queues a PortPollFailed, replacing one still pending.
+func (w *portWatcher) pollFailed(err error, now time.Time) {
+	e := PortEvent{Kind: PortPollFailed, Time: now, Err: err}
+	if w.failed != nil {
+		w.failed.PortEvent = e
+		return
+	}
+	w.failed = &queuedPortEvent{PortEvent: e}
+	w.queue = append(w.queue, w.failed)
+}
+
+// diff queues the changes between the last successful poll and bindings.
+func (w *portWatcher) diff(bindings []PortBinding, now time.Time) {
+	cur := make(map[portWatchKey]PortBinding, len(bindings))
+	for _, b := range bindings {
+		k := watchKey(&b)
+		if _, dup := cur[k]; dup {
+			continue
+		}
+		cur[k] = b
+		if _, ok := w.seen[k]; !ok {
+			w.push(k, PortEvent{Kind: PortOpened, Time: now, Binding: b})
+		}
+	}
+	// Closed events follow the poll's opened events, in no particular order.
+	for k, b := range w.seen {
+		if _, ok := cur[k]; !ok {
+			w.push(k, PortEvent{Kind: PortClosed, Time: now, Binding: b})
+		}
+	}
+	w.seen = cur
+}
+
+// head drops cancelled events from the front of the queue and returns the
+// first remaining one, or nil.
+func (w *portWatcher) head() *queuedPortEvent {
+	for len(w.queue) > 0 && w.queue[0].cancelled {
+		w.queue = w.queue[1:]
+	}
+	if len(w.queue) == 0 {
+		return nil
+	}
+	return w.queue[0]
+}
+
+// delivered removes the head event after it was sent.
+func (w *portWatcher) delivered() {
+	q := w.queue[0]
+	w.queue = w.queue[1:]
+	if w.failed == q {
+		w.failed = nil
+	} else if w.pending[q.key] == q {
+		delete(w.pending, q.key)
+	}
+}
+
+func (w *portWatcher) run(ctx context.Context, poll func() ([]PortBinding, error), interval time.Duration, ch chan<- PortEvent) {
+	defer close(ch)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var out chan<- PortEvent
+		var next PortEvent
+		if q := w.head(); q != nil {
+			out = ch
+			next = q.PortEvent
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bindings, err := poll()
+			if err != nil {
+				w.pollFailed(err, time.Now())
+				continue
+			}
+			w.diff(bindings, time.Now())
+		case out <- next:
+			w.delivered()
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package sysprims
+
+// Nice value range accepted by SetPriority.
+const (
+	minNice = -20
+	maxNice = 19
+)
+
+// GetPriority returns the scheduling priority of pid as a nice value
+// (-20 highest to 19 lowest).
+//
+// On Unix this wraps getpriority(2). On Windows the process priority class is
+// mapped to a representative nice value: realtime -20, high -14, above normal
+// -7, normal 0, below normal 7, idle 19.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to query this process
+func GetPriority(pid uint32) (int, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return 0, err
+	}
+	return getPriority(pid)
+}
+
+// SetPriority sets the scheduling priority of pid to nice (-20 to 19).
+//
+// On Unix this wraps setpriority(2); lowering the nice value usually requires
+// privilege. On Linux it applies to the process's main thread, as renice(1)
+// does. On Windows nice is mapped to a priority class: below -7 high, below 0
+// above normal, below 7 normal, below 19 below normal, 19 idle. The realtime
+// class is never set.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is invalid or nice is outside -20..19
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to change this process's priority
+func SetPriority(pid uint32, nice int) error {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return err
+	}
+	if nice < minNice || nice > maxNice {
+		return &Error{Code: ErrInvalidArgument, Message: "nice must be between -20 and 19"}
+	}
+	return setPriority(pid, nice)
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <sys/resource.h>
+
+static int sysprims_go_getpriority(int pid, int *nice) {
+	errno = 0;
+	int r = getpriority(PRIO_PROCESS, (id_t)pid);
+	if (r == -1 && errno != 0) {
+		return errno;
+	}
+	*nice = r;
+	return 0;
+}
+
+static int sysprims_go_setpriority(int pid, int nice) {
+	if (setpriority(PRIO_PROCESS, (id_t)pid, nice) != 0) {
+		return errno;
+	}
+	return 0;
+}
+*/
+import "C"
+
+import "syscall"
+
+// getPriority wraps getpriority(2), which reports -1 both as a value and as
+// the error sentinel; errno disambiguates.
+func getPriority(pid uint32) (int, error) {
+	var nice C.int
+	if rc := C.sysprims_go_getpriority(C.int(pid), &nice); rc != 0 {
+		return 0, errnoError(pid, syscall.Errno(rc))
+	}
+	return int(nice), nil
+}
+
+func setPriority(pid uint32, nice int) error {
+	if rc := C.sysprims_go_setpriority(C.int(pid), C.int(nice)); rc != 0 {
+		return errnoError(pid, syscall.Errno(rc))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build windows
+
+package sysprims
+
+import "syscall"
+
+// Windows priority classes.
+const (
+	idlePriorityClass        = 0x00000040
+	belowNormalPriorityClass = 0x00004000
+	normalPriorityClass      = 0x00000020
+	aboveNormalPriorityClass = 0x00008000
+	highPriorityClass        = 0x00000080
+	realtimePriorityClass    = 0x00000100
+)
+
+var (
+	procGetPriorityClass = modKernel32.NewProc("GetPriorityClass")
+	procSetPriorityClass = modKernel32.NewProc("SetPriorityClass")
+)
+
+// getPriority maps the process priority class to a nice value.
+func getPriority(pid uint32) (int, error) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return 0, winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	r, _, e := procGetPriorityClass.Call(uintptr(h))
+	switch r {
+	case 0:
+		return 0, systemError(e)
+	case realtimePriorityClass:
+		return -20, nil
+	case highPriorityClass:
+		return -14, nil
+	case aboveNormalPriorityClass:
+		return -7, nil
+	case belowNormalPriorityClass:
+		return 7, nil
+	case idlePriorityClass:
+		return 19, nil
+	default:
+		return 0, nil
+	}
+}
+
+// setPriority maps nice to a priority class, never choosing realtime.
+func setPriority(pid uint32, nice int) error {
+	var class uintptr
+	switch {
+	case nice < -7:
+		class = highPriorityClass
+	case nice < 0:
+		class = aboveNormalPriorityClass
+	case nice < 7:
+		class = normalPriorityClass
+	case nice < 19:
+		class = belowNormalPriorityClass
+	default:
+		class = idlePriorityClass
+	}
+
+	h, err := syscall.OpenProcess(processSetInformation, false, pid)
+	if err != nil {
+		return winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	if r, _, e := procSetPriorityClass.Call(uintptr(h), class); r == 0 {
+		if e == syscall.ERROR_ACCESS_DENIED {
+			return (&Error{Code: ErrPermissionDenied, Message: e.Error()}).withErrno(syscall.ERROR_ACCESS_DENIED)
+		}
+		return systemError(e)
+	}
+	return nil
+}
@@ -7,6 +7,9 @@ package sysprims
 import "C"
 import (
 	"encoding/json"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
 	"unsafe"
 )
@@ -23,6 +26,13 @@ type ProcessInfo struct {
 	User *string `json:"user,omitempty"`
 	// CPUPercent is the CPU usage percentage (0-100).
 	CPUPercent float64 `json:"cpu_percent"`
+	// CPUUserTimeMS and CPUSystemTimeMS are the cumulative CPU time spent in
+	// user and kernel mode since process start, in milliseconds (requires
+	// ProcessOptions.IncludeCPUTimes). Unlike CPUPercent they are raw
+	// counters: sample twice and divide the difference by the interval to
+	// get a rate over any window.
+	CPUUserTimeMS   *uint64 `json:"cpu_user_time_ms,omitempty"`
+	CPUSystemTimeMS *uint64 `json:"cpu_system_time_ms,omitempty"`
 	// MemoryKB is the memory usage in kilobytes.
 	MemoryKB uint64 `json:"memory_kb"`
 	// ElapsedSeconds is the process runtime in seconds (may be nil if unavailable).
@@ -31,17 +41,68 @@ type ProcessInfo struct {
 	StartTimeUnixMS *uint64 `json:"start_time_unix_ms,omitempty"`
 	// ExePath is the absolute executable path, best-effort.
 	ExePath *string `json:"exe_path,omitempty"`
-	// State is the process state (may be nil if unavailable).
+	// State is the process state, one of the State constants (may be nil if
+	// unavailable).
 	State *string `json:"state,omitempty"`
+	// RawState is State as reported by the library, before normalization.
+	RawState *string `json:"raw_state,omitempty"`
 	// Cmdline is the command line arguments (may be empty if unavailable).
 	Cmdline []string `json:"cmdline,omitempty"`
 	// Env is process environment variables (same-user best-effort, may be nil).
 	Env map[string]string `json:"env,omitempty"`
 	// ThreadCount is the best-effort thread count for this process.
 	ThreadCount *uint32 `json:"thread_count,omitempty"`
+	// OpenFdCount is the number of open file descriptors (handles on
+	// Windows), counted without resolving them (requires
+	// ProcessOptions.IncludeFdCount). See [FdCount].
+	OpenFdCount *uint32 `json:"open_fd_count,omitempty"`
+	// OomScore is the kernel's current OOM badness score (Linux only;
+	// requires ProcessOptions.IncludeOOM).
+	OomScore *int32 `json:"oom_score,omitempty"`
+	// OomScoreAdj is the OOM score adjustment, -1000 to 1000 (Linux only;
+	// requires ProcessOptions.IncludeOOM).
+	OomScoreAdj *int32 `json:"oom_score_adj,omitempty"`
+	// Nice is the scheduling priority as reported by GetPriority (requires
+	// ProcessOptions.IncludeNice).
+	Nice *int `json:"nice,omitempty"`
+	// Memory breaks MemoryKB down into resident, virtual, shared, and swap
+	// (requires ProcessOptions.IncludeMemoryDetail).
+	Memory *MemoryDetail `json:"memory,omitempty"`
+	// CgroupPath is the process's cgroup, from /proc/<pid>/cgroup (Linux
+	// only; requires ProcessOptions.IncludeCgroup). The cgroup v2 path is
+	// preferred; on hybrid hosts where it is "/", the first v1 path is used.
+	CgroupPath *string `json:"cgroup_path,omitempty"`
+	// ContainerID is the Docker, containerd, CRI-O, or Podman container ID
+	// recognized in CgroupPath, best-effort (requires
+	// ProcessOptions.IncludeCgroup).
+	ContainerID *string `json:"container_id,omitempty"`
+	// TTY is the controlling terminal relative to /dev, such as "pts/3" on
+	// Linux or "ttys003" on macOS; nil when the process has none (requires
+	// ProcessOptions.IncludeTTY; always nil on Windows).
+	TTY *string `json:"tty,omitempty"`
+	// UID, EUID, GID, and EGID are the real and effective user and group IDs
+	// (requires ProcessOptions.IncludeIDs; always nil on Windows). EUID
+	// differing from UID indicates a setuid process.
+	UID  *uint32 `json:"uid,omitempty"`
+	EUID *uint32 `json:"euid,omitempty"`
+	GID  *uint32 `json:"gid,omitempty"`
+	EGID *uint32 `json:"egid,omitempty"`
+	// VoluntaryCtxSwitches and InvoluntaryCtxSwitches count the times the
+	// process gave up the CPU (for example to wait on I/O) and was
+	// preempted; MinorFaults and MajorFaults count page faults served
+	// without and with disk I/O. All are cumulative since process start
+	// (requires ProcessOptions.IncludeSchedStats). The switch counts are
+	// Linux only. On Windows MinorFaults is PageFaultCount, which includes
+	// hard faults, and MajorFaults is nil.
+	VoluntaryCtxSwitches   *uint64 `json:"voluntary_ctx_switches,omitempty"`
+	InvoluntaryCtxSwitches *uint64 `json:"involuntary_ctx_switches,omitempty"`
+	MinorFaults            *uint64 `json:"minor_faults,omitempty"`
+	MajorFaults            *uint64 `json:"major_faults,omitempty"`
 }
 
-// ProcessSnapshot represents a point-in-time listing of processes.
+// ProcessSnapshot represents a point-in-time listing of processes. Use it
+// by pointer: it holds the lock of its ByPID cache, so it must not be
+// copied.
 type ProcessSnapshot struct {
 	// SchemaID identifies the JSON schema version.
 	SchemaID string `json:"schema_id"`
@@ -49,6 +110,12 @@ type ProcessSnapshot struct {
 	Timestamp string `json:"timestamp"`
 	// Processes is the list of process information.
 	Processes []ProcessInfo `json:"processes"`
+
+	// ByPID cache; see ProcessSnapshot.ByPID.
+	byPIDMu   sync.Mutex
+	byPID     map[uint32]*ProcessInfo
+	byPIDBase *ProcessInfo
+	byPIDLen  int
 }
 
 // WaitPidResult is the result of waiting for a PID to exit.
@@ -61,6 +128,16 @@ type WaitPidResult struct {
 	TimedOut  bool     `json:"timed_out"`
 	ExitCode  *int32   `json:"exit_code,omitempty"`
 	Warnings  []string `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+	// Signaled is set when the process was terminated by a signal, which
+	// ExitSignal holds; ExitCode is then nil. Both come from the wait
+	// status, so they are only known where the process is reaped ([Reap],
+	// [ReapAll], [ChildHandle.Wait]). On polling paths such as [WaitPID],
+	// which watch a process without being its parent, Signaled is false and
+	// ExitSignal nil even for a killed process. Always unset on Windows.
+	Signaled   bool `json:"signaled,omitempty"`
+	ExitSignal *int `json:"exit_signal,omitempty"`
 }
 
 type Protocol string
@@ -68,6 +145,9 @@ type Protocol string
 const (
 	ProtocolTCP Protocol = "tcp"
 	ProtocolUDP Protocol = "udp"
+	// ProtocolUnix marks unix domain sockets in [SocketInfo]; port listings
+	// never report it.
+	ProtocolUnix Protocol = "unix"
 )
 
 type CpuMode string
@@ -77,6 +157,21 @@ const (
 	CpuModeMonitor  CpuMode = "monitor"
 )
 
+// SortKey selects the metric used to rank processes in TopProcesses and
+// ProcessSnapshot.SortBy.
+type SortKey string
+
+const (
+	// SortByCPU ranks by CPUPercent (lifetime, or sampled in monitor mode).
+	SortByCPU SortKey = "cpu"
+	// SortByMemory ranks by MemoryKB.
+	SortByMemory SortKey = "memory"
+	// SortByThreads ranks by ThreadCount.
+	SortByThreads SortKey = "threads"
+	// SortByFds ranks by open file descriptor count.
+	SortByFds SortKey = "fds"
+)
+
 // PortBinding contains information about a listening socket binding.
 type PortBinding struct {
 	Protocol  Protocol     `json:"protocol"`
@@ -85,6 +180,39 @@ type PortBinding struct {
 	State     *string      `json:"state,omitempty"`
 	PID       *uint32      `json:"pid,omitempty"`
 	Process   *ProcessInfo `json:"process,omitempty"`
+	// RemoteAddr and RemotePort are the peer of a connected socket, when the
+	// platform reports one. Listeners have none; see [Connections] for
+	// connected sockets.
+	RemoteAddr *string `json:"remote_addr,omitempty"`
+	RemotePort *uint16 `json:"remote_port,omitempty"`
+	// Family is the address family of LocalAddr, set by the Go bindings. An
+	// IPv4-mapped IPv6 address (::ffff:a.b.c.d) only carries IPv4 traffic,
+	// so it is reported in IPv4 form with FamilyIPv4.
+	Family AddressFamily `json:"family,omitempty"`
+	// ScopeID is the interface index (zone) of a link-local IPv6 LocalAddr,
+	// as in fe80::1%eth0; see net.InterfaceByIndex. Linux only.
+	ScopeID *uint32 `json:"scope_id,omitempty"`
+	// V6Only is the IPV6_V6ONLY flag of an IPv6 binding. A wildcard "::"
+	// binding with V6Only false is dual-stack and also accepts IPv4. Linux
+	// only (read via sock_diag); nil when unreadable.
+	V6Only *bool `json:"v6only,omitempty"`
+	// RxQueueBytes and TxQueueBytes are the receive and send buffer usage
+	// of a UDP socket, and Drops counts the datagrams it dropped, such as
+	// on a full receive buffer. Set only with PortFilter.IncludeQueueStats.
+	// On Linux they are read from /proc/net/udp{,6} and count buffer memory
+	// including per-packet overhead, as ss -u reports; on macOS the queues
+	// are read best-effort from the owning process and Drops is always nil.
+	// Nil on Windows and when the socket is ambiguous or unreadable.
+	RxQueueBytes *uint64 `json:"rx_queue_bytes,omitempty"`
+	TxQueueBytes *uint64 `json:"tx_queue_bytes,omitempty"`
+	Drops        *uint64 `json:"drops,omitempty"`
+	// BacklogCurrent is the number of connections of a TCP listener waiting
+	// to be accepted, and BacklogMax the limit of its accept queue (the
+	// listen backlog, capped by the system). A listener whose queue stays
+	// near BacklogMax is not accepting fast enough. Set only with
+	// PortFilter.IncludeQueueStats; Linux only, read with sock_diag.
+	BacklogCurrent *uint32 `json:"backlog_current,omitempty"`
+	BacklogMax     *uint32 `json:"backlog_max,omitempty"`
 	// NOTE: warnings and best-effort behavior are surfaced at snapshot level.
 }
 
@@ -95,22 +223,64 @@ type PortBindingsSnapshot struct {
 	Platform  string        `json:"platform"`
 	Bindings  []PortBinding `json:"bindings"`
 	Warnings  []string      `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+	// Processes holds the owning processes of Bindings by PID, set only with
+	// PortFilter.DedupeProcesses.
+	Processes map[uint32]*ProcessInfo `json:"processes,omitempty"`
 }
 
 // PortFilter specifies criteria for filtering port bindings.
+//
+// Protocol and LocalPort are evaluated by the library; the address and
+// process fields are evaluated by the Go bindings. Addresses compare by
+// value, so an IPv4-mapped IPv6 form such as "::ffff:127.0.0.1" matches
+// "127.0.0.1". Bindings without a local address never match an address
+// criterion, and bindings without process attribution never match a process
+// criterion.
 type PortFilter struct {
 	Protocol  *Protocol `json:"protocol,omitempty"`
 	LocalPort *uint16   `json:"local_port,omitempty"`
+	// LocalAddrEquals matches bindings on exactly this address.
+	LocalAddrEquals *string `json:"-"`
+	// WildcardOnly, when true, matches only bindings on 0.0.0.0 or ::.
+	WildcardOnly *bool `json:"-"`
+	// LoopbackOnly, when true, matches only bindings on 127.0.0.0/8 or ::1.
+	LoopbackOnly *bool `json:"-"`
+	// PID matches bindings owned by this process.
+	PID *uint32 `json:"-"`
+	// ProcessNameContains matches bindings whose owning process name
+	// contains this substring (case-insensitive).
+	ProcessNameContains *string `json:"-"`
+	// IncludeQueueStats sets RxQueueBytes, TxQueueBytes, and Drops on UDP
+	// bindings, and BacklogCurrent and BacklogMax on TCP listeners. It is
+	// off by default because it reads the socket tables again.
+	IncludeQueueStats bool `json:"-"`
+	// DedupeProcesses moves the Process of each attributed binding into
+	// PortBindingsSnapshot.Processes, keyed by PID, so a process owning
+	// several bindings is described once; the bindings keep only PID.
+	DedupeProcesses bool `json:"-"`
 }
 
 // ProcessFilter specifies criteria for filtering processes.
 //
 // All fields are optional. When multiple fields are set, they are ANDed together.
+//
+// Fields tagged `json:"-"` are evaluated by the Go bindings after the library
+// returns its results; the rest are evaluated by the library.
 type ProcessFilter struct {
 	// NameContains filters by process name substring (case-insensitive).
 	NameContains *string `json:"name_contains,omitempty"`
 	// NameEquals filters by exact process name match.
 	NameEquals *string `json:"name_equals,omitempty"`
+	// NameRegex filters to processes whose name matches this regular
+	// expression. The syntax is Go's regexp (RE2): no backreferences or
+	// lookaround, matching in linear time. The match is unanchored, so use
+	// ^ and $ for a whole-name match, and case-sensitive unless the pattern
+	// starts with (?i). It applies to Name only, not the command line. An
+	// invalid pattern fails with ErrInvalidArgument before any process is
+	// read.
+	NameRegex *string `json:"-"`
 	// UserEquals filters by exact username match.
 	UserEquals *string `json:"user_equals,omitempty"`
 	// PIDIn filters to only these PIDs.
@@ -121,10 +291,46 @@ type ProcessFilter struct {
 	StateIn []string `json:"state_in,omitempty"`
 	// CPUAbove filters to processes using more than this CPU percentage.
 	CPUAbove *float64 `json:"cpu_above,omitempty"`
+	// CPUBelow filters to processes using strictly less than this CPU
+	// percentage. With CPUAbove it selects a range.
+	CPUBelow *float64 `json:"-"`
 	// MemoryAboveKB filters to processes using more than this memory (KB).
 	MemoryAboveKB *uint64 `json:"memory_above_kb,omitempty"`
+	// MemoryBelowKB filters to processes using strictly less than this
+	// memory (KB).
+	MemoryBelowKB *uint64 `json:"-"`
 	// RunningForAtLeastSecs filters to processes running at least this many seconds.
 	RunningForAtLeastSecs *uint64 `json:"running_for_at_least_secs,omitempty"`
+	// ExePathContains filters by executable path substring (case-sensitive).
+	// Processes without a resolved ExePath never match.
+	ExePathContains *string `json:"-"`
+	// ExePathEquals filters by exact executable path match.
+	// Processes without a resolved ExePath never match.
+	ExePathEquals *string `json:"-"`
+	// UserIn filters to processes run by any of these usernames.
+	// Processes without a resolved User never match.
+	UserIn []string `json:"-"`
+	// PPIDIn filters to processes whose parent is any of these PIDs.
+	PPIDIn []uint32 `json:"-"`
+	// StartedAfterUnixMS filters to processes started at or after this time
+	// (Unix epoch ms). Processes with unknown start time never match.
+	StartedAfterUnixMS *uint64 `json:"-"`
+	// StartedBeforeUnixMS filters to processes started strictly before this
+	// time (Unix epoch ms). Processes with unknown start time never match.
+	StartedBeforeUnixMS *uint64 `json:"-"`
+	// CgroupContains filters by cgroup path substring (case-sensitive), such
+	// as a container ID or pod UID. Processes without a readable cgroup,
+	// including all processes on platforms other than Linux, never match.
+	CgroupContains *string `json:"-"`
+	// TTYEquals filters by exact controlling terminal, in the form of
+	// ProcessInfo.TTY (e.g. "pts/3"). Processes without a controlling
+	// terminal never match.
+	TTYEquals *string `json:"-"`
+	// FdCountAbove filters to processes with more than this many open file
+	// descriptors (handles on Windows), counted as by [FdCount]. Processes
+	// whose fds cannot be counted, such as other users' processes without
+	// privileges, never match.
+	FdCountAbove *uint32 `json:"-"`
 }
 
 // ProcessOptions controls optional process detail collection.
@@ -135,63 +341,131 @@ type ProcessOptions struct {
 	IncludeEnv bool `json:"include_env,omitempty"`
 	// IncludeThreads requests collection of process thread count.
 	IncludeThreads bool `json:"include_threads,omitempty"`
+	// CpuMode controls CPU measurement semantics for ProcessListWithOptions.
+	//
+	// In monitor mode the call blocks for SampleDuration and CPUPercent reports
+	// usage over that window (may exceed 100 on multi-core). Evaluated by the
+	// Go bindings; ProcessGetWithOptions ignores it.
+	CpuMode CpuMode `json:"-"`
+	// SampleDuration is used when CpuMode is monitor. 0 means default sample
+	// (1s); values above 10s are rejected.
+	SampleDuration time.Duration `json:"-"`
+	// Concurrency bounds the worker pool used by ProcessGetMany. 0 means
+	// runtime.GOMAXPROCS(0). Evaluated by the Go bindings.
+	Concurrency int `json:"-"`
+	// OmitCmdline drops Cmdline from results.
+	//
+	// The Omit fields are applied by the Go bindings after Go-side filters
+	// run. The library still collects the fields; omitting them keeps
+	// results small but does not reduce collection cost.
+	OmitCmdline bool `json:"-"`
+	// OmitExePath drops ExePath from results.
+	OmitExePath bool `json:"-"`
+	// OmitUser drops User from results.
+	OmitUser bool `json:"-"`
+	// IncludeOOM requests OomScore and OomScoreAdj. Read by the Go bindings
+	// from /proc; Linux only.
+	IncludeOOM bool `json:"-"`
+	// IncludeNice requests Nice, read by the Go bindings per process;
+	// processes whose priority cannot be read leave it nil.
+	IncludeNice bool `json:"-"`
+	// IncludeMemoryDetail requests Memory, read by the Go bindings per
+	// process; processes whose memory cannot be read leave it nil.
+	IncludeMemoryDetail bool `json:"-"`
+	// IncludeCgroup requests CgroupPath and ContainerID, read by the Go
+	// bindings from /proc; on platforms other than Linux they stay nil.
+	IncludeCgroup bool `json:"-"`
+	// IncludeTTY requests TTY, read by the Go bindings per process.
+	IncludeTTY bool `json:"-"`
+	// IncludeIDs requests UID, EUID, GID, and EGID, read by the Go bindings
+	// per process; processes whose IDs cannot be read leave them nil.
+	IncludeIDs bool `json:"-"`
+	// IncludeSchedStats requests the context-switch and page-fault
+	// counters, read by the Go bindings per process; counters that cannot
+	// be read are left nil.
+	IncludeSchedStats bool `json:"-"`
+	// IncludeCPUTimes requests CPUUserTimeMS and CPUSystemTimeMS, read by
+	// the Go bindings per process; processes whose times cannot be read
+	// leave them nil.
+	IncludeCPUTimes bool `json:"-"`
+	// IncludeFdCount requests OpenFdCount, counted by the Go bindings per
+	// process; processes whose fds cannot be counted leave it nil.
+	IncludeFdCount bool `json:"-"`
 }
 
-// FdInfo describes an open file descriptor.
-type FdInfo struct {
-	Fd   uint32  `json:"fd"`
-	Kind string  `json:"kind"`
-	Path *string `json:"path,omitempty"`
-}
-
-// FdSnapshot represents a point-in-time listing of open file descriptors.
-type FdSnapshot struct {
-	SchemaID  string   `json:"schema_id"`
-	Timestamp string   `json:"timestamp"`
-	Platform  string   `json:"platform"`
-	Pid       uint32   `json:"pid"`
-	Fds       []FdInfo `json:"fds"`
-	Warnings  []string `json:"warnings"`
-}
-
-// FdFilter specifies criteria for filtering file descriptors.
-type FdFilter struct {
-	Kind *string `json:"kind,omitempty"`
+// hasGoFields reports whether opts sets an Omit or Include option that the Go
+// bindings apply after the library returns, beyond IncludeEnv and
+// IncludeThreads.
+func (opts *ProcessOptions) hasGoFields() bool {
+	return opts != nil && (opts.OmitCmdline || opts.OmitExePath || opts.OmitUser ||
+		opts.IncludeOOM || opts.IncludeNice || opts.IncludeMemoryDetail ||
+		opts.IncludeCgroup || opts.IncludeTTY || opts.IncludeIDs ||
+		opts.IncludeSchedStats || opts.IncludeCPUTimes || opts.IncludeFdCount)
 }
 
-// ListFds returns a snapshot of open file descriptors for the given PID.
-//
-// Best-effort behavior:
-// - Fields may be omitted
-// - Warnings may be present
-// - Windows returns ErrNotSupported
-func ListFds(pid uint32, filter *FdFilter) (*FdSnapshot, error) {
-	var filterCStr *C.char
-	if filter != nil {
-		filterJSON, err := json.Marshal(filter)
-		if err != nil {
-			return nil, &Error{Code: ErrInvalidArgument, Message: "failed to marshal filter: " + err.Error()}
+// enrich adds the Go-collected fields opts asks for to p.
+func (opts *ProcessOptions) enrich(p *ProcessInfo) {
+	if opts == nil {
+		return
+	}
+	if opts.IncludeOOM {
+		readOOM(p)
+	}
+	if opts.IncludeNice {
+		if nice, err := getPriority(p.PID); err == nil {
+			p.Nice = &nice
 		}
-		filterCStr = C.CString(string(filterJSON))
-		defer C.free(unsafe.Pointer(filterCStr))
 	}
-
-	var resultCStr *C.char
-	if err := callAndCheck(func() C.SysprimsErrorCode {
-		return C.sysprims_proc_list_fds(C.uint32_t(pid), filterCStr, &resultCStr)
-	}); err != nil {
-		return nil, err
+	if opts.IncludeMemoryDetail {
+		if m, err := readMemoryDetail(p.PID); err == nil {
+			p.Memory = m
+		}
 	}
-	defer C.sysprims_free_string(resultCStr)
-
-	var snapshot FdSnapshot
-	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &snapshot); err != nil {
-		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
+	if opts.IncludeCgroup {
+		readCgroup(p)
+	}
+	if opts.IncludeTTY {
+		if tty, err := readTTY(p.PID); err == nil && tty != "" {
+			p.TTY = &tty
+		}
+	}
+	if opts.IncludeIDs {
+		readIDs(p)
+	}
+	if opts.IncludeSchedStats {
+		readSchedStats(p)
 	}
+	if opts.IncludeCPUTimes {
+		readCPUTimes(p)
+	}
+	if opts.IncludeFdCount {
+		readFdCount(p)
+	}
+}
 
-	return &snapshot, nil
+// omitFields clears the fields opts asks to omit from p.
+func (opts *ProcessOptions) omitFields(p *ProcessInfo) {
+	if opts == nil {
+		return
+	}
+	if opts.OmitCmdline {
+		p.Cmdline = nil
+	}
+	if opts.OmitExePath {
+		p.ExePath = nil
+	}
+	if opts.OmitUser {
+		p.User = nil
+	}
 }
 
+const (
+	// defaultSampleDuration matches the library default for monitor mode.
+	defaultSampleDuration = time.Second
+	// maxListSampleDuration bounds how long ProcessListWithOptions may block.
+	maxListSampleDuration = 10 * time.Second
+)
+
 // ProcessList returns a snapshot of running processes, optionally filtered.
 //
 // Pass nil for filter to return all processes.
@@ -219,7 +493,118 @@ func ProcessList(filter *ProcessFilter) (*ProcessSnapshot, error) {
 // with opt-in extended fields.
 //
 // Pass nil for opts to use defaults (`include_env=false`, `include_threads=false`).
+//
+// When opts.CpuMode is [CpuModeMonitor], the call blocks for the sample
+// duration and CPUPercent (including the CPUAbove and CPUBelow filters)
+// reflects usage over that window rather than the process lifetime.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Invalid filter, cpu mode, or sample duration (< 0 or > 10s)
+//   - [ErrNotSupported]: opts.IncludeOOM on a platform other than Linux
+//   - [ErrSystem]: System error reading process information
 func ProcessListWithOptions(filter *ProcessFilter, opts *ProcessOptions) (*ProcessSnapshot, error) {
+	cpuMode := CpuModeLifetime
+	sampleDuration := time.Duration(0)
+	if opts != nil {
+		cpuMode = opts.CpuMode
+		sampleDuration = opts.SampleDuration
+	}
+
+	cpuMode, err := normalizeCpuMode(cpuMode)
+	if err != nil {
+		return nil, err
+	}
+	if sampleDuration < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "sample duration must be >= 0"}
+	}
+	if sampleDuration > maxListSampleDuration {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "sample duration must be <= " + maxListSampleDuration.String()}
+	}
+	if err := opts.checkOOM(); err != nil {
+		return nil, err
+	}
+
+	var snapshot *ProcessSnapshot
+	if cpuMode == CpuModeMonitor {
+		if sampleDuration == 0 {
+			sampleDuration = defaultSampleDuration
+		}
+		snapshot, err = processListSampled(filter, opts, sampleDuration)
+	} else {
+		snapshot, err = processList(filter, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshot.Processes {
+		opts.enrich(&snapshot.Processes[i])
+	}
+	return snapshot, nil
+}
+
+// processListSampled takes two snapshots sampleDuration apart and replaces
+// CPUPercent with the CPU time consumed in between, mirroring the library's
+// monitor mode for descendants.
+func processListSampled(filter *ProcessFilter, opts *ProcessOptions, sampleDuration time.Duration) (*ProcessSnapshot, error) {
+	filter, cpuMatches, err := splitCPUBounds(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type cpuSample struct {
+		startTimeUnixMS *uint64
+		cpuNS           uint64
+	}
+
+	snap0, err := processList(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	t0 := make(map[uint32]cpuSample, len(snap0.Processes))
+	for _, p := range snap0.Processes {
+		if cpuNS, err := cpuTimeNS(p.PID); err == nil {
+			t0[p.PID] = cpuSample{startTimeUnixMS: p.StartTimeUnixMS, cpuNS: cpuNS}
+		}
+	}
+
+	time.Sleep(sampleDuration)
+
+	snap1, err := processList(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	dtNS := float64(sampleDuration.Nanoseconds())
+	processes := snap1.Processes[:0]
+	for _, p := range snap1.Processes {
+		if s, ok := t0[p.PID]; ok {
+			// PID reuse guard: only compute if start time matches.
+			sameProcess := s.startTimeUnixMS == nil || p.StartTimeUnixMS == nil ||
+				*s.startTimeUnixMS == *p.StartTimeUnixMS
+			if sameProcess {
+				if cpuNS, err := cpuTimeNS(p.PID); err == nil && cpuNS >= s.cpuNS {
+					p.CPUPercent = float64(cpuNS-s.cpuNS) / dtNS * 100
+				}
+			}
+		}
+		if !cpuMatches(p.CPUPercent) {
+			continue
+		}
+		processes = append(processes, p)
+	}
+	snap1.Processes = processes
+
+	// Sampled CPU semantics can exceed 100 on multi-core.
+	snap1.SchemaID = SchemaProcessSnapshotSampledV1
+	return snap1, nil
+}
+
+// processList returns a library snapshot with lifetime CPU semantics.
+func processList(filter *ProcessFilter, opts *ProcessOptions) (*ProcessSnapshot, error) {
+	if err := filter.validateGo(); err != nil {
+		return nil, err
+	}
+
 	var filterCStr *C.char
 	if filter != nil {
 		filterJSON, err := json.Marshal(filter)
@@ -252,10 +637,94 @@ func ProcessListWithOptions(filter *ProcessFilter, opts *ProcessOptions) (*Proce
 	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &snapshot); err != nil {
 		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
 	}
+	snapshot.Processes = filterProcesses(snapshot.Processes, filter)
+	for i := range snapshot.Processes {
+		opts.omitFields(&snapshot.Processes[i])
+	}
 
 	return &snapshot, nil
 }
 
+// FindFirst returns the first process matching filter.
+//
+// Pass nil for filter to match any process. Order follows [ProcessList]; when
+// several processes match, callers should not rely on which one is returned.
+//
+// This is a wrapper over a full [ProcessList] snapshot; it does not stop
+// enumeration early.
+//
+// # Errors
+//
+//   - [ErrNotFound]: No process matches filter
+//   - [ErrInvalidArgument]: Invalid filter JSON
+//   - [ErrSystem]: System error reading process information
+func FindFirst(filter *ProcessFilter) (*ProcessInfo, error) {
+	snapshot, err := ProcessList(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshot.Processes) == 0 {
+		return nil, &Error{Code: ErrNotFound, Message: "no process matches filter"}
+	}
+	return &snapshot.Processes[0], nil
+}
+
+// FindAll returns all processes matching filter.
+//
+// It is equivalent to [ProcessList] without the snapshot envelope. Unlike
+// [FindFirst], no match is not an error: the result is empty.
+func FindAll(filter *ProcessFilter) ([]ProcessInfo, error) {
+	snapshot, err := ProcessList(filter)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Processes, nil
+}
+
+// TopProcesses returns the n processes with the highest value for by, in
+// descending order. Ties are broken by ascending PID.
+//
+// Rankings come from a single [ProcessListWithOptions] snapshot, so
+// opts.CpuMode applies to [SortByCPU] and the call blocks for the sample
+// duration in monitor mode. [SortByThreads] implies IncludeThreads.
+// [SortByFds] additionally lists fds for each process (unsupported on
+// Windows); processes whose fds cannot be read rank as 0. Fewer than n
+// results are returned when fewer processes exist.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: n <= 0, unknown sort key, or invalid opts
+//   - [ErrSystem]: System error reading process information
+func TopProcesses(n int, by SortKey, opts *ProcessOptions) ([]ProcessInfo, error) {
+	if n <= 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "n must be > 0"}
+	}
+
+	var listOpts ProcessOptions
+	if opts != nil {
+		listOpts = *opts
+	}
+	switch by {
+	case SortByCPU, SortByMemory, SortByFds:
+	case SortByThreads:
+		listOpts.IncludeThreads = true
+	default:
+		return nil, &Error{Code: ErrInvalidArgument, Message: "invalid sort key: " + string(by)}
+	}
+
+	snapshot, err := ProcessListWithOptions(nil, &listOpts)
+	if err != nil {
+		return nil, err
+	}
+	processes := snapshot.Processes
+	sortProcesses(processes, by, true)
+
+	if len(processes) > n {
+		processes = processes[:n]
+	}
+	return processes, nil
+}
+
 // ProcessGet returns information for a single process by PID.
 //
 // # Errors
@@ -271,7 +740,15 @@ func ProcessGet(pid uint32) (*ProcessInfo, error) {
 // with opt-in extended fields.
 //
 // Pass nil for opts to use defaults (`include_env=false`, `include_threads=false`).
+//
+// # Errors
+//
+//   - [ErrNotSupported]: opts.IncludeOOM on a platform other than Linux
 func ProcessGetWithOptions(pid uint32, opts *ProcessOptions) (*ProcessInfo, error) {
+	if err := opts.checkOOM(); err != nil {
+		return nil, err
+	}
+
 	var optionsCStr *C.char
 	if opts != nil {
 		optionsJSON, err := json.Marshal(opts)
@@ -294,10 +771,204 @@ func ProcessGetWithOptions(pid uint32, opts *ProcessOptions) (*ProcessInfo, erro
 	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &info); err != nil {
 		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
 	}
+	opts.enrich(&info)
+	opts.omitFields(&info)
 
 	return &info, nil
 }
 
+// ProcessResult is the outcome of a single lookup in ProcessGetMany.
+//
+// Exactly one of Info and Error is set.
+type ProcessResult struct {
+	PID   uint32
+	Info  *ProcessInfo
+	Error *Error
+}
+
+// ProcessGetMany returns information for multiple processes by PID.
+//
+// PID validation happens for the entire slice before any lookups are made.
+// Lookups run on a bounded worker pool (see [ProcessOptions.Concurrency]);
+// per-PID failures such as [ErrNotFound] are reported in the corresponding
+// result rather than failing the batch. Results are in the same order as pids.
+//
+// This is implemented in Go (not a single FFI call) to avoid introducing new
+// FFI surface area.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pids is empty, contains 0 or a value > math.MaxInt32,
+//     or opts.Concurrency is negative
+//   - [ErrNotSupported]: opts.IncludeOOM on a platform other than Linux
+func ProcessGetMany(pids []uint32, opts *ProcessOptions) ([]ProcessResult, error) {
+	if err := validatePidList(pids); err != nil {
+		return nil, err
+	}
+	if err := opts.checkOOM(); err != nil {
+		return nil, err
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if opts != nil {
+		if opts.Concurrency < 0 {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "concurrency must be >= 0"}
+		}
+		if opts.Concurrency > 0 {
+			workers = opts.Concurrency
+		}
+	}
+	if workers > len(pids) {
+		workers = len(pids)
+	}
+
+	results := make([]ProcessResult, len(pids))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				pid := pids[i]
+				results[i].PID = pid
+
+				info, err := ProcessGetWithOptions(pid, opts)
+				if err == nil {
+					results[i].Info = info
+					continue
+				}
+
+				sErr, ok := err.(*Error)
+				if !ok {
+					sErr = &Error{Code: ErrInternal, Message: err.Error()}
+				}
+				results[i].Error = sErr
+			}
+		}()
+	}
+	for i := range pids {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results, nil
+}
+
+// ProcessGetEnvVar returns the value of a single environment variable of pid.
+//
+// Returns nil when the process environment does not contain key. Key
+// comparison follows platform semantics: case-sensitive on Unix,
+// case-insensitive on Windows.
+//
+// The library has no targeted lookup, so this reads the environment via
+// [ProcessGetWithOptions] and keeps only the requested value.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or key is empty
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: The process environment is not readable
+//   - [ErrNotSupported]: Environment collection is unsupported on this platform
+func ProcessGetEnvVar(pid uint32, key string) (*string, error) {
+	if key == "" {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "key must not be empty"}
+	}
+
+	info, err := ProcessGetWithOptions(pid, &ProcessOptions{IncludeEnv: true})
+	if err != nil {
+		return nil, err
+	}
+	if info.Env == nil {
+		if runtime.GOOS == "windows" {
+			return nil, &Error{Code: ErrNotSupported, Message: "process environment is not supported on windows"}
+		}
+		return nil, &Error{Code: ErrPermissionDenied, Message: "process environment is not readable"}
+	}
+
+	if value, ok := info.Env[key]; ok {
+		return &value, nil
+	}
+	if runtime.GOOS == "windows" {
+		for k, value := range info.Env {
+			if strings.EqualFold(k, key) {
+				return &value, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// CPUSample is the result of sampling a single process's CPU usage.
+type CPUSample struct {
+	// PID is the sampled process ID.
+	PID uint32
+	// CPUPercent is CPU usage over the measured window (may exceed 100 on
+	// multi-core).
+	CPUPercent float64
+	// CPUTimeBefore is the cumulative user+system CPU time at the first read.
+	CPUTimeBefore time.Duration
+	// CPUTimeAfter is the cumulative user+system CPU time at the second read.
+	// Equal to CPUTimeBefore when ExitedDuringSample is set.
+	CPUTimeAfter time.Duration
+	// Wall is the wall-clock time actually measured between the two reads.
+	Wall time.Duration
+	// ExitedDuringSample reports that the process disappeared before the
+	// second read; CPUPercent is then 0 and only CPUTimeBefore is meaningful.
+	ExitedDuringSample bool
+}
+
+// MonitorCPU samples CPU usage of a single process over the given window.
+//
+// The call blocks for sample. Unlike [ProcessListWithOptions] in monitor
+// mode, only the target process is read, and the result carries the raw
+// cumulative CPU times alongside the percentage.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32, or sample <= 0
+//   - [ErrNotFound]: Process doesn't exist at the first read
+//   - [ErrPermissionDenied]: Not permitted to read this process
+func MonitorCPU(pid uint32, sample time.Duration) (*CPUSample, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+	if sample <= 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "sample duration must be > 0"}
+	}
+
+	before, err := cpuTimeNS(pid)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	time.Sleep(sample)
+
+	after, err := cpuTimeNS(pid)
+	wall := time.Since(start)
+	result := &CPUSample{
+		PID:           pid,
+		CPUTimeBefore: time.Duration(before),
+		CPUTimeAfter:  time.Duration(before),
+		Wall:          wall,
+	}
+	if err != nil {
+		if sErr, ok := err.(*Error); ok && sErr.Code == ErrNotFound {
+			result.ExitedDuringSample = true
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result.CPUTimeAfter = time.Duration(after)
+	if after > before && wall > 0 {
+		result.CPUPercent = float64(after-before) / float64(wall.Nanoseconds()) * 100
+	}
+	return result, nil
+}
+
 // WaitPID waits for a PID to exit up to the provided timeout.
 //
 // Best-effort behavior:
@@ -324,13 +995,18 @@ func WaitPID(pid uint32, timeout time.Duration) (*WaitPidResult, error) {
 	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &result); err != nil {
 		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
 	}
+	result.WarningDetails = warningDetails("WaitPID", pid, result.Warnings)
+	if result.Exited {
+		releaseGroupJob(pid)
+	}
 
 	return &result, nil
 }
 
 // DescendantsLevel represents a single depth level in a descendants result.
 type DescendantsLevel struct {
-	// Level is the depth (1 = direct children, 2 = grandchildren, etc.).
+	// Level is the depth (1 = direct children, 2 = grandchildren, etc.; 0 =
+	// the root, with DescendantsOptions.IncludeRoot).
 	Level uint32 `json:"level"`
 	// Processes at this level.
 	Processes []ProcessInfo `json:"processes"`
@@ -346,6 +1022,19 @@ type DescendantsResult struct {
 	MatchedByFilter int                `json:"matched_by_filter"`
 	Timestamp       string             `json:"timestamp"`
 	Platform        string             `json:"platform"`
+	// Truncated is set when traversal stopped at DescendantsOptions.MaxTotal.
+	Truncated bool `json:"truncated,omitempty"`
+	// TimedOut is set when traversal stopped at DescendantsOptions.Timeout.
+	TimedOut bool `json:"timed_out,omitempty"`
+	// Warnings explains a truncated or timed-out traversal.
+	Warnings []string `json:"warnings,omitempty"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+	// RootPIDs lists the roots of a DescendantsOf traversal.
+	RootPIDs []uint32 `json:"root_pids,omitempty"`
+	// RootsByPID maps each reported process to the DescendantsOf roots whose
+	// subtrees contain it, nearest first.
+	RootsByPID map[uint32][]uint32 `json:"roots_by_pid,omitempty"`
 }
 
 // KillDescendantsResult is the result of a kill-descendants operation.
@@ -356,6 +1045,9 @@ type KillDescendantsResult struct {
 	Succeeded     []uint32              `json:"succeeded"`
 	Failed        []KillDescendantsFail `json:"failed"`
 	SkippedSafety int                   `json:"skipped_safety"`
+	// SkippedPolicy lists the descendants left alone by a [SafetyPolicy],
+	// in ascending order. It is nil when no policy applies.
+	SkippedPolicy []uint32 `json:"skipped_policy,omitempty"`
 }
 
 // KillDescendantsFail is a single failure in a kill-descendants operation.
@@ -373,6 +1065,20 @@ type DescendantsOptions struct {
 	CpuMode CpuMode
 	// SampleDuration is used when CpuMode is monitor. 0 means default sample.
 	SampleDuration time.Duration
+	// MaxTotal stops traversal after collecting this many descendants
+	// (before filtering) and sets Truncated. Nil means no cap; 0 is invalid.
+	MaxTotal *uint32
+	// Timeout stops traversal once it has run this long and sets TimedOut.
+	// 0 means no timeout. The time covers checking the roots and taking the
+	// process snapshot, including a monitor-mode sample; if those use it up,
+	// the result holds no levels. A sample longer than Timeout is not taken.
+	// The snapshot itself cannot be interrupted, so a call may still overrun
+	// Timeout by the time one snapshot takes.
+	Timeout time.Duration
+	// IncludeRoot adds a level 0 holding the root's ProcessInfo, read from
+	// the same snapshot as its descendants, and counts it in TotalFound.
+	// The filter does not apply to it, and MaxTotal does not count it.
+	IncludeRoot bool
 }
 
 type KillDescendantsOptions struct {
@@ -386,6 +1092,8 @@ type KillDescendantsOptions struct {
 	CpuMode CpuMode
 	// SampleDuration is used when CpuMode is monitor. 0 means default sample.
 	SampleDuration time.Duration
+	// Policy adds protections to the safety rules; see [SafetyPolicy].
+	Policy *SafetyPolicy
 }
 
 // Descendants returns the process subtree rooted at pid.
@@ -412,7 +1120,14 @@ func normalizeCpuMode(mode CpuMode) (CpuMode, error) {
 	}
 }
 
+// buildDescendantsConfigJSON encodes the library-evaluated filter fields plus
+// cpu mode/sample config. Go-side filter criteria are not encoded; callers
+// apply them to the result (see filterDescendants).
 func buildDescendantsConfigJSON(filter *ProcessFilter, mode CpuMode, sample time.Duration) (string, error) {
+	if err := filter.validateGo(); err != nil {
+		return "", err
+	}
+
 	config := make(map[string]interface{})
 	if filter != nil {
 		filterJSON, err := json.Marshal(filter)
@@ -451,7 +1166,22 @@ func buildDescendantsConfigJSON(filter *ProcessFilter, mode CpuMode, sample time
 }
 
 // DescendantsWithOptions returns descendants using optional cpu mode/sample config.
+//
+// With MaxTotal or Timeout set, traversal runs in the Go bindings and stops
+// early, returning the levels collected so far with a warning. IncludeRoot
+// also runs in the Go bindings, so the root and its descendants come from
+// one snapshot.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: root_pid is 0, filter/config is invalid,
+//     MaxTotal is 0, or Timeout is negative
+//   - [ErrNotFound]: root process doesn't exist
 func DescendantsWithOptions(pid uint32, opts *DescendantsOptions) (*DescendantsResult, error) {
+	if opts != nil && (opts.MaxTotal != nil || opts.Timeout != 0 || opts.IncludeRoot) {
+		return descendantsBounded(pid, opts)
+	}
+
 	maxLevels := uint32(^uint32(0))
 	var filter *ProcessFilter
 	cpuMode := CpuModeLifetime
@@ -495,6 +1225,7 @@ func DescendantsWithOptions(pid uint32, opts *DescendantsOptions) (*DescendantsR
 	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &result); err != nil {
 		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
 	}
+	filterDescendants(&result, filter)
 
 	return &result, nil
 }
@@ -519,10 +1250,29 @@ func KillDescendants(pid uint32, signal int, maxLevels uint32, filter *ProcessFi
 
 // KillDescendantsWithOptions sends a signal to descendants using optional
 // cpu mode/sample config for filter evaluation.
+//
+// When the filter sets Go-side criteria (such as ExePathContains), or a
+// [SafetyPolicy] applies (opts.Policy or the default policy), traversal and
+// signaling happen in the Go bindings with the same safety rules.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: root_pid is 0, filter/config is invalid, or more
+//     descendants than the policy's MaxKillCount would be signaled
+//   - [ErrNotFound]: root process doesn't exist
+//   - [ErrNotSupported]: The policy sets ProtectOtherUsers and the calling
+//     process's user cannot be resolved
 func KillDescendantsWithOptions(pid uint32, opts *KillDescendantsOptions) (*KillDescendantsResult, error) {
+	return killDescendants(pid, opts, nil)
+}
+
+// killDescendants implements KillDescendantsWithOptions. A non-nil root is
+// passed to killDescendantsFiltered for KillTree.
+func killDescendants(pid uint32, opts *KillDescendantsOptions, root *treeRoot) (*KillDescendantsResult, error) {
 	signal := 15
 	maxLevels := uint32(^uint32(0))
 	var filter *ProcessFilter
+	var policy *SafetyPolicy
 	cpuMode := CpuModeLifetime
 	sampleDuration := time.Duration(0)
 
@@ -534,10 +1284,25 @@ func KillDescendantsWithOptions(pid uint32, opts *KillDescendantsOptions) (*Kill
 			maxLevels = *opts.MaxLevels
 		}
 		filter = opts.Filter
+		policy = opts.Policy
 		cpuMode = opts.CpuMode
 		sampleDuration = opts.SampleDuration
 	}
 
+	check, err := resolveSafety(policy)
+	if err != nil {
+		return nil, err
+	}
+	if filter.hasGoCriteria() || check != nil {
+		return killDescendantsFiltered(pid, signal, &DescendantsOptions{
+			MaxLevels:      &maxLevels,
+			Filter:         filter,
+			CpuMode:        cpuMode,
+			SampleDuration: sampleDuration,
+			IncludeRoot:    root != nil,
+		}, check, root)
+	}
+
 	configJSON, err := buildDescendantsConfigJSON(filter, cpuMode, sampleDuration)
 	if err != nil {
 		return nil, err
@@ -579,16 +1344,30 @@ func KillDescendantsWithOptions(pid uint32, opts *KillDescendantsOptions) (*Kill
 //   - On macOS, SIP/TCC can restrict socket attribution even for same-user
 //     processes. In those environments, callers should treat results as best-effort
 //     and fall back to platform tooling if required.
+//   - The PID and ProcessNameContains filters select from the library's
+//     attribution, which covers every socket; they narrow the result, not
+//     the work. When some bindings could not be attributed, a warning says
+//     the filter may have missed them.
+//   - IPv4-mapped local addresses are reported in IPv4 form (see
+//     PortBinding.Family); address filters match either spelling. On Linux,
+//     when sock_diag is unavailable, a warning is added and ScopeID and
+//     V6Only are left nil.
 //
 // # Errors
 //
-//   - [ErrInvalidArgument]: Filter is invalid
+//   - [ErrInvalidArgument]: Filter is invalid, LocalAddrEquals is not an IP
+//     address, both WildcardOnly and LoopbackOnly are set, or PID is 0
 //   - [ErrPermissionDenied]: The platform denies even self inspection
 //   - [ErrNotSupported]: Port attribution is not supported on this platform
 func ListeningPorts(filter *PortFilter) (*PortBindingsSnapshot, error) {
+	localAddr, err := filter.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	var filterCStr *C.char
 	if filter != nil {
-		filterJSON, err := json.Marshal(filter)
+		filterJSON, err := json.Marshal(filter.forLibrary())
 		if err != nil {
 			return nil, &Error{Code: ErrInvalidArgument, Message: "failed to marshal filter: " + err.Error()}
 		}
@@ -608,6 +1387,29 @@ func ListeningPorts(filter *PortFilter) (*PortBindingsSnapshot, error) {
 	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &snapshot); err != nil {
 		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
 	}
+	for i := range snapshot.Bindings {
+		snapshot.Bindings[i].LocalPort = libraryPort(snapshot.Bindings[i].LocalPort)
+	}
+	annotatePortBindings(&snapshot)
+	filterPortBindings(&snapshot, filter, localAddr)
+	if filter != nil && filter.IncludeQueueStats {
+		annotateQueueStats(&snapshot)
+	}
+	if filter != nil && filter.DedupeProcesses {
+		dedupeProcesses(&snapshot)
+	}
+	snapshot.WarningDetails = warningDetails("ListeningPorts", 0, snapshot.Warnings)
 
 	return &snapshot, nil
 }
+
+// ListeningPortsForPID returns the listening ports owned by pid. It is
+// shorthand for ListeningPorts with PortFilter.PID set.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0
+//   - Errors from [ListeningPorts]
+func ListeningPortsForPID(pid uint32) (*PortBindingsSnapshot, error) {
+	return ListeningPorts(&PortFilter{PID: &pid})
+}
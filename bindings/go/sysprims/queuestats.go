@@ -0,0 +1,87 @@
+package sysprims
+
+import "net/netip"
+
+// queueStats holds the socket buffer usage of one socket: the receive and
+// send queues of a UDP socket, or the accept queue length and backlog of a
+// TCP listener.
+type queueStats struct {
+	rx, tx uint64
+	drops  *uint64
+}
+
+// queueKey identifies a socket by protocol, unmapped local address, and the
+// port as the library's listing reports it.
+type queueKey struct {
+	protocol Protocol
+	addr     netip.AddrPort
+}
+
+// annotateQueueStats sets RxQueueBytes, TxQueueBytes, and Drops on the UDP
+// bindings of snapshot, and BacklogCurrent and BacklogMax on its TCP
+// listeners. A binding whose socket cannot be told apart from another (such
+// as SO_REUSEPORT sockets on the same address) is left nil. A failed or
+// unsupported read adds a warning and leaves the fields nil.
+func annotateQueueStats(snapshot *PortBindingsSnapshot) {
+	var bindings []*PortBinding
+	for i := range snapshot.Bindings {
+		switch snapshot.Bindings[i].Protocol {
+		case ProtocolUDP, ProtocolTCP:
+			bindings = append(bindings, &snapshot.Bindings[i])
+		}
+	}
+	if len(bindings) == 0 {
+		return
+	}
+	snapshot.Warnings = append(snapshot.Warnings, readQueueStats(bindings)...)
+}
+
+// queueStatsFor returns the stats of b in stats. A nil entry marks a key
+// shared by several sockets.
+func queueStatsFor(b *PortBinding, stats map[queueKey]*queueStats) (*queueStats, bool) {
+	if b.LocalAddr == nil {
+		return nil, false
+	}
+	ip, err := netip.ParseAddr(*b.LocalAddr)
+	if err != nil {
+		return nil, false
+	}
+	s := stats[queueKey{b.Protocol, netip.AddrPortFrom(ip.Unmap(), b.LocalPort)}]
+	return s, s != nil
+}
+
+// addQueueStats records s for key, marking the key ambiguous when it was
+// already seen.
+func addQueueStats(stats map[queueKey]*queueStats, key queueKey, s *queueStats) {
+	if _, seen := stats[key]; seen {
+		stats[key] = nil
+		return
+	}
+	stats[key] = s
+}
+
+// setQueueStats copies s into the queue fields of b.
+func setQueueStats(b *PortBinding, s *queueStats) {
+	switch b.Protocol {
+	case ProtocolUDP:
+		rx, tx := s.rx, s.tx
+		b.RxQueueBytes = &rx
+		b.TxQueueBytes = &tx
+		b.Drops = s.drops
+	case ProtocolTCP:
+		current, limit := uint32(s.rx), uint32(s.tx)
+		b.BacklogCurrent = &current
+		b.BacklogMax = &limit
+	}
+}
+
+// backlogUnsupported returns a warning when bindings hold a TCP listener,
+// for platforms that do not report accept queues.
+func backlogUnsupported(bindings []*PortBinding) []string {
+	for _, b := range bindings {
+		if b.Protocol == ProtocolTCP {
+			return []string{"TCP accept queue statistics not supported on this platform"}
+		}
+	}
+	return nil
+}
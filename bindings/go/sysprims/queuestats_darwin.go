@@ -0,0 +1,132 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <netinet/in.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/proc_info.h>
+
+struct sysprims_go_udp_socket {
+	uint64_t so;
+	int v6;
+	uint8_t addr[16];
+	uint16_t port;
+	uint32_t rx;
+	uint32_t tx;
+};
+
+// sysprims_go_udp_sockets fills out with up to cap UDP sockets open in pid,
+// read with PROC_PIDFDSOCKETINFO, and stores the number found in n. Fds
+// closed during the scan are skipped.
+static int sysprims_go_udp_sockets(int pid, struct sysprims_go_udp_socket *out, int cap, int *n) {
+	*n = 0;
+	errno = 0;
+	int bytes = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, NULL, 0);
+	if (bytes <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	bytes += 32 * (int)sizeof(struct proc_fdinfo);
+	struct proc_fdinfo *fds = malloc(bytes);
+	if (fds == NULL) {
+		return ENOMEM;
+	}
+	errno = 0;
+	bytes = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, fds, bytes);
+	if (bytes <= 0) {
+		free(fds);
+		return errno == 0 ? ESRCH : errno;
+	}
+	int count = bytes / (int)sizeof(struct proc_fdinfo);
+	for (int i = 0; i < count && *n < cap; i++) {
+		if (fds[i].proc_fdtype != PROX_FDTYPE_SOCKET) {
+			continue;
+		}
+		struct socket_fdinfo si;
+		if (proc_pidfdinfo(pid, fds[i].proc_fd, PROC_PIDFDSOCKETINFO, &si, sizeof(si)) < (int)sizeof(si)) {
+			continue;
+		}
+		if (si.psi.soi_kind != SOCKINFO_IN || si.psi.soi_protocol != IPPROTO_UDP) {
+			continue;
+		}
+		struct in_sockinfo *in = &si.psi.soi_proto.pri_in;
+		struct sysprims_go_udp_socket *s = &out[(*n)++];
+		memset(s, 0, sizeof(*s));
+		s->so = si.psi.soi_so;
+		s->v6 = (in->insi_vflag & INI_IPV6) != 0;
+		if (s->v6) {
+			memcpy(s->addr, &in->insi_laddr.ina_6, 16);
+		} else {
+			memcpy(s->addr, &in->insi_laddr.ina_46.i46a_addr4, 4);
+		}
+		s->port = ntohs((uint16_t)in->insi_lport);
+		s->rx = si.psi.soi_rcv.sbi_cc;
+		s->tx = si.psi.soi_snd.sbi_cc;
+	}
+	free(fds);
+	return 0;
+}
+*/
+import "C"
+
+import (
+	"net/netip"
+	"unsafe"
+)
+
+// maxUDPSocketsPerProcess bounds the UDP sockets read from one process.
+const maxUDPSocketsPerProcess = 4096
+
+// readQueueStats reads the receive and send buffer byte counts of the UDP
+// sockets held by the bindings' owning processes, via PROC_PIDFDSOCKETINFO.
+// It is best-effort: bindings without an owning PID or owned by unreadable
+// processes are left nil. Drops are not tracked per socket, so Drops stays
+// nil, and TCP accept queues are not reported.
+func readQueueStats(bindings []*PortBinding) []string {
+	warnings := backlogUnsupported(bindings)
+	buf := C.malloc(C.size_t(maxUDPSocketsPerProcess) * C.size_t(C.sizeof_struct_sysprims_go_udp_socket))
+	if buf == nil {
+		return append(warnings, "socket queue statistics unavailable: out of memory")
+	}
+	defer C.free(buf)
+
+	stats := make(map[queueKey]*queueStats)
+	owner := make(map[queueKey]uint64)
+	scanned := make(map[uint32]bool)
+	for _, b := range bindings {
+		if b.Protocol != ProtocolUDP || b.PID == nil || scanned[*b.PID] {
+			continue
+		}
+		scanned[*b.PID] = true
+		var n C.int
+		if C.sysprims_go_udp_sockets(C.int(*b.PID), (*C.struct_sysprims_go_udp_socket)(buf), maxUDPSocketsPerProcess, &n) != 0 {
+			continue
+		}
+		for _, s := range unsafe.Slice((*C.struct_sysprims_go_udp_socket)(buf), int(n)) {
+			var ip netip.Addr
+			if s.v6 != 0 {
+				ip = netip.AddrFrom16(*(*[16]byte)(unsafe.Pointer(&s.addr[0]))).Unmap()
+			} else {
+				ip = netip.AddrFrom4(*(*[4]byte)(unsafe.Pointer(&s.addr[0])))
+			}
+			key := queueKey{ProtocolUDP, netip.AddrPortFrom(ip, uint16(s.port))}
+			// A socket inherited by several processes is seen once per
+			// process; only distinct sockets make a key ambiguous.
+			if so, ok := owner[key]; ok && so == uint64(s.so) {
+				continue
+			}
+			owner[key] = uint64(s.so)
+			addQueueStats(stats, key, &queueStats{rx: uint64(s.rx), tx: uint64(s.tx)})
+		}
+	}
+
+	for _, b := range bindings {
+		if s, ok := queueStatsFor(b, stats); ok {
+			setQueueStats(b, s)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// readQueueStats reads the queue statistics of the bindings: UDP queues and
+// drops from the tx_queue, rx_queue, and drops columns of /proc/net/udp and
+// /proc/net/udp6, and TCP accept queues with sock_diag.
+//
+// The UDP queue columns count the buffer memory charged to the socket,
+// including per-packet overhead, as ss -u reports it. /proc/net/tcp lacks
+// the backlog limit of a listener, so TCP reads idiag_rqueue (connections
+// waiting to be accepted) and idiag_wqueue (the backlog) as ss -lt does.
+func readQueueStats(bindings []*PortBinding) []string {
+	var udp, tcp bool
+	for _, b := range bindings {
+		udp = udp || b.Protocol == ProtocolUDP
+		tcp = tcp || b.Protocol == ProtocolTCP
+	}
+
+	stats := make(map[queueKey]*queueStats)
+	var warnings []string
+	if udp {
+		scanned, err := scanProcNetSockets(func(protocol Protocol, fields []string, _ uint64) bool {
+			if protocol != ProtocolUDP {
+				return true
+			}
+			addr, port, err := parseProcNetEndpoint(fields[1])
+			if err != nil {
+				return false
+			}
+			ip, err := netip.ParseAddr(addr)
+			if err != nil {
+				return false
+			}
+			txHex, rxHex, ok := strings.Cut(fields[4], ":")
+			if !ok {
+				return false
+			}
+			tx, err := strconv.ParseUint(txHex, 16, 64)
+			if err != nil {
+				return false
+			}
+			rx, err := strconv.ParseUint(rxHex, 16, 64)
+			if err != nil {
+				return false
+			}
+			s := &queueStats{rx: rx, tx: tx}
+			if len(fields) > 12 {
+				if drops, err := strconv.ParseUint(fields[12], 10, 64); err == nil {
+					s.drops = &drops
+				}
+			}
+			addQueueStats(stats, queueKey{ProtocolUDP, netip.AddrPortFrom(ip.Unmap(), port)}, s)
+			return true
+		})
+		warnings = append(warnings, scanned...)
+		if err != nil {
+			warnings = append(warnings, "UDP queue statistics unavailable: "+err.Error())
+		}
+	}
+	if tcp {
+		for _, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+			err := sockDiagDump(family, ProtocolTCP, func(msg []byte) {
+				port := binary.BigEndian.Uint16(msg[4:])
+				ip := net.IP(msg[8:24])
+				if family == syscall.AF_INET {
+					ip = ip[:4]
+				}
+				addr, ok := netip.AddrFromSlice(ip)
+				if !ok {
+					return
+				}
+				// idiag_rqueue and idiag_wqueue follow idiag_expires.
+				s := &queueStats{
+					rx: uint64(binary.NativeEndian.Uint32(msg[56:])),
+					tx: uint64(binary.NativeEndian.Uint32(msg[60:])),
+				}
+				addQueueStats(stats, queueKey{ProtocolTCP, netip.AddrPortFrom(addr.Unmap(), port)}, s)
+			})
+			if err != nil {
+				warnings = append(warnings, "TCP accept queue statistics unavailable: "+err.Error())
+				break
+			}
+		}
+	}
+
+	for _, b := range bindings {
+		if s, ok := queueStatsFor(b, stats); ok {
+			setQueueStats(b, s)
+		}
+	}
+	return warnings
+}
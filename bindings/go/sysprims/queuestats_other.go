@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package sysprims
+
+// readQueueStats leaves the queue fields nil: per-socket buffer usage is
+// not exposed here.
+func readQueueStats(bindings []*PortBinding) []string {
+	return backlogUnsupported(bindings)
+}
@@ -0,0 +1,153 @@
+package sysprims
+
+/*
+#include "sysprims.h"
+#include <stdlib.h>
+#include <string.h>
+*/
+import "C"
+import (
+	"encoding/json"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// The Raw variants return the library's JSON payload verbatim, for callers
+// that forward it as-is. Nothing is decoded, so the filtering, enrichment,
+// and typed warnings the Go bindings add are unavailable; options that need
+// them are rejected with ErrInvalidArgument rather than silently ignored.
+
+// ProcessListRaw is like [ProcessListWithOptions] but returns the snapshot
+// JSON without decoding it.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Invalid filter/options, or a Go-side filter field,
+//     [CpuModeMonitor], or an Omit or Include option other than IncludeEnv
+//     and IncludeThreads is set
+//   - [ErrSystem]: System error reading process information
+func ProcessListRaw(filter *ProcessFilter, opts *ProcessOptions) (json.RawMessage, error) {
+	if filter.hasGoCriteria() {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "raw listing does not support Go-side filter fields"}
+	}
+	if opts != nil {
+		mode, err := normalizeCpuMode(opts.CpuMode)
+		if err != nil {
+			return nil, err
+		}
+		if mode == CpuModeMonitor {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "raw listing does not support cpu monitor mode"}
+		}
+		if opts.hasGoFields() {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "raw listing does not support Go-side options"}
+		}
+	}
+
+	var filterCStr *C.char
+	if filter != nil {
+		filterJSON, err := json.Marshal(filter)
+		if err != nil {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "failed to marshal filter: " + err.Error()}
+		}
+		filterCStr = C.CString(string(filterJSON))
+		defer C.free(unsafe.Pointer(filterCStr))
+	}
+
+	var optionsCStr *C.char
+	if opts != nil {
+		optionsJSON, err := json.Marshal(opts)
+		if err != nil {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "failed to marshal options: " + err.Error()}
+		}
+		optionsCStr = C.CString(string(optionsJSON))
+		defer C.free(unsafe.Pointer(optionsCStr))
+	}
+
+	var resultCStr *C.char
+	if err := callAndCheck(func() C.SysprimsErrorCode {
+		return C.sysprims_proc_list_ex(filterCStr, optionsCStr, &resultCStr)
+	}); err != nil {
+		return nil, err
+	}
+	return takeRawJSON(resultCStr)
+}
+
+// ListeningPortsRaw is like [ListeningPorts] but returns the snapshot JSON
+// without decoding it. filter.LocalPort selects by real port number, but on
+// little-endian Linux the payload's local_port values are byte-swapped, as
+// the library reports them; ListeningPorts corrects them.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: A Go-side address or process criterion,
+//     IncludeQueueStats, or DedupeProcesses is set
+func ListeningPortsRaw(filter *PortFilter) (json.RawMessage, error) {
+	if filter.hasGoCriteria() {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "raw listing does not support Go-side filter fields"}
+	}
+	if filter != nil && (filter.IncludeQueueStats || filter.DedupeProcesses) {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "raw listing does not support Go-collected fields"}
+	}
+
+	var filterCStr *C.char
+	if filter != nil {
+		filterJSON, err := json.Marshal(filter.forLibrary())
+		if err != nil {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "failed to marshal filter: " + err.Error()}
+		}
+		filterCStr = C.CString(string(filterJSON))
+		defer C.free(unsafe.Pointer(filterCStr))
+	}
+
+	var resultCStr *C.char
+	if err := callAndCheck(func() C.SysprimsErrorCode {
+		return C.sysprims_proc_listening_ports(filterCStr, &resultCStr)
+	}); err != nil {
+		return nil, err
+	}
+	return takeRawJSON(resultCStr)
+}
+
+// ListFdsRaw is like [ListFds] but returns the snapshot JSON without
+// decoding it. Socket details, fd flags, offsets, and deleted status are not
+// resolved.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: A Go-side criterion (path, fd range, paging,
+//     CloseOnExec, IncludeOffsets, or OnlyDeleted) is set
+func ListFdsRaw(pid uint32, filter *FdFilter) (json.RawMessage, error) {
+	if filter.hasGoCriteria() {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "raw listing does not support Go-side filter fields"}
+	}
+
+	var filterCStr *C.char
+	if filter != nil {
+		filterJSON, err := json.Marshal(filter)
+		if err != nil {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "failed to marshal filter: " + err.Error()}
+		}
+		filterCStr = C.CString(string(filterJSON))
+		defer C.free(unsafe.Pointer(filterCStr))
+	}
+
+	var resultCStr *C.char
+	if err := callAndCheck(func() C.SysprimsErrorCode {
+		return C.sysprims_proc_list_fds(C.uint32_t(pid), filterCStr, &resultCStr)
+	}); err != nil {
+		return nil, err
+	}
+	return takeRawJSON(resultCStr)
+}
+
+// takeRawJSON copies a library-owned JSON string into Go memory, frees the C
+// string, and verifies the payload is valid UTF-8 JSON.
+func takeRawJSON(s *C.char) (json.RawMessage, error) {
+	defer C.sysprims_free_string(s)
+
+	raw := json.RawMessage(C.GoBytes(unsafe.Pointer(s), C.int(C.strlen(s))))
+	if !utf8.Valid(raw) || !json.Valid(raw) {
+		return nil, &Error{Code: ErrInternal, Message: "library returned invalid JSON"}
+	}
+	return raw, nil
+}
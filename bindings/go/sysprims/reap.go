@@ -0,0 +1,61 @@
+package sysprims
+
+import "time"
+
+// Reap collects the exit status of pid if it has exited, without blocking,
+// so an exited child stops showing up as a zombie.
+//
+// Only direct children of the calling process can be reaped. A child that
+// is still running yields Exited=false and TimedOut=true, as [WaitPID] with
+// a zero timeout would. A child killed by a signal has no ExitCode; it has
+// Signaled and ExitSignal set and a warning naming the signal.
+//
+// Reaping takes the status away from anyone else waiting on the child,
+// including an os/exec Cmd or a [Supervisor]; do not reap children they
+// own.
+//
+// On Windows there are no zombies: Reap reports the process's status like
+// WaitPID with a zero timeout and reaps nothing.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32, or pid is not a
+//     child of the calling process
+//   - [ErrNotFound]: pid doesn't exist (or was already reaped)
+func Reap(pid uint32) (*WaitPidResult, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+	return reap(pid)
+}
+
+// ReapAll reaps every exited child of the calling process without blocking
+// and returns their results. It returns an empty slice when no child has
+// exited.
+//
+// The same caveats as [Reap] apply, and more broadly: ReapAll also reaps
+// children started with os/exec, whose Wait then fails. Use it only in
+// programs that manage all of their children through sysprims.
+//
+// On Windows ReapAll is a no-op returning an empty slice.
+func ReapAll() ([]WaitPidResult, error) {
+	return reapAll()
+}
+
+// newWaitPidResult builds a result without classifying its warnings; the
+// caller sets WarningDetails under its own operation name.
+func newWaitPidResult(pid uint32, exited, timedOut bool, exitCode *int32, warnings []string) *WaitPidResult {
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return &WaitPidResult{
+		SchemaID:  SchemaWaitPidResultV1,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Platform:  Platform(),
+		PID:       pid,
+		Exited:    exited,
+		TimedOut:  timedOut,
+		ExitCode:  exitCode,
+		Warnings:  warnings,
+	}
+}
@@ -0,0 +1,64 @@
+//go:build !windows
+
+package sysprims
+
+import (
+	"strconv"
+	"syscall"
+)
+
+func reap(pid uint32) (*WaitPidResult, error) {
+	for {
+		var ws syscall.WaitStatus
+		got, err := syscall.Wait4(int(pid), &ws, syscall.WNOHANG, nil)
+		switch {
+		case err == syscall.EINTR:
+			continue
+		case err == syscall.ECHILD:
+			if syscall.Kill(int(pid), 0) == syscall.ESRCH {
+				return nil, errnoError(pid, syscall.ESRCH)
+			}
+			return nil, &Error{Code: ErrInvalidArgument, Message: "process " + strconv.FormatUint(uint64(pid), 10) + " is not a child of this process"}
+		case err != nil:
+			return nil, errnoError(pid, err.(syscall.Errno))
+		case got == 0:
+			return newWaitPidResult(pid, false, true, nil, nil), nil
+		default:
+			result := exitResult(pid, ws)
+			result.WarningDetails = warningDetails("Reap", pid, result.Warnings)
+			return result, nil
+		}
+	}
+}
+
+func reapAll() ([]WaitPidResult, error) {
+	results := []WaitPidResult{}
+	for {
+		var ws syscall.WaitStatus
+		got, err := syscall.Wait4(-1, &ws, syscall.WNOHANG, nil)
+		switch {
+		case err == syscall.EINTR:
+			continue
+		case err == syscall.ECHILD, err == nil && got == 0:
+			return results, nil
+		case err != nil:
+			return results, (&Error{Code: ErrSystem, Message: "wait4 failed: " + err.Error()}).withErrno(err.(syscall.Errno))
+		}
+		result := exitResult(uint32(got), ws)
+		result.WarningDetails = warningDetails("ReapAll", result.PID, result.Warnings)
+		results = append(results, *result)
+	}
+}
+
+// exitResult converts a reaped child's wait status.
+func exitResult(pid uint32, ws syscall.WaitStatus) *WaitPidResult {
+	if ws.Signaled() {
+		sig := int(ws.Signal())
+		result := newWaitPidResult(pid, true, false, nil, []string{"terminated by signal " + strconv.Itoa(sig)})
+		result.Signaled = true
+		result.ExitSignal = &sig
+		return result
+	}
+	code := int32(ws.ExitStatus())
+	return newWaitPidResult(pid, true, false, &code, nil)
+}
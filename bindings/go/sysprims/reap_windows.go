@@ -0,0 +1,11 @@
+//go:build windows
+
+package sysprims
+
+func reap(pid uint32) (*WaitPidResult, error) {
+	return WaitPID(pid, 0)
+}
+
+func reapAll() ([]WaitPidResult, error) {
+	return []WaitPidResult{}, nil
+}
@@ -0,0 +1,51 @@
+package sysprims
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// IsRetryable reports whether err is a transient failure worth retrying:
+// an [ErrSystem] error whose errno commonly clears on retry (see
+// [Error.IsTransient]), such as EMFILE, EAGAIN, or EINTR, or such a
+// syscall.Errno itself. Every other error, including [ErrInvalidArgument],
+// [ErrNotFound], and [ErrPermissionDenied], is not retryable.
+func IsRetryable(err error) bool {
+	var sErr *Error
+	if errors.As(err, &sErr) {
+		return sErr.Code == ErrSystem && sErr.IsTransient()
+	}
+	var errno syscall.Errno
+	return errors.As(err, &errno) && isTransientErrno(errno)
+}
+
+// Retry calls fn up to attempts times until it succeeds or fails with an
+// error that is not [IsRetryable], and returns fn's last error. It sleeps
+// backoff before the second attempt and doubles the delay before each
+// further one.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: attempts < 1 or backoff is negative; fn is not
+//     called
+//   - The last error from fn
+func Retry(attempts int, backoff time.Duration, fn func() error) error {
+	if attempts < 1 {
+		return &Error{Code: ErrInvalidArgument, Message: "attempts must be >= 1"}
+	}
+	if backoff < 0 {
+		return &Error{Code: ErrInvalidArgument, Message: "backoff must be >= 0"}
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = fn(); err == nil || !IsRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
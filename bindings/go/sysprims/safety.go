@@ -0,0 +1,164 @@
+package sysprims
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// SafetyPolicy adds caller-defined protections to the built-in safety rules
+// of destructive operations ([KillDescendantsWithOptions], [KillTree],
+// [KillMatching], [KillByPort], and [TerminateTree]).
+//
+// A policy is evaluated against the same process snapshot the operation
+// uses to pick its targets, so a process is judged by the name and user it
+// had when it was chosen. PIDs left alone by the policy are reported in the
+// result's SkippedPolicy, apart from the built-in SkippedSafety.
+type SafetyPolicy struct {
+	// ProtectedPIDs are never signaled.
+	ProtectedPIDs []uint32
+	// ProtectedNames are process names (compared with ProcessInfo.Name
+	// exactly) that are never signaled.
+	ProtectedNames []string
+	// ProtectOtherUsers leaves alone processes whose User differs from the
+	// calling process's, and those whose user cannot be read.
+	ProtectOtherUsers bool
+	// MaxKillCount, when positive, fails the operation with
+	// ErrInvalidArgument before anything is signaled if more targets remain
+	// after the other rules.
+	MaxKillCount int
+}
+
+// defaultPolicy applies to the operations listed on SafetyPolicy; nil means
+// none.
+var defaultPolicy atomic.Pointer[SafetyPolicy]
+
+// SetDefaultSafetyPolicy sets a policy applied by [KillDescendantsWithOptions],
+// [KillTree], [KillMatching] (and so [KillByName]), [KillByPort], and
+// [TerminateTree] in addition to the policy passed to the call, if any: the
+// protections of both apply, and the smaller MaxKillCount wins. Pass nil to
+// remove it (the default). The policy is copied.
+//
+// Operations on explicit PIDs, such as [KillMany], [GracefulShutdown],
+// [GracefulStop], [KillAndWait], the Suspend and Resume functions,
+// [SignalSession], and [SignalSelfGroupWithOptions], do not consult it.
+//
+// SetDefaultSafetyPolicy is safe to call concurrently with other functions
+// in this package.
+func SetDefaultSafetyPolicy(p *SafetyPolicy) {
+	if p == nil {
+		defaultPolicy.Store(nil)
+		return
+	}
+	c := p.merge(nil)
+	defaultPolicy.Store(&c)
+}
+
+// DefaultSafetyPolicy returns a copy of the policy set by
+// [SetDefaultSafetyPolicy], or nil.
+func DefaultSafetyPolicy() *SafetyPolicy {
+	p := defaultPolicy.Load()
+	if p == nil {
+		return nil
+	}
+	c := p.merge(nil)
+	return &c
+}
+
+// merge returns the union of p and q, either of which may be nil.
+func (p *SafetyPolicy) merge(q *SafetyPolicy) SafetyPolicy {
+	var m SafetyPolicy
+	for _, s := range []*SafetyPolicy{p, q} {
+		if s == nil {
+			continue
+		}
+		m.ProtectedPIDs = append(m.ProtectedPIDs, s.ProtectedPIDs...)
+		m.ProtectedNames = append(m.ProtectedNames, s.ProtectedNames...)
+		m.ProtectOtherUsers = m.ProtectOtherUsers || s.ProtectOtherUsers
+		if s.MaxKillCount > 0 && (m.MaxKillCount == 0 || s.MaxKillCount < m.MaxKillCount) {
+			m.MaxKillCount = s.MaxKillCount
+		}
+	}
+	return m
+}
+
+// safetyCheck is the combination of a call's policy and the default policy,
+// ready to evaluate processes.
+type safetyCheck struct {
+	pids       map[uint32]bool
+	names      map[string]bool
+	otherUsers bool
+	user       string
+	max        int
+}
+
+// resolveSafety combines p with the default policy. It returns nil when
+// neither is set.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: MaxKillCount is negative
+//   - [ErrNotSupported]: ProtectOtherUsers is set and the calling process's
+//     user cannot be resolved
+func resolveSafety(p *SafetyPolicy) (*safetyCheck, error) {
+	d := defaultPolicy.Load()
+	if p == nil && d == nil {
+		return nil, nil
+	}
+	if p != nil && p.MaxKillCount < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "max kill count must be >= 0"}
+	}
+	m := d.merge(p)
+
+	c := &safetyCheck{
+		pids:       make(map[uint32]bool, len(m.ProtectedPIDs)),
+		names:      make(map[string]bool, len(m.ProtectedNames)),
+		otherUsers: m.ProtectOtherUsers,
+		max:        m.MaxKillCount,
+	}
+	for _, pid := range m.ProtectedPIDs {
+		c.pids[pid] = true
+	}
+	for _, name := range m.ProtectedNames {
+		c.names[name] = true
+	}
+	if c.otherUsers {
+		self, err := Self()
+		if err != nil {
+			return nil, err
+		}
+		if self.User == nil {
+			return nil, &Error{Code: ErrNotSupported, Message: "cannot resolve the calling process's user"}
+		}
+		c.user = *self.User
+	}
+	return c, nil
+}
+
+// protects reports whether the policy leaves pid, described by p, alone. p
+// is nil when pid is missing from the snapshot; its name and user are then
+// unknown, so any name or user rule protects it. A nil check protects
+// nothing.
+func (c *safetyCheck) protects(pid uint32, p *ProcessInfo) bool {
+	if c == nil {
+		return false
+	}
+	if c.pids[pid] {
+		return true
+	}
+	if p == nil {
+		return len(c.names) > 0 || c.otherUsers
+	}
+	if c.names[p.Name] {
+		return true
+	}
+	return c.otherUsers && (p.User == nil || *p.User != c.user)
+}
+
+// checkCount fails when n targets exceed MaxKillCount.
+func (c *safetyCheck) checkCount(n int) error {
+	if c == nil || c.max == 0 || n <= c.max {
+		return nil
+	}
+	return &Error{Code: ErrInvalidArgument, Message: strconv.Itoa(n) + " targets exceed the safety policy's max kill count of " +
+		strconv.Itoa(c.max) + "; nothing was signaled"}
+}
@@ -0,0 +1,20 @@
+package sysprims
+
+// schedStats holds the context-switch and page-fault counters of a process.
+// Counters the platform does not report are nil.
+type schedStats struct {
+	voluntaryCsw, involuntaryCsw, minorFaults, majorFaults *uint64
+}
+
+// readSchedStats fills the context-switch and page-fault counters of p.
+// Counters that cannot be read are left nil.
+func readSchedStats(p *ProcessInfo) {
+	s, err := readProcessSchedStats(p.PID)
+	if err != nil {
+		return
+	}
+	p.VoluntaryCtxSwitches = s.voluntaryCsw
+	p.InvoluntaryCtxSwitches = s.involuntaryCsw
+	p.MinorFaults = s.minorFaults
+	p.MajorFaults = s.majorFaults
+}
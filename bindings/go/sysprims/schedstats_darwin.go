@@ -0,0 +1,43 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <sys/proc_info.h>
+
+static int sysprims_go_task_faults(int pid, int32_t *faults, int32_t *pageins) {
+	struct proc_taskinfo ti;
+	int n = proc_pidinfo(pid, PROC_PIDTASKINFO, 0, &ti, sizeof(ti));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	if (n < (int)sizeof(ti)) {
+		return EIO;
+	}
+	*faults = ti.pti_faults;
+	*pageins = ti.pti_pageins;
+	return 0;
+}
+*/
+import "C"
+
+import "syscall"
+
+// readProcessSchedStats reads the page fault counts of pid from
+// proc_pidinfo task info: pti_pageins are the faults that read from disk
+// (major), the rest of pti_faults are minor. Task info only has a combined
+// context switch count, so the switch counters stay nil.
+func readProcessSchedStats(pid uint32) (schedStats, error) {
+	var faults, pageins C.int32_t
+	if rc := C.sysprims_go_task_faults(C.int(pid), &faults, &pageins); rc != 0 {
+		return schedStats{}, errnoError(pid, syscall.Errno(rc))
+	}
+	major := uint64(uint32(pageins))
+	minor := uint64(uint32(faults))
+	if minor >= major {
+		minor -= major
+	}
+	return schedStats{minorFaults: &minor, majorFaults: &major}, nil
+}
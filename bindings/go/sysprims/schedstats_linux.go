@@ -0,0 +1,85 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readProcessSchedStats reads minflt and majflt from /proc/<pid>/stat and
+// sums the voluntary and nonvoluntary context switch counts of the live
+// threads in /proc/<pid>/task/*/status; /proc/<pid>/status only reports the
+// main thread's. The switch counts stay nil on kernels that do not report
+// them.
+func readProcessSchedStats(pid uint32) (schedStats, error) {
+	dir := "/proc/" + strconv.FormatUint(uint64(pid), 10)
+	path := dir + "/stat"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return schedStats{}, procReadError(pid, err)
+	}
+	_, fields, err := parseProcStat(path, data)
+	if err != nil {
+		return schedStats{}, err
+	}
+	minflt, err1 := strconv.ParseUint(fields[7], 10, 64)
+	majflt, err2 := strconv.ParseUint(fields[9], 10, 64)
+	if err1 != nil || err2 != nil {
+		return schedStats{}, &Error{Code: ErrSystem, Message: "malformed page fault counts in " + path}
+	}
+	s := schedStats{minorFaults: &minflt, majorFaults: &majflt}
+
+	entries, err := os.ReadDir(dir + "/task")
+	if err != nil {
+		return schedStats{}, procReadError(pid, err)
+	}
+	var voluntary, involuntary uint64
+	found := false
+	for _, e := range entries {
+		v, inv, ok := taskCtxSwitches(dir + "/task/" + e.Name() + "/status")
+		if ok {
+			voluntary += v
+			involuntary += inv
+			found = true
+		}
+	}
+	if found {
+		s.voluntaryCsw, s.involuntaryCsw = &voluntary, &involuntary
+	}
+	return s, nil
+}
+
+// taskCtxSwitches reads the context switch counts from a task status file.
+// ok is false when the task is gone or does not report them.
+func taskCtxSwitches(path string) (voluntary, involuntary uint64, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer func() { _ = f.Close() }()
+
+	found := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() && found < 2 {
+		name, rest, _ := strings.Cut(scanner.Text(), ":")
+		var dst *uint64
+		switch name {
+		case "voluntary_ctxt_switches":
+			dst = &voluntary
+		case "nonvoluntary_ctxt_switches":
+			dst = &involuntary
+		default:
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(rest), 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		*dst = n
+		found++
+	}
+	return voluntary, involuntary, found == 2
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package sysprims
+
+import "runtime"
+
+// readProcessSchedStats is not implemented on this platform.
+func readProcessSchedStats(pid uint32) (schedStats, error) {
+	return schedStats{}, &Error{Code: ErrNotSupported, Message: "context switch and page fault counters are not supported on " + runtime.GOOS}
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// readProcessSchedStats reads PageFaultCount for pid, which Windows does not
+// split into soft and hard faults; it is reported as the minor fault count.
+// Per-process context switch counts are not available.
+func readProcessSchedStats(pid uint32) (schedStats, error) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, pid)
+	if err != nil {
+		return schedStats{}, winProcessError(pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var pmc processMemoryCountersEx
+	pmc.cb = uint32(unsafe.Sizeof(pmc))
+	r, _, e := procGetProcessMemoryInfo.Call(uintptr(h), uintptr(unsafe.Pointer(&pmc)), uintptr(pmc.cb))
+	if r == 0 {
+		return schedStats{}, systemError(e)
+	}
+	faults := uint64(pmc.pageFaultCount)
+	return schedStats{minorFaults: &faults}, nil
+}
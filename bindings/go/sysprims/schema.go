@@ -0,0 +1,51 @@
+package sysprims
+
+// Schema IDs of the JSON documents sysprims produces and accepts, as found
+// in their SchemaID fields. They match the library's published schemas under
+// https://schemas.3leaps.dev/sysprims/.
+const (
+	// SchemaProcessSnapshotV1 identifies a [ProcessSnapshot] with lifetime
+	// CPU (process/v1.1.0/process-info.schema.json).
+	SchemaProcessSnapshotV1 = "https://schemas.3leaps.dev/sysprims/process/v1.1.0/process-info.schema.json"
+	// SchemaProcessSnapshotSampledV1 identifies a [ProcessSnapshot] with
+	// sampled (monitor-mode) CPU, whose cpu_percent may exceed 100.
+	SchemaProcessSnapshotSampledV1 = "https://schemas.3leaps.dev/sysprims/process/v1.1.0/process-info-sampled.schema.json"
+	// SchemaProcessFilterV1 identifies [ProcessFilter] input.
+	SchemaProcessFilterV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/process-filter.schema.json"
+	// SchemaPortBindingsV1 identifies a [PortBindingsSnapshot].
+	SchemaPortBindingsV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/port-bindings.schema.json"
+	// SchemaPortFilterV1 identifies [PortFilter] input.
+	SchemaPortFilterV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/port-filter.schema.json"
+	// SchemaFdSnapshotV1 identifies an [FdSnapshot].
+	SchemaFdSnapshotV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/fd-snapshot.schema.json"
+	// SchemaFdFilterV1 identifies [FdFilter] input.
+	SchemaFdFilterV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/fd-filter.schema.json"
+	// SchemaWaitPidResultV1 identifies a [WaitPidResult].
+	SchemaWaitPidResultV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/wait-pid-result.schema.json"
+	// SchemaBatchKillResultV1 identifies a [KillDescendantsResult].
+	SchemaBatchKillResultV1 = "https://schemas.3leaps.dev/sysprims/signal/v1.0.0/batch-kill-result.schema.json"
+	// SchemaTerminateTreeConfigV1 identifies [TerminateTreeConfig] input.
+	SchemaTerminateTreeConfigV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/terminate-tree-config.schema.json"
+	// SchemaTerminateTreeResultV1 identifies a [TerminateTreeResult].
+	SchemaTerminateTreeResultV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/terminate-tree-result.schema.json"
+	// SchemaSpawnInGroupConfigV1 identifies [SpawnInGroupConfig] input.
+	SchemaSpawnInGroupConfigV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/spawn-in-group-config.schema.json"
+	// SchemaSpawnInGroupResultV1 identifies a [SpawnInGroupResult].
+	SchemaSpawnInGroupResultV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/spawn-in-group-result.schema.json"
+	// SchemaTimeoutResultV1 identifies a [TimeoutResult].
+	SchemaTimeoutResultV1 = "https://schemas.3leaps.dev/sysprims/timeout/v1.0.0/timeout-result.schema.json"
+	// SchemaDescendantsResultV1 identifies a [DescendantsResult] with
+	// lifetime CPU.
+	SchemaDescendantsResultV1 = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/descendants-result.schema.json"
+	// SchemaDescendantsResultSampledV1 identifies a [DescendantsResult] with
+	// sampled (monitor-mode) CPU.
+	SchemaDescendantsResultSampledV1 = "https://schemas.3leaps.dev/sysprims/process/v1.1.0/descendants-result-sampled.schema.json"
+)
+
+// SchemaMatches reports whether s.SchemaID is a process snapshot schema
+// these bindings understand: [SchemaProcessSnapshotV1], or
+// [SchemaProcessSnapshotSampledV1] for a monitor-mode snapshot. A false
+// result means the library and the bindings have drifted apart.
+func (s *ProcessSnapshot) SchemaMatches() bool {
+	return s.SchemaID == SchemaProcessSnapshotV1 || s.SchemaID == SchemaProcessSnapshotSampledV1
+}
@@ -4,6 +4,11 @@ package sysprims
 #include "sysprims.h"
 */
 import "C"
+import (
+	"os"
+	"runtime"
+	"strconv"
+)
 
 // SelfPGID returns the current process group ID (PGID).
 //
@@ -34,3 +39,146 @@ func SelfSID() (uint32, error) {
 	}
 	return uint32(sid), nil
 }
+
+// Self returns information for the current process. It is
+// ProcessGet(uint32(os.Getpid())).
+//
+// # Errors
+//
+//   - [ErrPermissionDenied]: Not permitted to read this process
+func Self() (*ProcessInfo, error) {
+	return SelfWithOptions(nil)
+}
+
+// SelfWithOptions is like [Self] with opt-in extended fields (see
+// [ProcessGetWithOptions]).
+func SelfWithOptions(opts *ProcessOptions) (*ProcessInfo, error) {
+	return ProcessGetWithOptions(uint32(os.Getpid()), opts)
+}
+
+// SignalSelfGroup sends signal to every process in the calling process's
+// group, itself included, with killpg(getpgid(0)). To leave the caller out,
+// use [SignalSelfGroupWithOptions].
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Invalid signal
+//   - [ErrNotSupported]: On Windows
+func SignalSelfGroup(signal int) error {
+	pgid, err := SelfPGID()
+	if err != nil {
+		return err
+	}
+	return KillGroup(pgid, signal)
+}
+
+// SignalSelfGroupOptions configures [SignalSelfGroupWithOptions].
+type SignalSelfGroupOptions struct {
+	// ExcludeSelf leaves the calling process unsignaled.
+	ExcludeSelf bool
+}
+
+// GroupSignalResult is the result of [SignalSelfGroupWithOptions] and
+// [SignalSession].
+type GroupSignalResult struct {
+	BatchKillResult
+	// SkippedSafety lists members left alone by the safety rules (PID 1).
+	SkippedSafety []uint32
+}
+
+// SignalSelfGroupWithOptions sends signal to the members of the calling
+// process's group one by one, as [SignalSession] does for a session, so the
+// caller can be left out and each member's outcome is reported.
+//
+// Members are read from one [ProcessList] snapshot; a process that joins the
+// group afterwards is not signaled, and one that leaves it or exits is
+// reported in Failed. When the caller is included it is signaled last, so a
+// terminating signal still reaches the others first.
+//
+// # Errors
+//
+//   - [ErrNotSupported]: On Windows
+//   - [ErrSystem]: System error reading process information
+func SignalSelfGroupWithOptions(signal int, opts *SignalSelfGroupOptions) (*GroupSignalResult, error) {
+	pgid, err := SelfPGID()
+	if err != nil {
+		return nil, err
+	}
+	excludeSelf := opts != nil && opts.ExcludeSelf
+	return signalMembers(pgid, false, signal, excludeSelf)
+}
+
+// SignalSession sends signal to every process in session sid, one by one:
+// there is no killsid(). With excludeSelf the calling process is left
+// unsignaled; otherwise, if it belongs to the session, it is signaled last.
+//
+// Members are read from one [ProcessList] snapshot, as for
+// [SignalSelfGroupWithOptions]. PID 1 is never signaled and is listed in
+// SkippedSafety.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: sid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: No process is in the session
+//   - [ErrNotSupported]: On Windows
+//   - [ErrSystem]: System error reading process information
+func SignalSession(sid uint32, signal int, excludeSelf bool) (*GroupSignalResult, error) {
+	if err := validatePidList([]uint32{sid}); err != nil {
+		return nil, err
+	}
+	return signalMembers(sid, true, signal, excludeSelf)
+}
+
+// signalMembers signals the members of a process group or session
+// individually, the caller last.
+func signalMembers(id uint32, session bool, signal int, excludeSelf bool) (*GroupSignalResult, error) {
+	if runtime.GOOS == "windows" {
+		return nil, &Error{Code: ErrNotSupported, Message: "process groups and sessions are not supported on windows"}
+	}
+	snapshot, err := ProcessList(nil)
+	if err != nil {
+		return nil, err
+	}
+	members, err := membersOf(id, session, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		kind := "process group"
+		if session {
+			kind = "session"
+		}
+		return nil, &Error{Code: ErrNotFound, Message: "no process in " + kind + " " + strconv.FormatUint(uint64(id), 10)}
+	}
+
+	self := uint32(os.Getpid())
+	result := &GroupSignalResult{
+		BatchKillResult: BatchKillResult{Succeeded: []uint32{}, Failed: []BatchKillFailure{}},
+		SkippedSafety:   []uint32{},
+	}
+	var targets []uint32
+	includesSelf := false
+	for _, pid := range members {
+		switch {
+		case pid == self:
+			includesSelf = !excludeSelf
+		case pid == 1:
+			result.SkippedSafety = append(result.SkippedSafety, pid)
+		default:
+			targets = append(targets, pid)
+		}
+	}
+	if includesSelf {
+		targets = append(targets, self)
+	}
+	if len(targets) == 0 {
+		return result, nil
+	}
+	batch, err := KillMany(targets, signal)
+	if err != nil {
+		return nil, err
+	}
+	result.Succeeded = append(result.Succeeded, batch.Succeeded...)
+	result.Failed = append(result.Failed, batch.Failed...)
+	return result, nil
+}
@@ -2,10 +2,30 @@ package sysprims
 
 /*
 #include "sysprims.h"
+
+// sysprims_go_signal_many signals each pid in turn on the calling thread,
+// recording each code and, for failures, the last-error message before the
+// next send overwrites it. The library has no batch entry point, so this
+// keeps a batch to a single cgo transition.
+static void sysprims_go_signal_many(const uint32_t *pids, size_t n, int32_t signal,
+		SysprimsErrorCode *codes, char **msgs) {
+	for (size_t i = 0; i < n; i++) {
+		codes[i] = sysprims_signal_send(pids[i], signal);
+		msgs[i] = codes[i] == SYSPRIMS_ERROR_CODE_OK ? NULL : sysprims_last_error();
+	}
+}
 */
 import "C"
 
-import "math"
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
 
 const (
 	SIGINT  = 2  // Interrupt
@@ -16,7 +36,8 @@ const (
 // Kill sends a signal to a process.
 //
 // On Unix, this calls kill(pid, signal).
-// On Windows, SIGTERM and SIGKILL are mapped to TerminateProcess.
+// On Windows, SIGTERM and SIGKILL are mapped to TerminateProcess; use
+// [SignalGraceful] to let a console process clean up first.
 // Other signals return [ErrNotSupported] on Windows.
 //
 // # Arguments
@@ -46,6 +67,15 @@ type BatchKillResult struct {
 	Failed    []BatchKillFailure
 }
 
+// GracefulShutdownResult is the result of [GracefulShutdown].
+type GracefulShutdownResult struct {
+	BatchKillResult
+	// Graceful lists PIDs that exited within the grace period.
+	Graceful []uint32
+	// Forced lists PIDs that were sent SIGKILL after the grace period.
+	Forced []uint32
+}
+
 func validatePidList(pids []uint32) error {
 	if len(pids) == 0 {
 		return &Error{Code: ErrInvalidArgument, Message: "pids must not be empty"}
@@ -66,28 +96,91 @@ func validatePidList(pids []uint32) error {
 // PID validation happens for the entire slice before any signals are sent.
 // Individual send failures are collected and returned in the aggregate result.
 //
-// This is implemented in Go (not a single FFI call) to avoid introducing new
-// FFI surface area.
+// The library has no batch signal entry point, so the whole batch is sent
+// from a small C loop over sysprims_signal_send in one cgo transition on one
+// locked thread, rather than one transition and thread lock per PID as with
+// [Kill]. Per-PID results and errors are the same as calling Kill for each
+// PID in order (see BenchmarkKillMany).
 func KillMany(pids []uint32, signal int) (*BatchKillResult, error) {
 	if err := validatePidList(pids); err != nil {
 		return nil, err
 	}
 
+	codes := make([]C.SysprimsErrorCode, len(pids))
+	msgs := make([]*C.char, len(pids))
+	runtime.LockOSThread()
+	C.sysprims_go_signal_many((*C.uint32_t)(unsafe.Pointer(&pids[0])), C.size_t(len(pids)),
+		C.int32_t(signal), &codes[0], &msgs[0])
+	runtime.UnlockOSThread()
+
 	r := &BatchKillResult{}
-	for _, pid := range pids {
-		err := Kill(pid, signal)
-		if err == nil {
+	for i, pid := range pids {
+		if codes[i] == C.SYSPRIMS_ERROR_CODE_OK {
 			r.Succeeded = append(r.Succeeded, pid)
 			continue
 		}
+		r.Failed = append(r.Failed, BatchKillFailure{PID: pid, Error: takeLastError(codes[i], msgs[i])})
+	}
 
-		sErr, ok := err.(*Error)
-		if !ok {
-			return nil, err
-		}
-		r.Failed = append(r.Failed, BatchKillFailure{PID: pid, Error: sErr})
+	return r, nil
+}
+
+// KillManyContext is like [KillMany] but can be cancelled and can signal
+// several PIDs at once.
+//
+// Up to concurrency kills are in flight at a time, each a separate [Kill];
+// concurrency <= 0 signals one PID at a time, in order. Once ctx is done no
+// new kills are started, and the PIDs signaled so far are returned together
+// with ctx.Err(); since kills are started in input order, they are a prefix
+// of pids. Succeeded and Failed are in input order whatever the concurrency.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pids is empty or holds an invalid PID
+//   - ctx.Err(): ctx was done before every PID was signaled; the result
+//     holds the partial outcome
+func KillManyContext(ctx context.Context, pids []uint32, signal int, concurrency int) (*BatchKillResult, error) {
+	if err := validatePidList(pids); err != nil {
+		return nil, err
+	}
+
+	// Workers claim input indexes in order and record each outcome in
+	// place, so the result can be assembled in input order afterwards.
+	errs := make([]error, len(pids))
+	sent := make([]bool, len(pids))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := min(max(concurrency, 1), len(pids)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= len(pids) {
+					return
+				}
+				errs[i] = Kill(pids[i], signal)
+				sent[i] = true
+			}
+		}()
 	}
+	wg.Wait()
 
+	r := &BatchKillResult{}
+	complete := true
+	for i, pid := range pids {
+		switch {
+		case !sent[i]:
+			complete = false
+		case errs[i] == nil:
+			r.Succeeded = append(r.Succeeded, pid)
+		default:
+			r.Failed = append(r.Failed, BatchKillFailure{PID: pid, Error: asError(errs[i])})
+		}
+	}
+	if !complete {
+		return r, ctx.Err()
+	}
 	return r, nil
 }
 
@@ -101,6 +194,128 @@ func ForceKillMany(pids []uint32) (*BatchKillResult, error) {
 	return KillMany(pids, SIGKILL)
 }
 
+// gracefulShutdownWaiters bounds the PIDs GracefulShutdown waits on at once.
+const gracefulShutdownWaiters = 32
+
+// GracefulShutdown sends SIGTERM to every PID, waits up to grace for them to
+// exit, then sends SIGKILL to any that are still running.
+//
+// PID validation happens for the entire slice before any signals are sent.
+// Waiting uses [WaitPID] polling against a single deadline, with up to 32
+// PIDs waited on at a time, so the whole call takes at most about grace plus
+// the time to signal. A PID that is already gone when signaled counts as
+// graceful.
+//
+// Each process's identity is read before SIGTERM and checked again before
+// SIGKILL, so a PID that exited and was reused during the grace period is
+// counted as graceful rather than killed. A PID whose identity cannot be
+// read is reported in Failed and not signaled.
+//
+// In the result, Graceful and Forced partition the PIDs that are known to be
+// gone or killed; Succeeded is their union. PIDs that could not be signaled or
+// waited on (for example [ErrPermissionDenied]) are reported in Failed and
+// are not escalated.
+//
+// On Windows SIGTERM maps to TerminateProcess, so every successful PID is
+// effectively forced on the first step and reported as Graceful.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pids is invalid or grace is negative
+func GracefulShutdown(pids []uint32, grace time.Duration) (*GracefulShutdownResult, error) {
+	if err := validatePidList(pids); err != nil {
+		return nil, err
+	}
+	if grace < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "grace must be >= 0"}
+	}
+
+	r := &GracefulShutdownResult{}
+	var waiting []uint32
+	var ids []ProcessIdentity
+	for _, pid := range pids {
+		id, err := IdentityOf(pid)
+		if err == nil {
+			err = TerminateIdentity(id)
+		}
+		if err == nil {
+			waiting = append(waiting, pid)
+			ids = append(ids, id)
+			continue
+		}
+		sErr := asError(err)
+		if sErr.Code == ErrNotFound {
+			r.Graceful = append(r.Graceful, pid)
+			continue
+		}
+		r.Failed = append(r.Failed, BatchKillFailure{PID: pid, Error: sErr})
+	}
+
+	// Wait for all signaled PIDs against a single deadline. A PID claimed
+	// after the deadline gets a single check.
+	deadline := time.Now().Add(grace)
+	exited := make([]bool, len(waiting))
+	waitErrs := make([]*Error, len(waiting))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := min(gracefulShutdownWaiters, len(waiting)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(waiting) {
+					return
+				}
+				res, err := WaitPID(waiting[i], max(time.Until(deadline), 0))
+				switch {
+				case err == nil:
+					exited[i] = res.Exited
+				case asError(err).Code == ErrNotFound:
+					exited[i] = true
+				default:
+					waitErrs[i] = asError(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, pid := range waiting {
+		if exited[i] {
+			r.Graceful = append(r.Graceful, pid)
+			continue
+		}
+		if waitErrs[i] != nil {
+			r.Failed = append(r.Failed, BatchKillFailure{PID: pid, Error: waitErrs[i]})
+			continue
+		}
+
+		err := ForceKillIdentity(ids[i])
+		switch {
+		case err == nil:
+			r.Forced = append(r.Forced, pid)
+		case asError(err).Code == ErrNotFound:
+			// Exited (and possibly had its PID reused) between the deadline
+			// and the kill.
+			r.Graceful = append(r.Graceful, pid)
+		default:
+			r.Failed = append(r.Failed, BatchKillFailure{PID: pid, Error: asError(err)})
+		}
+	}
+
+	r.Succeeded = append(append(r.Succeeded, r.Graceful...), r.Forced...)
+	return r, nil
+}
+
+// asError returns err as a *Error, wrapping foreign errors as ErrInternal.
+func asError(err error) *Error {
+	if sErr, ok := err.(*Error); ok {
+		return sErr
+	}
+	return &Error{Code: ErrInternal, Message: err.Error()}
+}
+
 // Terminate sends SIGTERM to a process.
 //
 // This is a convenience wrapper for Kill(pid, SIGTERM).
@@ -130,9 +345,16 @@ func ForceKill(pid uint32) error {
 //
 // # Platform Notes
 //
-// This function returns [ErrNotSupported] on Windows, which does not
-// have the concept of process groups. Use Job Objects via [RunWithTimeout]
-// with [GroupByDefault] for tree-kill behavior on Windows.
+// Windows has no process groups. There, pgid must be the PID of a child
+// started by [SpawnInGroup] (or [SpawnInGroupHandle]) whose
+// TreeKillReliability is "guaranteed": SIGTERM and SIGKILL terminate its
+// whole Job Object, grandchildren included, even after the child itself has
+// exited. The PID is forgotten once the job is terminated (by KillGroup or
+// [TerminateTree]) or the child is waited on (by [WaitPID], a
+// [ChildHandle], or a [Supervisor]), so a later call returns
+// [ErrNotSupported], as does any other PID; use [TerminateTree] or
+// [RunWithTimeout] with [GroupByDefault] for those. If the library no longer
+// holds the child's job, KillGroup returns [ErrNotFound].
 //
 // # Arguments
 //
@@ -142,9 +364,10 @@ func ForceKill(pid uint32) error {
 // # Errors
 //
 //   - [ErrInvalidArgument]: pgid is invalid
-//   - [ErrNotSupported]: Always on Windows
+//   - [ErrNotSupported]: On Windows, pgid does not lead a sysprims Job
+//     Object, or signal is not SIGTERM or SIGKILL
+//   - [ErrNotFound]: On Windows, the library no longer holds pgid's Job
+//     Object
 func KillGroup(pgid uint32, signal int) error {
-	return callAndCheck(func() C.SysprimsErrorCode {
-		return C.sysprims_signal_send_group(C.uint32_t(pgid), C.int32_t(signal))
-	})
+	return killGroup(pgid, signal)
 }
@@ -0,0 +1,129 @@
+package sysprims
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Signal is a signal number. It converts to and from the int signal
+// parameters used across the API, and adds names: String gives "SIGTERM"
+// style names and [ParseSignal] reads them back.
+//
+// Signal implements encoding.TextMarshaler and encoding.TextUnmarshaler, so
+// config fields of type Signal accept "TERM", "SIGTERM", or "15".
+type Signal int
+
+// SignalInfo is the result of [ParseSignal].
+type SignalInfo struct {
+	// Signal is the platform's number for the signal.
+	Signal Signal
+	// Name is the canonical name, such as "SIGTERM".
+	Name string
+	// Deliverable reports whether [Kill] can send the signal on this
+	// platform. On Windows only SIGTERM and SIGKILL are deliverable; the
+	// other POSIX names parse with Linux numbers.
+	Deliverable bool
+}
+
+// String returns the signal's name, such as "SIGTERM", or "signal N" for a
+// number without a name on this platform.
+func (s Signal) String() string {
+	for _, n := range signalNames {
+		if n.signal == s {
+			return "SIG" + n.name
+		}
+	}
+	return "signal " + strconv.Itoa(int(s))
+}
+
+// ParseSignal parses a signal name with or without the SIG prefix ("TERM",
+// "SIGTERM", case-insensitive) or a decimal number ("15") into the
+// platform's signal number.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: s is not a signal name or number known on this
+//     platform
+func ParseSignal(s string) (SignalInfo, error) {
+	trimmed := strings.ToUpper(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(trimmed); err == nil {
+		for _, name := range signalNames {
+			if int(name.signal) == n {
+				return signalInfo(name.signal), nil
+			}
+		}
+	} else {
+		trimmed = strings.TrimPrefix(trimmed, "SIG")
+		for _, name := range signalNames {
+			if name.name == trimmed {
+				return signalInfo(name.signal), nil
+			}
+		}
+	}
+	return SignalInfo{}, &Error{Code: ErrInvalidArgument, Message: "unknown signal: " + s}
+}
+
+// Signals returns the signals [Kill] can deliver on this platform, in
+// ascending order.
+func Signals() []Signal {
+	var signals []Signal
+	for _, n := range signalNames {
+		if signalDeliverable(n.signal) {
+			signals = append(signals, n.signal)
+		}
+	}
+	sort.Slice(signals, func(i, j int) bool { return signals[i] < signals[j] })
+	return signals
+}
+
+// MarshalText encodes s as its name, or as its number when it has none.
+// Negative numbers, which are not signals, fail.
+func (s Signal) MarshalText() ([]byte, error) {
+	name := s.String()
+	if strings.HasPrefix(name, "SIG") {
+		return []byte(name), nil
+	}
+	if s < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "invalid signal: " + strconv.Itoa(int(s))}
+	}
+	return []byte(strconv.Itoa(int(s))), nil
+}
+
+// UnmarshalText decodes a name or number with [ParseSignal]. Signals that
+// are not deliverable on this platform are accepted, and so is any
+// non-negative decimal number, so the output of MarshalText always decodes.
+func (s *Signal) UnmarshalText(text []byte) error {
+	info, err := ParseSignal(string(text))
+	if err != nil {
+		n, numErr := strconv.ParseUint(string(text), 10, 31)
+		if numErr != nil {
+			return err
+		}
+		*s = Signal(n)
+		return nil
+	}
+	*s = info.Signal
+	return nil
+}
+
+// Send sends s to pid. It is [Kill] with a typed signal.
+func (s Signal) Send(pid uint32) error {
+	return Kill(pid, int(s))
+}
+
+// SendGroup sends s to the process group pgid. It is [KillGroup] with a
+// typed signal.
+func (s Signal) SendGroup(pgid uint32) error {
+	return KillGroup(pgid, int(s))
+}
+
+// signalName pairs a signal with its name without the SIG prefix.
+type signalName struct {
+	name   string
+	signal Signal
+}
+
+func signalInfo(s Signal) SignalInfo {
+	return SignalInfo{Signal: s, Name: s.String(), Deliverable: signalDeliverable(s)}
+}
@@ -13,4 +13,45 @@ const (
 	SIGQUIT = int(syscall.SIGQUIT)
 	SIGUSR1 = int(syscall.SIGUSR1)
 	SIGUSR2 = int(syscall.SIGUSR2)
+	SIGSTOP = int(syscall.SIGSTOP)
+	SIGCONT = int(syscall.SIGCONT)
 )
+
+// signalNames lists the signals common to Linux and macOS, in the host's
+// numbering.
+var signalNames = []signalName{
+	{"HUP", Signal(syscall.SIGHUP)},
+	{"INT", Signal(syscall.SIGINT)},
+	{"QUIT", Signal(syscall.SIGQUIT)},
+	{"ILL", Signal(syscall.SIGILL)},
+	{"TRAP", Signal(syscall.SIGTRAP)},
+	{"ABRT", Signal(syscall.SIGABRT)},
+	{"BUS", Signal(syscall.SIGBUS)},
+	{"FPE", Signal(syscall.SIGFPE)},
+	{"KILL", Signal(syscall.SIGKILL)},
+	{"USR1", Signal(syscall.SIGUSR1)},
+	{"SEGV", Signal(syscall.SIGSEGV)},
+	{"USR2", Signal(syscall.SIGUSR2)},
+	{"PIPE", Signal(syscall.SIGPIPE)},
+	{"ALRM", Signal(syscall.SIGALRM)},
+	{"TERM", Signal(syscall.SIGTERM)},
+	{"CHLD", Signal(syscall.SIGCHLD)},
+	{"CONT", Signal(syscall.SIGCONT)},
+	{"STOP", Signal(syscall.SIGSTOP)},
+	{"TSTP", Signal(syscall.SIGTSTP)},
+	{"TTIN", Signal(syscall.SIGTTIN)},
+	{"TTOU", Signal(syscall.SIGTTOU)},
+	{"URG", Signal(syscall.SIGURG)},
+	{"XCPU", Signal(syscall.SIGXCPU)},
+	{"XFSZ", Signal(syscall.SIGXFSZ)},
+	{"VTALRM", Signal(syscall.SIGVTALRM)},
+	{"PROF", Signal(syscall.SIGPROF)},
+	{"WINCH", Signal(syscall.SIGWINCH)},
+	{"IO", Signal(syscall.SIGIO)},
+	{"SYS", Signal(syscall.SIGSYS)},
+}
+
+// signalDeliverable reports whether Kill can send s: every named signal is.
+func signalDeliverable(Signal) bool {
+	return true
+}
@@ -12,4 +12,23 @@ const (
 	SIGQUIT = 3
 	SIGUSR1 = 10
 	SIGUSR2 = 12
+	SIGSTOP = 19
+	SIGCONT = 18
 )
+
+// signalNames lists the POSIX signals by their Linux numbers, so names
+// from Unix configs parse on Windows.
+var signalNames = []signalName{
+	{"HUP", 1}, {"INT", 2}, {"QUIT", 3}, {"ILL", 4}, {"TRAP", 5},
+	{"ABRT", 6}, {"BUS", 7}, {"FPE", 8}, {"KILL", 9}, {"USR1", 10},
+	{"SEGV", 11}, {"USR2", 12}, {"PIPE", 13}, {"ALRM", 14}, {"TERM", 15},
+	{"CHLD", 17}, {"CONT", 18}, {"STOP", 19}, {"TSTP", 20}, {"TTIN", 21},
+	{"TTOU", 22}, {"URG", 23}, {"XCPU", 24}, {"XFSZ", 25}, {"VTALRM", 26},
+	{"PROF", 27}, {"WINCH", 28}, {"IO", 29}, {"SYS", 31},
+}
+
+// signalDeliverable reports whether Kill can send s: only SIGTERM and
+// SIGKILL, which map to TerminateProcess.
+func signalDeliverable(s Signal) bool {
+	return s == SIGTERM || s == SIGKILL
+}
@@ -0,0 +1,162 @@
+package sysprims
+
+import "sort"
+
+// ByPID returns an index of Processes keyed by PID. The pointers refer to
+// elements of Processes, so edits through them are visible in the snapshot.
+//
+// The index is built on first use and cached. [ProcessSnapshot.SortBy]
+// invalidates it, as does replacing or resizing Processes; reordering the
+// slice in place by other means requires building a fresh snapshot value.
+// If a PID appears more than once, the first occurrence wins.
+//
+// ByPID may be called from several goroutines at once, provided none of
+// them modifies the snapshot meanwhile.
+func (s *ProcessSnapshot) ByPID() map[uint32]*ProcessInfo {
+	s.byPIDMu.Lock()
+	defer s.byPIDMu.Unlock()
+	if s.byPID != nil && s.byPIDLen == len(s.Processes) &&
+		(len(s.Processes) == 0 || s.byPIDBase == &s.Processes[0]) {
+		return s.byPID
+	}
+
+	m := make(map[uint32]*ProcessInfo, len(s.Processes))
+	for i := range s.Processes {
+		if _, ok := m[s.Processes[i].PID]; !ok {
+			m[s.Processes[i].PID] = &s.Processes[i]
+		}
+	}
+	s.byPID = m
+	s.byPIDLen = len(s.Processes)
+	s.byPIDBase = nil
+	if len(s.Processes) > 0 {
+		s.byPIDBase = &s.Processes[0]
+	}
+	return m
+}
+
+// SortBy sorts Processes in place by key, ascending unless desc is set. Ties
+// are broken by ascending PID.
+//
+// [SortByThreads] ranks processes without ThreadCount as 0. [SortByFds] lists
+// fds for each process, as [TopProcesses] does. Unknown keys leave the order
+// unchanged.
+func (s *ProcessSnapshot) SortBy(key SortKey, desc bool) {
+	switch key {
+	case SortByCPU, SortByMemory, SortByThreads, SortByFds:
+	default:
+		return
+	}
+	sortProcesses(s.Processes, key, desc)
+	s.byPIDMu.Lock()
+	s.byPID = nil
+	s.byPIDMu.Unlock()
+}
+
+// Filter returns the processes for which pred returns true, in snapshot
+// order. The result is a new slice; the snapshot is not modified.
+func (s *ProcessSnapshot) Filter(pred func(*ProcessInfo) bool) []ProcessInfo {
+	var out []ProcessInfo
+	for i := range s.Processes {
+		if pred(&s.Processes[i]) {
+			out = append(out, s.Processes[i])
+		}
+	}
+	return out
+}
+
+// SnapshotDiff is the result of [ProcessSnapshot.Diff].
+type SnapshotDiff struct {
+	// Started lists processes not in the previous snapshot, in snapshot
+	// order.
+	Started []ProcessInfo `json:"started"`
+	// Exited lists the previous snapshot's info for processes that are gone,
+	// by PID.
+	Exited []ProcessInfo `json:"exited"`
+	// Survived lists the current info for processes in both snapshots, in
+	// snapshot order.
+	Survived []ProcessInfo `json:"survived"`
+}
+
+// Diff compares s against an earlier snapshot prev. Pass nil for prev to
+// report every process as started.
+//
+// Processes are keyed by PID and start time: a PID whose start time differs
+// between the snapshots has been reused, so the old process is reported in
+// Exited and the new one in Started. When either start time is unavailable,
+// the PID alone identifies the process. Compare snapshots taken with the
+// same filter; a process that stops matching the filter is reported as
+// exited. If a PID appears more than once in prev, the first occurrence
+// wins.
+func (s *ProcessSnapshot) Diff(prev *ProcessSnapshot) SnapshotDiff {
+	old := make(map[uint32]ProcessInfo)
+	if prev != nil {
+		for _, p := range prev.Processes {
+			if _, ok := old[p.PID]; !ok {
+				old[p.PID] = p
+			}
+		}
+	}
+	var cur []ProcessInfo
+	if s != nil {
+		cur = s.Processes
+	}
+
+	diff := SnapshotDiff{Survived: []ProcessInfo{}}
+	diff.Started, diff.Exited = diffProcesses(old, cur, func(_, p *ProcessInfo) {
+		diff.Survived = append(diff.Survived, *p)
+	})
+	return diff
+}
+
+// TotalMemoryKB returns the sum of MemoryKB across Processes.
+func (s *ProcessSnapshot) TotalMemoryKB() uint64 {
+	var total uint64
+	for i := range s.Processes {
+		total += s.Processes[i].MemoryKB
+	}
+	return total
+}
+
+// TotalCPUPercent returns the sum of CPUPercent across Processes. On
+// multi-core systems the total can exceed 100.
+func (s *ProcessSnapshot) TotalCPUPercent() float64 {
+	var total float64
+	for i := range s.Processes {
+		total += s.Processes[i].CPUPercent
+	}
+	return total
+}
+
+// sortProcesses sorts processes in place by key, breaking ties by ascending
+// PID. key must be a known SortKey.
+func sortProcesses(processes []ProcessInfo, key SortKey, desc bool) {
+	keys := make(map[uint32]float64, len(processes))
+	for _, p := range processes {
+		switch key {
+		case SortByCPU:
+			keys[p.PID] = p.CPUPercent
+		case SortByMemory:
+			keys[p.PID] = float64(p.MemoryKB)
+		case SortByThreads:
+			if p.ThreadCount != nil {
+				keys[p.PID] = float64(*p.ThreadCount)
+			}
+		case SortByFds:
+			if fds, err := listFds(p.PID, nil); err == nil {
+				keys[p.PID] = float64(len(fds.Fds))
+			}
+		}
+	}
+
+	sort.SliceStable(processes, func(i, j int) bool {
+		ki, kj := keys[processes[i].PID], keys[processes[j].PID]
+		if ki != kj {
+			if desc {
+				return ki > kj
+			}
+			return ki < kj
+		}
+		return processes[i].PID < processes[j].PID
+	})
+}
@@ -0,0 +1,78 @@
+package sysprims
+
+import (
+	"runtime"
+	"time"
+)
+
+// FdSocket is a socket held by a process, with the fd that owns it.
+type FdSocket struct {
+	// Fd is the owning file descriptor number.
+	Fd uint32 `json:"fd"`
+	SocketInfo
+}
+
+// SocketSnapshot represents a point-in-time listing of a process's sockets.
+type SocketSnapshot struct {
+	Timestamp string     `json:"timestamp"`
+	Platform  string     `json:"platform"`
+	PID       uint32     `json:"pid"`
+	Sockets   []FdSocket `json:"sockets"`
+	Warnings  []string   `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+}
+
+// SocketsForPID returns every socket held by pid, listening or connected,
+// with its owning fd number, sorted by fd.
+//
+// It joins the fd table from [ListFds] with the system socket tables.
+//
+// Best-effort behavior:
+// - Endpoint details are resolved on Linux only; elsewhere only Fd is set
+// - Sockets that cannot be resolved carry only Fd and Inode, with a warning
+// - On macOS, a permission-denied fd listing yields no sockets and a warning
+// - Windows returns ErrNotSupported
+//
+// # Errors
+//
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to list this process's fds (not on macOS)
+//   - [ErrNotSupported]: Fd listing is unavailable on this platform
+func SocketsForPID(pid uint32) (*SocketSnapshot, error) {
+	kind := "socket"
+	fds, err := listFdsResolved(pid, &FdFilter{Kind: &kind})
+	if err != nil {
+		sErr := asError(err)
+		if runtime.GOOS != "darwin" || sErr.Code != ErrPermissionDenied {
+			return nil, err
+		}
+		fds = &FdSnapshot{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Platform:  Platform(),
+			Warnings:  []string{"permission denied listing fds (SIP/TCC restricts other processes): " + sErr.Message},
+		}
+	}
+
+	sockets := make([]FdSocket, 0, len(fds.Fds))
+	for _, fd := range fds.Fds {
+		entry := FdSocket{Fd: fd.Fd}
+		if fd.Socket != nil {
+			entry.SocketInfo = *fd.Socket
+		}
+		sockets = append(sockets, entry)
+	}
+	warnings := fds.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+
+	return &SocketSnapshot{
+		Timestamp:      fds.Timestamp,
+		Platform:       fds.Platform,
+		PID:            pid,
+		Sockets:        sockets,
+		Warnings:       warnings,
+		WarningDetails: warningDetails("SocketsForPID", pid, warnings),
+	}, nil
+}
@@ -0,0 +1,264 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// procNetTables lists the tcp and udp tables of a /proc net directory.
+var procNetTables = []struct {
+	name     string
+	protocol Protocol
+}{
+	{"tcp", ProtocolTCP},
+	{"tcp6", ProtocolTCP},
+	{"udp", ProtocolUDP},
+	{"udp6", ProtocolUDP},
+}
+
+// tcpStates maps /proc/net/tcp state codes to names.
+//
+// Names follow the snake_case style used for "listen" in port bindings.
+var tcpStates = map[string]string{
+	"01": "established",
+	"02": "syn_sent",
+	"03": "syn_recv",
+	"04": "fin_wait1",
+	"05": "fin_wait2",
+	"06": "time_wait",
+	"07": "close",
+	"08": "close_wait",
+	"09": "last_ack",
+	"0A": "listen",
+	"0B": "closing",
+}
+
+// unixStates maps /proc/net/unix St codes to names.
+var unixStates = map[string]string{
+	"01": "unconnected",
+	"02": "connecting",
+	"03": "connected",
+	"04": "disconnecting",
+}
+
+// unixAcceptCon is __SO_ACCEPTCON in /proc/net/unix Flags, set on listeners.
+const unixAcceptCon = 0x10000
+
+// readSocketTable returns inet and unix socket details keyed by socket
+// inode, as seen from pid's network namespace: the tables are read from
+// /proc/<pid>/net, so the sockets of a process in another namespace resolve.
+//
+// Missing tables (e.g. IPv6 disabled) are skipped silently. Other read
+// failures are reported as warnings. A nil map means no table was readable.
+func readSocketTable(pid uint32) (map[uint64]SocketInfo, []string) {
+	var warnings []string
+	var table map[uint64]SocketInfo
+
+	dir := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/net/"
+	read := func(path string, parse func(io.Reader) (int, error)) {
+		f, err := os.Open(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				warnings = append(warnings, fmt.Sprintf("failed to read %s: %v", path, err))
+			}
+			return
+		}
+		defer func() { _ = f.Close() }()
+		if table == nil {
+			table = make(map[uint64]SocketInfo)
+		}
+		malformed, err := parse(f)
+		if malformed > 0 {
+			warnings = append(warnings, fmt.Sprintf("skipped %d malformed entries in %s", malformed, path))
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to read %s: %v", path, err))
+		}
+	}
+
+	for _, t := range procNetTables {
+		read(dir+t.name, func(r io.Reader) (int, error) { return parseProcNet(r, t.protocol, table) })
+	}
+	read(dir+"unix", func(r io.Reader) (int, error) { return parseProcNetUnix(r, table) })
+
+	return table, warnings
+}
+
+// netNamespace identifies pid's network namespace, for sharing one socket
+// table among processes. ok is false when it cannot be read.
+func netNamespace(pid uint32) (ns string, ok bool) {
+	ns, err := os.Readlink("/proc/" + strconv.FormatUint(uint64(pid), 10) + "/ns/net")
+	return ns, err == nil
+}
+
+// parseProcNetUnix parses /proc/net/unix into table and returns the number of
+// malformed lines skipped and any error reading r; entries read before an
+// error are kept.
+//
+// Columns are: Num RefCount Protocol Flags Type St Inode [Path].
+func parseProcNetUnix(r io.Reader, table map[uint64]SocketInfo) (int, error) {
+	malformed := 0
+	scanner := bufio.NewScanner(r)
+	header := true
+	for scanner.Scan() {
+		if header {
+			header = false
+			continue
+		}
+		fields, path := splitUnixLine(scanner.Text())
+		if len(fields) < 7 {
+			continue
+		}
+		inode, info, err := parseProcNetUnixFields(fields, path)
+		if err != nil {
+			malformed++
+			continue
+		}
+		table[inode] = info
+	}
+	return malformed, scanner.Err()
+}
+
+// parseProcNetUnixFields decodes the inode, path, and state of one
+// /proc/net/unix line, already split by splitUnixLine into 7 fields.
+func parseProcNetUnixFields(fields []string, path string) (uint64, SocketInfo, error) {
+	inode, err := strconv.ParseUint(fields[6], 10, 64)
+	if err != nil {
+		return 0, SocketInfo{}, err
+	}
+	flags, err := strconv.ParseUint(fields[3], 16, 32)
+	if err != nil {
+		return 0, SocketInfo{}, err
+	}
+
+	info := SocketInfo{Protocol: ProtocolUnix}
+	if path != "" {
+		info.LocalAddr = &path
+	}
+	if flags&unixAcceptCon != 0 {
+		state := "listen"
+		info.State = &state
+	} else if state, ok := unixStates[fields[5]]; ok {
+		info.State = &state
+	}
+	return inode, info, nil
+}
+
+// parseProcNet parses one /proc/net table into table and returns the number
+// of malformed lines skipped and any error reading r; entries read before an
+// error are kept.
+func parseProcNet(r io.Reader, protocol Protocol, table map[uint64]SocketInfo) (int, error) {
+	malformed := 0
+	scanner := bufio.NewScanner(r)
+	header := true
+	for scanner.Scan() {
+		if header {
+			header = false
+			continue
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			// Inode 0 is used for sockets in TIME_WAIT that no longer belong to an fd.
+			continue
+		}
+		info, err := parseProcNetFields(fields, protocol)
+		if err != nil {
+			malformed++
+			continue
+		}
+		table[inode] = info
+	}
+	return malformed, scanner.Err()
+}
+
+// parseProcNetFields decodes the endpoints and state of one /proc/net
+// tcp/udp line, already split into at least 10 fields.
+func parseProcNetFields(fields []string, protocol Protocol) (SocketInfo, error) {
+	localAddr, localPort, err := parseProcNetEndpoint(fields[1])
+	if err != nil {
+		return SocketInfo{}, err
+	}
+	remoteAddr, remotePort, err := parseProcNetEndpoint(fields[2])
+	if err != nil {
+		return SocketInfo{}, err
+	}
+
+	info := SocketInfo{
+		Protocol:  protocol,
+		LocalAddr: &localAddr,
+		LocalPort: localPort,
+	}
+	if remotePort != 0 {
+		info.RemoteAddr = &remoteAddr
+		info.RemotePort = &remotePort
+	}
+	if protocol == ProtocolTCP {
+		if state, ok := tcpStates[fields[3]]; ok {
+			info.State = &state
+		}
+	}
+	return info, nil
+}
+
+// splitUnixLine splits a /proc/net/unix line into its seven fixed columns and
+// the optional path. The inode column is space-padded and paths may contain
+// spaces, so the path is everything after the inode and one separator.
+func splitUnixLine(line string) ([]string, string) {
+	fields := make([]string, 0, 7)
+	rest := line
+	for len(fields) < 7 {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			return fields, ""
+		}
+		field, tail, _ := strings.Cut(rest, " ")
+		fields = append(fields, field)
+		rest = tail
+	}
+	return fields, rest
+}
+
+// parseProcNetEndpoint decodes an "ADDR:PORT" hex pair from /proc/net.
+//
+// Addresses are printed as 32-bit words in host byte order; the port is
+// printed as a plain hex number.
+func parseProcNetEndpoint(s string) (string, uint16, error) {
+	addrHex, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid endpoint %q", s)
+	}
+
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", portHex)
+	}
+
+	if len(addrHex) != 8 && len(addrHex) != 32 {
+		return "", 0, fmt.Errorf("invalid address %q", addrHex)
+	}
+	ip := make(net.IP, len(addrHex)/2)
+	for i := 0; i < len(addrHex)/8; i++ {
+		word, err := strconv.ParseUint(addrHex[i*8:i*8+8], 16, 32)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid address %q", addrHex)
+		}
+		binary.NativeEndian.PutUint32(ip[i*4:], uint32(word))
+	}
+
+	return ip.String(), uint16(port), nil
+}
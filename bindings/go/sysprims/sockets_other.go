@@ -0,0 +1,16 @@
+//go:build !linux
+
+package sysprims
+
+import "runtime"
+
+// readSocketTable is not implemented on this platform.
+func readSocketTable(pid uint32) (map[uint64]SocketInfo, []string) {
+	return nil, []string{"socket details are not supported on " + runtime.GOOS}
+}
+
+// netNamespace reports a single namespace: there is no table to vary by
+// process.
+func netNamespace(pid uint32) (ns string, ok bool) {
+	return "", true
+}
@@ -18,22 +18,80 @@ type SpawnInGroupConfig struct {
 	Argv     []string          `json:"argv"`
 	Cwd      *string           `json:"cwd,omitempty"`
 	Env      map[string]string `json:"env,omitempty"`
+	// Stdin, Stdout, and Stderr redirect the child's standard streams; nil
+	// inherits the parent's. Redirection is performed by the Go bindings
+	// and is not supported on Windows (see [StdioTarget]).
+	Stdin  *StdioTarget `json:"-"`
+	Stdout *StdioTarget `json:"-"`
+	Stderr *StdioTarget `json:"-"`
+	// Detached starts the child in a new session (setsid) so it survives
+	// the end of the caller's session, such as a closed terminal or SSH
+	// connection. Performed by the Go bindings.
+	//
+	// The child still leads its own process group, so TerminateTree on its
+	// PID kills its tree as usual. It is outside the caller's process group
+	// and session, though: a group-wide kill of the caller's group (as
+	// [GroupByDefault] performs for RunWithTimeout) or a hangup of the
+	// caller's terminal does not reach it. The caller remains its parent
+	// and should still wait for it (or exit) to avoid a zombie; redirect
+	// its stdio (for example to os.DevNull) so it does not hold the
+	// caller's terminal open.
+	//
+	// On Windows the child is started with DETACHED_PROCESS and
+	// CREATE_NEW_PROCESS_GROUP instead: it has no console and leads its own
+	// console process group, reported as PGID. It gets no Job Object, so
+	// its TreeKillReliability is "best_effort", and it stays in any Job
+	// Object the caller belongs to, so a [GroupByDefault] kill of the
+	// caller's job still reaches it.
+	Detached bool `json:"-"`
+	// RunAsUID, RunAsGID, and SupplementaryGIDs run the child with other
+	// credentials, applied by the Go bindings (setgroups, setgid, then
+	// setuid) in the child before exec. See [TimeoutConfig.RunAsUID].
+	RunAsUID          *uint32  `json:"-"`
+	RunAsGID          *uint32  `json:"-"`
+	SupplementaryGIDs []uint32 `json:"-"`
 }
 
 // SpawnInGroupResult is the outcome of SpawnInGroup.
 type SpawnInGroupResult struct {
-	SchemaID            string   `json:"schema_id"`
-	Timestamp           string   `json:"timestamp"`
-	Platform            string   `json:"platform"`
-	PID                 uint32   `json:"pid"`
-	PGID                *uint32  `json:"pgid,omitempty"`
+	SchemaID  string  `json:"schema_id"`
+	Timestamp string  `json:"timestamp"`
+	Platform  string  `json:"platform"`
+	PID       uint32  `json:"pid"`
+	PGID      *uint32 `json:"pgid,omitempty"`
+	// SID is the session ID of a Detached child, which leads its session.
+	SID                 *uint32  `json:"sid,omitempty"`
 	TreeKillReliability string   `json:"tree_kill_reliability"`
 	Warnings            []string `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
 }
 
+// SpawnInGroup spawns a process in a new process group (Unix) or Job Object
+// (Windows) and returns without waiting for it.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Empty argv or an invalid StdioTarget
+//   - [ErrNotFound]: Command not found
+//   - [ErrPermissionDenied]: Command not executable, or RunAs fields set
+//     without the privilege to change credentials
+//   - [ErrSpawnFailed]: Spawn failed for another reason, including a
+//     redirection file that cannot be opened
+//   - [ErrNotSupported]: Stdio redirection or RunAs fields on Windows
 func SpawnInGroup(config SpawnInGroupConfig) (*SpawnInGroupResult, error) {
+	if config.hasStdio() || config.Detached ||
+		hasCredential(config.RunAsUID, config.RunAsGID, config.SupplementaryGIDs) {
+		result, err := spawnInGo(config)
+		if err != nil {
+			return nil, err
+		}
+		result.WarningDetails = warningDetails("SpawnInGroup", result.PID, result.Warnings)
+		logTreeKillReliability("SpawnInGroup", result.PID, result.TreeKillReliability)
+		return result, nil
+	}
 	if config.SchemaID == "" {
-		config.SchemaID = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/spawn-in-group-config.schema.json"
+		config.SchemaID = SchemaSpawnInGroupConfigV1
 	}
 
 	b, err := json.Marshal(config)
@@ -56,6 +114,11 @@ func SpawnInGroup(config SpawnInGroupConfig) (*SpawnInGroupResult, error) {
 	if err := json.Unmarshal([]byte(C.GoString(out)), &result); err != nil {
 		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
 	}
+	result.WarningDetails = warningDetails("SpawnInGroup", result.PID, result.Warnings)
+	logTreeKillReliability("SpawnInGroup", result.PID, result.TreeKillReliability)
+	if result.TreeKillReliability == "guaranteed" {
+		trackGroupJob(result.PID)
+	}
 
 	return &result, nil
 }
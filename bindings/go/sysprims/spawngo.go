@@ -0,0 +1,92 @@
+package sysprims
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+)
+
+// StdioTarget redirects one of a spawned child's standard streams. Set
+// exactly one of Path and File.
+//
+// Ownership: a file opened from Path belongs to SpawnInGroup, which closes
+// its own copy once the child is started. File belongs to the caller; the
+// child receives a duplicate of the descriptor, so the caller may close File
+// as soon as SpawnInGroup returns without affecting the child. To redirect
+// a raw descriptor, wrap it with os.NewFile.
+type StdioTarget struct {
+	// Path is a file to redirect to. For Stdout and Stderr it is created
+	// (mode 0666 before umask) if missing and truncated unless Append is
+	// set; for Stdin it is opened read-only. Use os.DevNull to discard
+	// output or provide empty input.
+	Path string
+	// File is an open file, pipe, or socket to redirect to.
+	File *os.File
+	// Append opens Path for appending instead of truncating it (Stdout and
+	// Stderr only).
+	Append bool
+}
+
+// hasCredential reports whether any RunAs field is set.
+func hasCredential(uid, gid *uint32, groups []uint32) bool {
+	return uid != nil || gid != nil || groups != nil
+}
+
+func (c *SpawnInGroupConfig) hasStdio() bool {
+	return c.Stdin != nil || c.Stdout != nil || c.Stderr != nil
+}
+
+// validateStdio checks each target of c.
+func (c *SpawnInGroupConfig) validateStdio() error {
+	for _, s := range []struct {
+		name   string
+		target *StdioTarget
+	}{{"stdin", c.Stdin}, {"stdout", c.Stdout}, {"stderr", c.Stderr}} {
+		t := s.target
+		if t == nil {
+			continue
+		}
+		if (t.Path == "") == (t.File == nil) {
+			return &Error{Code: ErrInvalidArgument, Message: s.name + ": exactly one of path and file must be set"}
+		}
+		if t.Append && (t.Path == "" || s.name == "stdin") {
+			return &Error{Code: ErrInvalidArgument, Message: s.name + ": append applies to stdout and stderr paths only"}
+		}
+	}
+	return nil
+}
+
+// open returns the file for t, opening Path for reading (input) or writing.
+// owned reports whether the caller must close it.
+func (t *StdioTarget) open(input bool) (f *os.File, owned bool, err error) {
+	if t.File != nil {
+		return t.File, false, nil
+	}
+	if input {
+		f, err = os.Open(t.Path)
+	} else {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if t.Append {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		f, err = os.OpenFile(t.Path, flags, 0o666)
+	}
+	if err != nil {
+		return nil, false, &Error{Code: ErrSpawnFailed, Message: "failed to open redirection file: " + err.Error()}
+	}
+	return f, true, nil
+}
+
+// spawnError maps an os.StartProcess or exec.LookPath failure like the
+// library maps spawn failures.
+func spawnError(command string, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, exec.ErrNotFound):
+		return &Error{Code: ErrNotFound, Message: "Command '" + command + "' not found"}
+	case errors.Is(err, fs.ErrPermission):
+		return &Error{Code: ErrPermissionDenied, Message: "Permission denied: cannot execute '" + command + "'"}
+	default:
+		return &Error{Code: ErrSpawnFailed, Message: "Failed to spawn process: " + command + ": " + err.Error()}
+	}
+}
@@ -0,0 +1,245 @@
+//go:build !windows
+
+package sysprims
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// spawnInGo implements SpawnInGroup for configs with stdio redirection,
+// Detached, or RunAs fields, which the library cannot express. Like the
+// library it makes the child a process group leader (a session leader when
+// detached) and resolves argv[0] on the child's PATH.
+func spawnInGo(config SpawnInGroupConfig) (*SpawnInGroupResult, error) {
+	if len(config.Argv) == 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "argv must not be empty"}
+	}
+	command := config.Argv[0]
+	if command == "" {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "argv[0] (command) must not be empty"}
+	}
+	if err := config.validateStdio(); err != nil {
+		return nil, err
+	}
+	cred, err := credential(config.RunAsUID, config.RunAsGID, config.SupplementaryGIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	env := mergeEnv(os.Environ(), config.Env)
+	path, err := lookCommand(command, env)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	for i, t := range []*StdioTarget{config.Stdin, config.Stdout, config.Stderr} {
+		if t == nil {
+			continue
+		}
+		f, owned, err := t.open(i == 0)
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			defer func() { _ = f.Close() }()
+		}
+		files[i] = f
+	}
+
+	// setsid also starts a new process group led by the child.
+	sys := &syscall.SysProcAttr{Setpgid: true, Credential: cred}
+	if config.Detached {
+		sys = &syscall.SysProcAttr{Setsid: true, Credential: cred}
+	}
+	attr := &os.ProcAttr{Env: env, Files: files, Sys: sys}
+	if config.Cwd != nil {
+		attr.Dir = *config.Cwd
+	}
+	proc, err := os.StartProcess(path, config.Argv, attr)
+	if err != nil {
+		return nil, spawnError(command, err)
+	}
+	pid := uint32(proc.Pid)
+	// The child stays ours to wait on; the handle is not needed.
+	_ = proc.Release()
+
+	result := &SpawnInGroupResult{
+		SchemaID:            SchemaSpawnInGroupResultV1,
+		Timestamp:           time.Now().UTC().Format(time.RFC3339),
+		Platform:            Platform(),
+		PID:                 pid,
+		PGID:                &pid,
+		TreeKillReliability: "guaranteed",
+		Warnings:            []string{},
+	}
+	if config.Detached {
+		result.SID = &pid
+	}
+	return result, nil
+}
+
+// runWithTimeoutInGo implements RunWithTimeout for configs with RunAs
+// fields, mirroring the library: the child inherits the environment and
+// stdio, and on timeout it (or its group) gets config.Signal, then SIGKILL
+// after KillAfter. In group mode the SIGKILL is always sent, since members
+// may outlive the leader.
+func runWithTimeoutInGo(command string, args []string, timeout time.Duration, config TimeoutConfig) (*TimeoutResult, error) {
+	if command == "" {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "command cannot be empty"}
+	}
+	if timeout.Milliseconds() <= 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "timeout_ms must be > 0"}
+	}
+	cred, err := credential(config.RunAsUID, config.RunAsGID, config.SupplementaryGIDs)
+	if err != nil {
+		return nil, err
+	}
+	path, err := lookCommand(command, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+
+	group := config.Grouping == GroupByDefault
+	attr := &os.ProcAttr{
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+		Sys:   &syscall.SysProcAttr{Setpgid: group, Credential: cred},
+	}
+	proc, err := os.StartProcess(path, append([]string{command}, args...), attr)
+	if err != nil {
+		return nil, spawnError(command, err)
+	}
+	done := make(chan *os.ProcessState, 1)
+	go func() {
+		state, _ := proc.Wait()
+		done <- state
+	}()
+
+	timer := time.NewTimer(timeout)
+	select {
+	case state := <-done:
+		timer.Stop()
+		result := &TimeoutResult{SchemaID: SchemaTimeoutResultV1, Status: "completed"}
+		if state != nil && state.ExitCode() >= 0 {
+			code := state.ExitCode()
+			result.ExitCode = &code
+		} else if state != nil {
+			if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+				sig := int(ws.Signal())
+				result.Signaled = true
+				result.ExitSignal = &sig
+			}
+		}
+		return result, nil
+	case <-timer.C:
+	}
+
+	target, reliability := proc.Pid, "guaranteed"
+	if group {
+		target = -proc.Pid
+	} else {
+		reliability = "best_effort"
+	}
+	signal := config.Signal
+	result := &TimeoutResult{
+		SchemaID:            SchemaTimeoutResultV1,
+		Status:              "timed_out",
+		SignalSent:          &signal,
+		TreeKillReliability: &reliability,
+	}
+	_ = syscall.Kill(target, syscall.Signal(signal))
+
+	grace := time.NewTimer(config.KillAfter)
+	escalated := true
+	if group {
+		<-grace.C
+	} else {
+		select {
+		case <-done:
+			grace.Stop()
+			escalated = false
+		case <-grace.C:
+		}
+	}
+	if escalated {
+		_ = syscall.Kill(target, syscall.SIGKILL)
+		<-done
+	}
+	result.Escalated = &escalated
+	return result, nil
+}
+
+// credential returns the credentials for a child run with the given RunAs
+// fields, or nil when none is set. Unset IDs keep the caller's; nil groups
+// clear the supplementary groups.
+func credential(uid, gid *uint32, groups []uint32) (*syscall.Credential, error) {
+	if !hasCredential(uid, gid, groups) {
+		return nil, nil
+	}
+	if os.Geteuid() != 0 {
+		return nil, &Error{Code: ErrPermissionDenied, Message: "changing credentials requires an effective uid of 0"}
+	}
+	cred := &syscall.Credential{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid()), Groups: groups}
+	if uid != nil {
+		cred.Uid = *uid
+	}
+	if gid != nil {
+		cred.Gid = *gid
+	}
+	return cred, nil
+}
+
+// mergeEnv applies overrides to base, a list of KEY=VALUE entries.
+func mergeEnv(base []string, overrides map[string]string) []string {
+	if len(overrides) == 0 {
+		return base
+	}
+	env := make([]string, 0, len(base)+len(overrides))
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, ok := overrides[key]; !ok {
+			env = append(env, kv)
+		}
+	}
+	for k, v := range overrides {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// lookCommand resolves command on the PATH in env, as the library does.
+func lookCommand(command string, env []string) (string, error) {
+	if strings.Contains(command, "/") {
+		return command, nil
+	}
+	var pathList string
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, "PATH="); ok {
+			pathList = v
+		}
+	}
+	denied := false
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			dir = "."
+		}
+		candidate := filepath.Join(dir, command)
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if info.Mode()&0o111 == 0 {
+			denied = true
+			continue
+		}
+		return candidate, nil
+	}
+	if denied {
+		return "", &Error{Code: ErrPermissionDenied, Message: "Permission denied: cannot execute '" + command + "'"}
+	}
+	return "", &Error{Code: ErrNotFound, Message: "Command '" + command + "' not found"}
+}
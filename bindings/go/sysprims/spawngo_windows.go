@@ -0,0 +1,145 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// detachedProcess is DETACHED_PROCESS, which syscall does not define.
+const detachedProcess = 0x00000008
+
+// spawnInGo implements Detached SpawnInGroup: the child gets no console
+// (DETACHED_PROCESS) and leads a new console process group
+// (CREATE_NEW_PROCESS_GROUP), so console control events sent to the
+// caller's group do not reach it. The Job Object that makes tree kill
+// reliable is created and tracked by the library, so a detached child has
+// none and its tree kill is best effort. Stdio redirection and credential
+// changes are not supported.
+func spawnInGo(config SpawnInGroupConfig) (*SpawnInGroupResult, error) {
+	if hasCredential(config.RunAsUID, config.RunAsGID, config.SupplementaryGIDs) {
+		return nil, &Error{Code: ErrNotSupported, Message: "RunAs credentials are not supported on windows"}
+	}
+	if config.hasStdio() {
+		return nil, &Error{Code: ErrNotSupported, Message: "stdio redirection for SpawnInGroup is not supported on windows"}
+	}
+	if len(config.Argv) == 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "argv must not be empty"}
+	}
+	command := config.Argv[0]
+	if command == "" {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "argv[0] (command) must not be empty"}
+	}
+
+	// exec.Cmd drops duplicate variables case-insensitively, keeping the
+	// last, so the overrides win over the inherited environment.
+	cmd := exec.Command(command, config.Argv[1:]...)
+	if cmd.Err != nil {
+		return nil, spawnError(command, cmd.Err)
+	}
+	cmd.Env = os.Environ()
+	for k, v := range config.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	if config.Cwd != nil {
+		cmd.Dir = *config.Cwd
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP}
+	if err := cmd.Start(); err != nil {
+		return nil, spawnError(command, err)
+	}
+	pid := uint32(cmd.Process.Pid)
+	// Callers wait on the PID by other means; the handle is not needed.
+	_ = cmd.Process.Release()
+
+	return &SpawnInGroupResult{
+		SchemaID:            SchemaSpawnInGroupResultV1,
+		Timestamp:           time.Now().UTC().Format(time.RFC3339),
+		Platform:            Platform(),
+		PID:                 pid,
+		PGID:                &pid,
+		TreeKillReliability: "best_effort",
+		Warnings:            []string{"Detached process spawned without a Job Object; tree kill is best effort"},
+	}, nil
+}
+
+// runWithTimeoutInGo implements RunWithTimeout for ConsoleCtrl configs: the
+// child inherits the environment and stdio and, with GroupByDefault, leads
+// a new console process group. On timeout it gets a console control event,
+// then TerminateProcess after KillAfter. RunAs credentials are not
+// supported.
+func runWithTimeoutInGo(command string, args []string, timeout time.Duration, config TimeoutConfig) (*TimeoutResult, error) {
+	if hasCredential(config.RunAsUID, config.RunAsGID, config.SupplementaryGIDs) {
+		return nil, &Error{Code: ErrNotSupported, Message: "RunAs credentials are not supported on windows"}
+	}
+	if command == "" {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "command cannot be empty"}
+	}
+	if timeout.Milliseconds() <= 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "timeout_ms must be > 0"}
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, spawnError(command, err)
+	}
+
+	sys := &syscall.SysProcAttr{}
+	if config.Grouping == GroupByDefault {
+		sys.CreationFlags = syscall.CREATE_NEW_PROCESS_GROUP
+	}
+	attr := &os.ProcAttr{Files: []*os.File{os.Stdin, os.Stdout, os.Stderr}, Sys: sys}
+	proc, err := os.StartProcess(path, append([]string{command}, args...), attr)
+	if err != nil {
+		return nil, spawnError(command, err)
+	}
+	done := make(chan *os.ProcessState, 1)
+	go func() {
+		state, _ := proc.Wait()
+		done <- state
+	}()
+
+	timer := time.NewTimer(timeout)
+	select {
+	case state := <-done:
+		timer.Stop()
+		result := &TimeoutResult{SchemaID: SchemaTimeoutResultV1, Status: "completed"}
+		if state != nil {
+			code := state.ExitCode()
+			result.ExitCode = &code
+		}
+		return result, nil
+	case <-timer.C:
+	}
+
+	signal := config.Signal
+	if signal == 0 {
+		signal = SIGTERM
+	}
+	reliability := "best_effort"
+	result := &TimeoutResult{
+		SchemaID:            SchemaTimeoutResultV1,
+		Status:              "timed_out",
+		SignalSent:          &signal,
+		TreeKillReliability: &reliability,
+	}
+	escalated := true
+	if signalGraceful(uint32(proc.Pid)) == nil {
+		grace := time.NewTimer(config.KillAfter)
+		select {
+		case <-done:
+			grace.Stop()
+			escalated = false
+		case <-grace.C:
+		}
+	}
+	if escalated {
+		_ = proc.Kill()
+		<-done
+	}
+	result.Escalated = &escalated
+	return result, nil
+}
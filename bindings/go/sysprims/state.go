@@ -0,0 +1,80 @@
+package sysprims
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Canonical values of ProcessInfo.State and ThreadInfo.State.
+const (
+	StateRunning  = "running"
+	StateSleeping = "sleeping"
+	StateStopped  = "stopped"
+	StateZombie   = "zombie"
+	StateUnknown  = "unknown"
+)
+
+// UnmarshalJSON decodes p and normalizes State to one of the State constants,
+// keeping the reported string in RawState.
+func (p *ProcessInfo) UnmarshalJSON(data []byte) error {
+	type plain ProcessInfo
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	if p.State != nil {
+		if p.RawState == nil {
+			raw := *p.State
+			p.RawState = &raw
+		}
+		state := normalizeState(*p.State)
+		p.State = &state
+	}
+	return nil
+}
+
+// IsZombie reports whether p has exited but not been reaped by its parent.
+func (p *ProcessInfo) IsZombie() bool {
+	return p.State != nil && *p.State == StateZombie
+}
+
+// normalizeState maps platform and library state spellings, including
+// single-letter ps(1) codes, to a State constant.
+func normalizeState(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "running", "run", "runnable", "r":
+		return StateRunning
+	case "sleeping", "sleep", "idle", "disk-sleep", "s", "d", "i":
+		return StateSleeping
+	case "stopped", "stop", "tracing-stop", "t":
+		return StateStopped
+	case "zombie", "defunct", "dead", "z", "x":
+		return StateZombie
+	default:
+		return StateUnknown
+	}
+}
+
+// ZombiesOf returns the zombie (defunct) children of parentPID.
+//
+// A zombie persists until its parent reaps it with wait(2); a growing list
+// usually means the parent is not handling SIGCHLD. Windows has no zombie
+// state, so the result is always empty there.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: parentPID is 0 or > math.MaxInt32
+//   - [ErrSystem]: System error reading process information
+func ZombiesOf(parentPID uint32) ([]ProcessInfo, error) {
+	if err := validatePidList([]uint32{parentPID}); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := ProcessList(&ProcessFilter{
+		StateIn: []string{StateZombie},
+		PPIDIn:  []uint32{parentPID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Filter((*ProcessInfo).IsZombie), nil
+}
@@ -0,0 +1,378 @@
+package sysprims
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// RestartPolicy controls whether a [Supervisor] respawns an exited process.
+type RestartPolicy string
+
+const (
+	// RestartAlways respawns after every exit. It is the default.
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure respawns after a non-zero exit, a signal, or a failed
+	// spawn.
+	RestartOnFailure RestartPolicy = "on_failure"
+	// RestartNever leaves the process stopped after its first exit.
+	RestartNever RestartPolicy = "never"
+)
+
+// Supervisor backoff defaults.
+const (
+	DefaultSupervisorInitialBackoff = 100 * time.Millisecond
+	DefaultSupervisorMaxBackoff     = 30 * time.Second
+)
+
+// SupervisedProcess is one process managed by a [Supervisor].
+type SupervisedProcess struct {
+	// Name identifies the process in events; names must be unique.
+	Name string
+	// Config is passed to SpawnInGroup on every (re)spawn.
+	Config SpawnInGroupConfig
+	// Restart is the restart policy. Empty means RestartAlways.
+	Restart RestartPolicy
+}
+
+// SupervisorConfig configures [NewSupervisor].
+type SupervisorConfig struct {
+	Processes []SupervisedProcess
+	// InitialBackoff is the delay before the first respawn after an exit;
+	// it doubles on each consecutive respawn up to MaxBackoff. A process
+	// that ran for at least MaxBackoff resets it. 0 selects the defaults.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Terminate configures the TerminateTree call Stop makes per process.
+	Terminate TerminateTreeConfig
+}
+
+// SupervisorEventKind is the type of a [SupervisorEvent].
+type SupervisorEventKind string
+
+const (
+	// SupervisorStarted: a process was spawned; PID is set.
+	SupervisorStarted SupervisorEventKind = "started"
+	// SupervisorSpawnFailed: SpawnInGroup failed; Err is set.
+	SupervisorSpawnFailed SupervisorEventKind = "spawn_failed"
+	// SupervisorExited: a process exited; PID and, when known, ExitCode are
+	// set.
+	SupervisorExited SupervisorEventKind = "exited"
+	// SupervisorRestarting: a respawn is scheduled after Backoff.
+	SupervisorRestarting SupervisorEventKind = "restarting"
+	// SupervisorGaveUp: the restart policy leaves the process stopped.
+	SupervisorGaveUp SupervisorEventKind = "gave_up"
+)
+
+// SupervisorEvent is a lifecycle event emitted by a [Supervisor].
+type SupervisorEvent struct {
+	Time time.Time
+	Name string
+	Kind SupervisorEventKind
+	PID  uint32
+	// ExitCode is nil when the process was killed by a signal or its status
+	// could not be collected.
+	ExitCode *int
+	Err      error
+	// Restarts counts the respawns of this process so far.
+	Restarts int
+	// Backoff is the delay before the respawn (SupervisorRestarting only).
+	Backoff time.Duration
+}
+
+// Supervisor spawns a set of processes with [SpawnInGroup], waits for them
+// to exit, and respawns them according to their [RestartPolicy] with
+// exponential backoff.
+//
+// Supervised processes are children of the calling process; the Supervisor
+// reaps them itself (using os.Process.Wait) to collect exit codes, so
+// callers must not wait on them.
+type Supervisor struct {
+	cfg    SupervisorConfig
+	events chan SupervisorEvent
+
+	mu       sync.Mutex
+	started  bool
+	stopped  bool
+	finished bool // Stop has terminated everything and supervision ended
+	running  map[string]uint32
+	queue    []SupervisorEvent
+	wake     chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewSupervisor validates config and returns a Supervisor that has not yet
+// spawned anything; call Start.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: No processes, an empty or duplicate Name, an
+//     empty Argv, an unknown Restart policy, or a negative backoff
+func NewSupervisor(config SupervisorConfig) (*Supervisor, error) {
+	if len(config.Processes) == 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "supervisor needs at least one process"}
+	}
+	if config.InitialBackoff < 0 || config.MaxBackoff < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "backoff must be >= 0"}
+	}
+	if config.InitialBackoff == 0 {
+		config.InitialBackoff = DefaultSupervisorInitialBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = DefaultSupervisorMaxBackoff
+	}
+	config.MaxBackoff = max(config.MaxBackoff, config.InitialBackoff)
+
+	names := make(map[string]bool, len(config.Processes))
+	processes := make([]SupervisedProcess, len(config.Processes))
+	for i, p := range config.Processes {
+		if p.Name == "" || names[p.Name] {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "supervised process names must be unique and non-empty: " + p.Name}
+		}
+		names[p.Name] = true
+		if len(p.Config.Argv) == 0 {
+			return nil, &Error{Code: ErrInvalidArgument, Message: "supervised process " + p.Name + " has empty argv"}
+		}
+		switch p.Restart {
+		case "":
+			p.Restart = RestartAlways
+		case RestartAlways, RestartOnFailure, RestartNever:
+		default:
+			return nil, &Error{Code: ErrInvalidArgument, Message: "invalid restart policy: " + string(p.Restart)}
+		}
+		processes[i] = p
+	}
+	config.Processes = processes
+
+	return &Supervisor{
+		cfg:     config,
+		events:  make(chan SupervisorEvent),
+		running: make(map[string]uint32),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}, nil
+}
+
+// Events returns the lifecycle event channel. Events are queued without
+// bound so supervision never blocks on the consumer; drain the channel. It
+// is closed after Stop has returned and every event has been received.
+func (s *Supervisor) Events() <-chan SupervisorEvent {
+	return s.events
+}
+
+// Start spawns every process and begins supervising. Spawn failures are
+// reported as events, not errors.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: Start was already called
+func (s *Supervisor) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return &Error{Code: ErrInvalidArgument, Message: "supervisor already started"}
+	}
+	s.started = true
+
+	go s.pump()
+	for i := range s.cfg.Processes {
+		s.wg.Add(1)
+		go s.supervise(&s.cfg.Processes[i])
+	}
+	return nil
+}
+
+// Running returns the PIDs of the processes currently running, by name.
+func (s *Supervisor) Running() map[string]uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]uint32, len(s.running))
+	for name, pid := range s.running {
+		out[name] = pid
+	}
+	return out
+}
+
+// Stop disables restarts, terminates each running process tree with
+// [TerminateTree], and waits for supervision to finish.
+//
+// If ctx is done first, Stop returns ctx.Err() while termination continues
+// in the background; the event channel is still closed once it completes.
+// Calling Stop again waits again.
+//
+// # Errors
+//
+//   - Errors from TerminateTree, joined
+//   - ctx.Err(): ctx was done before every process exited
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	first := !s.stopped
+	s.stopped = true
+	var pids []uint32
+	if first {
+		close(s.stop)
+		for _, pid := range s.running {
+			pids = append(pids, pid)
+		}
+		if !s.started {
+			s.started = true
+			go s.pump()
+		}
+	}
+	s.mu.Unlock()
+
+	errs := make([]error, len(pids))
+	var terminating sync.WaitGroup
+	for i, pid := range pids {
+		terminating.Add(1)
+		go func(i int, pid uint32) {
+			defer terminating.Done()
+			_, err := TerminateTree(pid, s.cfg.Terminate)
+			// A process that exited on its own meanwhile is not an error.
+			var sErr *Error
+			if err != nil && !(errors.As(err, &sErr) && sErr.Code == ErrNotFound) {
+				errs[i] = err
+			}
+		}(i, pid)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		terminating.Wait()
+		s.wg.Wait()
+		close(done)
+	}()
+	if first {
+		go func() {
+			<-done
+			s.mu.Lock()
+			s.finished = true
+			s.mu.Unlock()
+			s.signal()
+		}()
+	}
+	select {
+	case <-done:
+		return errors.Join(errs...)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// supervise runs the spawn/wait/respawn loop for one process until Stop.
+func (s *Supervisor) supervise(p *SupervisedProcess) {
+	defer s.wg.Done()
+
+	backoff := s.cfg.InitialBackoff
+	for restarts := 0; ; restarts++ {
+		began := time.Now()
+		failed := true
+		res, err := SpawnInGroup(p.Config)
+		if err != nil {
+			s.emit(SupervisorEvent{Name: p.Name, Kind: SupervisorSpawnFailed, Err: err, Restarts: restarts})
+		} else {
+			exitCode, ok := s.wait(p.Name, res.PID, restarts)
+			if !ok {
+				return
+			}
+			failed = exitCode == nil || *exitCode != 0
+		}
+
+		if p.Restart == RestartNever || (p.Restart == RestartOnFailure && !failed) {
+			s.emit(SupervisorEvent{Name: p.Name, Kind: SupervisorGaveUp, Restarts: restarts})
+			return
+		}
+		if time.Since(began) >= s.cfg.MaxBackoff {
+			backoff = s.cfg.InitialBackoff
+		}
+		s.emit(SupervisorEvent{Name: p.Name, Kind: SupervisorRestarting, Restarts: restarts, Backoff: backoff})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, s.cfg.MaxBackoff)
+	}
+}
+
+// wait registers a spawned pid, reaps it, and reports its exit. It returns
+// ok=false when the Supervisor is stopping.
+func (s *Supervisor) wait(name string, pid uint32, restarts int) (exitCode *int, ok bool) {
+	s.mu.Lock()
+	stopping := s.stopped
+	if !stopping {
+		s.running[name] = pid
+	}
+	s.mu.Unlock()
+	s.emit(SupervisorEvent{Name: name, Kind: SupervisorStarted, PID: pid, Restarts: restarts})
+	if stopping {
+		// Stop ran while this process was spawning, so it is not in the
+		// set Stop terminated.
+		_, _ = TerminateTree(pid, s.cfg.Terminate)
+	}
+
+	var waitErr error
+	proc, err := os.FindProcess(int(pid))
+	if err == nil {
+		var state *os.ProcessState
+		state, waitErr = proc.Wait()
+		releaseGroupJob(pid)
+		if waitErr == nil && state.ExitCode() >= 0 {
+			code := state.ExitCode()
+			exitCode = &code
+		}
+	} else {
+		waitErr = err
+	}
+
+	s.mu.Lock()
+	delete(s.running, name)
+	stopping = s.stopped
+	s.mu.Unlock()
+	s.emit(SupervisorEvent{Name: name, Kind: SupervisorExited, PID: pid, ExitCode: exitCode, Err: waitErr, Restarts: restarts})
+	return exitCode, !stopping
+}
+
+// emit queues e for the event pump.
+func (s *Supervisor) emit(e SupervisorEvent) {
+	e.Time = time.Now()
+	s.mu.Lock()
+	s.queue = append(s.queue, e)
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *Supervisor) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump delivers queued events in order and closes the channel once Stop
+// has finished and the queue is empty.
+func (s *Supervisor) pump() {
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			finished := s.finished
+			s.mu.Unlock()
+			if finished {
+				close(s.events)
+				return
+			}
+			<-s.wake
+			continue
+		}
+		e := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+		s.events <- e
+	}
+}
@@ -0,0 +1,131 @@
+package sysprims
+
+import "os"
+
+// Suspend stops pid with SIGSTOP until [Resume]. SIGSTOP cannot be caught
+// or ignored.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to signal this process
+//   - [ErrNotSupported]: Always on Windows
+func Suspend(pid uint32) error {
+	if err := checkSuspendSupported(); err != nil {
+		return err
+	}
+	return Kill(pid, SIGSTOP)
+}
+
+// Resume continues a process stopped by [Suspend] with SIGCONT.
+//
+// # Errors
+//
+//   - See [Suspend]
+func Resume(pid uint32) error {
+	if err := checkSuspendSupported(); err != nil {
+		return err
+	}
+	return Kill(pid, SIGCONT)
+}
+
+// SuspendTree stops pid and its descendants with SIGSTOP.
+//
+// The descendants are found with [DescendantsWithOptions], so opts bounds
+// and filters them as it does there; the root itself is always included.
+// As with [KillDescendants], the calling process, its parent, and PID 1 are
+// never signaled and are counted in SkippedSafety.
+//
+// Processes are stopped deepest level first and the root last, so no
+// parent is left running and reacting to stopped children. A child forked
+// after the traversal by a parent not yet stopped is not suspended.
+//
+// The result lists Succeeded in signaling order. A PID that fails (for
+// example because it exited meanwhile) is reported in Failed and does not
+// stop the others.
+//
+// # Errors
+//
+//   - [ErrNotSupported]: Always on Windows
+//   - Errors from [DescendantsWithOptions]
+func SuspendTree(pid uint32, opts *DescendantsOptions) (*KillDescendantsResult, error) {
+	return signalTree(pid, opts, SIGSTOP, false)
+}
+
+// ResumeTree continues pid and its descendants with SIGCONT, in the reverse
+// of [SuspendTree]'s order: the root first, then each level down, so a
+// child never runs while its parent is still stopped.
+//
+// # Errors
+//
+//   - See [SuspendTree]
+func ResumeTree(pid uint32, opts *DescendantsOptions) (*KillDescendantsResult, error) {
+	return signalTree(pid, opts, SIGCONT, true)
+}
+
+// checkSuspendSupported rejects platforms without SIGSTOP delivery.
+func checkSuspendSupported() error {
+	if !signalDeliverable(Signal(SIGSTOP)) {
+		return &Error{Code: ErrNotSupported, Message: "suspend/resume is not supported on this platform"}
+	}
+	return nil
+}
+
+// signalTree sends signal to pid and its descendants one at a time, root
+// first when rootFirst is set and otherwise deepest level first.
+func signalTree(pid uint32, opts *DescendantsOptions, signal int, rootFirst bool) (*KillDescendantsResult, error) {
+	if err := checkSuspendSupported(); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &DescendantsOptions{}
+	}
+	desc, err := DescendantsWithOptions(pid, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// levels[0] is the root; later entries follow traversal depth.
+	levels := [][]uint32{{pid}}
+	for _, level := range desc.Levels {
+		pids := make([]uint32, 0, len(level.Processes))
+		for _, p := range level.Processes {
+			pids = append(pids, p.PID)
+		}
+		levels = append(levels, pids)
+	}
+	if !rootFirst {
+		for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+			levels[i], levels[j] = levels[j], levels[i]
+		}
+	}
+
+	result := &KillDescendantsResult{
+		SchemaID:   SchemaBatchKillResultV1,
+		SignalSent: signal,
+		RootPID:    pid,
+		Succeeded:  []uint32{},
+		Failed:     []KillDescendantsFail{},
+	}
+	selfPID, parentPID := uint32(os.Getpid()), uint32(os.Getppid())
+	seen := make(map[uint32]bool)
+	for _, level := range levels {
+		for _, p := range level {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			if p == selfPID || p == parentPID || p == 1 {
+				result.SkippedSafety++
+				continue
+			}
+			if err := Kill(p, signal); err != nil {
+				result.Failed = append(result.Failed, KillDescendantsFail{PID: p, Error: err.Error()})
+				continue
+			}
+			result.Succeeded = append(result.Succeeded, p)
+		}
+	}
+	return result, nil
+}
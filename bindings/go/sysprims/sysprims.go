@@ -8,8 +8,10 @@
 // Prefer typed APIs over parsing process tool output:
 //   - `ps eww -p <pid>` -> [ProcessGetWithOptions] with [ProcessOptions.IncludeEnv]
 //   - `ps -M -p <pid>` -> [ProcessGetWithOptions] with [ProcessOptions.IncludeThreads]
+//     for a count, or [ListThreads] for per-thread detail
 //   - `lsof -p <pid>` -> [ListFds]
 //   - `kill -9 <pid>` -> [Kill] with [SIGKILL]
+//   - `renice -n <nice> -p <pid>` -> [SetPriority]
 //   - `kill` loops for process trees -> [KillDescendantsWithOptions] with filter + [CpuModeMonitor]
 //
 // This keeps behavior cross-platform and avoids fragile text parsing.
@@ -33,7 +35,9 @@
 // # Platform Notes
 //
 // Some operations have platform-specific behavior:
-//   - [KillGroup] returns [ErrNotSupported] on Windows
+//   - [KillGroup] on Windows only accepts the leaders of SpawnInGroup Job
+//     Objects
+//   - [GetCPUAffinity] and [SetCPUAffinity] return [ErrNotSupported] on macOS
 //   - Signal mapping differs between Unix and Windows (see [Kill] documentation)
 package sysprims
 
@@ -42,6 +46,10 @@ package sysprims
 #include <stdlib.h>
 */
 import "C"
+import (
+	"os"
+	"strconv"
+)
 
 // Version returns the sysprims library version string.
 //
@@ -52,17 +60,46 @@ func Version() string {
 	return C.GoString(cVer)
 }
 
+// ExpectedABIVersion is the FFI ABI version these bindings are built for.
+const ExpectedABIVersion uint32 = 1
+
 // ABIVersion returns the FFI ABI version number.
 //
 // Use this to verify compatibility between the Go bindings and the
 // underlying library. If the ABI version changes, the bindings may
-// not work correctly.
-//
-// The current bindings expect ABI version 1.
+// not work correctly; see [CheckABI].
 func ABIVersion() uint32 {
 	return uint32(C.sysprims_abi_version())
 }
 
+// CheckABI verifies that the linked library speaks [ExpectedABIVersion].
+//
+// The package runs it at init and panics on a mismatch, so a wrong shared
+// library fails at startup rather than deep inside an FFI call. Set the
+// environment variable SYSPRIMS_SKIP_ABI_CHECK to a non-empty value to skip
+// the init check.
+//
+// # Errors
+//
+//   - [ErrNotSupported]: The library's ABI version differs
+func CheckABI() error {
+	if abi := ABIVersion(); abi != ExpectedABIVersion {
+		return &Error{Code: ErrNotSupported, Message: "sysprims library " + Version() + " has ABI version " +
+			strconv.FormatUint(uint64(abi), 10) + ", but these bindings require ABI version " +
+			strconv.FormatUint(uint64(ExpectedABIVersion), 10)}
+	}
+	return nil
+}
+
+func init() {
+	if os.Getenv("SYSPRIMS_SKIP_ABI_CHECK") != "" {
+		return
+	}
+	if err := CheckABI(); err != nil {
+		panic("sysprims: " + err.Error())
+	}
+}
+
 // Platform returns the current platform name.
 //
 // Returns one of: "linux", "macos", "windows", "freebsd", etc.
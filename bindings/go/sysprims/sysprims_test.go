@@ -1,11 +1,29 @@
 package sysprims_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"net"
+	"net/netip"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
@@ -35,6 +53,16 @@ func TestABIVersion(t *testing.T) {
 	t.Logf("ABI Version: %d", abi)
 }
 
+// TestCheckABI verifies the linked library matches the bindings' ABI.
+func TestCheckABI(t *testing.T) {
+	if err := sysprims.CheckABI(); err != nil {
+		t.Errorf("CheckABI() = %v", err)
+	}
+	if abi := sysprims.ABIVersion(); abi != sysprims.ExpectedABIVersion {
+		t.Errorf("ABIVersion() = %d, want %d", abi, sysprims.ExpectedABIVersion)
+	}
+}
+
 // TestPlatform verifies that Platform returns a valid platform name.
 func TestPlatform(t *testing.T) {
 	p := sysprims.Platform()
@@ -91,260 +119,6124 @@ func TestKillNonexistentPID(t *testing.T) {
 	}
 }
 
-// TestProcessList verifies that ProcessList returns processes.
-func TestProcessList(t *testing.T) {
-	snapshot, err := sysprims.ProcessList(nil)
-	if err != nil {
-		t.Fatalf("ProcessList(nil) failed: %v", err)
+// TestCanSignal verifies CanSignal is true for the test process, false
+// without error for PID 1 when unprivileged, and reports invalid and
+// missing PIDs as errors.
+func TestCanSignal(t *testing.T) {
+	ok, err := sysprims.CanSignal(uint32(os.Getpid()))
+	if err != nil || !ok {
+		t.Errorf("CanSignal(self) = %v, %v; want true", ok, err)
 	}
 
-	if len(snapshot.Processes) == 0 {
-		t.Error("ProcessList returned empty list")
+	var sErr *sysprims.Error
+	if _, err := sysprims.CanSignal(0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("CanSignal(0) expected ErrInvalidArgument, got %v", err)
 	}
-
-	if snapshot.SchemaID == "" {
-		t.Error("ProcessList returned empty schema_id")
+	if ok, err := sysprims.CanSignal(99999999); err == nil {
+		t.Errorf("CanSignal(99999999) = %v, nil; want an error", ok)
 	}
 
-	t.Logf("Found %d processes, schema_id: %s", len(snapshot.Processes), snapshot.SchemaID)
+	if runtime.GOOS == "linux" {
+		if os.Geteuid() == 0 {
+			t.Log("running as root; skipping the PID 1 check")
+			return
+		}
+		ok, err := sysprims.CanSignal(1)
+		if err != nil || ok {
+			t.Errorf("CanSignal(1) = %v, %v; want false, nil", ok, err)
+		}
+	}
 }
 
-// TestProcessListWithFilter verifies filtering works.
-func TestProcessListWithFilter(t *testing.T) {
-	// Filter by current process name
-	currentName := "sysprims" // Test binary name will contain this
-
-	snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{
-		NameContains: &currentName,
-	})
-	if err != nil {
-		t.Fatalf("ProcessList with filter failed: %v", err)
+// TestSignalNames verifies Signal names, ParseSignal's accepted spellings
+// and deliverability, Signals, and text round-tripping.
+func TestSignalNames(t *testing.T) {
+	if got := sysprims.Signal(sysprims.SIGTERM).String(); got != "SIGTERM" {
+		t.Errorf("SIGTERM.String() = %q", got)
+	}
+	if got := sysprims.Signal(1000).String(); got != "signal 1000" {
+		t.Errorf("Signal(1000).String() = %q", got)
 	}
 
-	// Should find at least one process (might not match depending on test binary name)
-	t.Logf("Found %d processes matching %q", len(snapshot.Processes), currentName)
-}
+	for _, s := range []string{"TERM", "SIGTERM", "sigterm", " Term ", "15"} {
+		info, err := sysprims.ParseSignal(s)
+		if err != nil {
+			t.Errorf("ParseSignal(%q) failed: %v", s, err)
+			continue
+		}
+		if info.Signal != sysprims.SIGTERM || info.Name != "SIGTERM" || !info.Deliverable {
+			t.Errorf("ParseSignal(%q) = %+v", s, info)
+		}
+	}
+	var sErr *sysprims.Error
+	for _, s := range []string{"", "SIG", "NOPE", "0", "-15", "1000"} {
+		if _, err := sysprims.ParseSignal(s); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("ParseSignal(%q) expected ErrInvalidArgument, got %v", s, err)
+		}
+	}
 
-func TestProcessListWithOptions(t *testing.T) {
-	pid := uint32(os.Getpid())
-	snapshot, err := sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{PIDIn: []uint32{pid}}, &sysprims.ProcessOptions{
-		IncludeThreads: true,
-	})
+	usr1, err := sysprims.ParseSignal("USR1")
 	if err != nil {
-		t.Fatalf("ProcessListWithOptions failed: %v", err)
+		t.Fatalf("ParseSignal(USR1) failed: %v", err)
+	}
+	if usr1.Signal != sysprims.Signal(sysprims.SIGUSR1) {
+		t.Errorf("USR1 = %d, want %d", usr1.Signal, sysprims.SIGUSR1)
+	}
+	if usr1.Deliverable == (runtime.GOOS == "windows") {
+		t.Errorf("USR1 Deliverable = %v on %s", usr1.Deliverable, runtime.GOOS)
 	}
 
-	if len(snapshot.Processes) == 0 {
-		t.Fatal("ProcessListWithOptions returned empty process list")
+	signals := sysprims.Signals()
+	found := map[sysprims.Signal]bool{}
+	for i, s := range signals {
+		found[s] = true
+		if i > 0 && signals[i-1] >= s {
+			t.Errorf("Signals() not ascending: %v", signals)
+		}
+	}
+	if !found[sysprims.SIGTERM] || !found[sysprims.SIGKILL] {
+		t.Errorf("Signals() = %v, missing SIGTERM or SIGKILL", signals)
+	}
+	if runtime.GOOS == "windows" && len(signals) != 2 {
+		t.Errorf("Signals() on windows = %v, want SIGKILL and SIGTERM", signals)
 	}
 
-	if snapshot.Processes[0].PID != pid {
-		t.Fatalf("ProcessListWithOptions returned wrong pid: got %d, expected %d", snapshot.Processes[0].PID, pid)
+	var cfg struct {
+		Stop sysprims.Signal `json:"stop"`
+	}
+	if err := json.Unmarshal([]byte(`{"stop":"INT"}`), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if cfg.Stop != sysprims.SIGINT {
+		t.Errorf("unmarshaled stop = %v, want SIGINT", cfg.Stop)
+	}
+	out, err := json.Marshal(cfg)
+	if err != nil || string(out) != `{"stop":"SIGINT"}` {
+		t.Errorf("marshal = %s, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"stop":"BOGUS"}`), &cfg); err == nil {
+		t.Error("unmarshal of an unknown signal succeeded")
+	}
+	// Unnamed numbers round-trip; negative ones do not marshal.
+	for _, sig := range []sysprims.Signal{0, 34} {
+		var back sysprims.Signal
+		text, err := sig.MarshalText()
+		if err != nil {
+			t.Errorf("MarshalText(%d) failed: %v", sig, err)
+		} else if err := back.UnmarshalText(text); err != nil || back != sig {
+			t.Errorf("UnmarshalText(%q) = %d, %v; want %d", text, back, err, sig)
+		}
+	}
+	if _, err := sysprims.Signal(-1).MarshalText(); err == nil {
+		t.Error("MarshalText(-1) succeeded")
 	}
 }
 
-// TestProcessGetSelf verifies that ProcessGet works for the current process.
-func TestProcessGetSelf(t *testing.T) {
-	pid := uint32(os.Getpid())
+// TestGracefulShutdown verifies that a TERM-respecting process exits
+// gracefully and a TERM-ignoring one is force-killed after the grace period.
+func TestGracefulShutdown(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1) and POSIX signals")
+	}
 
-	info, err := sysprims.ProcessGet(pid)
+	polite := exec.Command("sleep", "30")
+	stubborn := exec.Command("sh", "-c", "trap '' TERM; exec sleep 30")
+	var pids []uint32
+	var reaped []chan struct{}
+	for _, cmd := range []*exec.Cmd{polite, stubborn} {
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start child: %v", err)
+		}
+		done := make(chan struct{})
+		go func(cmd *exec.Cmd) {
+			// Reap promptly so exited children do not linger as zombies.
+			_ = cmd.Wait()
+			close(done)
+		}(cmd)
+		defer func(cmd *exec.Cmd) {
+			_ = cmd.Process.Kill()
+			<-done
+		}(cmd)
+		pids = append(pids, uint32(cmd.Process.Pid))
+		reaped = append(reaped, done)
+	}
+
+	// Wait for the shell to install its trap and exec sleep.
+	stubbornPID := uint32(stubborn.Process.Pid)
+	for i := 0; ; i++ {
+		info, err := sysprims.ProcessGet(stubbornPID)
+		if err == nil && info.Name == "sleep" {
+			break
+		}
+		if i == 100 {
+			t.Skip("TERM-ignoring child did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	result, err := sysprims.GracefulShutdown(pids, 500*time.Millisecond)
 	if err != nil {
-		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+		t.Fatalf("GracefulShutdown failed: %v", err)
+	}
+	if len(result.Failed) != 0 {
+		t.Fatalf("GracefulShutdown reported failures: %+v", result.Failed)
+	}
+	if len(result.Graceful) != 1 || result.Graceful[0] != pids[0] {
+		t.Errorf("Graceful = %v, want [%d]", result.Graceful, pids[0])
+	}
+	if len(result.Forced) != 1 || result.Forced[0] != pids[1] {
+		t.Errorf("Forced = %v, want [%d]", result.Forced, pids[1])
+	}
+	if len(result.Succeeded) != 2 {
+		t.Errorf("Succeeded = %v, want both PIDs", result.Succeeded)
 	}
 
-	if info.PID != pid {
-		t.Errorf("ProcessGet returned wrong PID: got %d, expected %d", info.PID, pid)
+	for i, done := range reaped {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Errorf("child %d still running after GracefulShutdown", pids[i])
+		}
 	}
+}
 
-	if info.Name == "" {
-		t.Error("ProcessGet returned empty name")
+// TestGracefulShutdownInvalid verifies argument validation.
+func TestGracefulShutdownInvalid(t *testing.T) {
+	var sErr *sysprims.Error
+	if _, err := sysprims.GracefulShutdown(nil, time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("GracefulShutdown(nil) expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.GracefulShutdown([]uint32{0}, time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("GracefulShutdown([0]) expected ErrInvalidArgument, got %v", err)
 	}
+	if _, err := sysprims.GracefulShutdown([]uint32{uint32(os.Getpid())}, -time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("GracefulShutdown(negative grace) expected ErrInvalidArgument, got %v", err)
+	}
+}
 
-	t.Logf("Process: %s (PID %d, PPID %d)", info.Name, info.PID, info.PPID)
+// cancelAfter is a context whose Err reports context.Canceled from its
+// n+1th call on, so a batch is cancelled at a deterministic point.
+type cancelAfter struct {
+	context.Context
+	n     int64
+	calls atomic.Int64
 }
 
-func TestProcessGetWithOptionsSelf(t *testing.T) {
-	pid := uint32(os.Getpid())
+func (c *cancelAfter) Err() error {
+	if c.calls.Add(1) > c.n {
+		return context.Canceled
+	}
+	return nil
+}
 
-	info, err := sysprims.ProcessGetWithOptions(pid, &sysprims.ProcessOptions{
-		IncludeThreads: true,
-	})
+// TestKillManyContext verifies concurrent signaling keeps input order, and
+// cancellation mid-batch returns the partial result with ctx.Err().
+func TestKillManyContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signal 0 is not supported on windows")
+	}
+
+	// The self PID succeeds; the rest lie beyond pid_max and do not exist.
+	self := uint32(os.Getpid())
+	pids := []uint32{self}
+	for i := uint32(0); i < 200000; i++ {
+		pids = append(pids, 1<<30+i)
+	}
+	checkOrder := func(res *sysprims.BatchKillResult) {
+		t.Helper()
+		if len(res.Succeeded) != 1 || res.Succeeded[0] != self {
+			t.Errorf("Succeeded = %v, want [%d]", res.Succeeded, self)
+		}
+		for i, f := range res.Failed {
+			if f.PID != pids[i+1] || f.Error.Code != sysprims.ErrNotFound {
+				t.Fatalf("Failed[%d] = %d (%v), want %d not found", i, f.PID, f.Error, pids[i+1])
+			}
+		}
+	}
+
+	small := pids[:50]
+	want, err := sysprims.KillMany(small, 0)
 	if err != nil {
-		t.Fatalf("ProcessGetWithOptions(%d) failed: %v", pid, err)
+		t.Fatalf("KillMany failed: %v", err)
+	}
+	for _, concurrency := range []int{0, 1, 8} {
+		got, err := sysprims.KillManyContext(context.Background(), small, 0, concurrency)
+		if err != nil {
+			t.Fatalf("concurrency %d: %v", concurrency, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("concurrency %d: result differs from KillMany", concurrency)
+		}
 	}
 
-	if info.PID != pid {
-		t.Errorf("ProcessGetWithOptions returned wrong PID: got %d, expected %d", info.PID, pid)
+	ctx := &cancelAfter{Context: context.Background(), n: 1000}
+	res, err := sysprims.KillManyContext(ctx, pids, 0, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
 	}
+	if done := len(res.Succeeded) + len(res.Failed); done != 1000 {
+		t.Errorf("signaled %d of %d PIDs; want the 1000 started before cancellation", done, len(pids))
+	}
+	checkOrder(res)
 
-	if info.ThreadCount != nil && *info.ThreadCount == 0 {
-		t.Error("ProcessGetWithOptions returned invalid thread_count of 0")
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if res, err := sysprims.KillManyContext(cancelled, pids, 0, 0); !errors.Is(err, context.Canceled) ||
+		len(res.Succeeded)+len(res.Failed) != 0 {
+		t.Errorf("cancelled context = %+v, %v; want nothing signaled and context.Canceled", res, err)
 	}
 }
 
-func TestListFdsSelf(t *testing.T) {
-	pid := uint32(os.Getpid())
-	snap, err := sysprims.ListFds(pid, nil)
-
+// TestKillAndWait verifies KillAndWait reports a TERM-respecting child as
+// exited, times out on a TERM-ignoring one without an error, and validates
+// its arguments.
+func TestKillAndWait(t *testing.T) {
+	var sErr *sysprims.Error
+	if _, err := sysprims.KillAndWait(0, sysprims.SIGTERM, time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("KillAndWait(0) expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.KillAndWait(uint32(os.Getpid()), sysprims.SIGTERM, -time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("KillAndWait(negative timeout) expected ErrInvalidArgument, got %v", err)
+	}
 	if runtime.GOOS == "windows" {
-		if err == nil {
-			t.Fatalf("expected ListFds to fail on windows")
+		t.Skip("uses sleep(1) and POSIX signals")
+	}
+
+	polite := exec.Command("sleep", "30")
+	stubborn := exec.Command("sh", "-c", "trap '' TERM; exec sleep 30")
+	for _, cmd := range []*exec.Cmd{polite, stubborn} {
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start child: %v", err)
 		}
-		if sErr, ok := err.(*sysprims.Error); ok {
-			if sErr.Code != sysprims.ErrNotSupported {
-				t.Fatalf("expected ErrNotSupported, got %d (%s)", sErr.Code, sErr.Code)
-			}
+		done := make(chan struct{})
+		go func(cmd *exec.Cmd) {
+			_ = cmd.Wait()
+			close(done)
+		}(cmd)
+		defer func(cmd *exec.Cmd) {
+			_ = cmd.Process.Kill()
+			<-done
+		}(cmd)
+	}
+
+	// Wait for the shell to install its trap and exec sleep.
+	stubbornPID := uint32(stubborn.Process.Pid)
+	for i := 0; ; i++ {
+		info, err := sysprims.ProcessGet(stubbornPID)
+		if err == nil && info.Name == "sleep" {
+			break
 		}
-		return
+		if i == 100 {
+			t.Skip("TERM-ignoring child did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
 	}
 
+	res, err := sysprims.KillAndWait(uint32(polite.Process.Pid), sysprims.SIGTERM, 5*time.Second)
 	if err != nil {
-		t.Fatalf("ListFds(%d) failed: %v", pid, err)
+		t.Fatalf("KillAndWait(polite) failed: %v", err)
+	}
+	if !res.Exited || res.TimedOut || res.ElapsedMS >= 5000 {
+		t.Errorf("KillAndWait(polite) = %+v", res)
 	}
 
-	if snap.SchemaID == "" {
-		t.Fatalf("ListFds returned empty schema_id")
+	res, err = sysprims.KillAndWait(stubbornPID, sysprims.SIGTERM, 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("KillAndWait(stubborn, TERM) failed: %v", err)
 	}
-	if snap.Pid != pid {
-		t.Fatalf("ListFds returned wrong pid: got %d expected %d", snap.Pid, pid)
+	if res.Exited || !res.TimedOut || res.ElapsedMS < 300 {
+		t.Errorf("KillAndWait(stubborn, TERM) = %+v", res)
 	}
-	if len(snap.Fds) == 0 {
-		t.Fatalf("ListFds returned empty fd list; warnings=%v", snap.Warnings)
+
+	res, err = sysprims.KillAndWait(stubbornPID, sysprims.SIGKILL, 5*time.Second)
+	if err != nil {
+		t.Fatalf("KillAndWait(stubborn, KILL) failed: %v", err)
+	}
+	if !res.Exited || res.TimedOut {
+		t.Errorf("KillAndWait(stubborn, KILL) = %+v", res)
 	}
 }
 
-// TestProcessGetInvalidPID verifies that ProcessGet rejects PID 0.
-func TestProcessGetInvalidPID(t *testing.T) {
-	_, err := sysprims.ProcessGet(0)
-	if err == nil {
-		t.Error("ProcessGet(0) should return error")
+// TestGracefulStop verifies a TERM-respecting process ends on SIGTERM and a
+// TERM-ignoring one is escalated to SIGKILL.
+func TestGracefulStop(t *testing.T) {
+	var sErr *sysprims.Error
+	if _, err := sysprims.GracefulStop(0, time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("GracefulStop(0) expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.GracefulStop(uint32(os.Getpid()), -time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("GracefulStop(negative grace) expected ErrInvalidArgument, got %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1) and POSIX signals")
 	}
 
-	sErr, ok := err.(*sysprims.Error)
-	if !ok {
-		t.Errorf("Expected *sysprims.Error, got %T", err)
-		return
+	polite := exec.Command("sleep", "30")
+	stubborn := exec.Command("sh", "-c", "trap '' TERM; exec sleep 30")
+	for _, cmd := range []*exec.Cmd{polite, stubborn} {
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start child: %v", err)
+		}
+		done := make(chan struct{})
+		go func(cmd *exec.Cmd) {
+			_ = cmd.Wait()
+			close(done)
+		}(cmd)
+		defer func(cmd *exec.Cmd) {
+			_ = cmd.Process.Kill()
+			<-done
+		}(cmd)
 	}
-	if sErr.Code != sysprims.ErrInvalidArgument {
-		t.Errorf("Expected ErrInvalidArgument, got %d (%s)", sErr.Code, sErr.Code)
+
+	// Wait for the shell to install its trap and exec sleep.
+	stubbornPID := uint32(stubborn.Process.Pid)
+	for i := 0; ; i++ {
+		info, err := sysprims.ProcessGet(stubbornPID)
+		if err == nil && info.Name == "sleep" {
+			break
+		}
+		if i == 100 {
+			t.Skip("TERM-ignoring child did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
 	}
-}
 
-// TestProcessGetNonexistent verifies that ProcessGet returns NotFound for nonexistent PIDs.
-func TestProcessGetNonexistent(t *testing.T) {
-	_, err := sysprims.ProcessGet(99999999)
-	if err == nil {
-		t.Skip("PID 99999999 unexpectedly exists on this system")
+	res, err := sysprims.GracefulStop(uint32(polite.Process.Pid), 5*time.Second)
+	if err != nil {
+		t.Fatalf("GracefulStop(polite) failed: %v", err)
+	}
+	if !res.Exited || res.Escalated || res.EndedBy != sysprims.SIGTERM || res.GraceMS >= 5000 {
+		t.Errorf("GracefulStop(polite) = %+v", res)
 	}
 
-	sErr, ok := err.(*sysprims.Error)
-	if !ok {
-		t.Errorf("Expected *sysprims.Error, got %T", err)
-		return
+	res, err = sysprims.GracefulStopWithOptions(stubbornPID, 300*time.Millisecond, sysprims.GracefulStopOptions{KillTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("GracefulStop(stubborn) failed: %v", err)
 	}
-	if sErr.Code != sysprims.ErrNotFound {
-		t.Errorf("Expected ErrNotFound, got %d (%s)", sErr.Code, sErr.Code)
+	if !res.Exited || !res.Escalated || res.EndedBy != sysprims.SIGKILL || res.GraceMS < 300 || res.ElapsedMS < res.GraceMS {
+		t.Errorf("GracefulStop(stubborn) = %+v", res)
 	}
 }
 
-func TestSelfSessionIDs(t *testing.T) {
-	pgid, err := sysprims.SelfPGID()
-	if runtime.GOOS == "windows" {
-		if err == nil {
-			t.Fatalf("expected SelfPGID to fail on windows")
+// TestGracefulHelper is not a real test. Run with SYSPRIMS_TEST_HELPER set
+// to "graceful", it reports readiness, waits for a graceful stop (SIGTERM,
+// or a console control event on Windows), and exits with code 42.
+func TestGracefulHelper(t *testing.T) {
+	if os.Getenv("SYSPRIMS_TEST_HELPER") != "graceful" {
+		return
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	fmt.Println("ready")
+	<-stop
+	os.Exit(42)
+}
+
+// TestSignalGraceful verifies a graceful stop reaches a process that handles
+// it, directly and as the first stage of RunWithTimeout with ConsoleCtrl.
+func TestSignalGraceful(t *testing.T) {
+	var sErr *sysprims.Error
+	if err := sysprims.SignalGraceful(0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("SignalGraceful(0) expected ErrInvalidArgument, got %v", err)
+	}
+	t.Setenv("SYSPRIMS_TEST_HELPER", "graceful")
+	helperArgs := []string{"-test.run=^TestGracefulHelper$"}
+
+	// On Windows the helper would share this test's console process group,
+	// so it is only signaled through RunWithTimeout, which gives it its own.
+	if runtime.GOOS != "windows" {
+		cmd := exec.Command(os.Args[0], helperArgs...)
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			t.Fatalf("StdoutPipe: %v", err)
 		}
-		if sErr, ok := err.(*sysprims.Error); ok {
-			if sErr.Code != sysprims.ErrNotSupported {
-				t.Fatalf("expected ErrNotSupported, got %d (%s)", sErr.Code, sErr.Code)
-			}
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start helper: %v", err)
 		}
-	} else {
-		if err != nil {
-			t.Fatalf("SelfPGID failed: %v", err)
+		defer func() { _ = cmd.Process.Kill() }()
+		line := make([]byte, len("ready\n"))
+		if _, err := io.ReadFull(out, line); err != nil || string(line) != "ready\n" {
+			t.Fatalf("helper did not report ready: %q, %v", line, err)
 		}
-		if pgid == 0 {
-			t.Fatalf("SelfPGID returned 0")
+		if err := sysprims.SignalGraceful(uint32(cmd.Process.Pid)); err != nil {
+			t.Fatalf("SignalGraceful failed: %v", err)
+		}
+		if err := cmd.Wait(); cmd.ProcessState == nil || cmd.ProcessState.ExitCode() != 42 {
+			t.Errorf("helper exit = %v, want code 42", err)
 		}
 	}
 
-	sid, err := sysprims.SelfSID()
-	if runtime.GOOS == "windows" {
-		if err == nil {
-			t.Fatalf("expected SelfSID to fail on windows")
+	cfg := sysprims.DefaultTimeoutConfig()
+	cfg.KillAfter = 10 * time.Second
+	cfg.ConsoleCtrl = true
+	if runtime.GOOS != "windows" {
+		// In group mode SIGKILL always follows, since members may outlive
+		// the leader.
+		cfg.Grouping = sysprims.Foreground
+	}
+	res, err := sysprims.RunWithTimeout(os.Args[0], helperArgs, 2*time.Second, cfg)
+	if err != nil {
+		t.Fatalf("RunWithTimeout failed: %v", err)
+	}
+	if !res.TimedOut() || res.Escalated == nil {
+		t.Fatalf("RunWithTimeout = %+v; want timed out", res)
+	}
+	if *res.Escalated {
+		if runtime.GOOS == "windows" {
+			t.Skip("helper has no console to receive the control event")
 		}
-		if sErr, ok := err.(*sysprims.Error); ok {
-			if sErr.Code != sysprims.ErrNotSupported {
-				t.Fatalf("expected ErrNotSupported, got %d (%s)", sErr.Code, sErr.Code)
+		t.Error("helper ignored the graceful stop and was escalated")
+	}
+}
+
+// TestSignalGroupHelper is not a real test. Run with SYSPRIMS_TEST_HELPER
+// set to "signalgroup" as the leader of a new session, it signals its group
+// and session with and without itself, and prints "ok" or the first failure.
+func TestSignalGroupHelper(t *testing.T) {
+	if os.Getenv("SYSPRIMS_TEST_HELPER") != "signalgroup" {
+		return
+	}
+	fmt.Println(signalGroupHelper())
+	os.Exit(0)
+}
+
+func signalGroupHelper() string {
+	terms := make(chan os.Signal, 4)
+	signal.Notify(terms, syscall.SIGTERM)
+	self := uint32(os.Getpid())
+
+	// Children inherit the helper's group and session.
+	startSleeps := func(n int) ([]*exec.Cmd, []uint32) {
+		var cmds []*exec.Cmd
+		var pids []uint32
+		for i := 0; i < n; i++ {
+			cmd := exec.Command("sleep", "30")
+			if err := cmd.Start(); err == nil {
+				cmds = append(cmds, cmd)
+				pids = append(pids, uint32(cmd.Process.Pid))
 			}
 		}
-	} else {
-		if err != nil {
-			t.Fatalf("SelfSID failed: %v", err)
+		sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+		return cmds, pids
+	}
+	killed := func(cmds []*exec.Cmd) string {
+		for _, cmd := range cmds {
+			done := make(chan error, 1)
+			go func() { done <- cmd.Wait() }()
+			select {
+			case err := <-done:
+				if err == nil {
+					return fmt.Sprintf("pid %d exited cleanly", cmd.Process.Pid)
+				}
+			case <-time.After(5 * time.Second):
+				_ = cmd.Process.Kill()
+				return fmt.Sprintf("pid %d was not signaled", cmd.Process.Pid)
+			}
 		}
-		if sid == 0 {
-			t.Fatalf("SelfSID returned 0")
+		return ""
+	}
+	gotTerm := func() bool {
+		select {
+		case <-terms:
+			return true
+		case <-time.After(200 * time.Millisecond):
+			return false
 		}
 	}
-}
 
-func TestListeningPortsSelfListener(t *testing.T) {
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	cmds, pids := startSleeps(2)
+	res, err := sysprims.SignalSelfGroupWithOptions(sysprims.SIGTERM, &sysprims.SignalSelfGroupOptions{ExcludeSelf: true})
 	if err != nil {
-		var opErr *net.OpError
-		if errors.As(err, &opErr) && (errors.Is(opErr.Err, syscall.EPERM) || errors.Is(opErr.Err, syscall.EACCES)) {
-			t.Skipf("net.Listen denied in this environment: %v", err)
+		return "SignalSelfGroupWithOptions: " + err.Error()
+	}
+	if !reflect.DeepEqual(res.Succeeded, pids) {
+		return fmt.Sprintf("group without self signaled %v, want %v", res.Succeeded, pids)
+	}
+	if msg := killed(cmds); msg != "" {
+		return msg
+	}
+	if gotTerm() {
+		return "excluded caller was signaled by its group"
+	}
+
+	sid, err := sysprims.SelfSID()
+	if err != nil || sid != self {
+		return fmt.Sprintf("SelfSID = %d, %v; want %d", sid, err, self)
+	}
+	cmds, _ = startSleeps(1)
+	if res, err = sysprims.SignalSession(sid, sysprims.SIGTERM, true); err != nil {
+		return "SignalSession(excludeSelf): " + err.Error()
+	}
+	if msg := killed(cmds); msg != "" {
+		return msg
+	}
+	if gotTerm() {
+		return "excluded caller was signaled by its session"
+	}
+
+	cmds, pids = startSleeps(2)
+	if res, err = sysprims.SignalSession(sid, sysprims.SIGTERM, false); err != nil {
+		return "SignalSession: " + err.Error()
+	}
+	if want := append(pids, self); !reflect.DeepEqual(res.Succeeded, want) {
+		return fmt.Sprintf("session signaled %v, want %v (caller last)", res.Succeeded, want)
+	}
+	if msg := killed(cmds); msg != "" {
+		return msg
+	}
+	if !gotTerm() {
+		return "caller was not signaled by its session"
+	}
+
+	cmds, _ = startSleeps(1)
+	if err := sysprims.SignalSelfGroup(sysprims.SIGTERM); err != nil {
+		return "SignalSelfGroup: " + err.Error()
+	}
+	if msg := killed(cmds); msg != "" {
+		return msg
+	}
+	if !gotTerm() {
+		return "caller was not signaled by its group"
+	}
+	return "ok"
+}
+
+// TestSignalSelfGroupAndSession verifies the group and session helpers,
+// including their exclude-self behavior, from a helper that leads its own
+// session so the test's own group is never signaled.
+func TestSignalSelfGroupAndSession(t *testing.T) {
+	var sErr *sysprims.Error
+	if _, err := sysprims.SignalSession(0, sysprims.SIGTERM, true); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("SignalSession(0) expected ErrInvalidArgument, got %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		if err := sysprims.SignalSelfGroup(sysprims.SIGTERM); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("SignalSelfGroup expected ErrNotSupported, got %v", err)
+		}
+		if _, err := sysprims.SignalSession(1234, sysprims.SIGTERM, true); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("SignalSession expected ErrNotSupported, got %v", err)
+		}
+		return
+	}
+	if _, err := sysprims.SignalSession(math.MaxInt32, sysprims.SIGTERM, true); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("SignalSession(empty session) expected ErrNotFound, got %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	h, err := sysprims.SpawnInGroupHandle(sysprims.SpawnInGroupConfig{
+		Argv:     []string{os.Args[0], "-test.run=^TestSignalGroupHelper$"},
+		Env:      map[string]string{"SYSPRIMS_TEST_HELPER": "signalgroup"},
+		Stdout:   &sysprims.StdioTarget{Path: out},
+		Detached: true,
+	})
+	if err != nil {
+		t.Skipf("failed to start helper: %v", err)
+	}
+	res, err := h.Wait(60 * time.Second)
+	if err != nil || !res.Exited {
+		_, _ = h.TerminateTree(sysprims.TerminateTreeConfig{})
+		t.Fatalf("helper did not finish: %+v, %v", res, err)
+	}
+	b, _ := os.ReadFile(out)
+	if !strings.HasPrefix(string(b), "ok\n") {
+		t.Errorf("helper reported: %s", b)
+	}
+}
+
+// TestKillMatching verifies KillMatching refuses an empty filter, reports
+// targets without signaling in a dry run, skips the calling process and its
+// ancestors, and signals matching children.
+func TestKillMatching(t *testing.T) {
+	var sErr *sysprims.Error
+	for _, filter := range []*sysprims.ProcessFilter{nil, {}, {PIDIn: []uint32{}}} {
+		if _, err := sysprims.KillMatching(filter, sysprims.SIGTERM, nil); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("KillMatching(%+v) expected ErrInvalidArgument, got %v", filter, err)
+		}
+	}
+
+	self, parent := uint32(os.Getpid()), uint32(os.Getppid())
+	dry := &sysprims.KillMatchingOptions{DryRun: true}
+	res, err := sysprims.KillMatching(&sysprims.ProcessFilter{PIDIn: []uint32{self, parent}}, sysprims.SIGTERM, dry)
+	if err != nil {
+		t.Fatalf("KillMatching(self, parent) failed: %v", err)
+	}
+	if len(res.Targets) != 0 || len(res.SkippedSafety) != 2 {
+		t.Errorf("KillMatching(self, parent) = targets %v, skipped %v", res.Targets, res.SkippedSafety)
+	}
+	if parent > 1 {
+		res, err = sysprims.KillMatching(&sysprims.ProcessFilter{PIDIn: []uint32{parent}}, sysprims.SIGTERM,
+			&sysprims.KillMatchingOptions{DryRun: true, AllowAncestors: true})
+		if err != nil {
+			t.Fatalf("KillMatching(parent, AllowAncestors) failed: %v", err)
+		}
+		if len(res.Targets) != 1 || res.Targets[0] != parent {
+			t.Errorf("KillMatching(parent, AllowAncestors) targets = %v", res.Targets)
+		}
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	var pids []uint32
+	var reaped []chan struct{}
+	for i := 0; i < 2; i++ {
+		cmd := exec.Command("sleep", "30")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start child: %v", err)
+		}
+		done := make(chan struct{})
+		go func() {
+			_ = cmd.Wait()
+			close(done)
+		}()
+		defer func() {
+			_ = cmd.Process.Kill()
+			<-done
+		}()
+		pids = append(pids, uint32(cmd.Process.Pid))
+		reaped = append(reaped, done)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	name := "sleep"
+	filter := &sysprims.ProcessFilter{NameEquals: &name, PPIDIn: []uint32{self}}
+	res, err = sysprims.KillMatching(filter, sysprims.SIGTERM, dry)
+	if err != nil {
+		t.Fatalf("KillMatching(dry run) failed: %v", err)
+	}
+	if !reflect.DeepEqual(res.Targets, pids) || len(res.Succeeded) != 0 {
+		t.Errorf("dry run = targets %v, succeeded %v; want targets %v", res.Targets, res.Succeeded, pids)
+	}
+	for _, pid := range pids {
+		if ok, err := sysprims.CanSignal(pid); !ok || err != nil {
+			t.Errorf("child %d gone after dry run: %v", pid, err)
+		}
+	}
+
+	res, err = sysprims.KillMatching(filter, sysprims.SIGTERM, nil)
+	if err != nil {
+		t.Fatalf("KillMatching failed: %v", err)
+	}
+	if !reflect.DeepEqual(res.Succeeded, pids) || len(res.Failed) != 0 || res.Targets != nil {
+		t.Errorf("KillMatching = %+v; want succeeded %v", res, pids)
+	}
+	for i, done := range reaped {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Errorf("child %d still running after KillMatching", pids[i])
+		}
+	}
+}
+
+// TestKillByName verifies KillByName signals a distinctly named child in
+// exact and substring modes and reports ErrNotFound once nothing matches.
+func TestKillByName(t *testing.T) {
+	var sErr *sysprims.Error
+	if _, err := sysprims.KillByName("", false, sysprims.SIGTERM); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("KillByName(\"\") expected ErrInvalidArgument, got %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+	sleepPath, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skipf("sleep not found: %v", err)
+	}
+	data, err := os.ReadFile(sleepPath)
+	if err != nil {
+		t.Skipf("cannot read %s: %v", sleepPath, err)
+	}
+	// Process names are truncated to 15 bytes on Linux.
+	name := "spkbn" + strconv.Itoa(os.Getpid()%100000)
+	exe := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(exe, data, 0o755); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	for _, exact := range []bool{true, false} {
+		cmd := exec.Command(exe, "30")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start child: %v", err)
+		}
+		done := make(chan struct{})
+		go func() {
+			_ = cmd.Wait()
+			close(done)
+		}()
+		pid := uint32(cmd.Process.Pid)
+		for i := 0; ; i++ {
+			if info, err := sysprims.ProcessGet(pid); err == nil && info.Name == name {
+				break
+			}
+			if i == 100 {
+				_ = cmd.Process.Kill()
+				<-done
+				t.Skip("child did not start")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		pattern := name
+		if !exact {
+			pattern = strings.ToUpper(name[1:])
+		}
+		res, err := sysprims.KillByName(pattern, exact, sysprims.SIGTERM)
+		if err != nil {
+			_ = cmd.Process.Kill()
+		} else if len(res.Succeeded) != 1 || res.Succeeded[0] != pid {
+			t.Errorf("KillByName(%q, %v) = %+v, want [%d] signaled", pattern, exact, res, pid)
+		}
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			_ = cmd.Process.Kill()
+			<-done
+			t.Errorf("child still running after KillByName(%q, %v)", pattern, exact)
+		}
+		if err != nil {
+			t.Fatalf("KillByName(%q, %v) failed: %v", pattern, exact, err)
+		}
+	}
+
+	if _, err := sysprims.KillByName(name, true, sysprims.SIGTERM); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("KillByName(%q) with no match expected ErrNotFound, got %v", name, err)
+	}
+}
+
+// TestProcessList verifies that ProcessList returns processes.
+func TestProcessList(t *testing.T) {
+	snapshot, err := sysprims.ProcessList(nil)
+	if err != nil {
+		t.Fatalf("ProcessList(nil) failed: %v", err)
+	}
+
+	if len(snapshot.Processes) == 0 {
+		t.Error("ProcessList returned empty list")
+	}
+
+	if snapshot.SchemaID == "" {
+		t.Error("ProcessList returned empty schema_id")
+	}
+
+	t.Logf("Found %d processes, schema_id: %s", len(snapshot.Processes), snapshot.SchemaID)
+}
+
+// TestProcessSnapshotSchemaMatches verifies a snapshot carries the exported
+// schema ID and that SchemaMatches rejects other versions.
+func TestProcessSnapshotSchemaMatches(t *testing.T) {
+	snapshot, err := sysprims.ProcessList(nil)
+	if err != nil {
+		t.Fatalf("ProcessList failed: %v", err)
+	}
+	if snapshot.SchemaID != sysprims.SchemaProcessSnapshotV1 || !snapshot.SchemaMatches() {
+		t.Errorf("schema_id = %q, want %q", snapshot.SchemaID, sysprims.SchemaProcessSnapshotV1)
+	}
+	sampled := sysprims.ProcessSnapshot{SchemaID: sysprims.SchemaProcessSnapshotSampledV1}
+	if !sampled.SchemaMatches() {
+		t.Errorf("SchemaMatches() rejected %q", sampled.SchemaID)
+	}
+	drifted := sysprims.ProcessSnapshot{SchemaID: "https://schemas.3leaps.dev/sysprims/process/v2.0.0/process-info.schema.json"}
+	if drifted.SchemaMatches() {
+		t.Errorf("SchemaMatches() accepted %q", drifted.SchemaID)
+	}
+}
+
+// TestProcessListWithFilter verifies filtering works.
+func TestProcessListWithFilter(t *testing.T) {
+	// Filter by current process name
+	currentName := "sysprims" // Test binary name will contain this
+
+	snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{
+		NameContains: &currentName,
+	})
+	if err != nil {
+		t.Fatalf("ProcessList with filter failed: %v", err)
+	}
+
+	// Should find at least one process (might not match depending on test binary name)
+	t.Logf("Found %d processes matching %q", len(snapshot.Processes), currentName)
+}
+
+func TestProcessListWithOptions(t *testing.T) {
+	pid := uint32(os.Getpid())
+	snapshot, err := sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{PIDIn: []uint32{pid}}, &sysprims.ProcessOptions{
+		IncludeThreads: true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessListWithOptions failed: %v", err)
+	}
+
+	if len(snapshot.Processes) == 0 {
+		t.Fatal("ProcessListWithOptions returned empty process list")
+	}
+
+	if snapshot.Processes[0].PID != pid {
+		t.Fatalf("ProcessListWithOptions returned wrong pid: got %d, expected %d", snapshot.Processes[0].PID, pid)
+	}
+}
+
+// TestProcessListMonitorCPU verifies sampled CPU reflects current load.
+func TestProcessListMonitorCPU(t *testing.T) {
+	pid := uint32(os.Getpid())
+
+	before, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+
+	defer startBusyLoop()()
+
+	snapshot, err := sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{PIDIn: []uint32{pid}}, &sysprims.ProcessOptions{
+		CpuMode:        sysprims.CpuModeMonitor,
+		SampleDuration: 500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("ProcessListWithOptions(monitor) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 || snapshot.Processes[0].PID != pid {
+		t.Fatalf("ProcessListWithOptions(monitor) returned %d processes, expected self", len(snapshot.Processes))
+	}
+	if snapshot.SchemaID != sysprims.SchemaProcessSnapshotSampledV1 || !snapshot.SchemaMatches() {
+		t.Errorf("Expected sampled schema_id, got %q", snapshot.SchemaID)
+	}
+
+	sampled := snapshot.Processes[0].CPUPercent
+	if sampled <= before.CPUPercent {
+		t.Errorf("Sampled CPU %.2f%% should exceed lifetime CPU %.2f%%", sampled, before.CPUPercent)
+	}
+	t.Logf("CPU lifetime=%.2f%% sampled=%.2f%%", before.CPUPercent, sampled)
+}
+
+// TestProcessListMonitorCPUInvalidDuration verifies the sample bound is enforced.
+func TestProcessListMonitorCPUInvalidDuration(t *testing.T) {
+	for _, d := range []time.Duration{-time.Second, 11 * time.Second} {
+		_, err := sysprims.ProcessListWithOptions(nil, &sysprims.ProcessOptions{
+			CpuMode:        sysprims.CpuModeMonitor,
+			SampleDuration: d,
+		})
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("SampleDuration=%v: expected ErrInvalidArgument, got %v", d, err)
+		}
+	}
+}
+
+// TestProcessListOmitFields verifies omitted fields are cleared after filtering.
+func TestProcessListOmitFields(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+
+	filter := &sysprims.ProcessFilter{PIDIn: []uint32{pid}, ExePathEquals: info.ExePath}
+	if info.ExePath == nil {
+		filter.ExePathEquals = nil
+	}
+	opts := &sysprims.ProcessOptions{OmitCmdline: true, OmitExePath: true, OmitUser: true}
+
+	snapshot, err := sysprims.ProcessListWithOptions(filter, opts)
+	if err != nil {
+		t.Fatalf("ProcessListWithOptions(omit) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 {
+		t.Fatalf("ProcessListWithOptions(omit) returned %d processes, expected 1", len(snapshot.Processes))
+	}
+	p := snapshot.Processes[0]
+	if p.Cmdline != nil || p.ExePath != nil || p.User != nil {
+		t.Errorf("omitted fields present: cmdline=%v exe=%v user=%v", p.Cmdline, p.ExePath, p.User)
+	}
+	if p.Name == "" || p.MemoryKB == 0 {
+		t.Errorf("non-omitted fields missing: name=%q memory_kb=%d", p.Name, p.MemoryKB)
+	}
+
+	got, err := sysprims.ProcessGetWithOptions(pid, opts)
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions(omit) failed: %v", err)
+	}
+	if got.Cmdline != nil || got.ExePath != nil || got.User != nil {
+		t.Error("ProcessGetWithOptions did not omit fields")
+	}
+}
+
+// BenchmarkProcessListOmit compares listing with and without omitted fields.
+func BenchmarkProcessListOmit(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts *sysprims.ProcessOptions
+	}{
+		{"full", nil},
+		{"omit", &sysprims.ProcessOptions{OmitCmdline: true, OmitExePath: true, OmitUser: true}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := sysprims.ProcessListWithOptions(nil, bc.opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestFindFirst verifies the match and no-match contracts.
+func TestFindFirst(t *testing.T) {
+	pid := uint32(os.Getpid())
+
+	info, err := sysprims.FindFirst(&sysprims.ProcessFilter{PIDIn: []uint32{pid}})
+	if err != nil {
+		t.Fatalf("FindFirst(self) failed: %v", err)
+	}
+	if info.PID != pid {
+		t.Errorf("FindFirst returned wrong PID: got %d, expected %d", info.PID, pid)
+	}
+
+	name := "sysprims-no-such-process-name"
+	_, err = sysprims.FindFirst(&sysprims.ProcessFilter{NameEquals: &name})
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("FindFirst(no match) expected ErrNotFound, got %v", err)
+	}
+
+	all, err := sysprims.FindAll(&sysprims.ProcessFilter{NameEquals: &name})
+	if err != nil {
+		t.Fatalf("FindAll(no match) failed: %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("FindAll(no match) returned %d processes", len(all))
+	}
+}
+
+// TestTopProcesses verifies ordering, tie-breaking, and argument validation.
+func TestTopProcesses(t *testing.T) {
+	top, err := sysprims.TopProcesses(5, sysprims.SortByMemory, nil)
+	if err != nil {
+		t.Fatalf("TopProcesses(memory) failed: %v", err)
+	}
+	if len(top) == 0 || len(top) > 5 {
+		t.Fatalf("TopProcesses(5) returned %d processes", len(top))
+	}
+	for i := 1; i < len(top); i++ {
+		prev, cur := top[i-1], top[i]
+		if prev.MemoryKB < cur.MemoryKB || (prev.MemoryKB == cur.MemoryKB && prev.PID > cur.PID) {
+			t.Errorf("TopProcesses not ordered at %d: (%d, %d KB) before (%d, %d KB)", i, prev.PID, prev.MemoryKB, cur.PID, cur.MemoryKB)
+		}
+	}
+
+	threads, err := sysprims.TopProcesses(3, sysprims.SortByThreads, nil)
+	if err != nil {
+		t.Fatalf("TopProcesses(threads) failed: %v", err)
+	}
+	for _, p := range threads {
+		if p.ThreadCount == nil {
+			t.Logf("PID %d has no thread_count (best-effort)", p.PID)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		fds, err := sysprims.TopProcesses(1, sysprims.SortByFds, nil)
+		if err != nil {
+			t.Fatalf("TopProcesses(fds) failed: %v", err)
+		}
+		if len(fds) != 1 {
+			t.Errorf("TopProcesses(1, fds) returned %d processes", len(fds))
+		}
+	}
+
+	all, err := sysprims.ProcessList(nil)
+	if err != nil {
+		t.Fatalf("ProcessList failed: %v", err)
+	}
+	many, err := sysprims.TopProcesses(len(all.Processes)+1000, sysprims.SortByCPU, nil)
+	if err != nil {
+		t.Fatalf("TopProcesses(large n) failed: %v", err)
+	}
+	if len(many) > len(all.Processes)+100 {
+		t.Errorf("TopProcesses(large n) returned %d processes for a system with ~%d", len(many), len(all.Processes))
+	}
+
+	for _, tc := range []struct {
+		n  int
+		by sysprims.SortKey
+	}{{0, sysprims.SortByCPU}, {-1, sysprims.SortByCPU}, {1, "bogus"}} {
+		_, err := sysprims.TopProcesses(tc.n, tc.by, nil)
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("TopProcesses(%d, %q) expected ErrInvalidArgument, got %v", tc.n, tc.by, err)
+		}
+	}
+}
+
+// testSnapshot returns a small fixed snapshot for ProcessSnapshot helper tests.
+func testSnapshot() *sysprims.ProcessSnapshot {
+	threads := func(n uint32) *uint32 { return &n }
+	return &sysprims.ProcessSnapshot{Processes: []sysprims.ProcessInfo{
+		{PID: 30, Name: "c", CPUPercent: 1.5, MemoryKB: 300, ThreadCount: threads(2)},
+		{PID: 10, Name: "a", CPUPercent: 4.0, MemoryKB: 100},
+		{PID: 20, Name: "b", CPUPercent: 1.5, MemoryKB: 200, ThreadCount: threads(8)},
+	}}
+}
+
+// TestProcessSnapshotSortBy verifies ordering by each key in both directions.
+func TestProcessSnapshotSortBy(t *testing.T) {
+	tests := []struct {
+		key  sysprims.SortKey
+		desc bool
+		want []uint32
+	}{
+		{sysprims.SortByCPU, true, []uint32{10, 20, 30}},
+		{sysprims.SortByCPU, false, []uint32{20, 30, 10}},
+		{sysprims.SortByMemory, true, []uint32{30, 20, 10}},
+		{sysprims.SortByMemory, false, []uint32{10, 20, 30}},
+		{sysprims.SortByThreads, true, []uint32{20, 30, 10}},
+		{sysprims.SortByThreads, false, []uint32{10, 30, 20}},
+		{"bogus", true, []uint32{30, 10, 20}},
+	}
+	for _, tc := range tests {
+		s := testSnapshot()
+		s.SortBy(tc.key, tc.desc)
+		var got []uint32
+		for _, p := range s.Processes {
+			got = append(got, p.PID)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("SortBy(%q, %v) = %v, want %v", tc.key, tc.desc, got, tc.want)
+		}
+	}
+}
+
+// TestProcessSnapshotByPID verifies lookup, caching, invalidation, and
+// concurrent use.
+func TestProcessSnapshotByPID(t *testing.T) {
+	s := testSnapshot()
+	m := s.ByPID()
+	if len(m) != 3 || m[20] == nil || m[20].Name != "b" || m[99] != nil {
+		t.Fatalf("ByPID() = %v", m)
+	}
+
+	m[20].Name = "renamed"
+	if s.Processes[2].Name != "renamed" {
+		t.Error("ByPID() pointer does not alias the snapshot element")
+	}
+
+	s.SortBy(sysprims.SortByMemory, false)
+	for _, pid := range []uint32{10, 20, 30} {
+		if p := s.ByPID()[pid]; p == nil || p.PID != pid {
+			t.Errorf("ByPID()[%d] after SortBy = %+v", pid, p)
+		}
+	}
+
+	s.Processes = append(s.Processes, sysprims.ProcessInfo{PID: 40})
+	if s.ByPID()[40] == nil {
+		t.Error("ByPID() not rebuilt after Processes grew")
+	}
+
+	empty := &sysprims.ProcessSnapshot{}
+	if m := empty.ByPID(); m == nil || len(m) != 0 {
+		t.Errorf("ByPID() on empty snapshot = %v", m)
+	}
+
+	// Concurrent first uses share one index (go test -race checks the
+	// cache).
+	fresh := testSnapshot()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if fresh.ByPID()[20] == nil {
+				t.Error("concurrent ByPID() missed pid 20")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestProcessSnapshotFilterTotals verifies Filter and the aggregate helpers.
+func TestProcessSnapshotFilterTotals(t *testing.T) {
+	tests := []struct {
+		name    string
+		pred    func(*sysprims.ProcessInfo) bool
+		wantPID []uint32
+	}{
+		{"all", func(*sysprims.ProcessInfo) bool { return true }, []uint32{30, 10, 20}},
+		{"none", func(*sysprims.ProcessInfo) bool { return false }, nil},
+		{"memory>150", func(p *sysprims.ProcessInfo) bool { return p.MemoryKB > 150 }, []uint32{30, 20}},
+	}
+	for _, tc := range tests {
+		s := testSnapshot()
+		var got []uint32
+		for _, p := range s.Filter(tc.pred) {
+			got = append(got, p.PID)
+		}
+		if !reflect.DeepEqual(got, tc.wantPID) {
+			t.Errorf("Filter(%s) = %v, want %v", tc.name, got, tc.wantPID)
+		}
+		if len(s.Processes) != 3 {
+			t.Errorf("Filter(%s) modified the snapshot", tc.name)
+		}
+	}
+
+	s := testSnapshot()
+	if got := s.TotalMemoryKB(); got != 600 {
+		t.Errorf("TotalMemoryKB() = %d, want 600", got)
+	}
+	if got := s.TotalCPUPercent(); got != 7.0 {
+		t.Errorf("TotalCPUPercent() = %v, want 7", got)
+	}
+	empty := &sysprims.ProcessSnapshot{}
+	if empty.TotalMemoryKB() != 0 || empty.TotalCPUPercent() != 0 {
+		t.Error("totals on empty snapshot should be 0")
+	}
+}
+
+// TestProcessSnapshotWriteCSV verifies column selection, empty cells for unset
+// fields, quoting, and rejection of unknown columns.
+func TestProcessSnapshotWriteCSV(t *testing.T) {
+	s := testSnapshot()
+	s.Processes[1].Cmdline = []string{"a", "--x=1,2"}
+	uid, swap := uint32(1000), uint64(7)
+	s.Processes[0].UID = &uid
+	s.Processes[0].Memory = &sysprims.MemoryDetail{RSSKB: 5, VSZKB: 9, SwapKB: &swap}
+
+	tests := []struct {
+		name    string
+		columns []string
+		want    string
+	}{
+		{"selected", []string{"pid", "thread_count", "cpu_percent", "cmdline"},
+			"pid,thread_count,cpu_percent,cmdline\n30,2,1.5,\n10,,4,\"a --x=1,2\"\n20,8,1.5,\n"},
+		{"default", nil,
+			"pid,ppid,name,user,state,cpu_percent,memory_kb,elapsed_seconds\n" +
+				"30,0,c,,,1.5,300,\n10,0,a,,,4,100,\n20,0,b,,,1.5,200,\n"},
+		{"extended", []string{"pid", "uid", "memory.rss_kb", "memory.swap_kb", "memory.shared_kb"},
+			"pid,uid,memory.rss_kb,memory.swap_kb,memory.shared_kb\n30,1000,5,7,\n10,,,,\n20,,,,\n"},
+	}
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := s.WriteCSV(&buf, tc.columns); err != nil {
+			t.Fatalf("WriteCSV(%s) failed: %v", tc.name, err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("WriteCSV(%s) =\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+	}
+
+	for _, bad := range [][]string{{}, {"pid", "env"}, {"PID"}, {"memory"}} {
+		var sErr *sysprims.Error
+		if err := s.WriteCSV(&bytes.Buffer{}, bad); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("WriteCSV(%v) expected ErrInvalidArgument, got %v", bad, err)
+		}
+	}
+}
+
+// TestProcessSnapshotFormat verifies column selection, alignment, sorting
+// without reordering the snapshot, and the row limit.
+func TestProcessSnapshotFormat(t *testing.T) {
+	s := testSnapshot()
+	got := s.Format(sysprims.FormatOptions{
+		Columns:    []string{"pid", "name", "cpu_percent", "thread_count", "bogus"},
+		SortBy:     sysprims.SortByMemory,
+		Descending: true,
+		Limit:      2,
+	})
+	want := "PID  NAME  CPU_PERCENT  THREAD_COUNT\n" +
+		"30   c     1.5          2\n" +
+		"20   b     1.5          8\n"
+	if got != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+	}
+	if s.Processes[0].PID != 30 || s.Processes[1].PID != 10 {
+		t.Error("Format reordered the snapshot")
+	}
+	if got := s.Format(sysprims.FormatOptions{Columns: []string{"bogus"}}); got != "" {
+		t.Errorf("Format(unknown columns) = %q, want empty", got)
+	}
+
+	state := "sleeping"
+	p := sysprims.ProcessInfo{PID: 7, PPID: 1, Name: "svc", State: &state, CPUPercent: 2.34, MemoryKB: 64}
+	if got, want := p.String(), "pid 7 (svc) ppid 1 sleeping cpu 2.3% mem 64KB"; got != want {
+		t.Errorf("ProcessInfo.String() = %q, want %q", got, want)
+	}
+
+	addr, listen, pid := "::1", "listen", uint32(42)
+	b := sysprims.PortBinding{Protocol: sysprims.ProtocolTCP, LocalAddr: &addr, LocalPort: 8080, State: &listen, PID: &pid}
+	if got, want := b.String(), "tcp [::1]:8080 listen pid 42"; got != want {
+		t.Errorf("PortBinding.String() = %q, want %q", got, want)
+	}
+	if got, want := (sysprims.PortBinding{Protocol: sysprims.ProtocolUDP, LocalPort: 53}).String(), "udp *:53"; got != want {
+		t.Errorf("PortBinding.String() = %q, want %q", got, want)
+	}
+}
+
+// TestProcessInfoTimeAccessors verifies StartTime/Elapsed round-trips and absent fields.
+func TestProcessInfoTimeAccessors(t *testing.T) {
+	startMS := uint64(1700000000123)
+	elapsed := uint64(42)
+	info := sysprims.ProcessInfo{StartTimeUnixMS: &startMS, ElapsedSeconds: &elapsed}
+
+	start, ok := info.StartTime()
+	if !ok || uint64(start.UnixMilli()) != startMS {
+		t.Errorf("StartTime() = %v, %v; expected %d ms", start, ok, startMS)
+	}
+	d, ok := info.Elapsed()
+	if !ok || d != 42*time.Second {
+		t.Errorf("Elapsed() = %v, %v; expected 42s", d, ok)
+	}
+
+	var empty sysprims.ProcessInfo
+	if _, ok := empty.StartTime(); ok {
+		t.Error("StartTime() reported ok for absent field")
+	}
+	if _, ok := empty.Elapsed(); ok {
+		t.Error("Elapsed() reported ok for absent field")
+	}
+}
+
+// TestSnapshotTime verifies timestamp parsing with and without fractional seconds.
+func TestSnapshotTime(t *testing.T) {
+	want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, ts := range []string{"2026-01-02T03:04:05Z", "2026-01-02T03:04:05.000000000Z", "2026-01-02T03:04:05.5Z"} {
+		snap := sysprims.ProcessSnapshot{Timestamp: ts}
+		got, err := snap.Time()
+		if err != nil {
+			t.Errorf("Time(%q) failed: %v", ts, err)
+			continue
+		}
+		if got.Truncate(time.Second) != want {
+			t.Errorf("Time(%q) = %v, expected %v", ts, got, want)
+		}
+	}
+
+	var empty sysprims.FdSnapshot
+	_, err := empty.Time()
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("Time() on absent timestamp expected ErrNotFound, got %v", err)
+	}
+
+	bad := sysprims.WaitPidResult{Timestamp: "yesterday"}
+	if _, err := bad.Time(); err == nil {
+		t.Error("Time() on malformed timestamp should fail")
+	}
+
+	live, err := sysprims.ProcessList(nil)
+	if err != nil {
+		t.Fatalf("ProcessList failed: %v", err)
+	}
+	liveTime, err := live.Time()
+	if err != nil {
+		t.Fatalf("Time() on live snapshot failed: %v", err)
+	}
+	if skew := time.Since(liveTime); skew < -time.Minute || skew > time.Minute {
+		t.Errorf("live snapshot time %v is far from now", liveTime)
+	}
+}
+
+// TestIdentityOfSelf verifies identity construction and liveness for self.
+func TestIdentityOfSelf(t *testing.T) {
+	pid := uint32(os.Getpid())
+
+	id, err := sysprims.IdentityOf(pid)
+	if err != nil {
+		t.Fatalf("IdentityOf(%d) failed: %v", pid, err)
+	}
+	if id.PID != pid || id.StartTimeUnixMS == 0 {
+		t.Fatalf("IdentityOf(%d) = %+v", pid, id)
+	}
+
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+	fromInfo, ok := info.Identity()
+	if !ok || fromInfo != id {
+		t.Errorf("ProcessInfo.Identity() = %+v, %v; expected %+v", fromInfo, ok, id)
+	}
+
+	running, err := id.StillRunning()
+	if err != nil || !running {
+		t.Errorf("StillRunning() for self = %v, %v; expected true", running, err)
+	}
+}
+
+// TestIdentityStillRunningReusedPID simulates PID reuse with mismatched start times.
+func TestIdentityStillRunningReusedPID(t *testing.T) {
+	id, err := sysprims.IdentityOf(uint32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("IdentityOf(self) failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		shiftMS int64
+		running bool
+	}{
+		{"exact", 0, true},
+		{"rounding within tolerance", 100, true},
+		{"older process", -5000, false},
+		{"newer process", 5000, false},
+		{"one second apart", 1000, false},
+	}
+	for _, tt := range tests {
+		probe := sysprims.ProcessIdentity{PID: id.PID, StartTimeUnixMS: uint64(int64(id.StartTimeUnixMS) + tt.shiftMS)}
+		running, err := probe.StillRunning()
+		if err != nil {
+			t.Errorf("%s: StillRunning() failed: %v", tt.name, err)
+			continue
+		}
+		if running != tt.running {
+			t.Errorf("%s: StillRunning() = %v, expected %v", tt.name, running, tt.running)
+		}
+	}
+}
+
+// TestIdentityStillRunningExited verifies exited and missing processes report false.
+func TestIdentityStillRunningExited(t *testing.T) {
+	missing := sysprims.ProcessIdentity{PID: 99999999, StartTimeUnixMS: 1}
+	running, err := missing.StillRunning()
+	if err != nil || running {
+		t.Errorf("StillRunning() for missing pid = %v, %v; expected false, nil", running, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	id, err := sysprims.IdentityOf(uint32(cmd.Process.Pid))
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		t.Fatalf("IdentityOf(child) failed: %v", err)
+	}
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+
+	running, err = id.StillRunning()
+	if err != nil || running {
+		t.Errorf("StillRunning() for reaped child = %v, %v; expected false, nil", running, err)
+	}
+}
+
+// TestKillIdentityStale verifies a reused PID is never signaled.
+func TestKillIdentityStale(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	child := uint32(cmd.Process.Pid)
+
+	id, err := sysprims.IdentityOf(child)
+	if err != nil {
+		t.Fatalf("IdentityOf(child) failed: %v", err)
+	}
+	stale := sysprims.ProcessIdentity{PID: child, StartTimeUnixMS: id.StartTimeUnixMS - 60_000}
+
+	calls := map[string]func() error{
+		"KillIdentity":      func() error { return sysprims.KillIdentity(stale, sysprims.SIGKILL) },
+		"TerminateIdentity": func() error { return sysprims.TerminateIdentity(stale) },
+		"ForceKillIdentity": func() error { return sysprims.ForceKillIdentity(stale) },
+	}
+	for name, call := range calls {
+		err := call()
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound || !strings.Contains(sErr.Message, "stale") {
+			t.Errorf("%s(stale) expected stale ErrNotFound, got %v", name, err)
+		}
+	}
+
+	if running, err := id.StillRunning(); err != nil || !running {
+		t.Fatalf("child should survive stale kills: running=%v err=%v", running, err)
+	}
+
+	if err := sysprims.ForceKillIdentity(id); err != nil {
+		t.Fatalf("ForceKillIdentity(current) failed: %v", err)
+	}
+	_ = cmd.Wait()
+	if running, _ := id.StillRunning(); running {
+		t.Error("child still running after ForceKillIdentity")
+	}
+}
+
+// TestProcessStateNormalization verifies canonical State values and RawState.
+func TestProcessStateNormalization(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"running", sysprims.StateRunning},
+		{"R", sysprims.StateRunning},
+		{"sleeping", sysprims.StateSleeping},
+		{"D", sysprims.StateSleeping},
+		{"stopped", sysprims.StateStopped},
+		{"zombie", sysprims.StateZombie},
+		{"Z", sysprims.StateZombie},
+		{"defunct", sysprims.StateZombie},
+		{"bogus", sysprims.StateUnknown},
+	}
+	for _, tc := range tests {
+		var info sysprims.ProcessInfo
+		if err := json.Unmarshal([]byte(`{"pid":1,"state":"`+tc.raw+`"}`), &info); err != nil {
+			t.Fatalf("Unmarshal(%q) failed: %v", tc.raw, err)
+		}
+		if info.State == nil || *info.State != tc.want {
+			t.Errorf("State(%q) = %v, want %q", tc.raw, info.State, tc.want)
+		}
+		if info.RawState == nil || *info.RawState != tc.raw {
+			t.Errorf("RawState(%q) = %v", tc.raw, info.RawState)
+		}
+		if info.IsZombie() != (tc.want == sysprims.StateZombie) {
+			t.Errorf("IsZombie(%q) = %v", tc.raw, info.IsZombie())
+		}
+	}
+
+	var noState sysprims.ProcessInfo
+	if err := json.Unmarshal([]byte(`{"pid":1}`), &noState); err != nil || noState.State != nil || noState.RawState != nil {
+		t.Errorf("missing state decoded as %v/%v (err %v)", noState.State, noState.RawState, err)
+	}
+}
+
+// TestZombiesOf verifies that an unreaped child is reported as a zombie.
+func TestZombiesOf(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no zombie state on Windows")
+	}
+
+	// The shell forks a short-lived child, then execs into sleep, which
+	// never reaps it.
+	cmd := exec.Command("sh", "-c", "sleep 0 & exec sleep 30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sh: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	parent := uint32(cmd.Process.Pid)
+
+	var zombies []sysprims.ProcessInfo
+	for i := 0; i < 100 && len(zombies) == 0; i++ {
+		var err error
+		zombies, err = sysprims.ZombiesOf(parent)
+		if err != nil {
+			t.Fatalf("ZombiesOf(%d) failed: %v", parent, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if len(zombies) != 1 {
+		t.Fatalf("ZombiesOf(%d) = %d zombies, want 1", parent, len(zombies))
+	}
+	if z := zombies[0]; z.PPID != parent || !z.IsZombie() || z.RawState == nil {
+		t.Errorf("zombie = %+v", z)
+	}
+
+	self, err := sysprims.ZombiesOf(uint32(os.Getpid()))
+	if err != nil || len(self) != 0 {
+		t.Errorf("ZombiesOf(self) = %v, %v; want none", self, err)
+	}
+}
+
+// TestOSProcess verifies os.Process interop, including stale and missing PIDs.
+func TestOSProcess(t *testing.T) {
+	self, err := sysprims.OSProcess(uint32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("OSProcess(self) failed: %v", err)
+	}
+	if self.Pid != os.Getpid() {
+		t.Errorf("OSProcess(self).Pid = %d, want %d", self.Pid, os.Getpid())
+	}
+	_ = self.Release()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", "ping -n 30 127.0.0.1 >NUL")
+	} else {
+		cmd = exec.Command("sleep", "30")
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start child: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	child := uint32(cmd.Process.Pid)
+
+	info, err := sysprims.ProcessGet(child)
+	if err != nil {
+		t.Fatalf("ProcessGet(child) failed: %v", err)
+	}
+	if info.StartTimeUnixMS != nil {
+		stale := *info
+		staleStart := *info.StartTimeUnixMS - 60_000
+		stale.StartTimeUnixMS = &staleStart
+		var sErr *sysprims.Error
+		if _, err := stale.OSProcess(); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+			t.Errorf("stale OSProcess expected ErrNotFound, got %v", err)
+		}
+	}
+
+	proc, err := info.OSProcess()
+	if err != nil {
+		t.Fatalf("OSProcess(child) failed: %v", err)
+	}
+	if err := proc.Kill(); err != nil {
+		t.Errorf("os.Process.Kill failed: %v", err)
+	}
+	_ = proc.Release()
+	_ = cmd.Wait()
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.OSProcess(child); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("OSProcess(exited child) expected ErrNotFound, got %v", err)
+	}
+	if _, err := sysprims.OSProcess(0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("OSProcess(0) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestIdentityInvalid verifies argument validation and absent start times.
+func TestIdentityInvalid(t *testing.T) {
+	_, err := sysprims.IdentityOf(0)
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("IdentityOf(0) expected ErrInvalidArgument, got %v", err)
+	}
+
+	var info sysprims.ProcessInfo
+	if _, ok := info.Identity(); ok {
+		t.Error("Identity() reported ok without start time")
+	}
+}
+
+// TestProcessListExePathFilter verifies Go-side exe path matching.
+func TestProcessListExePathFilter(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+	if info.ExePath == nil {
+		t.Skip("exe_path not available for self")
+	}
+
+	snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{PIDIn: []uint32{pid}, ExePathEquals: info.ExePath})
+	if err != nil {
+		t.Fatalf("ProcessList(ExePathEquals) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 {
+		t.Errorf("ProcessList(ExePathEquals self) returned %d processes, expected 1", len(snapshot.Processes))
+	}
+
+	dir := filepath.Dir(*info.ExePath)
+	snapshot, err = sysprims.ProcessList(&sysprims.ProcessFilter{PIDIn: []uint32{pid}, ExePathContains: &dir})
+	if err != nil {
+		t.Fatalf("ProcessList(ExePathContains) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 {
+		t.Errorf("ProcessList(ExePathContains dir) returned %d processes, expected 1", len(snapshot.Processes))
+	}
+
+	missing := "/sysprims/no/such/install/dir/"
+	snapshot, err = sysprims.ProcessList(&sysprims.ProcessFilter{ExePathContains: &missing})
+	if err != nil {
+		t.Fatalf("ProcessList(ExePathContains missing) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 0 {
+		t.Errorf("ProcessList(ExePathContains missing) returned %d processes", len(snapshot.Processes))
+	}
+}
+
+// TestProcessListNameRegex verifies NameRegex matching, anchoring, and
+// case folding, and that an invalid pattern fails up front.
+func TestProcessListNameRegex(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+	if len(info.Name) < 2 {
+		t.Skipf("self name %q too short", info.Name)
+	}
+	name := regexp.QuoteMeta(info.Name)
+	prefix := regexp.QuoteMeta(info.Name[:len(info.Name)-1])
+
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{"^" + name + "$", 1},
+		{prefix, 1},
+		{"^" + prefix + "$", 0},
+		{"(?i)^" + strings.ToUpper(name) + "$", 1},
+	}
+	for _, tt := range tests {
+		pattern := tt.pattern
+		snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{PIDIn: []uint32{pid}, NameRegex: &pattern})
+		if err != nil {
+			t.Fatalf("ProcessList(NameRegex %q) failed: %v", pattern, err)
+		}
+		if len(snapshot.Processes) != tt.want {
+			t.Errorf("ProcessList(NameRegex %q) returned %d processes, expected %d", pattern, len(snapshot.Processes), tt.want)
+		}
+	}
+
+	invalid := "worker-[0-9"
+	filter := &sysprims.ProcessFilter{NameRegex: &invalid}
+	var sErr *sysprims.Error
+	if _, err := sysprims.ProcessList(filter); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ProcessList(invalid NameRegex) expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.Descendants(info.PPID, 1, filter); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("Descendants(invalid NameRegex) expected ErrInvalidArgument, got %v", err)
+	}
+	maxTotal := uint32(10)
+	if _, err := sysprims.DescendantsWithOptions(info.PPID, &sysprims.DescendantsOptions{Filter: filter, MaxTotal: &maxTotal}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("DescendantsWithOptions(invalid NameRegex) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestProcessListUserInPPIDIn verifies OR-within-field, AND-across-fields semantics.
+func TestProcessListUserInPPIDIn(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+
+	snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{
+		PIDIn:  []uint32{pid},
+		PPIDIn: []uint32{99999999, info.PPID},
+	})
+	if err != nil {
+		t.Fatalf("ProcessList(PPIDIn) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 {
+		t.Errorf("ProcessList(PPIDIn with parent) returned %d processes, expected 1", len(snapshot.Processes))
+	}
+
+	snapshot, err = sysprims.ProcessList(&sysprims.ProcessFilter{PPIDIn: []uint32{info.PPID}})
+	if err != nil {
+		t.Fatalf("ProcessList(PPIDIn) failed: %v", err)
+	}
+	for _, p := range snapshot.Processes {
+		if p.PPID != info.PPID {
+			t.Errorf("ProcessList(PPIDIn) returned pid %d with ppid %d", p.PID, p.PPID)
+		}
+	}
+
+	if info.User == nil {
+		t.Skip("user not available for self")
+	}
+	snapshot, err = sysprims.ProcessList(&sysprims.ProcessFilter{
+		PIDIn:  []uint32{pid},
+		UserIn: []string{"sysprims-no-such-user", *info.User},
+	})
+	if err != nil {
+		t.Fatalf("ProcessList(UserIn) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 {
+		t.Errorf("ProcessList(UserIn with self user) returned %d processes, expected 1", len(snapshot.Processes))
+	}
+
+	snapshot, err = sysprims.ProcessList(&sysprims.ProcessFilter{
+		PIDIn:  []uint32{pid},
+		UserIn: []string{*info.User},
+		PPIDIn: []uint32{99999999},
+	})
+	if err != nil {
+		t.Fatalf("ProcessList(UserIn+PPIDIn) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 0 {
+		t.Errorf("ProcessList(UserIn AND non-matching PPIDIn) returned %d processes, expected 0", len(snapshot.Processes))
+	}
+
+	desc, err := sysprims.Descendants(info.PPID, 1, &sysprims.ProcessFilter{UserIn: []string{"sysprims-no-such-user"}})
+	if err != nil {
+		t.Fatalf("Descendants(UserIn) failed: %v", err)
+	}
+	if desc.MatchedByFilter != 0 {
+		t.Errorf("Descendants(UserIn no-such-user) matched %d processes", desc.MatchedByFilter)
+	}
+}
+
+// TestProcessListStartedWindow verifies the half-open start-time window.
+func TestProcessListStartedWindow(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+	if info.StartTimeUnixMS == nil {
+		t.Skip("start time not available for self")
+	}
+	start := *info.StartTimeUnixMS
+	u64 := func(v uint64) *uint64 { return &v }
+
+	tests := []struct {
+		name          string
+		after, before *uint64
+		want          int
+	}{
+		{"inside", u64(start - 60_000), u64(start + 60_000), 1},
+		{"after is inclusive", u64(start), nil, 1},
+		{"before is exclusive", nil, u64(start), 0},
+		{"window in the past", u64(start - 120_000), u64(start - 60_000), 0},
+		{"window in the future", u64(start + 60_000), nil, 0},
+	}
+	for _, tt := range tests {
+		snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{
+			PIDIn:               []uint32{pid},
+			StartedAfterUnixMS:  tt.after,
+			StartedBeforeUnixMS: tt.before,
+		})
+		if err != nil {
+			t.Fatalf("%s: ProcessList failed: %v", tt.name, err)
+		}
+		if len(snapshot.Processes) != tt.want {
+			t.Errorf("%s: ProcessList returned %d processes, expected %d", tt.name, len(snapshot.Processes), tt.want)
+		}
+	}
+}
+
+// TestProcessListBelowBounds verifies CPUBelow and MemoryBelowKB are
+// strict upper bounds that combine with the Above filters, in lifetime and
+// monitor mode and through Descendants.
+func TestProcessListBelowBounds(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+	f64 := func(v float64) *float64 { return &v }
+	u64 := func(v uint64) *uint64 { return &v }
+	roomyKB := info.MemoryKB*4 + 1<<20
+
+	tests := []struct {
+		name   string
+		filter sysprims.ProcessFilter
+		want   int
+	}{
+		{"cpu below is exclusive of 0", sysprims.ProcessFilter{CPUBelow: f64(0)}, 0},
+		{"cpu below a high bound", sysprims.ProcessFilter{CPUBelow: f64(1e6)}, 1},
+		{"cpu range", sysprims.ProcessFilter{CPUAbove: f64(0), CPUBelow: f64(1e6)}, 1},
+		{"memory below 1 KB", sysprims.ProcessFilter{MemoryBelowKB: u64(1)}, 0},
+		{"memory below a high bound", sysprims.ProcessFilter{MemoryBelowKB: u64(roomyKB)}, 1},
+		{"memory range", sysprims.ProcessFilter{MemoryAboveKB: u64(1), MemoryBelowKB: u64(roomyKB)}, 1},
+		{"empty memory range", sysprims.ProcessFilter{MemoryAboveKB: u64(roomyKB), MemoryBelowKB: u64(roomyKB)}, 0},
+	}
+	for _, tt := range tests {
+		filter := tt.filter
+		filter.PIDIn = []uint32{pid}
+		snapshot, err := sysprims.ProcessList(&filter)
+		if err != nil {
+			t.Fatalf("%s: ProcessList failed: %v", tt.name, err)
+		}
+		if len(snapshot.Processes) != tt.want {
+			t.Errorf("%s: ProcessList returned %d processes, expected %d", tt.name, len(snapshot.Processes), tt.want)
+		}
+	}
+
+	monitor := &sysprims.ProcessOptions{CpuMode: sysprims.CpuModeMonitor, SampleDuration: 50 * time.Millisecond}
+	snapshot, err := sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{PIDIn: []uint32{pid}, CPUBelow: f64(0)}, monitor)
+	if err != nil {
+		t.Fatalf("ProcessListWithOptions(monitor, CPUBelow) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 0 {
+		t.Errorf("monitor mode CPUBelow 0 returned %d processes, expected 0", len(snapshot.Processes))
+	}
+	snapshot, err = sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{PIDIn: []uint32{pid}, CPUAbove: f64(0)}, monitor)
+	if err != nil {
+		t.Fatalf("ProcessListWithOptions(monitor, CPUAbove) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 {
+		t.Errorf("monitor mode CPUAbove 0 returned %d processes, expected 1 (inclusive)", len(snapshot.Processes))
+	}
+	start := time.Now()
+	_, err = sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{CPUAbove: f64(101)}, &sysprims.ProcessOptions{
+		CpuMode:        sysprims.CpuModeMonitor,
+		SampleDuration: 2 * time.Second,
+	})
+	if e, ok := err.(*sysprims.Error); !ok || e.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("monitor mode CPUAbove 101: expected ErrInvalidArgument, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("monitor mode CPUAbove 101 took %v; expected rejection before sampling", elapsed)
+	}
+
+	desc, err := sysprims.Descendants(info.PPID, 1, &sysprims.ProcessFilter{PIDIn: []uint32{pid}, MemoryBelowKB: u64(1)})
+	if err != nil {
+		t.Fatalf("Descendants(MemoryBelowKB) failed: %v", err)
+	}
+	if desc.MatchedByFilter != 0 {
+		t.Errorf("Descendants(MemoryBelowKB 1) matched %d processes", desc.MatchedByFilter)
+	}
+	desc, err = sysprims.Descendants(info.PPID, 1, &sysprims.ProcessFilter{PIDIn: []uint32{pid}, MemoryBelowKB: u64(roomyKB)})
+	if err != nil {
+		t.Fatalf("Descendants(MemoryBelowKB) failed: %v", err)
+	}
+	if desc.MatchedByFilter != 1 {
+		t.Errorf("Descendants(MemoryBelowKB high) matched %d processes, expected 1", desc.MatchedByFilter)
+	}
+}
+
+// TestDescendantsMaxTotalTimeout verifies that a capped or timed-out
+// traversal stops early with a flag and a warning, and that an uncapped
+// bounded traversal matches the library's.
+func TestDescendantsMaxTotalTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	var children []uint32
+	for i := 0; i < 4; i++ {
+		cmd := exec.Command("sleep", "30")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start sleep: %v", err)
+		}
+		defer func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}()
+		children = append(children, uint32(cmd.Process.Pid))
+	}
+
+	self := uint32(os.Getpid())
+	one := uint32(1)
+	full, err := sysprims.DescendantsWithOptions(self, &sysprims.DescendantsOptions{MaxLevels: &one})
+	if err != nil {
+		t.Fatalf("Descendants failed: %v", err)
+	}
+
+	two := uint32(2)
+	capped, err := sysprims.DescendantsWithOptions(self, &sysprims.DescendantsOptions{MaxLevels: &one, MaxTotal: &two})
+	if err != nil {
+		t.Fatalf("Descendants(MaxTotal 2) failed: %v", err)
+	}
+	if !capped.Truncated || capped.TimedOut || capped.TotalFound != 2 || len(capped.Warnings) == 0 {
+		t.Errorf("MaxTotal 2: truncated=%v timed_out=%v total=%d warnings=%v", capped.Truncated, capped.TimedOut, capped.TotalFound, capped.Warnings)
+	}
+
+	large := uint32(1 << 20)
+	sleepName := "sleep"
+	bounded, err := sysprims.DescendantsWithOptions(self, &sysprims.DescendantsOptions{
+		MaxLevels: &one,
+		MaxTotal:  &large,
+		Filter:    &sysprims.ProcessFilter{NameEquals: &sleepName, PIDIn: children},
+	})
+	if err != nil {
+		t.Fatalf("Descendants(MaxTotal large) failed: %v", err)
+	}
+	if bounded.Truncated || bounded.TotalFound != full.TotalFound || bounded.MatchedByFilter != len(children) {
+		t.Errorf("MaxTotal large: truncated=%v total=%d (library %d) matched=%d, want %d",
+			bounded.Truncated, bounded.TotalFound, full.TotalFound, bounded.MatchedByFilter, len(children))
+	}
+	if bounded.SchemaID != full.SchemaID {
+		t.Errorf("SchemaID = %q, library %q", bounded.SchemaID, full.SchemaID)
+	}
+
+	timed, err := sysprims.DescendantsWithOptions(self, &sysprims.DescendantsOptions{Timeout: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("Descendants(Timeout) failed: %v", err)
+	}
+	if !timed.TimedOut || timed.TotalFound != 0 || len(timed.Warnings) == 0 {
+		t.Errorf("Timeout 1ns: timed_out=%v total=%d warnings=%v", timed.TimedOut, timed.TotalFound, timed.Warnings)
+	}
+
+	// A monitor sample longer than Timeout is not taken.
+	start := time.Now()
+	sampled, err := sysprims.DescendantsWithOptions(self, &sysprims.DescendantsOptions{
+		CpuMode:        sysprims.CpuModeMonitor,
+		SampleDuration: 2 * time.Second,
+		Timeout:        100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Descendants(monitor, Timeout) failed: %v", err)
+	}
+	if !sampled.TimedOut || len(sampled.Levels) != 0 || time.Since(start) >= time.Second {
+		t.Errorf("monitor Timeout 100ms: timed_out=%v levels=%d elapsed=%v", sampled.TimedOut, len(sampled.Levels), time.Since(start))
+	}
+
+	zero := uint32(0)
+	var sErr *sysprims.Error
+	if _, err := sysprims.DescendantsWithOptions(self, &sysprims.DescendantsOptions{MaxTotal: &zero}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("Descendants(MaxTotal 0) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestDescendantsOf verifies that overlapping subtrees are merged without
+// double-counting and that each process records its contributing roots.
+func TestDescendantsOf(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh(1)")
+	}
+
+	// mid is a child of this process with a child of its own, so its subtree
+	// lies inside ours.
+	cmd := exec.Command("sh", "-c", "sleep 30 & exec sleep 31")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sh: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	self := uint32(os.Getpid())
+	mid := uint32(cmd.Process.Pid)
+
+	var leaf uint32
+	for i := 0; i < 100 && leaf == 0; i++ {
+		res, err := sysprims.Descendants(mid, 1, nil)
+		if err != nil {
+			t.Fatalf("Descendants(mid) failed: %v", err)
+		}
+		if len(res.Levels) > 0 && len(res.Levels[0].Processes) > 0 {
+			leaf = res.Levels[0].Processes[0].PID
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if leaf == 0 {
+		t.Skip("sh did not start its background child")
+	}
+	defer func() { _ = sysprims.Kill(leaf, 9) }()
+
+	single, err := sysprims.Descendants(self, ^uint32(0), nil)
+	if err != nil {
+		t.Fatalf("Descendants(self) failed: %v", err)
+	}
+	union, err := sysprims.DescendantsOf([]uint32{mid, self, mid}, nil)
+	if err != nil {
+		t.Fatalf("DescendantsOf failed: %v", err)
+	}
+	if !reflect.DeepEqual(union.RootPIDs, []uint32{mid, self}) || union.RootPID != 0 {
+		t.Errorf("RootPIDs = %v RootPID = %d, want [%d %d] and 0", union.RootPIDs, union.RootPID, mid, self)
+	}
+
+	depth := map[uint32]uint32{}
+	for _, level := range union.Levels {
+		for _, p := range level.Processes {
+			if _, dup := depth[p.PID]; dup {
+				t.Errorf("pid %d reported twice", p.PID)
+			}
+			depth[p.PID] = level.Level
+		}
+	}
+	if union.TotalFound != len(depth) || union.TotalFound != single.TotalFound {
+		t.Errorf("TotalFound = %d (%d unique), Descendants(self) found %d", union.TotalFound, len(depth), single.TotalFound)
+	}
+	if depth[mid] != 1 || depth[leaf] != 1 {
+		t.Errorf("depths: mid=%d leaf=%d, want 1 and 1 (nearest root)", depth[mid], depth[leaf])
+	}
+	if got := union.RootsByPID[leaf]; !reflect.DeepEqual(got, []uint32{mid, self}) {
+		t.Errorf("RootsByPID[leaf] = %v, want [%d %d]", got, mid, self)
+	}
+	if got := union.RootsByPID[mid]; !reflect.DeepEqual(got, []uint32{self}) {
+		t.Errorf("RootsByPID[mid] = %v, want [%d]", got, self)
+	}
+
+	one := uint32(1)
+	shallow, err := sysprims.DescendantsOf([]uint32{self, mid}, &sysprims.DescendantsOptions{MaxLevels: &one})
+	if err != nil {
+		t.Fatalf("DescendantsOf(MaxLevels 1) failed: %v", err)
+	}
+	if got := shallow.RootsByPID[leaf]; !reflect.DeepEqual(got, []uint32{mid}) {
+		t.Errorf("MaxLevels 1: RootsByPID[leaf] = %v, want [%d]", got, mid)
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.DescendantsOf(nil, nil); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("DescendantsOf(nil) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestDescendantsIncludeRoot verifies IncludeRoot reports the root at level
+// 0, unfiltered and counted in TotalFound, from the same traversal.
+func TestDescendantsIncludeRoot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh(1)")
+	}
+
+	cmd := exec.Command("sh", "-c", "sleep 30 & exec sleep 31")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sh: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	root := uint32(cmd.Process.Pid)
+
+	var leaf uint32
+	for i := 0; i < 100 && leaf == 0; i++ {
+		res, err := sysprims.Descendants(root, 1, nil)
+		if err != nil {
+			t.Fatalf("Descendants(root) failed: %v", err)
+		}
+		if len(res.Levels) > 0 && len(res.Levels[0].Processes) > 0 {
+			leaf = res.Levels[0].Processes[0].PID
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if leaf == 0 {
+		t.Skip("sh did not start its background child")
+	}
+	defer func() { _ = sysprims.Kill(leaf, 9) }()
+
+	res, err := sysprims.DescendantsWithOptions(root, &sysprims.DescendantsOptions{IncludeRoot: true})
+	if err != nil {
+		t.Fatalf("DescendantsWithOptions(IncludeRoot) failed: %v", err)
+	}
+	if len(res.Levels) != 2 || res.Levels[0].Level != 0 || len(res.Levels[0].Processes) != 1 ||
+		res.Levels[0].Processes[0].PID != root || res.Levels[1].Processes[0].PID != leaf {
+		t.Fatalf("levels = %+v, want root at level 0 and leaf at level 1", res.Levels)
+	}
+	if res.TotalFound != 2 || res.MatchedByFilter != 2 {
+		t.Errorf("TotalFound = %d MatchedByFilter = %d, want 2 and 2", res.TotalFound, res.MatchedByFilter)
+	}
+
+	none := "no-such-process-name"
+	filtered, err := sysprims.DescendantsWithOptions(root, &sysprims.DescendantsOptions{
+		IncludeRoot: true,
+		Filter:      &sysprims.ProcessFilter{NameEquals: &none},
+	})
+	if err != nil {
+		t.Fatalf("DescendantsWithOptions(IncludeRoot, filter) failed: %v", err)
+	}
+	if len(filtered.Levels) != 1 || filtered.Levels[0].Processes[0].PID != root {
+		t.Errorf("filtered levels = %+v, want only the root", filtered.Levels)
+	}
+
+	union, err := sysprims.DescendantsOf([]uint32{root, leaf}, &sysprims.DescendantsOptions{IncludeRoot: true})
+	if err != nil {
+		t.Fatalf("DescendantsOf(IncludeRoot) failed: %v", err)
+	}
+	if len(union.Levels) != 1 || len(union.Levels[0].Processes) != 2 || union.TotalFound != 2 {
+		t.Errorf("DescendantsOf levels = %+v total = %d, want both roots at level 0 only", union.Levels, union.TotalFound)
+	}
+	if got := union.RootsByPID[leaf]; !reflect.DeepEqual(got, []uint32{root}) {
+		t.Errorf("RootsByPID[leaf] = %v, want [%d]", got, root)
+	}
+}
+
+// TestKillDescendantsExePathFilter verifies exe path criteria reach descendants and kills.
+func TestKillDescendantsExePathFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	child := uint32(cmd.Process.Pid)
+
+	childInfo, err := sysprims.ProcessGet(child)
+	if err != nil {
+		t.Fatalf("ProcessGet(child) failed: %v", err)
+	}
+	if childInfo.ExePath == nil {
+		t.Skip("exe_path not available for child")
+	}
+
+	self := uint32(os.Getpid())
+	missing := "/sysprims/no/such/install/dir/"
+	desc, err := sysprims.Descendants(self, 1, &sysprims.ProcessFilter{ExePathContains: &missing})
+	if err != nil {
+		t.Fatalf("Descendants(ExePathContains missing) failed: %v", err)
+	}
+	if desc.TotalFound == 0 || desc.MatchedByFilter != 0 || len(desc.Levels) != 0 {
+		t.Errorf("Descendants(missing) total=%d matched=%d levels=%d", desc.TotalFound, desc.MatchedByFilter, len(desc.Levels))
+	}
+
+	res, err := sysprims.KillDescendants(self, sysprims.SIGKILL, 1, &sysprims.ProcessFilter{ExePathContains: &missing})
+	if err != nil {
+		t.Fatalf("KillDescendants(missing) failed: %v", err)
+	}
+	if len(res.Succeeded) != 0 {
+		t.Fatalf("KillDescendants(missing) signaled %v", res.Succeeded)
+	}
+
+	res, err = sysprims.KillDescendants(self, sysprims.SIGKILL, 1, &sysprims.ProcessFilter{PIDIn: []uint32{child}, ExePathEquals: childInfo.ExePath})
+	if err != nil {
+		t.Fatalf("KillDescendants(ExePathEquals) failed: %v", err)
+	}
+	if len(res.Succeeded) != 1 || res.Succeeded[0] != child {
+		t.Errorf("KillDescendants(ExePathEquals) succeeded=%v failed=%v, expected [%d]", res.Succeeded, res.Failed, child)
+	}
+	if res.SchemaID == "" {
+		t.Error("KillDescendants result has empty schema_id")
+	}
+}
+
+// TestKillTree verifies the root is signaled after its descendants and the
+// safety rules cover the root.
+func TestKillTree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and sleep")
+	}
+
+	// The root execs sleep, so it outlives its children rather than exiting
+	// once they are killed.
+	cmd := exec.Command("sh", "-c", "sleep 30 & sleep 30 & exec sleep 30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sh: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	root := uint32(cmd.Process.Pid)
+
+	for i := 0; ; i++ {
+		desc, err := sysprims.Descendants(root, 1, nil)
+		if err == nil && desc.TotalFound == 2 {
+			break
+		}
+		if i == 100 {
+			t.Skip("sh did not start its children")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	res, err := sysprims.KillTree(root, sysprims.SIGKILL, nil)
+	if err != nil {
+		t.Fatalf("KillTree failed: %v", err)
+	}
+	if len(res.Succeeded) != 3 || res.Succeeded[2] != root || len(res.Failed) != 0 {
+		t.Errorf("KillTree succeeded=%v failed=%v; want both sleeps, then %d", res.Succeeded, res.Failed, root)
+	}
+	if err := cmd.Wait(); err == nil {
+		t.Error("root exited cleanly; want killed")
+	}
+
+	self := uint32(os.Getpid())
+	res, err = sysprims.KillTree(self, 0, &sysprims.KillDescendantsOptions{Filter: &sysprims.ProcessFilter{PIDIn: []uint32{1}}})
+	if err != nil {
+		t.Fatalf("KillTree(self) failed: %v", err)
+	}
+	for _, pid := range res.Succeeded {
+		if pid == self {
+			t.Error("KillTree signaled the calling process")
+		}
+	}
+	if res.SkippedSafety == 0 {
+		t.Error("KillTree(self) did not count the root as skipped")
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.KillTree(0, sysprims.SIGTERM, nil); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("KillTree(0) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestSafetyPolicy verifies a SafetyPolicy, per call or by default, leaves
+// protected processes alone across the destructive operations and that
+// MaxKillCount aborts before anything is signaled.
+func TestSafetyPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and sleep")
+	}
+
+	cmd := exec.Command("sh", "-c", "sleep 30 & sleep 30 & exec sleep 30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sh: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	root := uint32(cmd.Process.Pid)
+
+	var children []uint32
+	for i := 0; ; i++ {
+		desc, err := sysprims.Descendants(root, 1, nil)
+		if err == nil && desc.TotalFound == 2 {
+			for _, p := range desc.Levels[0].Processes {
+				children = append(children, p.PID)
+			}
+			break
+		}
+		if i == 100 {
+			t.Skip("sh did not start its children")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	defer func() { _, _ = sysprims.KillMany(children, sysprims.SIGKILL) }()
+	sort.Slice(children, func(i, j int) bool { return children[i] < children[j] })
+
+	var sErr *sysprims.Error
+	filter := &sysprims.ProcessFilter{PIDIn: children}
+	limit := &sysprims.SafetyPolicy{MaxKillCount: 1}
+	if _, err := sysprims.KillMatching(filter, sysprims.SIGKILL, &sysprims.KillMatchingOptions{Policy: limit}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("KillMatching over MaxKillCount expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.KillDescendantsWithOptions(root, &sysprims.KillDescendantsOptions{Policy: limit}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("KillDescendants over MaxKillCount expected ErrInvalidArgument, got %v", err)
+	}
+	// The two children fit, but the root counts too.
+	if _, err := sysprims.KillTree(root, sysprims.SIGKILL, &sysprims.KillDescendantsOptions{Policy: &sysprims.SafetyPolicy{MaxKillCount: 2}}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("KillTree over MaxKillCount expected ErrInvalidArgument, got %v", err)
+	}
+
+	res, err := sysprims.KillMatching(filter, sysprims.SIGKILL, &sysprims.KillMatchingOptions{
+		DryRun: true,
+		Policy: &sysprims.SafetyPolicy{ProtectedNames: []string{"sleep"}},
+	})
+	if err != nil {
+		t.Fatalf("KillMatching failed: %v", err)
+	}
+	if !reflect.DeepEqual(res.SkippedPolicy, children) || len(res.Targets) != 0 {
+		t.Errorf("KillMatching skipped_policy=%v targets=%v; want %v skipped", res.SkippedPolicy, res.Targets, children)
+	}
+
+	tt, err := sysprims.TerminateTree(root, sysprims.TerminateTreeConfig{Policy: &sysprims.SafetyPolicy{ProtectedPIDs: []uint32{root}}})
+	if err != nil {
+		t.Fatalf("TerminateTree failed: %v", err)
+	}
+	if len(tt.SkippedPolicy) == 0 || len(tt.Targets) != 0 || tt.Exited {
+		t.Errorf("TerminateTree skipped_policy=%v targets=%v exited=%v; want skipped", tt.SkippedPolicy, tt.Targets, tt.Exited)
+	}
+
+	sysprims.SetDefaultSafetyPolicy(&sysprims.SafetyPolicy{ProtectedPIDs: children[:1]})
+	defer sysprims.SetDefaultSafetyPolicy(nil)
+	if p := sysprims.DefaultSafetyPolicy(); p == nil || !reflect.DeepEqual(p.ProtectedPIDs, children[:1]) {
+		t.Errorf("DefaultSafetyPolicy() = %+v", p)
+	}
+	kd, err := sysprims.KillTree(root, sysprims.SIGKILL, &sysprims.KillDescendantsOptions{
+		Policy: &sysprims.SafetyPolicy{ProtectedPIDs: []uint32{root}},
+	})
+	if err != nil {
+		t.Fatalf("KillTree failed: %v", err)
+	}
+	want := []uint32{children[0], root}
+	if !reflect.DeepEqual(kd.SkippedPolicy, want) || !reflect.DeepEqual(kd.Succeeded, children[1:]) {
+		t.Errorf("KillTree skipped_policy=%v succeeded=%v; want %v skipped, %v signaled", kd.SkippedPolicy, kd.Succeeded, want, children[1:])
+	}
+	if id, err := sysprims.IdentityOf(root); err != nil {
+		t.Errorf("protected root is gone: %v", err)
+	} else if running, _ := id.StillRunning(); !running {
+		t.Error("protected root is not running")
+	}
+}
+
+// TestProcessListRaw verifies the raw payload decodes to the same shape.
+func TestProcessListRaw(t *testing.T) {
+	pid := uint32(os.Getpid())
+	raw, err := sysprims.ProcessListRaw(&sysprims.ProcessFilter{PIDIn: []uint32{pid}}, nil)
+	if err != nil {
+		t.Fatalf("ProcessListRaw failed: %v", err)
+	}
+	if !json.Valid(raw) {
+		t.Fatal("ProcessListRaw returned invalid JSON")
+	}
+
+	var snapshot sysprims.ProcessSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		t.Fatalf("ProcessListRaw payload does not decode: %v", err)
+	}
+	if len(snapshot.Processes) != 1 || snapshot.Processes[0].PID != pid {
+		t.Errorf("ProcessListRaw returned %d processes, expected self", len(snapshot.Processes))
+	}
+
+	dir := "/"
+	for name, call := range map[string]func() error{
+		"go-side filter": func() error {
+			_, err := sysprims.ProcessListRaw(&sysprims.ProcessFilter{ExePathContains: &dir}, nil)
+			return err
+		},
+		"monitor mode": func() error {
+			_, err := sysprims.ProcessListRaw(nil, &sysprims.ProcessOptions{CpuMode: sysprims.CpuModeMonitor})
+			return err
+		},
+		"fd path filter": func() error {
+			_, err := sysprims.ListFdsRaw(pid, &sysprims.FdFilter{PathContains: &dir})
+			return err
+		},
+	} {
+		var sErr *sysprims.Error
+		if err := call(); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+}
+
+// TestListeningPortsAndFdsRaw verifies the other raw variants return valid JSON.
+func TestListeningPortsAndFdsRaw(t *testing.T) {
+	raw, err := sysprims.ListeningPortsRaw(nil)
+	if err != nil {
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || (sErr.Code != sysprims.ErrPermissionDenied && sErr.Code != sysprims.ErrNotSupported) {
+			t.Fatalf("ListeningPortsRaw failed: %v", err)
+		}
+	} else if !json.Valid(raw) {
+		t.Error("ListeningPortsRaw returned invalid JSON")
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	raw, err = sysprims.ListFdsRaw(uint32(os.Getpid()), nil)
+	if err != nil {
+		t.Fatalf("ListFdsRaw failed: %v", err)
+	}
+	var snapshot sysprims.FdSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		t.Fatalf("ListFdsRaw payload does not decode: %v", err)
+	}
+	if len(snapshot.Fds) == 0 {
+		t.Error("ListFdsRaw returned no fds for self")
+	}
+}
+
+// TestSnapshotWriteJSON verifies that WriteJSON emits schema-shaped documents
+// without the fields added by the Go bindings.
+func TestSnapshotWriteJSON(t *testing.T) {
+	pid := uint32(os.Getpid())
+	snap, err := sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{PIDIn: []uint32{pid}}, &sysprims.ProcessOptions{IncludeNice: true})
+	if err != nil {
+		t.Fatalf("ProcessListWithOptions failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := snap.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var doc struct {
+		SchemaID  string                       `json:"schema_id"`
+		Processes []map[string]json.RawMessage `json:"processes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteJSON output does not decode: %v\n%s", err, buf.String())
+	}
+	if doc.SchemaID != snap.SchemaID || len(doc.Processes) != len(snap.Processes) {
+		t.Fatalf("schema_id=%q processes=%d, want %q and %d", doc.SchemaID, len(doc.Processes), snap.SchemaID, len(snap.Processes))
+	}
+	for _, p := range doc.Processes {
+		for _, key := range []string{"pid", "state", "cmdline", "elapsed_seconds"} {
+			if _, ok := p[key]; !ok {
+				t.Errorf("process is missing required %q", key)
+			}
+		}
+		for _, key := range []string{"raw_state", "nice", "oom_score", "oom_score_adj"} {
+			if _, ok := p[key]; ok {
+				t.Errorf("process has Go-only field %q", key)
+			}
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	fds, err := sysprims.ListFds(pid, nil)
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	buf.Reset()
+	if err := fds.WriteJSON(&buf); err != nil {
+		t.Fatalf("FdSnapshot.WriteJSON failed: %v", err)
+	}
+	var fdDoc map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &fdDoc); err != nil {
+		t.Fatalf("FdSnapshot.WriteJSON output does not decode: %v", err)
+	}
+	if _, ok := fdDoc["total_fds"]; ok {
+		t.Error("fd document has Go-only total_fds")
+	}
+	var decoded sysprims.FdSnapshot
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("FdSnapshot.WriteJSON output does not decode: %v", err)
+	}
+	if len(decoded.Fds) != len(fds.Fds) {
+		t.Errorf("decoded %d fds, want %d", len(decoded.Fds), len(fds.Fds))
+	}
+	for _, fd := range decoded.Fds {
+		if fd.Socket != nil {
+			t.Errorf("fd %d has Go-only socket details", fd.Fd)
+		}
+	}
+}
+
+// TestIncludeMemoryDetail verifies the opt-in memory breakdown for the
+// current process.
+func TestIncludeMemoryDetail(t *testing.T) {
+	pid := uint32(os.Getpid())
+	plain, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet failed: %v", err)
+	}
+	if plain.Memory != nil {
+		t.Errorf("Memory = %+v without IncludeMemoryDetail", plain.Memory)
+	}
+
+	info, err := sysprims.ProcessGetWithOptions(pid, &sysprims.ProcessOptions{IncludeMemoryDetail: true})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	m := info.Memory
+	if m == nil {
+		t.Fatal("Memory not set")
+	}
+	if m.RSSKB == 0 || m.VSZKB < m.RSSKB {
+		t.Errorf("rss=%d vsz=%d, want 0 < rss <= vsz", m.RSSKB, m.VSZKB)
+	}
+	if runtime.GOOS == "linux" {
+		if m.SharedKB == nil || *m.SharedKB > m.RSSKB || m.SwapKB == nil {
+			t.Errorf("shared=%v swap=%v, want both set and shared <= rss %d", m.SharedKB, m.SwapKB, m.RSSKB)
+		}
+	}
+
+	if _, err := sysprims.ProcessListRaw(nil, &sysprims.ProcessOptions{IncludeMemoryDetail: true}); err == nil {
+		t.Error("ProcessListRaw accepted IncludeMemoryDetail")
+	}
+}
+
+// TestIncludeCgroup verifies IncludeCgroup fills CgroupPath on Linux only and
+// that CgroupContains selects by that path.
+func TestIncludeCgroup(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGetWithOptions(pid, &sysprims.ProcessOptions{IncludeCgroup: true})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	if runtime.GOOS != "linux" {
+		if info.CgroupPath != nil || info.ContainerID != nil {
+			t.Errorf("cgroup fields set on %s: %v %v", runtime.GOOS, info.CgroupPath, info.ContainerID)
+		}
+		return
+	}
+	if info.CgroupPath == nil || !strings.HasPrefix(*info.CgroupPath, "/") {
+		t.Fatalf("CgroupPath = %v, want an absolute cgroup path", info.CgroupPath)
+	}
+
+	self := []uint32{pid}
+	snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{PIDIn: self, CgroupContains: info.CgroupPath})
+	if err != nil {
+		t.Fatalf("ProcessList failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 {
+		t.Errorf("CgroupContains %q matched %d processes, want self", *info.CgroupPath, len(snapshot.Processes))
+	}
+	other := *info.CgroupPath + "/no-such-child"
+	snapshot, err = sysprims.ProcessList(&sysprims.ProcessFilter{PIDIn: self, CgroupContains: &other})
+	if err != nil {
+		t.Fatalf("ProcessList failed: %v", err)
+	}
+	if len(snapshot.Processes) != 0 {
+		t.Errorf("CgroupContains %q matched self", other)
+	}
+
+	if _, err := sysprims.ProcessListRaw(nil, &sysprims.ProcessOptions{IncludeCgroup: true}); err == nil {
+		t.Error("ProcessListRaw accepted IncludeCgroup")
+	}
+}
+
+// TestIncludeTTY verifies IncludeTTY reports the controlling terminal of a
+// process running under script(1)'s pseudo-terminal, and TTYEquals selects
+// it.
+func TestIncludeTTY(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses script(1) from util-linux")
+	}
+	if _, err := exec.LookPath("script"); err != nil {
+		t.Skip("script not installed")
+	}
+	cmd := exec.Command("script", "-qec", "sleep 30", "/dev/null")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start script: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	sleepName := "sleep"
+	filter := &sysprims.ProcessFilter{NameEquals: &sleepName}
+	var sleeper *sysprims.ProcessInfo
+	for i := 0; i < 100 && sleeper == nil; i++ {
+		desc, err := sysprims.Descendants(uint32(cmd.Process.Pid), ^uint32(0), filter)
+		if err != nil {
+			t.Fatalf("Descendants failed: %v", err)
+		}
+		for _, level := range desc.Levels {
+			if len(level.Processes) > 0 {
+				sleeper = &level.Processes[0]
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if sleeper == nil {
+		t.Skip("sleep did not start under script")
+	}
+
+	info, err := sysprims.ProcessGetWithOptions(sleeper.PID, &sysprims.ProcessOptions{IncludeTTY: true})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	if info.TTY == nil || !strings.HasPrefix(*info.TTY, "pts/") {
+		t.Fatalf("TTY = %v, want pts/N", info.TTY)
+	}
+	if _, err := os.Stat("/dev/" + *info.TTY); err != nil {
+		t.Errorf("TTY %s does not name a device: %v", *info.TTY, err)
+	}
+
+	snapshot, err := sysprims.ProcessList(&sysprims.ProcessFilter{TTYEquals: info.TTY, NameEquals: &sleepName})
+	if err != nil {
+		t.Fatalf("ProcessList failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 || snapshot.Processes[0].PID != sleeper.PID {
+		t.Errorf("TTYEquals %s matched %d sleep processes, want only %d", *info.TTY, len(snapshot.Processes), sleeper.PID)
+	}
+
+	self, err := sysprims.ProcessGetWithOptions(uint32(os.Getpid()), &sysprims.ProcessOptions{IncludeTTY: true})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions(self) failed: %v", err)
+	}
+	if self.TTY != nil && *self.TTY == *info.TTY {
+		t.Errorf("test process shares the script pty %s", *info.TTY)
+	}
+}
+
+// TestIncludeIDs verifies IncludeIDs reports the test process's real and
+// effective user and group IDs.
+func TestIncludeIDs(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGetWithOptions(pid, &sysprims.ProcessOptions{IncludeIDs: true})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		if info.UID != nil || info.EUID != nil || info.GID != nil || info.EGID != nil {
+			t.Error("numeric ids set on windows")
+		}
+		return
+	}
+	if info.UID == nil || info.EUID == nil || info.GID == nil || info.EGID == nil {
+		t.Fatalf("ids not set: uid=%v euid=%v gid=%v egid=%v", info.UID, info.EUID, info.GID, info.EGID)
+	}
+	want := []int{os.Getuid(), os.Geteuid(), os.Getgid(), os.Getegid()}
+	got := []int{int(*info.UID), int(*info.EUID), int(*info.GID), int(*info.EGID)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("uid/euid/gid/egid = %v, want %v", got, want)
+	}
+
+	plain, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet failed: %v", err)
+	}
+	if plain.UID != nil {
+		t.Error("UID set without IncludeIDs")
+	}
+}
+
+// TestIncludeSchedStats verifies IncludeSchedStats reports page fault and,
+// on Linux, context switch counters for the test process.
+func TestIncludeSchedStats(t *testing.T) {
+	pid := uint32(os.Getpid())
+	info, err := sysprims.ProcessGetWithOptions(pid, &sysprims.ProcessOptions{IncludeSchedStats: true})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	if info.MinorFaults == nil || *info.MinorFaults == 0 {
+		t.Errorf("MinorFaults = %v, want a positive count", info.MinorFaults)
+	}
+	switch runtime.GOOS {
+	case "linux":
+		if info.MajorFaults == nil || info.VoluntaryCtxSwitches == nil || info.InvoluntaryCtxSwitches == nil {
+			t.Fatalf("counters not set: major=%v voluntary=%v involuntary=%v", info.MajorFaults, info.VoluntaryCtxSwitches, info.InvoluntaryCtxSwitches)
+		}
+		// Sleeping yields the CPU, so the voluntary count must grow.
+		before := *info.VoluntaryCtxSwitches
+		time.Sleep(10 * time.Millisecond)
+		again, err := sysprims.ProcessGetWithOptions(pid, &sysprims.ProcessOptions{IncludeSchedStats: true})
+		if err != nil {
+			t.Fatalf("ProcessGetWithOptions failed: %v", err)
+		}
+		if again.VoluntaryCtxSwitches == nil || *again.VoluntaryCtxSwitches <= before {
+			t.Errorf("VoluntaryCtxSwitches did not grow past %d: %v", before, again.VoluntaryCtxSwitches)
+		}
+	case "windows":
+		if info.MajorFaults != nil || info.VoluntaryCtxSwitches != nil {
+			t.Error("counters Windows cannot report are set")
+		}
+	}
+
+	plain, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet failed: %v", err)
+	}
+	if plain.MinorFaults != nil {
+		t.Error("MinorFaults set without IncludeSchedStats")
+	}
+}
+
+// TestIncludeCPUTimes verifies IncludeCPUTimes splits the test process's CPU
+// time into user and system time that grow with work.
+func TestIncludeCPUTimes(t *testing.T) {
+	pid := uint32(os.Getpid())
+	opts := &sysprims.ProcessOptions{IncludeCPUTimes: true}
+	before, err := sysprims.ProcessGetWithOptions(pid, opts)
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	if before.CPUUserTimeMS == nil || before.CPUSystemTimeMS == nil {
+		t.Fatalf("times not set: user=%v system=%v", before.CPUUserTimeMS, before.CPUSystemTimeMS)
+	}
+
+	// Burn user CPU until the counter moves (clock ticks are coarse).
+	deadline := time.Now().Add(5 * time.Second)
+	x := 0
+	for time.Now().Before(deadline) {
+		for i := 0; i < 1_000_000; i++ {
+			x += i
+		}
+		after, err := sysprims.ProcessGetWithOptions(pid, opts)
+		if err != nil {
+			t.Fatalf("ProcessGetWithOptions failed: %v", err)
+		}
+		if *after.CPUUserTimeMS > *before.CPUUserTimeMS {
+			if *after.CPUSystemTimeMS < *before.CPUSystemTimeMS {
+				t.Errorf("system time went backwards: %d -> %d", *before.CPUSystemTimeMS, *after.CPUSystemTimeMS)
+			}
+			return
+		}
+	}
+	t.Errorf("user time did not grow past %d ms (x=%d)", *before.CPUUserTimeMS, x)
+}
+
+// TestIncludeFdCount verifies IncludeFdCount reports the test process's open
+// fds, rises as files are opened, and that FdCountAbove filters on it.
+func TestIncludeFdCount(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		t.Skip("fd counts not supported on this platform")
+	}
+	pid := uint32(os.Getpid())
+	opts := &sysprims.ProcessOptions{IncludeFdCount: true}
+	before, err := sysprims.ProcessGetWithOptions(pid, opts)
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	if before.OpenFdCount == nil {
+		t.Fatal("OpenFdCount not set")
+	}
+
+	const extra = 8
+	for i := 0; i < extra; i++ {
+		f, err := os.Open(os.Args[0])
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+	}
+	after, err := sysprims.ProcessGetWithOptions(pid, opts)
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions failed: %v", err)
+	}
+	// The runtime may open or close fds meanwhile; allow a little slack.
+	if *after.OpenFdCount+2 < *before.OpenFdCount+extra {
+		t.Errorf("OpenFdCount %d -> %d after opening %d files", *before.OpenFdCount, *after.OpenFdCount, extra)
+	}
+
+	plain, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet failed: %v", err)
+	}
+	if plain.OpenFdCount != nil {
+		t.Error("OpenFdCount set without IncludeFdCount")
+	}
+
+	above := func(n uint32) bool {
+		snap, err := sysprims.ProcessList(&sysprims.ProcessFilter{PIDIn: []uint32{pid}, FdCountAbove: &n})
+		var sErr *sysprims.Error
+		if errors.As(err, &sErr) && sErr.Code == sysprims.ErrNotSupported {
+			return false // the library reports an empty match this way
+		}
+		if err != nil {
+			t.Fatalf("ProcessList failed: %v", err)
+		}
+		return len(snap.Processes) == 1
+	}
+	if !above(1) {
+		t.Error("FdCountAbove=1 excluded the test process")
+	}
+	if above(*after.OpenFdCount + 1000) {
+		t.Error("FdCountAbove far above the count matched the test process")
+	}
+}
+
+// TestCPUUsage verifies CPUUsage measures a busy process over the sample,
+// rejects a non-positive sample, and reports ErrNotFound for a process that
+// exits mid-sample.
+func TestCPUUsage(t *testing.T) {
+	pid := uint32(os.Getpid())
+	var sErr *sysprims.Error
+	if _, err := sysprims.CPUUsage(pid, 0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("CPUUsage(0s) expected ErrInvalidArgument, got %v", err)
+	}
+
+	stop := make(chan struct{})
+	spun := make(chan int)
+	go func() {
+		x := 0
+		for {
+			select {
+			case <-stop:
+				spun <- x
+				return
+			default:
+			}
+			for i := 0; i < 100_000; i++ {
+				x += i
+			}
+		}
+	}()
+	usage, err := sysprims.CPUUsage(pid, 300*time.Millisecond)
+	close(stop)
+	x := <-spun
+	if err != nil {
+		t.Fatalf("CPUUsage failed: %v", err)
+	}
+	if usage < 20 {
+		t.Errorf("CPUUsage of a spinning process = %.1f%%, want >= 20 (x=%d)", usage, x)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	cmd := exec.Command("sleep", "0.1")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	go func() { _ = cmd.Wait() }()
+	if _, err := sysprims.CPUUsage(uint32(cmd.Process.Pid), time.Second); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("CPUUsage of an exiting process expected ErrNotFound, got %v", err)
+	}
+}
+
+// syntheticSnapshotJSON builds a large process snapshot payload.
+func syntheticSnapshotJSON(b *testing.B, n int) []byte {
+	b.Helper()
+	snapshot := sysprims.ProcessSnapshot{
+		SchemaID:  "https://schemas.3leaps.dev/sysprims/process/v1.1.0/process-info.schema.json",
+		Timestamp: "2026-01-02T03:04:05Z",
+		Processes: make([]sysprims.ProcessInfo, n),
+	}
+	user := "svc"
+	exe := "/usr/local/bin/worker"
+	for i := range snapshot.Processes {
+		snapshot.Processes[i] = sysprims.ProcessInfo{
+			PID:        uint32(i + 1),
+			PPID:       1,
+			Name:       "worker",
+			User:       &user,
+			CPUPercent: float64(i%100) / 3,
+			MemoryKB:   uint64(i * 17),
+			ExePath:    &exe,
+			Cmdline:    []string{exe, "--id", "12345", "--verbose"},
+		}
+	}
+	data, err := json.Marshal(&snapshot)
+	if err != nil {
+		b.Fatalf("marshal synthetic snapshot: %v", err)
+	}
+	return data
+}
+
+// BenchmarkSnapshotDecodeEncode measures the decode/re-encode path that the
+// raw variants avoid.
+func BenchmarkSnapshotDecodeEncode(b *testing.B) {
+	data := syntheticSnapshotJSON(b, 10000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var snapshot sysprims.ProcessSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(&snapshot); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSnapshotRawPassthrough measures the copy and validation performed
+// by the raw variants for the same payload.
+func BenchmarkSnapshotRawPassthrough(b *testing.B) {
+	data := syntheticSnapshotJSON(b, 10000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		raw := json.RawMessage(append([]byte(nil), data...))
+		if !json.Valid(raw) {
+			b.Fatal("invalid JSON")
+		}
+	}
+}
+
+// BenchmarkProcessList compares the decoded and raw listings on the live system.
+func BenchmarkProcessList(b *testing.B) {
+	b.Run("decoded", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := sysprims.ProcessList(nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := sysprims.ProcessListRaw(nil, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkFFICall measures the per-call FFI overhead on the success path
+// (signal 0 to self) and the error path (which also reads the thread-local
+// error). The "pinned" variants hold LockOSThread for the whole loop, which
+// models a pool of locked worker threads minus its channel handoff: the gap
+// to the default variants is the most such a pool could save.
+func BenchmarkFFICall(b *testing.B) {
+	if runtime.GOOS == "windows" {
+		b.Skip("signal 0 is not supported on windows")
+	}
+	self := uint32(os.Getpid())
+	const badPID = uint32(1 << 31)
+
+	run := func(pinned bool, pid uint32, wantErr bool) func(*testing.B) {
+		return func(b *testing.B) {
+			if pinned {
+				runtime.LockOSThread()
+				defer runtime.UnlockOSThread()
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := sysprims.Kill(pid, 0); (err != nil) != wantErr {
+					b.Fatalf("Kill(%d, 0) = %v", pid, err)
+				}
+			}
+		}
+	}
+	b.Run("ok", run(false, self, false))
+	b.Run("ok-pinned", run(true, self, false))
+	b.Run("error", run(false, badPID, true))
+	b.Run("error-pinned", run(true, badPID, true))
+}
+
+// BenchmarkKillMany compares KillMany against signaling the same PIDs with a
+// Kill loop, the implementation it replaced.
+func BenchmarkKillMany(b *testing.B) {
+	if runtime.GOOS == "windows" {
+		b.Skip("signal 0 is not supported on windows")
+	}
+	self := uint32(os.Getpid())
+	pids := make([]uint32, 64)
+	for i := range pids {
+		pids[i] = self
+		if i%8 == 7 {
+			pids[i] = math.MaxInt32 // beyond pid_max, so ESRCH
+		}
+	}
+
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			res, err := sysprims.KillMany(pids, 0)
+			if err != nil || len(res.Failed) != len(pids)/8 {
+				b.Fatalf("KillMany = %+v, %v", res, err)
+			}
+		}
+	})
+	b.Run("loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			failed := 0
+			for _, pid := range pids {
+				if sysprims.Kill(pid, 0) != nil {
+					failed++
+				}
+			}
+			if failed != len(pids)/8 {
+				b.Fatalf("Kill loop failed %d", failed)
+			}
+		}
+	})
+}
+
+// TestProcessListChanges verifies that only started and exited children are
+// reported between calls.
+func TestProcessListChanges(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	// startSleep starts a child and waits until it has exec'd, so the name
+	// change from fork to exec is not reported as a change.
+	startSleep := func() *exec.Cmd {
+		cmd := exec.Command("sleep", "30")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start sleep: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+		for i := 0; i < 100; i++ {
+			if info, err := sysprims.ProcessGet(uint32(cmd.Process.Pid)); err == nil && info.Name == "sleep" {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		return cmd
+	}
+	pids := func(ps []sysprims.ProcessInfo) []uint32 {
+		var out []uint32
+		for _, p := range ps {
+			out = append(out, p.PID)
+		}
+		return out
+	}
+
+	filter := &sysprims.ProcessFilter{PPIDIn: []uint32{uint32(os.Getpid())}}
+	a := startSleep()
+	aPID := uint32(a.Process.Pid)
+
+	full, cursor, err := sysprims.ProcessListChanges(nil, filter)
+	if err != nil {
+		t.Fatalf("ProcessListChanges(nil) failed: %v", err)
+	}
+	if !full.Full || cursor.Generation() != 0 {
+		t.Errorf("first call: Full=%v Generation=%d", full.Full, cursor.Generation())
+	}
+	if got := pids(full.Started); !reflect.DeepEqual(got, []uint32{aPID}) {
+		t.Fatalf("first call Started = %v, want [%d]", got, aPID)
+	}
+
+	unchanged, cursor2, err := sysprims.ProcessListChanges(cursor, filter)
+	if err != nil {
+		t.Fatalf("ProcessListChanges(unchanged) failed: %v", err)
+	}
+	if unchanged.Full || len(unchanged.Started) != 0 || len(unchanged.Exited) != 0 {
+		t.Errorf("unchanged call reported deltas: %+v", unchanged)
+	}
+	if cursor2.Generation() != 1 {
+		t.Errorf("Generation() = %d, want 1", cursor2.Generation())
+	}
+
+	b := startSleep()
+	bPID := uint32(b.Process.Pid)
+	_ = a.Process.Kill()
+	_ = a.Wait()
+
+	delta, _, err := sysprims.ProcessListChanges(cursor2, filter)
+	if err != nil {
+		t.Fatalf("ProcessListChanges(delta) failed: %v", err)
+	}
+	if got := pids(delta.Started); !reflect.DeepEqual(got, []uint32{bPID}) {
+		t.Errorf("Started = %v, want [%d]", got, bPID)
+	}
+	if got := pids(delta.Exited); !reflect.DeepEqual(got, []uint32{aPID}) {
+		t.Errorf("Exited = %v, want [%d]", got, aPID)
+	}
+
+	// Older cursors stay valid and diff against their own state.
+	again, _, err := sysprims.ProcessListChanges(cursor, filter)
+	if err != nil {
+		t.Fatalf("ProcessListChanges(old cursor) failed: %v", err)
+	}
+	if got := pids(again.Exited); !reflect.DeepEqual(got, []uint32{aPID}) {
+		t.Errorf("old cursor Exited = %v, want [%d]", got, aPID)
+	}
+	if _, err := again.Time(); err != nil {
+		t.Errorf("Time() failed: %v", err)
+	}
+}
+
+// TestProcessSnapshotDiff verifies Diff classifies processes by PID and start
+// time, treating a reused PID as an exit plus a start.
+func TestProcessSnapshotDiff(t *testing.T) {
+	start := func(ms uint64) *uint64 { return &ms }
+	prev := &sysprims.ProcessSnapshot{Processes: []sysprims.ProcessInfo{
+		{PID: 10, Name: "kept", StartTimeUnixMS: start(1000)},
+		{PID: 20, Name: "gone", StartTimeUnixMS: start(2000)},
+		{PID: 30, Name: "old", StartTimeUnixMS: start(3000)},
+		{PID: 40, Name: "untimed"},
+	}}
+	cur := &sysprims.ProcessSnapshot{Processes: []sysprims.ProcessInfo{
+		{PID: 50, Name: "new", StartTimeUnixMS: start(5000)},
+		{PID: 30, Name: "reused", StartTimeUnixMS: start(9000)},
+		{PID: 10, Name: "kept", StartTimeUnixMS: start(1100), MemoryKB: 7},
+		{PID: 40, Name: "untimed", StartTimeUnixMS: start(4000)},
+	}}
+
+	names := func(ps []sysprims.ProcessInfo) []string {
+		out := []string{}
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	diff := cur.Diff(prev)
+	if got, want := names(diff.Started), []string{"new", "reused"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Started = %v, want %v", got, want)
+	}
+	if got, want := names(diff.Exited), []string{"gone", "old"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Exited = %v, want %v", got, want)
+	}
+	if got, want := names(diff.Survived), []string{"kept", "untimed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Survived = %v, want %v", got, want)
+	}
+	if len(diff.Survived) > 0 && diff.Survived[0].MemoryKB != 7 {
+		t.Errorf("Survived should carry current info, got MemoryKB %d", diff.Survived[0].MemoryKB)
+	}
+
+	full := cur.Diff(nil)
+	if len(full.Started) != len(cur.Processes) || len(full.Exited) != 0 || len(full.Survived) != 0 {
+		t.Errorf("Diff(nil) = %d started, %d exited, %d survived; want all started",
+			len(full.Started), len(full.Exited), len(full.Survived))
+	}
+}
+
+// TestProcessGetSelf verifies that ProcessGet works for the current process.
+func TestProcessGetSelf(t *testing.T) {
+	pid := uint32(os.Getpid())
+
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+
+	if info.PID != pid {
+		t.Errorf("ProcessGet returned wrong PID: got %d, expected %d", info.PID, pid)
+	}
+
+	if info.Name == "" {
+		t.Error("ProcessGet returned empty name")
+	}
+
+	t.Logf("Process: %s (PID %d, PPID %d)", info.Name, info.PID, info.PPID)
+}
+
+// TestSelf verifies Self and SelfWithOptions describe the current process.
+func TestSelf(t *testing.T) {
+	pid := uint32(os.Getpid())
+
+	info, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet(%d) failed: %v", pid, err)
+	}
+	self, err := sysprims.Self()
+	if err != nil || self.PID != pid || self.Name != info.Name {
+		t.Errorf("Self() = %+v, %v; want pid %d named %q", self, err, pid, info.Name)
+	}
+	threaded, err := sysprims.SelfWithOptions(&sysprims.ProcessOptions{IncludeThreads: true})
+	if err != nil || threaded.PID != pid {
+		t.Errorf("SelfWithOptions = %+v, %v; want pid %d", threaded, err, pid)
+	}
+}
+
+func TestProcessGetWithOptionsSelf(t *testing.T) {
+	pid := uint32(os.Getpid())
+
+	info, err := sysprims.ProcessGetWithOptions(pid, &sysprims.ProcessOptions{
+		IncludeThreads: true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions(%d) failed: %v", pid, err)
+	}
+
+	if info.PID != pid {
+		t.Errorf("ProcessGetWithOptions returned wrong PID: got %d, expected %d", info.PID, pid)
+	}
+
+	if info.ThreadCount != nil && *info.ThreadCount == 0 {
+		t.Error("ProcessGetWithOptions returned invalid thread_count of 0")
+	}
+}
+
+// TestMonitorCPUSelf verifies single-process sampling under load.
+func TestMonitorCPUSelf(t *testing.T) {
+	defer startBusyLoop()()
+
+	pid := uint32(os.Getpid())
+	sample, err := sysprims.MonitorCPU(pid, 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("MonitorCPU(%d) failed: %v", pid, err)
+	}
+
+	if sample.PID != pid {
+		t.Errorf("MonitorCPU returned wrong PID: got %d, expected %d", sample.PID, pid)
+	}
+	if sample.ExitedDuringSample {
+		t.Error("MonitorCPU reported self as exited")
+	}
+	if sample.Wall < 300*time.Millisecond {
+		t.Errorf("MonitorCPU Wall = %v, expected >= 300ms", sample.Wall)
+	}
+	if sample.CPUTimeAfter <= sample.CPUTimeBefore {
+		t.Errorf("CPU time did not advance: before=%v after=%v", sample.CPUTimeBefore, sample.CPUTimeAfter)
+	}
+	if sample.CPUPercent <= 0 {
+		t.Errorf("MonitorCPU CPUPercent = %.2f, expected > 0", sample.CPUPercent)
+	}
+	t.Logf("CPU sampled=%.2f%% wall=%v", sample.CPUPercent, sample.Wall)
+}
+
+// TestCPUSampler verifies zero CPU on the first call, interval CPU afterwards,
+// and eviction of exited processes.
+func TestCPUSampler(t *testing.T) {
+	self := uint32(os.Getpid())
+	sampler := sysprims.NewCPUSampler()
+	selfFilter := &sysprims.ProcessFilter{PIDIn: []uint32{self}}
+
+	first, err := sampler.List(nil)
+	if err != nil {
+		t.Fatalf("first List failed: %v", err)
+	}
+	for _, p := range first.Processes {
+		if p.CPUPercent != 0 {
+			t.Fatalf("first List PID %d CPUPercent = %.2f, want 0", p.PID, p.CPUPercent)
+		}
+	}
+	if sampler.Tracked() == 0 {
+		t.Error("Tracked() = 0 after first List")
+	}
+
+	stop := startBusyLoop()
+	time.Sleep(300 * time.Millisecond)
+	stop()
+
+	start := time.Now()
+	second, err := sampler.List(selfFilter)
+	if err != nil {
+		t.Fatalf("second List failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("List blocked for %v", elapsed)
+	}
+	if len(second.Processes) != 1 || second.Processes[0].CPUPercent < 10 {
+		t.Fatalf("second List self = %+v, want CPUPercent well above 0", second.Processes)
+	}
+	if second.SchemaID == "" {
+		t.Error("second List missing SchemaID")
+	}
+	// Only the filtered process remains tracked.
+	if got := sampler.Tracked(); got != 1 {
+		t.Errorf("Tracked() = %d after filtered List, want 1", got)
+	}
+
+	zero := 0.0
+	filtered, err := sampler.List(&sysprims.ProcessFilter{PIDIn: []uint32{self}, CPUBelow: &zero})
+	if err != nil {
+		t.Fatalf("List(CPUBelow) failed: %v", err)
+	}
+	if len(filtered.Processes) != 0 {
+		t.Errorf("List(CPUBelow=0) returned %d processes", len(filtered.Processes))
+	}
+	high := 1e9
+	var sErr *sysprims.Error
+	if _, err := sampler.List(&sysprims.ProcessFilter{PIDIn: []uint32{self}, CPUAbove: &high}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("List(CPUAbove=%g) expected ErrInvalidArgument, got %v", high, err)
+	}
+
+	// Concurrent calls are serialized.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sampler.List(selfFilter); err != nil {
+				t.Errorf("concurrent List failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestMonitorCPUExitedDuringSample verifies a partial sample for a dying process.
+func TestMonitorCPUExitedDuringSample(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	cmd := exec.Command("sleep", "0.2")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	pid := uint32(cmd.Process.Pid)
+	go func() { _ = cmd.Wait() }()
+
+	sample, err := sysprims.MonitorCPU(pid, time.Second)
+	if err != nil {
+		t.Fatalf("MonitorCPU(%d) failed: %v", pid, err)
+	}
+	if !sample.ExitedDuringSample {
+		t.Error("Expected ExitedDuringSample for a process that exited mid-window")
+	}
+	if sample.CPUTimeAfter != sample.CPUTimeBefore {
+		t.Errorf("Partial sample should keep CPUTimeAfter == CPUTimeBefore, got %v != %v", sample.CPUTimeAfter, sample.CPUTimeBefore)
+	}
+}
+
+// TestMonitorCPUInvalid verifies argument validation and missing processes.
+func TestMonitorCPUInvalid(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		_, err := sysprims.MonitorCPU(uint32(os.Getpid()), d)
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("sample=%v: expected ErrInvalidArgument, got %v", d, err)
+		}
+	}
+
+	_, err := sysprims.MonitorCPU(99999999, 10*time.Millisecond)
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) {
+		t.Fatalf("Expected *sysprims.Error for missing pid, got %v", err)
+	}
+	if sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %d (%s)", sErr.Code, sErr.Code)
+	}
+}
+
+// startBusyLoop spins a goroutine until the returned stop function is called.
+func startBusyLoop() func() {
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// TestProcessGetMany verifies batch lookups preserve order and per-PID errors.
+func TestProcessGetMany(t *testing.T) {
+	self := uint32(os.Getpid())
+	pids := []uint32{self, 99999, self}
+
+	results, err := sysprims.ProcessGetMany(pids, &sysprims.ProcessOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ProcessGetMany failed: %v", err)
+	}
+	if len(results) != len(pids) {
+		t.Fatalf("ProcessGetMany returned %d results, expected %d", len(results), len(pids))
+	}
+
+	for i, r := range results {
+		if r.PID != pids[i] {
+			t.Errorf("results[%d].PID = %d, expected %d", i, r.PID, pids[i])
+		}
+	}
+	for _, i := range []int{0, 2} {
+		if results[i].Error != nil || results[i].Info == nil || results[i].Info.PID != self {
+			t.Errorf("results[%d] expected self info, got info=%v err=%v", i, results[i].Info, results[i].Error)
+		}
+	}
+	if results[1].Info == nil {
+		if results[1].Error == nil || results[1].Error.Code != sysprims.ErrNotFound {
+			t.Errorf("results[1] expected ErrNotFound, got %v", results[1].Error)
+		}
+	}
+}
+
+// TestProcessGetManyInvalid verifies PIDs are validated before any lookup.
+func TestProcessGetManyInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		pids []uint32
+		opts *sysprims.ProcessOptions
+	}{
+		{"empty", nil, nil},
+		{"zero pid", []uint32{uint32(os.Getpid()), 0}, nil},
+		{"negative concurrency", []uint32{uint32(os.Getpid())}, &sysprims.ProcessOptions{Concurrency: -1}},
+	}
+
+	for _, tt := range tests {
+		_, err := sysprims.ProcessGetMany(tt.pids, tt.opts)
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", tt.name, err)
+		}
+	}
+}
+
+// TestProcessGetEnvVar verifies single-variable lookup on a child process.
+func TestProcessGetEnvVar(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process environment is not collected on windows")
+	}
+
+	cmd := exec.Command("sleep", "30")
+	cmd.Env = append(os.Environ(), "SYSPRIMS_TEST_TAG=svc-42")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	child := uint32(cmd.Process.Pid)
+
+	value, err := sysprims.ProcessGetEnvVar(child, "SYSPRIMS_TEST_TAG")
+	if err != nil {
+		var sErr *sysprims.Error
+		if errors.As(err, &sErr) && sErr.Code == sysprims.ErrPermissionDenied {
+			t.Skipf("environment not readable in this environment: %v", err)
+		}
+		t.Fatalf("ProcessGetEnvVar failed: %v", err)
+	}
+	if value == nil || *value != "svc-42" {
+		t.Errorf("ProcessGetEnvVar(SYSPRIMS_TEST_TAG) = %v, expected svc-42", value)
+	}
+
+	value, err = sysprims.ProcessGetEnvVar(child, "sysprims_test_tag")
+	if err != nil {
+		t.Fatalf("ProcessGetEnvVar(lowercase) failed: %v", err)
+	}
+	if value != nil {
+		t.Errorf("ProcessGetEnvVar should be case-sensitive on Unix, got %q", *value)
+	}
+
+	value, err = sysprims.ProcessGetEnvVar(child, "SYSPRIMS_TEST_ABSENT")
+	if err != nil || value != nil {
+		t.Errorf("ProcessGetEnvVar(absent) = %v, %v; expected nil, nil", value, err)
+	}
+
+	_, err = sysprims.ProcessGetEnvVar(99999999, "PATH")
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("ProcessGetEnvVar(missing pid) expected ErrNotFound, got %v", err)
+	}
+
+	_, err = sysprims.ProcessGetEnvVar(child, "")
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ProcessGetEnvVar(empty key) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestPriority verifies reading priority and lowering it on a child.
+func TestPriority(t *testing.T) {
+	self := uint32(os.Getpid())
+	if _, err := sysprims.GetPriority(self); err != nil {
+		t.Fatalf("GetPriority(self) failed: %v", err)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", "ping -n 30 127.0.0.1 >NUL")
+	} else {
+		cmd = exec.Command("sleep", "30")
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start child: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	child := uint32(cmd.Process.Pid)
+
+	// Raising the nice value never requires privilege.
+	if err := sysprims.SetPriority(child, 10); err != nil {
+		t.Fatalf("SetPriority(child, 10) failed: %v", err)
+	}
+	want := 10
+	if runtime.GOOS == "windows" {
+		want = 7 // below normal priority class
+	}
+	if got, err := sysprims.GetPriority(child); err != nil || got != want {
+		t.Errorf("GetPriority(child) = %d, %v; want %d", got, err, want)
+	}
+
+	info, err := sysprims.ProcessGetWithOptions(child, &sysprims.ProcessOptions{IncludeNice: true})
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions(IncludeNice) failed: %v", err)
+	}
+	if info.Nice == nil || *info.Nice != want {
+		t.Errorf("ProcessInfo.Nice = %v, want %d", info.Nice, want)
+	}
+}
+
+// TestPriorityInvalid verifies argument validation and missing processes.
+func TestPriorityInvalid(t *testing.T) {
+	self := uint32(os.Getpid())
+	for _, tc := range []struct {
+		pid  uint32
+		nice int
+	}{{0, 0}, {self, -21}, {self, 20}} {
+		err := sysprims.SetPriority(tc.pid, tc.nice)
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("SetPriority(%d, %d) expected ErrInvalidArgument, got %v", tc.pid, tc.nice, err)
+		}
+	}
+
+	_, err := sysprims.GetPriority(99999)
+	if err == nil {
+		t.Skip("PID 99999 unexpectedly exists on this system")
+	}
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) || (sErr.Code != sysprims.ErrNotFound && sErr.Code != sysprims.ErrPermissionDenied) {
+		t.Errorf("GetPriority(99999) expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestOomScore verifies reading OOM scores and raising oom_score_adj on a child.
+func TestOomScore(t *testing.T) {
+	opts := &sysprims.ProcessOptions{IncludeOOM: true}
+	self := uint32(os.Getpid())
+
+	if runtime.GOOS != "linux" {
+		var sErr *sysprims.Error
+		if _, err := sysprims.ProcessGetWithOptions(self, opts); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("ProcessGetWithOptions(IncludeOOM) expected ErrNotSupported, got %v", err)
+		}
+		if err := sysprims.SetOomScoreAdj(self, 0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("SetOomScoreAdj expected ErrNotSupported, got %v", err)
+		}
+		return
+	}
+
+	info, err := sysprims.ProcessGetWithOptions(self, opts)
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions(IncludeOOM) failed: %v", err)
+	}
+	if info.OomScore == nil || info.OomScoreAdj == nil {
+		t.Fatalf("OOM fields missing: score=%v adj=%v", info.OomScore, info.OomScoreAdj)
+	}
+	if plain, err := sysprims.ProcessGet(self); err != nil || plain.OomScoreAdj != nil {
+		t.Errorf("ProcessGet without IncludeOOM should leave OOM fields nil: %+v, %v", plain, err)
+	}
+
+	snapshot, err := sysprims.ProcessListWithOptions(&sysprims.ProcessFilter{PIDIn: []uint32{self}}, opts)
+	if err != nil {
+		t.Fatalf("ProcessListWithOptions(IncludeOOM) failed: %v", err)
+	}
+	if len(snapshot.Processes) != 1 || snapshot.Processes[0].OomScoreAdj == nil {
+		t.Errorf("ProcessListWithOptions(IncludeOOM) missing OOM fields: %+v", snapshot.Processes)
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	child := uint32(cmd.Process.Pid)
+
+	// Raising the adjustment never requires privilege.
+	if err := sysprims.SetOomScoreAdj(child, 1000); err != nil {
+		t.Fatalf("SetOomScoreAdj(child, 1000) failed: %v", err)
+	}
+	childInfo, err := sysprims.ProcessGetWithOptions(child, opts)
+	if err != nil {
+		t.Fatalf("ProcessGetWithOptions(child) failed: %v", err)
+	}
+	if childInfo.OomScoreAdj == nil || *childInfo.OomScoreAdj != 1000 {
+		t.Errorf("child OomScoreAdj = %v, want 1000", childInfo.OomScoreAdj)
+	}
+}
+
+// TestSetOomScoreAdjInvalid verifies argument validation.
+func TestSetOomScoreAdjInvalid(t *testing.T) {
+	self := uint32(os.Getpid())
+	for _, tc := range []struct {
+		pid uint32
+		adj int32
+	}{{0, 0}, {self, -1001}, {self, 1001}} {
+		err := sysprims.SetOomScoreAdj(tc.pid, tc.adj)
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("SetOomScoreAdj(%d, %d) expected ErrInvalidArgument, got %v", tc.pid, tc.adj, err)
+		}
+	}
+}
+
+// TestCPUAffinity verifies reading affinity and pinning a child to one CPU.
+func TestCPUAffinity(t *testing.T) {
+	self := uint32(os.Getpid())
+	if runtime.GOOS == "darwin" {
+		var sErr *sysprims.Error
+		if _, err := sysprims.GetCPUAffinity(self); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("GetCPUAffinity expected ErrNotSupported, got %v", err)
+		}
+		return
+	}
+
+	cpus, err := sysprims.GetCPUAffinity(self)
+	if err != nil {
+		t.Fatalf("GetCPUAffinity(self) failed: %v", err)
+	}
+	if len(cpus) == 0 {
+		t.Fatal("GetCPUAffinity(self) returned no CPUs")
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", "ping -n 30 127.0.0.1 >NUL")
+	} else {
+		cmd = exec.Command("sleep", "30")
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start child: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	child := uint32(cmd.Process.Pid)
+
+	pin := []int{cpus[len(cpus)-1]}
+	if err := sysprims.SetCPUAffinity(child, pin); err != nil {
+		t.Fatalf("SetCPUAffinity(child, %v) failed: %v", pin, err)
+	}
+	got, err := sysprims.GetCPUAffinity(child)
+	if err != nil {
+		t.Fatalf("GetCPUAffinity(child) failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, pin) {
+		t.Errorf("GetCPUAffinity(child) = %v, want %v", got, pin)
+	}
+}
+
+// TestSetCPUAffinityInvalid verifies argument validation.
+func TestSetCPUAffinityInvalid(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("cpu affinity not supported")
+	}
+	self := uint32(os.Getpid())
+	for _, tc := range []struct {
+		pid  uint32
+		cpus []int
+	}{{0, []int{0}}, {self, nil}, {self, []int{-1}}, {self, []int{1 << 20}}} {
+		err := sysprims.SetCPUAffinity(tc.pid, tc.cpus)
+		var sErr *sysprims.Error
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("SetCPUAffinity(%d, %v) expected ErrInvalidArgument, got %v", tc.pid, tc.cpus, err)
+		}
+	}
+}
+
+// TestListThreadsSelf verifies per-thread listing for the current process.
+func TestListThreadsSelf(t *testing.T) {
+	pid := uint32(os.Getpid())
+	defer startBusyLoop()()
+
+	snap, err := sysprims.ListThreads(pid)
+	if err != nil {
+		var sErr *sysprims.Error
+		if errors.As(err, &sErr) && sErr.Code == sysprims.ErrNotSupported {
+			t.Skipf("ListThreads not supported: %v", err)
+		}
+		t.Fatalf("ListThreads(%d) failed: %v", pid, err)
+	}
+	if snap.PID != pid {
+		t.Errorf("ListThreads returned wrong PID: got %d, expected %d", snap.PID, pid)
+	}
+	if len(snap.Threads) == 0 {
+		t.Fatal("ListThreads returned no threads for self")
+	}
+	if _, err := snap.Time(); err != nil {
+		t.Errorf("ThreadSnapshot.Time() failed: %v", err)
+	}
+
+	var total uint64
+	for _, th := range snap.Threads {
+		if th.TID == 0 {
+			t.Error("ListThreads returned TID 0")
+		}
+		total += th.CPUTimeMS
+	}
+	if runtime.GOOS == "linux" {
+		foundMain := false
+		for _, th := range snap.Threads {
+			if th.TID == pid {
+				foundMain = true
+			}
+			if th.Name == nil || th.State == nil {
+				t.Errorf("thread %d missing name/state on linux", th.TID)
+			}
+		}
+		if !foundMain {
+			t.Errorf("ListThreads did not include main thread TID %d", pid)
+		}
+	}
+	t.Logf("threads=%d total_cpu_ms=%d warnings=%v", len(snap.Threads), total, snap.Warnings)
+}
+
+// TestListThreadsInvalid verifies argument validation and missing processes.
+func TestListThreadsInvalid(t *testing.T) {
+	_, err := sysprims.ListThreads(0)
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListThreads(0) expected ErrInvalidArgument, got %v", err)
+	}
+
+	_, err = sysprims.ListThreads(99999999)
+	if !errors.As(err, &sErr) || (sErr.Code != sysprims.ErrNotFound && sErr.Code != sysprims.ErrNotSupported) {
+		t.Errorf("ListThreads(missing) expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListFdsSelf(t *testing.T) {
+	pid := uint32(os.Getpid())
+	snap, err := sysprims.ListFds(pid, nil)
+
+	if runtime.GOOS == "windows" {
+		if err == nil {
+			t.Fatalf("expected ListFds to fail on windows")
+		}
+		if sErr, ok := err.(*sysprims.Error); ok {
+			if sErr.Code != sysprims.ErrNotSupported {
+				t.Fatalf("expected ErrNotSupported, got %d (%s)", sErr.Code, sErr.Code)
+			}
+		}
+		return
+	}
+
+	if err != nil {
+		t.Fatalf("ListFds(%d) failed: %v", pid, err)
+	}
+
+	if snap.SchemaID == "" {
+		t.Fatalf("ListFds returned empty schema_id")
+	}
+	if snap.Pid != pid {
+		t.Fatalf("ListFds returned wrong pid: got %d expected %d", snap.Pid, pid)
+	}
+	if len(snap.Fds) == 0 {
+		t.Fatalf("ListFds returned empty fd list; warnings=%v", snap.Warnings)
+	}
+}
+
+func TestListFdsPathContains(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ListFds is not supported on windows")
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "sysprims-fd-*.log")
+	if err != nil {
+		t.Fatalf("CreateTemp failed: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	needle := filepath.Base(f.Name())
+	snap, err := sysprims.ListFds(uint32(os.Getpid()), &sysprims.FdFilter{PathContains: &needle})
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	if len(snap.Fds) == 0 {
+		t.Skipf("temp file fd not visible (path resolution is best-effort); warnings=%v", snap.Warnings)
+	}
+	for _, fd := range snap.Fds {
+		if fd.Path == nil || !strings.Contains(*fd.Path, needle) {
+			t.Fatalf("fd %d does not match PathContains %q: %+v", fd.Fd, needle, fd)
+		}
+	}
+}
+
+// TestListFdsPathPrefixGlob verifies prefix and glob matching against files
+// opened in a temp dir.
+func TestListFdsPathPrefixGlob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ListFds is not supported on windows")
+	}
+
+	// Resolve symlinks (macOS /var -> /private/var) to match kernel paths.
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks failed: %v", err)
+	}
+	other, err := os.CreateTemp("", "sysprims-fd-other-*.log")
+	if err != nil {
+		t.Fatalf("CreateTemp failed: %v", err)
+	}
+	defer func() {
+		_ = other.Close()
+		_ = os.Remove(other.Name())
+	}()
+	for _, name := range []string{"a.log", "b.log", "c.txt"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Create(%s) failed: %v", name, err)
+		}
+		defer func() { _ = f.Close() }()
+	}
+
+	paths := func(filter *sysprims.FdFilter) []string {
+		t.Helper()
+		snap, err := sysprims.ListFds(uint32(os.Getpid()), filter)
+		if err != nil {
+			t.Fatalf("ListFds failed: %v", err)
+		}
+		var out []string
+		for _, fd := range snap.Fds {
+			out = append(out, filepath.Base(*fd.Path))
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	prefix := dir + string(filepath.Separator)
+	got := paths(&sysprims.FdFilter{PathPrefix: &prefix})
+	if len(got) == 0 {
+		t.Skip("temp file fds not visible (path resolution is best-effort)")
+	}
+	if !reflect.DeepEqual(got, []string{"a.log", "b.log", "c.txt"}) {
+		t.Errorf("PathPrefix = %v, want [a.log b.log c.txt]", got)
+	}
+
+	glob := filepath.Join(dir, "*.log")
+	if got := paths(&sysprims.FdFilter{PathGlob: &glob}); !reflect.DeepEqual(got, []string{"a.log", "b.log"}) {
+		t.Errorf("PathGlob = %v, want [a.log b.log]", got)
+	}
+
+	upper := strings.ToUpper(glob)
+	got = paths(&sysprims.FdFilter{PathGlob: &upper})
+	if runtime.GOOS == "darwin" && len(got) != 2 {
+		t.Errorf("PathGlob(upper) on darwin = %v, want case-insensitive match", got)
+	}
+	if runtime.GOOS == "linux" && len(got) != 0 {
+		t.Errorf("PathGlob(upper) on linux = %v, want case-sensitive", got)
+	}
+
+	for _, bad := range []string{"[", "x*[", "/tmp/[]", "/tmp/[a-"} {
+		var sErr *sysprims.Error
+		if _, err := sysprims.ListFds(uint32(os.Getpid()), &sysprims.FdFilter{PathGlob: &bad}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("ListFds(PathGlob %q) expected ErrInvalidArgument, got %v", bad, err)
+		}
+	}
+}
+
+// TestListFdsPaging verifies that paging with Offset and Limit visits every fd
+// in a range exactly once, in ascending order.
+func TestListFdsPaging(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ListFds is not supported on windows")
+	}
+
+	dir := t.TempDir()
+	var opened []uint32
+	for i := 0; i < 300; i++ {
+		f, err := os.Create(filepath.Join(dir, "f"+strconv.Itoa(i)))
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		opened = append(opened, uint32(f.Fd()))
+	}
+	sort.Slice(opened, func(i, j int) bool { return opened[i] < opened[j] })
+	lo, hi := opened[0], opened[len(opened)-1]
+
+	pid := uint32(os.Getpid())
+	const pageSize = 64
+	var seen []uint32
+	total := -1
+	for offset := 0; total < 0 || offset < total; offset += pageSize {
+		snap, err := sysprims.ListFds(pid, &sysprims.FdFilter{FdMin: &lo, FdMax: &hi, Offset: offset, Limit: pageSize})
+		if err != nil {
+			t.Fatalf("ListFds(offset %d) failed: %v", offset, err)
+		}
+		if total >= 0 && snap.TotalFds != total {
+			t.Fatalf("TotalFds changed between pages: %d -> %d", total, snap.TotalFds)
+		}
+		total = snap.TotalFds
+		if len(snap.Fds) > pageSize {
+			t.Fatalf("page has %d fds, want <= %d", len(snap.Fds), pageSize)
+		}
+		for _, fd := range snap.Fds {
+			seen = append(seen, fd.Fd)
+		}
+	}
+
+	if len(seen) != total {
+		t.Fatalf("paged %d fds, TotalFds = %d", len(seen), total)
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i] <= seen[i-1] {
+			t.Fatalf("fds not strictly ascending at %d: %d after %d", i, seen[i], seen[i-1])
+		}
+	}
+	index := make(map[uint32]bool, len(seen))
+	for _, fd := range seen {
+		index[fd] = true
+	}
+	for _, fd := range opened {
+		if !index[fd] {
+			t.Errorf("fd %d missing from paged listing", fd)
+		}
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.ListFds(pid, &sysprims.FdFilter{FdMin: &hi, FdMax: &lo}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListFds(FdMin > FdMax) expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.ListFds(pid, &sysprims.FdFilter{Limit: -1}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListFds(Limit -1) expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.ListFdsRaw(pid, &sysprims.FdFilter{Limit: 1}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListFdsRaw(Limit) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestListFdsMany verifies that readable PIDs get the same listing as ListFds
+// and that nonexistent PIDs are reported without failing the scan.
+func TestListFdsMany(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ListFds is not supported on windows")
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	self := uint32(os.Getpid())
+	child := uint32(cmd.Process.Pid)
+	const missing = uint32(math.MaxInt32 - 1)
+
+	scan, err := sysprims.ListFdsMany([]uint32{self, missing, child, self}, nil)
+	if err != nil {
+		t.Fatalf("ListFdsMany failed: %v", err)
+	}
+	if len(scan.Snapshots) != 2 || scan.Snapshots[self] == nil || scan.Snapshots[child] == nil {
+		t.Fatalf("snapshots for %v, want %d and %d", scan.Snapshots, self, child)
+	}
+	if e := scan.Errors[missing]; e == nil || e.Code != sysprims.ErrNotFound {
+		t.Errorf("Errors[missing] = %v, want ErrNotFound", e)
+	}
+	if len(scan.Errors) != 1 || len(scan.Warnings) != 1 || !strings.Contains(scan.Warnings[0], strconv.Itoa(int(missing))) {
+		t.Errorf("errors=%v warnings=%v, want one entry for %d", scan.Errors, scan.Warnings, missing)
+	}
+
+	single, err := sysprims.ListFds(child, nil)
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	got := scan.Snapshots[child]
+	if !reflect.DeepEqual(got.Fds, single.Fds) || got.Pid != child || got.TotalFds != single.TotalFds {
+		t.Errorf("ListFdsMany[child] = %+v, ListFds = %+v", got.Fds, single.Fds)
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.ListFdsMany([]uint32{self, 0}, nil); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("pid 0: expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestListFdsFlags verifies that FD_CLOEXEC and the access mode are reported
+// and that CloseOnExec filters on them.
+func TestListFdsFlags(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("fd flags are read on linux and macOS only")
+	}
+
+	path := filepath.Join(t.TempDir(), "flags")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	// os.Open sets O_CLOEXEC; syscall.Open does not.
+	cloexec, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer func() { _ = cloexec.Close() }()
+	leaky, err := syscall.Open(path, syscall.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("syscall.Open failed: %v", err)
+	}
+	defer func() { _ = syscall.Close(leaky) }()
+
+	pid := uint32(os.Getpid())
+	snap, err := sysprims.ListFds(pid, nil)
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	byFd := make(map[uint32]sysprims.FdInfo)
+	for _, fd := range snap.Fds {
+		byFd[fd.Fd] = fd
+	}
+
+	check := func(fd uint32, wantCloexec bool, wantAccess string) {
+		t.Helper()
+		info, ok := byFd[fd]
+		if !ok {
+			t.Fatalf("fd %d not listed", fd)
+		}
+		if info.CloseOnExec == nil || *info.CloseOnExec != wantCloexec {
+			t.Errorf("fd %d CloseOnExec = %v, want %v; warnings=%v", fd, info.CloseOnExec, wantCloexec, snap.Warnings)
+		}
+		if info.AccessMode == nil || *info.AccessMode != wantAccess {
+			t.Errorf("fd %d AccessMode = %v, want %q", fd, info.AccessMode, wantAccess)
+		}
+		if info.Flags == nil {
+			t.Errorf("fd %d Flags not set", fd)
+		}
+	}
+	check(uint32(cloexec.Fd()), true, "r")
+	check(uint32(leaky), false, "w")
+
+	no := false
+	leaks, err := sysprims.ListFds(pid, &sysprims.FdFilter{CloseOnExec: &no})
+	if err != nil {
+		t.Fatalf("ListFds(CloseOnExec false) failed: %v", err)
+	}
+	found := false
+	for _, fd := range leaks.Fds {
+		if fd.Fd == uint32(cloexec.Fd()) {
+			t.Errorf("close-on-exec fd %d matched CloseOnExec=false", fd.Fd)
+		}
+		if fd.Fd == uint32(leaky) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("leaky fd %d not matched by CloseOnExec=false", leaky)
+	}
+}
+
+// TestListFdsOffsets verifies that IncludeOffsets reports the read offset and
+// size of a regular file.
+func TestListFdsOffsets(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("fd offsets are read on linux and macOS only")
+	}
+
+	path := filepath.Join(t.TempDir(), "log")
+	if err := os.WriteFile(path, make([]byte, 4096), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Seek(1234, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+
+	fd := uint32(f.Fd())
+	pid := uint32(os.Getpid())
+	find := func(filter *sysprims.FdFilter) sysprims.FdInfo {
+		t.Helper()
+		snap, err := sysprims.ListFds(pid, filter)
+		if err != nil {
+			t.Fatalf("ListFds failed: %v", err)
+		}
+		for _, info := range snap.Fds {
+			if info.Fd == fd {
+				return info
+			}
+		}
+		t.Fatalf("fd %d not listed; warnings=%v", fd, snap.Warnings)
+		return sysprims.FdInfo{}
+	}
+
+	if info := find(&sysprims.FdFilter{FdMin: &fd, FdMax: &fd}); info.Offset != nil || info.FileSizeBytes != nil {
+		t.Errorf("offsets reported without IncludeOffsets: %v %v", info.Offset, info.FileSizeBytes)
+	}
+	info := find(&sysprims.FdFilter{FdMin: &fd, FdMax: &fd, IncludeOffsets: true})
+	if info.Offset == nil || *info.Offset != 1234 {
+		t.Errorf("Offset = %v, want 1234", info.Offset)
+	}
+	if info.FileSizeBytes == nil || *info.FileSizeBytes != 4096 {
+		t.Errorf("FileSizeBytes = %v, want 4096", info.FileSizeBytes)
+	}
+}
+
+// TestDeletedOpenFiles verifies that an unlinked but open file is flagged by
+// ListFds and reported by DeletedOpenFiles, while a linked file is not.
+func TestDeletedOpenFiles(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("deleted files are detected on linux and macOS only")
+	}
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks failed: %v", err)
+	}
+	keptPath := filepath.Join(dir, "kept.log")
+	kept, err := os.Create(keptPath)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	defer func() { _ = kept.Close() }()
+	gonePath := filepath.Join(dir, "gone.log")
+	gone, err := os.Create(gonePath)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	defer func() { _ = gone.Close() }()
+	if _, err := gone.Write(make([]byte, 2048)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := os.Remove(gonePath); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	pid := uint32(os.Getpid())
+	snap, err := sysprims.ListFds(pid, &sysprims.FdFilter{OnlyDeleted: true})
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	var found bool
+	for _, fd := range snap.Fds {
+		if fd.Fd == uint32(kept.Fd()) {
+			t.Errorf("linked file fd %d matched OnlyDeleted", fd.Fd)
+		}
+		if fd.Fd == uint32(gone.Fd()) {
+			found = true
+			if fd.Deleted == nil || !*fd.Deleted {
+				t.Errorf("Deleted = %v, want true", fd.Deleted)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("unlinked file fd %d not matched by OnlyDeleted; warnings=%v", gone.Fd(), snap.Warnings)
+	}
+
+	holders, err := sysprims.DeletedOpenFiles(&sysprims.ProcessFilter{PIDIn: []uint32{pid}})
+	if err != nil {
+		t.Fatalf("DeletedOpenFiles failed: %v", err)
+	}
+	for _, h := range holders {
+		if h.PID == pid && h.Path == gonePath {
+			if !reflect.DeepEqual(h.Fds, []uint32{uint32(gone.Fd())}) {
+				t.Errorf("Fds = %v, want [%d]", h.Fds, gone.Fd())
+			}
+			if h.SizeBytes == nil || *h.SizeBytes != 2048 {
+				t.Errorf("SizeBytes = %v, want 2048", h.SizeBytes)
+			}
+			return
+		}
+	}
+	t.Fatalf("%s not reported; holders=%+v", gonePath, holders)
+}
+
+// TestFdCount verifies that FdCount and FdCountsByKind agree with ListFds
+// for the current process.
+func TestFdCount(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("fd kinds are counted on linux and macOS only")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer func() { _ = r.Close(); _ = w.Close() }()
+
+	pid := uint32(os.Getpid())
+	snap, err := sysprims.ListFds(pid, nil)
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	want := map[string]uint32{}
+	for _, fd := range snap.Fds {
+		want[fd.Kind]++
+	}
+
+	// The runtime may open or close fds between calls; allow a little slack.
+	near := func(got, want uint32) bool { return got+2 >= want && got <= want+2 }
+	n, err := sysprims.FdCount(pid)
+	if err != nil {
+		t.Fatalf("FdCount failed: %v", err)
+	}
+	if !near(n, uint32(len(snap.Fds))) {
+		t.Errorf("FdCount = %d, ListFds found %d", n, len(snap.Fds))
+	}
+	counts, err := sysprims.FdCountsByKind(pid)
+	if err != nil {
+		t.Fatalf("FdCountsByKind failed: %v", err)
+	}
+	for kind, c := range counts {
+		if !near(c, want[kind]) {
+			t.Errorf("%s count = %d, ListFds found %d", kind, c, want[kind])
+		}
+	}
+	if counts["socket"] == 0 || counts["pipe"] < 2 {
+		t.Errorf("counts = %v, want a socket and two pipes", counts)
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.FdCount(0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("FdCount(0) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+// BenchmarkFdCount compares the count-only calls with a full ListFds of the
+// current process.
+func BenchmarkFdCount(b *testing.B) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		b.Skip("fd kinds are counted on linux and macOS only")
+	}
+	pid := uint32(os.Getpid())
+
+	b.Run("FdCount", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := sysprims.FdCount(pid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("FdCountsByKind", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := sysprims.FdCountsByKind(pid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ListFds", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := sysprims.ListFds(pid, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestWatchFdCounts verifies that a child leaking fds is detected as growing,
+// that a steady child is not, and that an exited child gets a final sample.
+func TestWatchFdCounts(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("fd kinds are counted on linux and macOS only")
+	}
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	leaker := exec.Command(bash, "-c", `for i in $(seq 10 70); do eval "exec $i</dev/null"; sleep 0.02; done; exec sleep 30`)
+	steady := exec.Command("sleep", "30")
+	for _, cmd := range []*exec.Cmd{leaker, steady} {
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start child: %v", err)
+		}
+		cmd := cmd
+		defer func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}()
+	}
+	leakPID, steadyPID := uint32(leaker.Process.Pid), uint32(steady.Process.Pid)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	samples, err := sysprims.WatchFdCounts(ctx, []uint32{leakPID, steadyPID}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchFdCounts failed: %v", err)
+	}
+
+	var got []sysprims.FdCountSample
+	deadline := time.After(1200 * time.Millisecond)
+	var exited bool
+collect:
+	for {
+		select {
+		case s, ok := <-samples:
+			if !ok {
+				break collect
+			}
+			got = append(got, s)
+			if s.PID == steadyPID && s.Exited {
+				exited = true
+				cancel()
+			}
+		case <-deadline:
+			deadline = nil
+			_ = steady.Process.Kill()
+			_ = steady.Wait()
+		}
+	}
+	if !exited {
+		t.Errorf("no Exited sample for pid %d", steadyPID)
+	}
+
+	growing := sysprims.DetectFdGrowth(got, 5)
+	if !reflect.DeepEqual(growing, []uint32{leakPID}) {
+		t.Errorf("DetectFdGrowth = %v, want [%d]; samples=%+v", growing, leakPID, got)
+	}
+
+	if _, err := sysprims.WatchFdCounts(ctx, []uint32{leakPID}, 0); err == nil {
+		t.Error("expected an error for a zero interval")
+	}
+}
+
+// TestDetectFdGrowth verifies the monotonic and slope criteria.
+func TestDetectFdGrowth(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	series := func(pid uint32, counts ...uint32) []sysprims.FdCountSample {
+		var out []sysprims.FdCountSample
+		for i, c := range counts {
+			out = append(out, sysprims.FdCountSample{PID: pid, Time: t0.Add(time.Duration(i) * time.Second), Count: c})
+		}
+		return out
+	}
+	var samples []sysprims.FdCountSample
+	samples = append(samples, series(3, 10, 20, 30, 40)...) // 10/s
+	samples = append(samples, series(1, 10, 12, 12, 14)...) // slow
+	samples = append(samples, series(2, 10, 50, 30, 60)...) // not monotonic
+	samples = append(samples, series(4, 10, 10, 10)...)     // flat
+	samples = append(samples, series(5, 10)...)             // one sample
+	samples = append(samples, sysprims.FdCountSample{PID: 1, Time: t0.Add(9 * time.Second), Exited: true})
+
+	if got := sysprims.DetectFdGrowth(samples, 5); !reflect.DeepEqual(got, []uint32{3}) {
+		t.Errorf("DetectFdGrowth(5) = %v, want [3]", got)
+	}
+	if got := sysprims.DetectFdGrowth(samples, 0); !reflect.DeepEqual(got, []uint32{1, 3}) {
+		t.Errorf("DetectFdGrowth(0) = %v, want [1 3]", got)
+	}
+}
+
+// TestWaitPIDWithOptions verifies exit detection at a short poll interval,
+// the Timeout bound, and context cancellation.
+func TestWaitPIDWithOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+
+	start := func(arg string) *exec.Cmd {
+		cmd := exec.Command("sleep", arg)
+		if err := cmd.Start(); err != nil {
+			t.Skipf("failed to start sleep: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+		return cmd
+	}
+
+	short := start("0.1")
+	began := time.Now()
+	res, err := sysprims.WaitPIDWithOptions(context.Background(), uint32(short.Process.Pid), sysprims.WaitPidOptions{PollInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitPIDWithOptions failed: %v", err)
+	}
+	if !res.Exited || res.TimedOut {
+		t.Errorf("exited=%v timed_out=%v, want exited", res.Exited, res.TimedOut)
+	}
+	if elapsed := time.Since(began); elapsed > 2*time.Second {
+		t.Errorf("exit noticed after %v", elapsed)
+	}
+
+	long := start("30")
+	pid := uint32(long.Process.Pid)
+	res, err = sysprims.WaitPIDWithOptions(context.Background(), pid, sysprims.WaitPidOptions{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitPIDWithOptions(Timeout) failed: %v", err)
+	}
+	if res.Exited || !res.TimedOut {
+		t.Errorf("Timeout: exited=%v timed_out=%v, want timed out", res.Exited, res.TimedOut)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := sysprims.WaitPIDWithOptions(ctx, pid, sysprims.WaitPidOptions{PollInterval: time.Hour}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.WaitPIDWithOptions(context.Background(), pid, sysprims.WaitPidOptions{PollInterval: -1}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("negative PollInterval: expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestWaitForProcess verifies WaitForProcess waits for a matching process to
+// appear and times out with ErrTimeout when none does.
+func TestWaitForProcess(t *testing.T) {
+	var sErr *sysprims.Error
+	if _, err := sysprims.WaitForProcess(nil, time.Second, 0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("WaitForProcess(nil) expected ErrInvalidArgument, got %v", err)
+	}
+	none := "no-such-process-name"
+	filter := &sysprims.ProcessFilter{NameEquals: &none}
+	if _, err := sysprims.WaitForProcess(filter, -time.Second, 0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("WaitForProcess(negative timeout) expected ErrInvalidArgument, got %v", err)
+	}
+	start := time.Now()
+	if _, err := sysprims.WaitForProcess(filter, 150*time.Millisecond, 40*time.Millisecond); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrTimeout {
+		t.Errorf("WaitForProcess(no match) expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("WaitForProcess timed out after %v, want >= 150ms", elapsed)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	// The child is named sleep only once sh execs it.
+	cmd := exec.Command("sh", "-c", "sleep 0.2; exec sleep 30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start sh: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	name := "sleep"
+	self := uint32(os.Getpid())
+	p, err := sysprims.WaitForProcess(&sysprims.ProcessFilter{NameEquals: &name, PPID: &self}, 5*time.Second, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForProcess failed: %v", err)
+	}
+	if p.PID != uint32(cmd.Process.Pid) {
+		t.Errorf("WaitForProcess returned pid %d, want %d", p.PID, cmd.Process.Pid)
+	}
+}
+
+// TestSupervisor verifies restart policies, backoff events, and that Stop
+// terminates running processes without respawning them.
+func TestSupervisor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh(1)")
+	}
+
+	sup, err := sysprims.NewSupervisor(sysprims.SupervisorConfig{
+		Processes: []sysprims.SupervisedProcess{
+			{Name: "flaky", Config: sysprims.SpawnInGroupConfig{Argv: []string{"sh", "-c", "exit 3"}}, Restart: sysprims.RestartOnFailure},
+			{Name: "once", Config: sysprims.SpawnInGroupConfig{Argv: []string{"true"}}, Restart: sysprims.RestartNever},
+			{Name: "server", Config: sysprims.SpawnInGroupConfig{Argv: []string{"sleep", "30"}}},
+		},
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     40 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewSupervisor failed: %v", err)
+	}
+	if err := sup.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	byName := map[string][]sysprims.SupervisorEvent{}
+	stopped := make(chan error, 1)
+	for ev := range sup.Events() {
+		byName[ev.Name] = append(byName[ev.Name], ev)
+		if ev.Name == "flaky" && ev.Kind == sysprims.SupervisorRestarting && ev.Restarts == 3 {
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				stopped <- sup.Stop(ctx)
+			}()
+		}
+	}
+	if err := <-stopped; err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+
+	kinds := func(name string) []sysprims.SupervisorEventKind {
+		var out []sysprims.SupervisorEventKind
+		for _, ev := range byName[name] {
+			out = append(out, ev.Kind)
+		}
+		return out
+	}
+	once := kinds("once")
+	if !reflect.DeepEqual(once, []sysprims.SupervisorEventKind{sysprims.SupervisorStarted, sysprims.SupervisorExited, sysprims.SupervisorGaveUp}) {
+		t.Errorf("once events = %v", once)
+	}
+	if ev := byName["once"][1]; ev.ExitCode == nil || *ev.ExitCode != 0 {
+		t.Errorf("once exit code = %v, want 0", ev.ExitCode)
+	}
+
+	var backoffs []time.Duration
+	for _, ev := range byName["flaky"] {
+		switch ev.Kind {
+		case sysprims.SupervisorExited:
+			if ev.ExitCode == nil || *ev.ExitCode != 3 {
+				t.Errorf("flaky exit code = %v, want 3", ev.ExitCode)
+			}
+		case sysprims.SupervisorRestarting:
+			backoffs = append(backoffs, ev.Backoff)
+		}
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond}
+	if len(backoffs) < len(want) || !reflect.DeepEqual(backoffs[:len(want)], want) {
+		t.Errorf("flaky backoffs = %v, want prefix %v", backoffs, want)
+	}
+
+	server := kinds("server")
+	if !reflect.DeepEqual(server, []sysprims.SupervisorEventKind{sysprims.SupervisorStarted, sysprims.SupervisorExited}) {
+		t.Errorf("server events = %v, want started then exited without restart", server)
+	}
+	if pid := byName["server"][0].PID; pid != 0 {
+		if err := sysprims.Kill(pid, 0); err == nil {
+			t.Errorf("server pid %d still alive after Stop", pid)
+		}
+	}
+	if len(sup.Running()) != 0 {
+		t.Errorf("Running() = %v after Stop", sup.Running())
+	}
+
+	if _, err := sysprims.NewSupervisor(sysprims.SupervisorConfig{}); err == nil {
+		t.Error("expected an error for an empty supervisor")
+	}
+}
+
+// TestReap verifies that exited children are reaped with their exit status,
+// running children are left alone, and non-children are rejected.
+func TestReap(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no zombies")
+	}
+
+	spawn := func(argv ...string) uint32 {
+		res, err := sysprims.SpawnInGroup(sysprims.SpawnInGroupConfig{Argv: argv})
+		if err != nil {
+			t.Skipf("SpawnInGroup failed: %v", err)
+		}
+		return res.PID
+	}
+
+	running := spawn("sleep", "30")
+	defer func() {
+		_ = sysprims.Kill(running, 9)
+		_, _ = sysprims.WaitPID(running, time.Second)
+		_, _ = sysprims.Reap(running)
+	}()
+	res, err := sysprims.Reap(running)
+	if err != nil {
+		t.Fatalf("Reap(running) failed: %v", err)
+	}
+	if res.Exited || !res.TimedOut {
+		t.Errorf("Reap(running): exited=%v timed_out=%v", res.Exited, res.TimedOut)
+	}
+
+	failed := spawn("sh", "-c", "exit 7")
+	if _, err := sysprims.WaitPID(failed, 5*time.Second); err != nil {
+		t.Fatalf("WaitPID failed: %v", err)
+	}
+	res, err = sysprims.Reap(failed)
+	if err != nil {
+		t.Fatalf("Reap(exited) failed: %v", err)
+	}
+	if !res.Exited || res.ExitCode == nil || *res.ExitCode != 7 {
+		t.Errorf("Reap(exited): exited=%v exit_code=%v, want 7", res.Exited, res.ExitCode)
+	}
+	var sErr *sysprims.Error
+	if _, err := sysprims.Reap(failed); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("second Reap: expected ErrNotFound, got %v", err)
+	}
+
+	if os.Getppid() > 1 {
+		if _, err := sysprims.Reap(uint32(os.Getppid())); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("Reap(parent): expected ErrInvalidArgument, got %v", err)
+		}
+	}
+
+	a, b := spawn("true"), spawn("true")
+	for _, pid := range []uint32{a, b} {
+		if _, err := sysprims.WaitPID(pid, 5*time.Second); err != nil {
+			t.Fatalf("WaitPID failed: %v", err)
+		}
+	}
+	all, err := sysprims.ReapAll()
+	if err != nil {
+		t.Fatalf("ReapAll failed: %v", err)
+	}
+	reaped := map[uint32]bool{}
+	for _, r := range all {
+		reaped[r.PID] = r.Exited
+	}
+	if !reaped[a] || !reaped[b] || len(reaped) != len(all) {
+		t.Errorf("ReapAll = %+v, want %d and %d", all, a, b)
+	}
+	if _, ok := reaped[running]; ok {
+		t.Errorf("ReapAll reaped running child %d", running)
+	}
+}
+
+// TestSpawnInGroupHandle verifies the handle reports real exit codes,
+// signals only a live child, and terminates the child's group.
+func TestSpawnInGroupHandle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and sleep")
+	}
+
+	failed, err := sysprims.SpawnInGroupHandle(sysprims.SpawnInGroupConfig{Argv: []string{"sh", "-c", "exit 7"}})
+	if err != nil {
+		t.Skipf("SpawnInGroupHandle failed: %v", err)
+	}
+	res, err := failed.Wait(5 * time.Second)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if !res.Exited || res.ExitCode == nil || *res.ExitCode != 7 {
+		t.Errorf("Wait = exited %v code %v, want exit code 7", res.Exited, res.ExitCode)
+	}
+	if again, err := failed.Wait(0); err != nil || again.ExitCode == nil || *again.ExitCode != 7 {
+		t.Errorf("second Wait = %+v, %v; want the same exit code", again, err)
+	}
+	var sErr *sysprims.Error
+	if err := failed.Kill(sysprims.SIGTERM); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("Kill after exit: expected ErrNotFound, got %v", err)
+	}
+
+	running, err := sysprims.SpawnInGroupHandle(sysprims.SpawnInGroupConfig{Argv: []string{"sleep", "30"}})
+	if err != nil {
+		t.Fatalf("SpawnInGroupHandle failed: %v", err)
+	}
+	defer func() { _, _ = running.TerminateTree(sysprims.TerminateTreeConfig{}) }()
+	res, err = running.Wait(0)
+	if err != nil || res.Exited || !res.TimedOut {
+		t.Fatalf("Wait(0) on running child = %+v, %v; want timed out", res, err)
+	}
+	if err := running.Kill(sysprims.SIGTERM); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	select {
+	case <-running.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("child did not exit after SIGTERM")
+	}
+	res, err = running.Wait(0)
+	if err != nil || !res.Exited || res.ExitCode != nil || len(res.Warnings) == 0 ||
+		!res.Signaled || res.ExitSignal == nil || *res.ExitSignal != sysprims.SIGTERM {
+		t.Errorf("Wait after SIGTERM = %+v, %v; want signaled exit with a warning", res, err)
+	}
+
+	tree, err := sysprims.SpawnInGroupHandle(sysprims.SpawnInGroupConfig{Argv: []string{"sleep", "30"}})
+	if err != nil {
+		t.Fatalf("SpawnInGroupHandle failed: %v", err)
+	}
+	if _, err := tree.TerminateTree(sysprims.TerminateTreeConfig{}); err != nil {
+		t.Fatalf("TerminateTree failed: %v", err)
+	}
+	if res, err := tree.Wait(5 * time.Second); err != nil || !res.Exited {
+		t.Errorf("Wait after TerminateTree = %+v, %v; want exited", res, err)
+	}
+}
+
+// TestTerminateTreeTargets verifies a dry run lists the group members without
+// signaling them, and a real run reports each member's exit.
+func TestTerminateTreeTargets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and process groups")
+	}
+
+	spawned, err := sysprims.SpawnInGroup(sysprims.SpawnInGroupConfig{Argv: []string{"sh", "-c", "sleep 30 & sleep 30 & wait"}})
+	if err != nil {
+		t.Skipf("SpawnInGroup failed: %v", err)
+	}
+	pid := spawned.PID
+	defer func() { _ = sysprims.KillGroup(pid, sysprims.SIGKILL) }()
+
+	var dry *sysprims.TerminateTreeResult
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dry, err = sysprims.TerminateTree(pid, sysprims.TerminateTreeConfig{DryRun: true})
+		if err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+		if len(dry.Targets) == 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(dry.Targets) != 3 || dry.PGID == nil || *dry.PGID != pid || len(dry.Processes) != 0 {
+		t.Fatalf("dry run = %+v; want the shell and both sleeps", dry)
+	}
+	if !sort.SliceIsSorted(dry.Targets, func(i, j int) bool { return dry.Targets[i] < dry.Targets[j] }) {
+		t.Errorf("dry run Targets %v not sorted", dry.Targets)
+	}
+	for _, target := range dry.Targets {
+		if err := sysprims.Kill(target, 0); err != nil {
+			t.Errorf("target %d not running after dry run: %v", target, err)
+		}
+	}
+
+	grace := uint64(5000)
+	res, err := sysprims.TerminateTree(pid, sysprims.TerminateTreeConfig{GraceTimeoutMS: &grace})
+	if err != nil {
+		t.Fatalf("TerminateTree failed: %v", err)
+	}
+	if !reflect.DeepEqual(res.Targets, dry.Targets) || len(res.Processes) != len(res.Targets) {
+		t.Fatalf("Targets = %v with %d outcomes; want %v", res.Targets, len(res.Processes), dry.Targets)
+	}
+	// The library waits only for the leader; the sleeps were signaled with
+	// it but may still be exiting.
+	for i, p := range res.Processes {
+		if p.PID != res.Targets[i] || p.Escalated || (p.PID == pid && !p.Exited) {
+			t.Errorf("outcome %d = %+v; want pid %d without escalation", i, p, res.Targets[i])
+		}
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.TerminateTree(0, sysprims.TerminateTreeConfig{DryRun: true}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("dry run of pid 0: expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestSpawnInGroupStdio verifies redirection of stdin from a path, stdout to
+// a path (truncating, then appending), and stderr to a caller-owned file.
+func TestSpawnInGroupStdio(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in")
+	outPath := filepath.Join(dir, "out")
+	errPath := filepath.Join(dir, "err")
+	if err := os.WriteFile(inPath, []byte("hello\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outPath, []byte("stale\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(appendOut bool) {
+		t.Helper()
+		errFile, err := os.Create(errPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := sysprims.SpawnInGroupHandle(sysprims.SpawnInGroupConfig{
+			Argv:   []string{"sh", "-c", `read x; echo "in:$x"; echo oops >&2`},
+			Stdin:  &sysprims.StdioTarget{Path: inPath},
+			Stdout: &sysprims.StdioTarget{Path: outPath, Append: appendOut},
+			Stderr: &sysprims.StdioTarget{File: errFile},
+		})
+		// The child holds its own copy of the descriptor.
+		_ = errFile.Close()
+		if err != nil {
+			var sErr *sysprims.Error
+			if runtime.GOOS == "windows" && errors.As(err, &sErr) && sErr.Code == sysprims.ErrNotSupported {
+				t.Skip("stdio redirection is not supported on windows")
+			}
+			t.Fatalf("SpawnInGroupHandle failed: %v", err)
+		}
+		res, err := h.Wait(5 * time.Second)
+		if err != nil || res.ExitCode == nil || *res.ExitCode != 0 {
+			t.Fatalf("Wait = %+v, %v; want exit code 0", res, err)
+		}
+		if res.PID == 0 || h.PGID == nil || *h.PGID != h.PID {
+			t.Errorf("child is not a group leader: pid %d pgid %v", h.PID, h.PGID)
+		}
+	}
+
+	run(false)
+	if got, _ := os.ReadFile(outPath); string(got) != "in:hello\n" {
+		t.Errorf("stdout = %q, want %q", got, "in:hello\n")
+	}
+	if got, _ := os.ReadFile(errPath); string(got) != "oops\n" {
+		t.Errorf("stderr = %q, want %q", got, "oops\n")
+	}
+	run(true)
+	if got, _ := os.ReadFile(outPath); string(got) != "in:hello\nin:hello\n" {
+		t.Errorf("appended stdout = %q", got)
+	}
+
+	var sErr *sysprims.Error
+	_, err := sysprims.SpawnInGroup(sysprims.SpawnInGroupConfig{
+		Argv:   []string{"true"},
+		Stdout: &sysprims.StdioTarget{Path: outPath, File: os.Stdout},
+	})
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("Path and File both set: expected ErrInvalidArgument, got %v", err)
+	}
+	_, err = sysprims.SpawnInGroup(sysprims.SpawnInGroupConfig{
+		Argv:   []string{"sysprims-no-such-command"},
+		Stdout: &sysprims.StdioTarget{Path: os.DevNull},
+	})
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("missing command: expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestSpawnInGroupDetached verifies a detached child leads a new session
+// (a console process group on Windows) and process group and can still be
+// tree-killed.
+func TestSpawnInGroupDetached(t *testing.T) {
+	cfg := sysprims.SpawnInGroupConfig{
+		Argv:     []string{"sleep", "30"},
+		Stdout:   &sysprims.StdioTarget{Path: os.DevNull},
+		Detached: true,
+	}
+	if runtime.GOOS == "windows" {
+		// Stdio cannot be redirected there, and the child has no console.
+		cfg.Argv, cfg.Stdout = []string{"ping", "-n", "30", "127.0.0.1"}, nil
+	}
+	h, err := sysprims.SpawnInGroupHandle(cfg)
+	if err != nil {
+		t.Fatalf("SpawnInGroupHandle failed: %v", err)
+	}
+	defer func() { _, _ = h.TerminateTree(sysprims.TerminateTreeConfig{}) }()
+
+	if runtime.GOOS == "windows" {
+		if h.SID != nil || h.PGID == nil || *h.PGID != h.PID || h.TreeKillReliability != "best_effort" {
+			t.Errorf("pid %d: sid %v pgid %v reliability %q, want no sid, pgid equal to pid, best_effort",
+				h.PID, h.SID, h.PGID, h.TreeKillReliability)
+		}
+	} else if h.SID == nil || *h.SID != h.PID || h.PGID == nil || *h.PGID != h.PID {
+		t.Errorf("pid %d: sid %v pgid %v, want both equal to pid", h.PID, h.SID, h.PGID)
+	}
+	if runtime.GOOS == "linux" {
+		// Fields after comm: state, ppid, pgrp, session.
+		sessionOf := func(pid int) string {
+			data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+			if err != nil {
+				t.Fatalf("read stat: %v", err)
+			}
+			fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+			return fields[3]
+		}
+		if got := sessionOf(int(h.PID)); got != strconv.Itoa(int(h.PID)) {
+			t.Errorf("child session = %s, want %d", got, h.PID)
+		}
+		if sessionOf(int(h.PID)) == sessionOf(os.Getpid()) {
+			t.Error("child shares the test's session")
+		}
+	}
+
+	if _, err := h.TerminateTree(sysprims.TerminateTreeConfig{}); err != nil {
+		t.Fatalf("TerminateTree failed: %v", err)
+	}
+	if res, err := h.Wait(5 * time.Second); err != nil || !res.Exited {
+		t.Errorf("Wait after TerminateTree = %+v, %v; want exited", res, err)
+	}
+}
+
+// TestRunAsCredentials verifies SpawnInGroup and RunWithTimeout run the
+// child with the requested UID, GID, and supplementary groups.
+func TestRunAsCredentials(t *testing.T) {
+	nobody := uint32(65534)
+	var sErr *sysprims.Error
+	out := filepath.Join(t.TempDir(), "id")
+	h, err := sysprims.SpawnInGroupHandle(sysprims.SpawnInGroupConfig{
+		Argv:              []string{"sh", "-c", "echo $(id -u) $(id -g) $(id -G)"},
+		Stdout:            &sysprims.StdioTarget{Path: out},
+		RunAsUID:          &nobody,
+		RunAsGID:          &nobody,
+		SupplementaryGIDs: []uint32{nobody},
+	})
+	switch {
+	case runtime.GOOS == "windows":
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("windows: expected ErrNotSupported, got %v", err)
+		}
+		return
+	case os.Geteuid() != 0:
+		if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrPermissionDenied {
+			t.Errorf("unprivileged: expected ErrPermissionDenied, got %v", err)
+		}
+		if _, err := sysprims.RunWithTimeout("true", nil, time.Second, sysprims.TimeoutConfig{RunAsUID: &nobody}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrPermissionDenied {
+			t.Errorf("RunWithTimeout unprivileged: expected ErrPermissionDenied, got %v", err)
+		}
+		return
+	case err != nil:
+		t.Fatalf("SpawnInGroupHandle failed: %v", err)
+	}
+	if res, err := h.Wait(5 * time.Second); err != nil || !res.Exited {
+		t.Fatalf("Wait = %+v, %v", res, err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "65534 65534 65534" {
+		t.Errorf("child ids = %q, want 65534 65534 65534", got)
+	}
+
+	// Supplementary groups are cleared when only the IDs are set.
+	cfg := sysprims.DefaultTimeoutConfig()
+	cfg.RunAsUID, cfg.RunAsGID = &nobody, &nobody
+	res, err := sysprims.RunWithTimeout("sh", []string{"-c", `test "$(id -u) $(id -G)" = "65534 65534"`}, 5*time.Second, cfg)
+	if err != nil || !res.Completed() || res.ExitCode == nil || *res.ExitCode != 0 || res.Signaled {
+		t.Errorf("RunWithTimeout as nobody = %+v, %v; want exit 0", res, err)
+	}
+
+	res, err = sysprims.RunWithTimeout("sh", []string{"-c", "kill -KILL $$"}, 5*time.Second, cfg)
+	if err != nil || !res.Completed() || res.ExitCode != nil || !res.Signaled ||
+		res.ExitSignal == nil || *res.ExitSignal != sysprims.SIGKILL {
+		t.Errorf("RunWithTimeout self-kill = %+v, %v; want signaled with SIGKILL", res, err)
+	}
+
+	cfg.KillAfter = 100 * time.Millisecond
+	res, err = sysprims.RunWithTimeout("sleep", []string{"30"}, 100*time.Millisecond, cfg)
+	if err != nil || !res.TimedOut() || res.SignalSent == nil || *res.SignalSent != sysprims.SIGTERM {
+		t.Errorf("RunWithTimeout timeout = %+v, %v; want timed out with SIGTERM", res, err)
+	}
+}
+
+func TestListFdsSocketDetail(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("socket details are resolved on linux only")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+
+	kind := "socket"
+	snap, err := sysprims.ListFds(uint32(os.Getpid()), &sysprims.FdFilter{Kind: &kind})
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+
+	for _, fd := range snap.Fds {
+		if fd.Socket == nil || fd.Socket.LocalPort != port {
+			continue
+		}
+		if fd.Socket.Protocol != sysprims.ProtocolTCP {
+			t.Fatalf("expected tcp socket, got %q", fd.Socket.Protocol)
+		}
+		if fd.Socket.LocalAddr == nil || *fd.Socket.LocalAddr != "127.0.0.1" {
+			t.Fatalf("unexpected local addr: %v", fd.Socket.LocalAddr)
+		}
+		if fd.Socket.State == nil || *fd.Socket.State != "listen" {
+			t.Fatalf("unexpected state: %v", fd.Socket.State)
+		}
+		return
+	}
+	t.Fatalf("listener port %d not found in socket fds; warnings=%v", port, snap.Warnings)
+}
+
+// TestListFdsSocketConnection verifies that both ends of an in-process TCP
+// connection and a unix listener are resolved with inodes and endpoints.
+func TestListFdsSocketConnection(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("socket details are resolved on linux only")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+	server, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	sockPath := filepath.Join(t.TempDir(), "s.sock")
+	unixLn, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("unix listen failed: %v", err)
+	}
+	defer func() { _ = unixLn.Close() }()
+
+	serverPort := uint16(listener.Addr().(*net.TCPAddr).Port)
+	clientPort := uint16(client.LocalAddr().(*net.TCPAddr).Port)
+
+	kind := "socket"
+	snap, err := sysprims.ListFds(uint32(os.Getpid()), &sysprims.FdFilter{Kind: &kind})
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+
+	var foundClient, foundServer, foundUnix bool
+	for _, fd := range snap.Fds {
+		s := fd.Socket
+		if s == nil {
+			t.Errorf("fd %d: socket details missing", fd.Fd)
+			continue
+		}
+		if s.Inode == 0 {
+			t.Errorf("fd %d: inode not set", fd.Fd)
+		}
+		switch {
+		case s.Protocol == sysprims.ProtocolTCP && s.LocalPort == clientPort &&
+			s.RemotePort != nil && *s.RemotePort == serverPort:
+			foundClient = true
+			if s.RemoteAddr == nil || *s.RemoteAddr != "127.0.0.1" {
+				t.Errorf("client remote addr = %v, want 127.0.0.1", s.RemoteAddr)
+			}
+			if s.State == nil || *s.State != "established" {
+				t.Errorf("client state = %v, want established", s.State)
+			}
+		case s.Protocol == sysprims.ProtocolTCP && s.LocalPort == serverPort &&
+			s.RemotePort != nil && *s.RemotePort == clientPort:
+			foundServer = true
+		case s.Protocol == sysprims.ProtocolUnix && s.LocalAddr != nil && *s.LocalAddr == sockPath:
+			foundUnix = true
+			if s.State == nil || *s.State != "listen" {
+				t.Errorf("unix listener state = %v, want listen", s.State)
+			}
+		}
+	}
+	if !foundClient || !foundServer || !foundUnix {
+		t.Fatalf("client=%v server=%v unix=%v; warnings=%v", foundClient, foundServer, foundUnix, snap.Warnings)
+	}
+}
+
+// TestNetnsHelper is not a real test. Run with SYSPRIMS_TEST_HELPER set to
+// "netns" and SYSPRIMS_TEST_SOCK to a path, it listens on that unix socket,
+// prints "ready", and waits for stdin to close.
+func TestNetnsHelper(t *testing.T) {
+	if os.Getenv("SYSPRIMS_TEST_HELPER") != "netns" {
+		return
+	}
+	ln, err := net.Listen("unix", os.Getenv("SYSPRIMS_TEST_SOCK"))
+	if err != nil {
+		fmt.Println("listen failed:", err)
+		os.Exit(1)
+	}
+	defer func() { _ = ln.Close() }()
+	fmt.Println("ready")
+	_, _ = io.Copy(io.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+// TestListFdsOtherNetns verifies a socket of a process in another network
+// namespace resolves from that namespace's tables, in ListFds and
+// ListFdsMany.
+func TestListFdsOtherNetns(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("socket details are resolved on linux only")
+	}
+	if _, err := exec.LookPath("unshare"); err != nil {
+		t.Skip("unshare not found")
+	}
+
+	sockPath := filepath.Join(t.TempDir(), "ns.sock")
+	cmd := exec.Command("unshare", "-rn", os.Args[0], "-test.run=^TestNetnsHelper$")
+	cmd.Env = append(os.Environ(), "SYSPRIMS_TEST_HELPER=netns", "SYSPRIMS_TEST_SOCK="+sockPath)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("StdinPipe failed: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe failed: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("unshare failed to start: %v", err)
+	}
+	defer func() {
+		_ = stdin.Close()
+		_ = cmd.Wait()
+	}()
+	if line, _ := bufio.NewReader(stdout).ReadString('\n'); line != "ready\n" {
+		t.Skipf("unshare -rn unavailable: %q", line)
+	}
+	pid := uint32(cmd.Process.Pid)
+
+	hasSock := func(snap *sysprims.FdSnapshot) bool {
+		for _, fd := range snap.Fds {
+			if fd.Socket != nil && fd.Socket.LocalAddr != nil && *fd.Socket.LocalAddr == sockPath {
+				return true
+			}
+		}
+		return false
+	}
+	kind := "socket"
+	snap, err := sysprims.ListFds(pid, &sysprims.FdFilter{Kind: &kind})
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	if !hasSock(snap) {
+		t.Errorf("ListFds: %s not resolved; warnings=%v", sockPath, snap.Warnings)
+	}
+
+	scan, err := sysprims.ListFdsMany([]uint32{uint32(os.Getpid()), pid}, &sysprims.FdFilter{Kind: &kind})
+	if err != nil {
+		t.Fatalf("ListFdsMany failed: %v", err)
+	}
+	if snap := scan.Snapshots[pid]; snap == nil || !hasSock(snap) {
+		t.Errorf("ListFdsMany: %s not resolved; errors=%v", sockPath, scan.Errors)
+	}
+}
+
+// TestProcessGetInvalidPID verifies that ProcessGet rejects PID 0.
+func TestProcessGetInvalidPID(t *testing.T) {
+	_, err := sysprims.ProcessGet(0)
+	if err == nil {
+		t.Error("ProcessGet(0) should return error")
+	}
+
+	sErr, ok := err.(*sysprims.Error)
+	if !ok {
+		t.Errorf("Expected *sysprims.Error, got %T", err)
+		return
+	}
+	if sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("Expected ErrInvalidArgument, got %d (%s)", sErr.Code, sErr.Code)
+	}
+}
+
+// TestProcessGetNonexistent verifies that ProcessGet returns NotFound for nonexistent PIDs.
+func TestProcessGetNonexistent(t *testing.T) {
+	_, err := sysprims.ProcessGet(99999999)
+	if err == nil {
+		t.Skip("PID 99999999 unexpectedly exists on this system")
+	}
+
+	sErr, ok := err.(*sysprims.Error)
+	if !ok {
+		t.Errorf("Expected *sysprims.Error, got %T", err)
+		return
+	}
+	if sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %d (%s)", sErr.Code, sErr.Code)
+	}
+}
+
+func TestSelfSessionIDs(t *testing.T) {
+	pgid, err := sysprims.SelfPGID()
+	if runtime.GOOS == "windows" {
+		if err == nil {
+			t.Fatalf("expected SelfPGID to fail on windows")
+		}
+		if sErr, ok := err.(*sysprims.Error); ok {
+			if sErr.Code != sysprims.ErrNotSupported {
+				t.Fatalf("expected ErrNotSupported, got %d (%s)", sErr.Code, sErr.Code)
+			}
+		}
+	} else {
+		if err != nil {
+			t.Fatalf("SelfPGID failed: %v", err)
+		}
+		if pgid == 0 {
+			t.Fatalf("SelfPGID returned 0")
+		}
+	}
+
+	sid, err := sysprims.SelfSID()
+	if runtime.GOOS == "windows" {
+		if err == nil {
+			t.Fatalf("expected SelfSID to fail on windows")
+		}
+		if sErr, ok := err.(*sysprims.Error); ok {
+			if sErr.Code != sysprims.ErrNotSupported {
+				t.Fatalf("expected ErrNotSupported, got %d (%s)", sErr.Code, sErr.Code)
+			}
+		}
+	} else {
+		if err != nil {
+			t.Fatalf("SelfSID failed: %v", err)
+		}
+		if sid == 0 {
+			t.Fatalf("SelfSID returned 0")
+		}
+	}
+}
+
+func TestListeningPortsSelfListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && (errors.Is(opErr.Err, syscall.EPERM) || errors.Is(opErr.Err, syscall.EACCES)) {
+			t.Skipf("net.Listen denied in this environment: %v", err)
+		}
+		t.Fatalf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	port := uint16(addr.Port)
+	pid := uint32(os.Getpid())
+
+	proto := sysprims.ProtocolTCP
+	filter := &sysprims.PortFilter{Protocol: &proto, LocalPort: &port}
+
+	snap, err := sysprims.ListeningPorts(filter)
+	if err != nil {
+		// Best-effort: on macOS SIP/TCC or constrained runners, this may fail.
+		if sErr, ok := err.(*sysprims.Error); ok {
+			if sErr.Code == sysprims.ErrPermissionDenied {
+				t.Skipf("ListeningPorts PermissionDenied in this environment: %v", err)
+			}
+			if sErr.Code == sysprims.ErrNotSupported {
+				t.Skipf("ListeningPorts NotSupported in this environment: %v", err)
+			}
+		}
+		t.Fatalf("ListeningPorts failed: %v", err)
+	}
+
+	found := false
+	for _, b := range snap.Bindings {
+		if b.LocalPort == port && b.PID != nil && *b.PID == pid {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		// Best-effort: port-to-PID mapping requires elevated privileges on most platforms.
+		// - macOS: SIP/TCC restrictions
+		// - Linux: /proc/<pid>/fd requires root or same-user
+		// - Windows: netstat access may be limited
+		// CI runners typically don't have these privileges.
+		hasPermissionWarnings := false
+		for _, w := range snap.Warnings {
+			if strings.Contains(w, "permission") || strings.Contains(w, "Permission") {
+				hasPermissionWarnings = true
+				break
+			}
+		}
+		if runtime.GOOS == "darwin" || hasPermissionWarnings {
+			t.Logf("Did not find self listener pid=%d port=%d; warnings=%v bindings=%d (best-effort: permission-limited)", pid, port, snap.Warnings, len(snap.Bindings))
+			return
+		}
+		t.Fatalf("Did not find self listener pid=%d port=%d; warnings=%v bindings=%d", pid, port, snap.Warnings, len(snap.Bindings))
+	}
+}
+
+// TestListeningPortsWarningDetails verifies ListeningPorts classifies each
+// warning, in order, as ClassifyWarning would.
+func TestListeningPortsWarningDetails(t *testing.T) {
+	snap, err := sysprims.ListeningPorts(nil)
+	if err != nil {
+		t.Fatalf("ListeningPorts failed: %v", err)
+	}
+	if len(snap.WarningDetails) != len(snap.Warnings) {
+		t.Fatalf("WarningDetails has %d entries for %d warnings", len(snap.WarningDetails), len(snap.Warnings))
+	}
+	for i, w := range snap.Warnings {
+		if got, want := snap.WarningDetails[i], sysprims.ClassifyWarning(w); got != want {
+			t.Errorf("WarningDetails[%d] = %+v, want %+v", i, got, want)
+		}
+		if strings.Contains(strings.ToLower(w), "permission") && snap.WarningDetails[i].Code != sysprims.WarningPermissionDenied {
+			t.Errorf("warning %q classified as %q, want %q", w, snap.WarningDetails[i].Code, sysprims.WarningPermissionDenied)
+		}
+	}
+}
+
+// TestListeningPortsAddressFilters verifies the wildcard, loopback, and
+// exact-address filters, including IPv4-mapped forms.
+func TestListeningPortsAddressFilters(t *testing.T) {
+	loopback, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = loopback.Close() }()
+	wildcard, err := net.Listen("tcp6", "[::]:0")
+	if err != nil {
+		t.Skipf("net.Listen(::) failed: %v", err)
+	}
+	defer func() { _ = wildcard.Close() }()
+
+	pid := uint32(os.Getpid())
+	tcp := sysprims.ProtocolTCP
+	yes := true
+	// selfAddrs returns the local addresses of this process's TCP listeners
+	// selected by filter.
+	selfAddrs := func(filter *sysprims.PortFilter) []string {
+		t.Helper()
+		filter.Protocol = &tcp
+		snap, err := sysprims.ListeningPorts(filter)
+		if err != nil {
+			var sErr *sysprims.Error
+			if errors.As(err, &sErr) && (sErr.Code == sysprims.ErrNotSupported || sErr.Code == sysprims.ErrPermissionDenied) {
+				t.Skipf("ListeningPorts unavailable: %v", err)
+			}
+			t.Fatalf("ListeningPorts failed: %v", err)
+		}
+		var addrs []string
+		for _, b := range snap.Bindings {
+			if b.PID != nil && *b.PID == pid && b.LocalAddr != nil {
+				addrs = append(addrs, *b.LocalAddr)
+			}
+		}
+		sort.Strings(addrs)
+		return addrs
+	}
+
+	all := selfAddrs(&sysprims.PortFilter{})
+	if len(all) != 2 {
+		t.Skipf("listeners not attributed to this process: %v", all)
+	}
+	if got := selfAddrs(&sysprims.PortFilter{WildcardOnly: &yes}); !reflect.DeepEqual(got, []string{"::"}) {
+		t.Errorf("WildcardOnly = %v, want [::]", got)
+	}
+	if got := selfAddrs(&sysprims.PortFilter{LoopbackOnly: &yes}); !reflect.DeepEqual(got, []string{"127.0.0.1"}) {
+		t.Errorf("LoopbackOnly = %v, want [127.0.0.1]", got)
+	}
+	mapped := "::ffff:127.0.0.1"
+	if got := selfAddrs(&sysprims.PortFilter{LocalAddrEquals: &mapped}); !reflect.DeepEqual(got, []string{"127.0.0.1"}) {
+		t.Errorf("LocalAddrEquals(%s) = %v, want [127.0.0.1]", mapped, got)
+	}
+	zero := "0.0.0.0"
+	if got := selfAddrs(&sysprims.PortFilter{LocalAddrEquals: &zero}); len(got) != 0 {
+		t.Errorf("LocalAddrEquals(0.0.0.0) = %v, want none (:: is a different address)", got)
+	}
+
+	var sErr *sysprims.Error
+	bad := "localhost"
+	if _, err := sysprims.ListeningPorts(&sysprims.PortFilter{LocalAddrEquals: &bad}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("LocalAddrEquals(localhost): expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.ListeningPortsRaw(&sysprims.PortFilter{LoopbackOnly: &yes}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListeningPortsRaw(LoopbackOnly): expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestListeningPortsAddressFamilies verifies Family, V6Only, and ScopeID on
+// IPv4, IPv6, dual-stack, IPv4-mapped, and link-local listeners, and that
+// address filters match a mapped listener in either spelling.
+func TestListeningPortsAddressFamilies(t *testing.T) {
+	linux := runtime.GOOS == "linux"
+	listen := func(network, host string) uint16 {
+		t.Helper()
+		ln, err := net.Listen(network, net.JoinHostPort(host, "0"))
+		if err != nil {
+			t.Skipf("net.Listen(%s, %s) failed: %v", network, host, err)
+		}
+		t.Cleanup(func() { _ = ln.Close() })
+		return uint16(ln.Addr().(*net.TCPAddr).Port)
+	}
+	v4Port := listen("tcp4", "127.0.0.1")
+	v6Port := listen("tcp6", "::1")
+	dualPort := listen("tcp", "")
+
+	// Go binds IPv4-mapped addresses on IPv4 sockets, so the mapped listener
+	// is created directly.
+	var mappedPort uint16
+	if linux {
+		fd, err := syscall.Socket(syscall.AF_INET6, syscall.SOCK_STREAM, 0)
+		if err != nil {
+			t.Fatalf("socket: %v", err)
+		}
+		defer func() { _ = syscall.Close(fd) }()
+		sa := &syscall.SockaddrInet6{Addr: [16]byte{10: 0xff, 11: 0xff, 12: 127, 15: 1}}
+		if err := syscall.Bind(fd, sa); err != nil {
+			t.Fatalf("bind [::ffff:127.0.0.1]: %v", err)
+		}
+		if err := syscall.Listen(fd, 1); err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		bound, err := syscall.Getsockname(fd)
+		if err != nil {
+			t.Fatalf("getsockname: %v", err)
+		}
+		mappedPort = uint16(bound.(*syscall.SockaddrInet6).Port)
+	}
+
+	var linkLocal *net.Interface
+	var linkLocalPort uint16
+	if ifaces, err := net.Interfaces(); err == nil {
+	search:
+		for i := range ifaces {
+			addrs, _ := ifaces[i].Addrs()
+			for _, a := range addrs {
+				if ipn, ok := a.(*net.IPNet); ok && ipn.IP.To4() == nil && ipn.IP.IsLinkLocalUnicast() {
+					ln, err := net.Listen("tcp6", "["+ipn.IP.String()+"%"+ifaces[i].Name+"]:0")
+					if err != nil {
+						continue
+					}
+					t.Cleanup(func() { _ = ln.Close() })
+					linkLocal = &ifaces[i]
+					linkLocalPort = uint16(ln.Addr().(*net.TCPAddr).Port)
+					break search
+				}
+			}
+		}
+	}
+
+	tcp := sysprims.ProtocolTCP
+	snap, err := sysprims.ListeningPorts(&sysprims.PortFilter{Protocol: &tcp})
+	if err != nil {
+		var sErr *sysprims.Error
+		if errors.As(err, &sErr) && (sErr.Code == sysprims.ErrNotSupported || sErr.Code == sysprims.ErrPermissionDenied) {
+			t.Skipf("ListeningPorts unavailable: %v", err)
+		}
+		t.Fatalf("ListeningPorts failed: %v", err)
+	}
+	byPort := make(map[uint16]sysprims.PortBinding)
+	for _, b := range snap.Bindings {
+		byPort[b.LocalPort] = b
+	}
+
+	check := func(name string, port uint16, addr string, family sysprims.AddressFamily, v6only *bool) {
+		t.Helper()
+		b, ok := byPort[port]
+		if !ok {
+			t.Errorf("%s listener on port %d not listed", name, port)
+			return
+		}
+		if b.LocalAddr == nil || *b.LocalAddr != addr || b.Family != family {
+			t.Errorf("%s: addr %v family %q, want %s %q", name, b.LocalAddr, b.Family, addr, family)
+		}
+		if !linux {
+			return
+		}
+		if (b.V6Only == nil) != (v6only == nil) || (v6only != nil && *b.V6Only != *v6only) {
+			t.Errorf("%s: V6Only = %v, want %v", name, b.V6Only, v6only)
+		}
+	}
+	yes, no := true, false
+	check("ipv4", v4Port, "127.0.0.1", sysprims.FamilyIPv4, nil)
+	check("ipv6", v6Port, "::1", sysprims.FamilyIPv6, &yes)
+	check("dual-stack", dualPort, "::", sysprims.FamilyIPv6, &no)
+	if linux {
+		check("mapped", mappedPort, "127.0.0.1", sysprims.FamilyIPv4, nil)
+
+		for _, addr := range []string{"::ffff:127.0.0.1", "127.0.0.1"} {
+			addr := addr
+			got, err := sysprims.ListeningPorts(&sysprims.PortFilter{Protocol: &tcp, LocalPort: &mappedPort, LocalAddrEquals: &addr})
+			if err != nil || len(got.Bindings) != 1 {
+				t.Errorf("LocalAddrEquals(%s) on mapped listener = %v, %v; want one binding", addr, got, err)
+			}
+		}
+	}
+	if linkLocal != nil {
+		b, ok := byPort[linkLocalPort]
+		switch {
+		case !ok:
+			// Only symmetric ports survive the listing's port decoding.
+		case linux && (b.ScopeID == nil || *b.ScopeID != uint32(linkLocal.Index)):
+			t.Errorf("link-local: ScopeID = %v, want %d", b.ScopeID, linkLocal.Index)
+		case linux && !strings.Contains(b.String(), "%"+linkLocal.Name):
+			t.Errorf("link-local: String() = %q, want zone %s", b.String(), linkLocal.Name)
+		}
+	}
+}
+
+// TestListeningPortsQueueStats verifies IncludeQueueStats reports the
+// receive queue of a UDP socket that has unread datagrams, and that raw
+// listing rejects it.
+func TestListeningPortsQueueStats(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot bind udp: %v", err)
+	}
+	defer conn.Close()
+	port := uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+
+	sender, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer sender.Close()
+	for i := 0; i < 4; i++ {
+		if _, err := sender.Write([]byte("queued datagram")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	proto := sysprims.ProtocolUDP
+	filter := &sysprims.PortFilter{Protocol: &proto, LocalPort: &port, IncludeQueueStats: true}
+	var sErr *sysprims.Error
+	if _, err := sysprims.ListeningPortsRaw(filter); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListeningPortsRaw(IncludeQueueStats) expected ErrInvalidArgument, got %v", err)
+	}
+
+	var binding *sysprims.PortBinding
+	deadline := time.Now().Add(2 * time.Second)
+	for binding == nil && time.Now().Before(deadline) {
+		snapshot, err := sysprims.ListeningPorts(filter)
+		if err != nil {
+			t.Skipf("ListeningPorts unavailable: %v", err)
+		}
+		for i := range snapshot.Bindings {
+			b := &snapshot.Bindings[i]
+			if b.RxQueueBytes != nil && *b.RxQueueBytes > 0 {
+				binding = b
+			}
+		}
+		if binding == nil {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if binding == nil {
+			t.Fatal("no UDP binding with a nonzero receive queue")
+		}
+		if binding.TxQueueBytes == nil || binding.Drops == nil {
+			t.Errorf("TxQueueBytes/Drops not set: %+v", binding)
+		}
+	case "darwin":
+		if binding == nil {
+			t.Skip("UDP queue statistics unavailable (best-effort on macOS)")
+		}
+	default:
+		if binding != nil {
+			t.Errorf("unexpected queue statistics on %s: %+v", runtime.GOOS, binding)
+		}
+	}
+}
+
+// TestListeningPortsByPID verifies ByPID groups bindings by owner with a
+// sentinel for unattributed ones, and that DedupeProcesses stores each
+// owner's ProcessInfo once.
+func TestListeningPortsByPID(t *testing.T) {
+	pid := uint32(4242)
+	grouped := (&sysprims.PortBindingsSnapshot{Bindings: []sysprims.PortBinding{
+		{Protocol: sysprims.ProtocolTCP, LocalPort: 1, PID: &pid},
+		{Protocol: sysprims.ProtocolTCP, LocalPort: 2},
+		{Protocol: sysprims.ProtocolUDP, LocalPort: 3, PID: &pid},
+	}}).ByPID()
+	if len(grouped) != 2 || len(grouped[pid]) != 2 || grouped[pid][1].LocalPort != 3 ||
+		len(grouped[sysprims.UnattributedPID]) != 1 || grouped[sysprims.UnattributedPID][0].LocalPort != 2 {
+		t.Errorf("ByPID = %+v", grouped)
+	}
+
+	for i := 0; i < 2; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Skipf("net.Listen failed: %v", err)
+		}
+		defer func() { _ = listener.Close() }()
+	}
+
+	self := uint32(os.Getpid())
+	filter := &sysprims.PortFilter{PID: &self, DedupeProcesses: true}
+	var sErr *sysprims.Error
+	if _, err := sysprims.ListeningPortsRaw(filter); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListeningPortsRaw(DedupeProcesses) expected ErrInvalidArgument, got %v", err)
+	}
+	snapshot, err := sysprims.ListeningPorts(filter)
+	if err != nil {
+		t.Skipf("ListeningPorts unavailable: %v", err)
+	}
+	if mine := snapshot.ByPID()[self]; len(mine) < 2 {
+		t.Skipf("own listeners not attributed: %+v", snapshot.Bindings)
+	}
+	for _, b := range snapshot.Bindings {
+		if b.Process != nil {
+			t.Errorf("binding %d still carries Process", b.LocalPort)
+		}
+	}
+	if p := snapshot.Processes[self]; p == nil || p.PID != self {
+		t.Errorf("Processes[self] = %+v", p)
+	}
+}
+
+// TestListeningPortsBacklog verifies IncludeQueueStats reports the backlog
+// of a TCP listener and that BacklogCurrent rises as un-accepted
+// connections queue up; other platforms warn instead.
+func TestListeningPortsBacklog(t *testing.T) {
+	// port is set once the listener is bound; filter refers to it.
+	var port uint16
+	proto := sysprims.ProtocolTCP
+	filter := &sysprims.PortFilter{Protocol: &proto, LocalPort: &port, IncludeQueueStats: true}
+	listener := func() *sysprims.PortBinding {
+		t.Helper()
+		snapshot, err := sysprims.ListeningPorts(filter)
+		if err != nil {
+			t.Skipf("ListeningPorts unavailable: %v", err)
+		}
+		for i := range snapshot.Bindings {
+			if b := &snapshot.Bindings[i]; b.LocalAddr != nil && *b.LocalAddr == "127.0.0.1" {
+				return b
+			}
+		}
+		t.Fatalf("listener on port %d not found in %+v", port, snapshot.Bindings)
+		return nil
+	}
+
+	if runtime.GOOS != "linux" {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		defer ln.Close()
+		port = uint16(ln.Addr().(*net.TCPAddr).Port)
+		snapshot, err := sysprims.ListeningPorts(filter)
+		if err != nil {
+			t.Skipf("ListeningPorts unavailable: %v", err)
+		}
+		for _, b := range snapshot.Bindings {
+			if b.BacklogCurrent != nil || b.BacklogMax != nil {
+				t.Errorf("unexpected backlog on %s: %+v", runtime.GOOS, b)
+			}
+		}
+		warned := false
+		for _, w := range snapshot.WarningDetails {
+			warned = warned || w.Code == sysprims.WarningNotSupported
+		}
+		if !warned {
+			t.Errorf("expected a not-supported warning, got %v", snapshot.Warnings)
+		}
+		return
+	}
+
+	const backlog = 2
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatalf("socket: %v", err)
+	}
+	defer func() { _ = syscall.Close(fd) }()
+	if err := syscall.Bind(fd, &syscall.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if err := syscall.Listen(fd, backlog); err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	bound, err := syscall.Getsockname(fd)
+	if err != nil {
+		t.Fatalf("getsockname: %v", err)
+	}
+	port = uint16(bound.(*syscall.SockaddrInet4).Port)
+
+	b := listener()
+	if b.BacklogCurrent == nil || b.BacklogMax == nil {
+		t.Fatalf("backlog not set: %+v", b)
+	}
+	if *b.BacklogCurrent != 0 || *b.BacklogMax != backlog {
+		t.Errorf("idle listener backlog = %d/%d, want 0/%d", *b.BacklogCurrent, *b.BacklogMax, backlog)
+	}
+
+	// Connections beyond the backlog time out; the queued ones stay open
+	// until the test ends.
+	var conns sync.WaitGroup
+	opened := make(chan net.Conn, 2*backlog)
+	for i := 0; i < 2*backlog; i++ {
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			if c, err := net.DialTimeout("tcp", "127.0.0.1:"+strconv.Itoa(int(port)), 500*time.Millisecond); err == nil {
+				opened <- c
+			}
+		}()
+	}
+	conns.Wait()
+	close(opened)
+	for c := range opened {
+		defer c.Close()
+	}
+
+	b = listener()
+	if b.BacklogCurrent == nil || *b.BacklogCurrent == 0 {
+		t.Fatalf("BacklogCurrent did not rise: %+v", b)
+	}
+	if *b.BacklogCurrent > backlog+1 {
+		t.Errorf("BacklogCurrent = %d, exceeds backlog %d", *b.BacklogCurrent, backlog)
+	}
+}
+
+// TestPortBindingAddrAccessors verifies LocalAddrPort, IsLoopback, and
+// IsWildcard on every address shape listings emit.
+func TestPortBindingAddrAccessors(t *testing.T) {
+	str := func(s string) *string { return &s }
+	cases := []struct {
+		addr     *string
+		want     string
+		ok       bool
+		loopback bool
+		wildcard bool
+	}{
+		{addr: nil},
+		{addr: str("")},
+		{addr: str("not-an-ip")},
+		{addr: str("0.0.0.0"), want: "0.0.0.0:8080", ok: true, wildcard: true},
+		{addr: str("::"), want: "[::]:8080", ok: true, wildcard: true},
+		{addr: str("127.0.0.1"), want: "127.0.0.1:8080", ok: true, loopback: true},
+		{addr: str("::1"), want: "[::1]:8080", ok: true, loopback: true},
+		{addr: str("::ffff:127.0.0.1"), want: "127.0.0.1:8080", ok: true, loopback: true},
+		{addr: str("fe80::1%eth0"), want: "[fe80::1%eth0]:8080", ok: true},
+	}
+	for _, tc := range cases {
+		name := "<nil>"
+		if tc.addr != nil {
+			name = *tc.addr
+		}
+		b := sysprims.PortBinding{Protocol: sysprims.ProtocolTCP, LocalAddr: tc.addr, LocalPort: 8080}
+		c := sysprims.Connection{Protocol: sysprims.ProtocolTCP, LocalAddr: tc.addr, LocalPort: 8080}
+		for _, got := range []struct {
+			kind               string
+			addrPort           func() (netip.AddrPort, bool)
+			loopback, wildcard bool
+		}{
+			{"PortBinding", b.LocalAddrPort, b.IsLoopback(), b.IsWildcard()},
+			{"Connection", c.LocalAddrPort, c.IsLoopback(), c.IsWildcard()},
+		} {
+			ap, ok := got.addrPort()
+			if ok != tc.ok || (tc.ok && ap.String() != tc.want) {
+				t.Errorf("%s %s: LocalAddrPort = %v, %v; want %s, %v", got.kind, name, ap, ok, tc.want, tc.ok)
+			}
+			if got.loopback != tc.loopback || got.wildcard != tc.wildcard {
+				t.Errorf("%s %s: IsLoopback %v IsWildcard %v, want %v %v", got.kind, name, got.loopback, got.wildcard, tc.loopback, tc.wildcard)
+			}
+		}
+	}
+
+	// A ScopeID supplies the zone when the address carries none.
+	scope := uint32(1)
+	b := sysprims.PortBinding{LocalAddr: str("fe80::1"), LocalPort: 80, ScopeID: &scope}
+	if ap, ok := b.LocalAddrPort(); !ok || ap.Addr().Zone() == "" {
+		t.Errorf("scoped LocalAddrPort = %v, %v; want a zone", ap, ok)
+	}
+	if _, ok := b.RemoteAddrPort(); ok {
+		t.Error("RemoteAddrPort without a peer: ok = true")
+	}
+	remotePort := uint16(443)
+	c := sysprims.Connection{RemoteAddr: str("::ffff:10.0.0.1"), RemotePort: &remotePort}
+	if ap, ok := c.RemoteAddrPort(); !ok || ap.String() != "10.0.0.1:443" {
+		t.Errorf("Connection.RemoteAddrPort = %v, %v; want 10.0.0.1:443", ap, ok)
+	}
+}
+
+// TestListeningPortsForPID verifies the PID and process name filters select
+// a listener opened by the test process.
+func TestListeningPortsForPID(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	pid := uint32(os.Getpid())
+	snap, err := sysprims.ListeningPortsForPID(pid)
+	if err != nil {
+		var sErr *sysprims.Error
+		if errors.As(err, &sErr) && (sErr.Code == sysprims.ErrNotSupported || sErr.Code == sysprims.ErrPermissionDenied) {
+			t.Skipf("ListeningPorts unavailable: %v", err)
+		}
+		t.Fatalf("ListeningPortsForPID failed: %v", err)
+	}
+	if len(snap.Bindings) == 0 {
+		t.Skip("listener not attributed to this process")
+	}
+	for _, b := range snap.Bindings {
+		if b.PID == nil || *b.PID != pid {
+			t.Errorf("binding %v not owned by pid %d", b, pid)
+		}
+	}
+
+	self, err := sysprims.ProcessGet(pid)
+	if err != nil {
+		t.Fatalf("ProcessGet failed: %v", err)
+	}
+	name := strings.ToUpper(self.Name)
+	byName, err := sysprims.ListeningPorts(&sysprims.PortFilter{ProcessNameContains: &name})
+	if err != nil {
+		t.Fatalf("ListeningPorts(ProcessNameContains) failed: %v", err)
+	}
+	found := false
+	for _, b := range byName.Bindings {
+		found = found || (b.PID != nil && *b.PID == pid)
+	}
+	if !found {
+		t.Errorf("ProcessNameContains %q did not match this process's listener", name)
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.ListeningPortsForPID(0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListeningPortsForPID(0): expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := sysprims.ListeningPortsRaw(&sysprims.PortFilter{PID: &pid}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ListeningPortsRaw(PID): expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// freePort returns an ephemeral TCP port that nothing listens on, for
+// tests that open the listener later.
+func freePort(t *testing.T) uint16 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+// TestWaitForPort verifies WaitForPortListening sees a listener opened after
+// the wait begins, WaitForPortFree times out while it is open and returns
+// once it closes.
+func TestWaitForPort(t *testing.T) {
+	port := freePort(t)
+	if _, err := sysprims.ListeningPorts(nil); err != nil {
+		t.Skipf("ListeningPorts unavailable: %v", err)
+	}
+	opts := &sysprims.PortWaitOptions{Interval: 20 * time.Millisecond}
+
+	listening := make(chan net.Listener, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ln, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(int(port)))
+		if err != nil {
+			t.Errorf("net.Listen failed: %v", err)
+		}
+		listening <- ln
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	b, err := sysprims.WaitForPortListening(ctx, sysprims.ProtocolTCP, port, opts)
+	ln := <-listening
+	if ln == nil {
+		return
+	}
+	defer func() { _ = ln.Close() }()
+	if err != nil {
+		t.Fatalf("WaitForPortListening failed: %v", err)
+	}
+	if b.LocalPort != port {
+		t.Errorf("LocalPort = %d, want %d", b.LocalPort, port)
+	}
+	if b.PID != nil && *b.PID != uint32(os.Getpid()) {
+		t.Errorf("PID = %d, want %d", *b.PID, os.Getpid())
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancelShort()
+	err = sysprims.WaitForPortFree(short, sysprims.ProtocolTCP, port, opts)
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) || sErr.Code != sysprims.ErrTimeout || !strings.Contains(sErr.Message, "still bound") {
+		t.Errorf("WaitForPortFree while listening: expected ErrTimeout naming the binding, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = ln.Close()
+	}()
+	if err := sysprims.WaitForPortFree(ctx, sysprims.ProtocolTCP, port, opts); err != nil {
+		t.Errorf("WaitForPortFree failed: %v", err)
+	}
+
+	if _, err := sysprims.WaitForPortListening(ctx, sysprims.ProtocolTCP, 0, nil); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("port 0: expected ErrInvalidArgument, got %v", err)
+	}
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := sysprims.WaitForPortListening(canceled, sysprims.ProtocolTCP, port, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled ctx: expected context.Canceled, got %v", err)
+	}
+}
+
+// TestWatchPorts verifies WatchPorts reports a listener opening and then
+// closing, and closes the channel on cancellation.
+func TestWatchPorts(t *testing.T) {
+	port := freePort(t)
+	tcp := sysprims.ProtocolTCP
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := sysprims.WatchPorts(ctx, &sysprims.PortFilter{Protocol: &tcp, LocalPort: &port}, 20*time.Millisecond)
+	if err != nil {
+		t.Skipf("WatchPorts unavailable: %v", err)
+	}
+	var sErr *sysprims.Error
+	if _, err := sysprims.WatchPorts(ctx, nil, 0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("interval 0: expected ErrInvalidArgument, got %v", err)
+	}
+
+	next := func(want sysprims.PortEventKind) sysprims.PortEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Kind != want || e.Binding.LocalPort != port || e.Initial {
+				t.Fatalf("event = %+v, want %s on port %d", e, want, port)
+			}
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", want)
+		}
+		return sysprims.PortEvent{}
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(int(port)))
+	if err != nil {
+		t.Fatalf("net.Listen failed: %v", err)
+	}
+	opened := next(sysprims.PortOpened)
+	if opened.Binding.PID != nil && *opened.Binding.PID != uint32(os.Getpid()) {
+		t.Errorf("opened PID = %d, want %d", *opened.Binding.PID, os.Getpid())
+	}
+	_ = ln.Close()
+	closed := next(sysprims.PortClosed)
+	if !reflect.DeepEqual(closed.Binding.PID, opened.Binding.PID) {
+		t.Errorf("closed PID = %v, want the opened binding's %v", closed.Binding.PID, opened.Binding.PID)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+// TestProcessForPort verifies the owner lookup for a port the test process
+// listens on over IPv4 and, when available, IPv6.
+func TestProcessForPort(t *testing.T) {
+	v4, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = v4.Close() }()
+	port := uint16(v4.Addr().(*net.TCPAddr).Port)
+	wantBindings := 1
+	if v6, err := net.Listen("tcp6", "[::1]:"+strconv.Itoa(int(port))); err == nil {
+		defer func() { _ = v6.Close() }()
+		wantBindings = 2
+	}
+
+	pid := uint32(os.Getpid())
+	info, binding, err := sysprims.ProcessForPort(sysprims.ProtocolTCP, port)
+	var sErr *sysprims.Error
+	if errors.As(err, &sErr) && (sErr.Code == sysprims.ErrNotSupported || sErr.Code == sysprims.ErrPermissionDenied) {
+		t.Skipf("port attribution unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("ProcessForPort failed: %v", err)
+	}
+	if info.PID != pid || binding.LocalPort != port || binding.PID == nil || *binding.PID != pid {
+		t.Errorf("ProcessForPort = pid %d, binding %v; want pid %d on port %d", info.PID, binding, pid, port)
+	}
+
+	owners, err := sysprims.ProcessesForPort(sysprims.ProtocolTCP, port)
+	if err != nil {
+		t.Fatalf("ProcessesForPort failed: %v", err)
+	}
+	if len(owners.Bindings) != wantBindings {
+		t.Errorf("ProcessesForPort found %d bindings, want %d", len(owners.Bindings), wantBindings)
+	}
+	if len(owners.Processes) != 1 || owners.Processes[0].PID != pid {
+		t.Errorf("ProcessesForPort owners = %v, want only pid %d", owners.Processes, pid)
+	}
+
+	idle := freePort(t)
+	if _, _, err := sysprims.ProcessForPort(sysprims.ProtocolTCP, idle); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("ProcessForPort(idle port %d): expected ErrNotFound, got %v", idle, err)
+	}
+	if _, err := sysprims.ProcessesForPort("sctp", port); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ProcessesForPort(sctp): expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestKillByPort verifies KillByPort signals a child holding a listener,
+// waits for the port to be released, and never signals the test process.
+func TestKillByPort(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("passing a listener to a child needs ExtraFiles")
+	}
+	// The port's two bytes differ, so a listing that reported them swapped
+	// would find some other listener, or none.
+	var ln net.Listener
+	var port uint16
+	for ln == nil {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("net.Listen failed: %v", err)
+		}
+		if p := uint16(l.Addr().(*net.TCPAddr).Port); p>>8 != p&0xff {
+			ln, port = l, p
+		} else {
+			_ = l.Close()
+		}
+	}
+
+	// While only the test process listens, it is protected.
+	res, err := sysprims.KillByPort(sysprims.ProtocolTCP, port, sysprims.SIGKILL, nil)
+	var sErr *sysprims.Error
+	if errors.As(err, &sErr) && (sErr.Code == sysprims.ErrNotSupported || sErr.Code == sysprims.ErrPermissionDenied) {
+		t.Skipf("KillByPort unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("KillByPort(self listener) failed: %v", err)
+	}
+	if len(res.Succeeded) != 0 || !reflect.DeepEqual(res.SkippedSafety, []uint32{uint32(os.Getpid())}) {
+		t.Fatalf("self listener: succeeded %v skipped %v, want only self skipped", res.Succeeded, res.SkippedSafety)
+	}
+
+	file, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+	cmd := exec.Command("sleep", "30")
+	cmd.ExtraFiles = []*os.File{file}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	// Reaping the child lets the port free up once it is killed.
+	waited := make(chan struct{})
+	go func() { _ = cmd.Wait(); close(waited) }()
+	defer func() { _ = cmd.Process.Kill(); <-waited }()
+	_ = file.Close()
+	_ = ln.Close()
+	child := uint32(cmd.Process.Pid)
+
+	res, err = sysprims.KillByPort(sysprims.ProtocolTCP, port, sysprims.SIGKILL, &sysprims.KillByPortOptions{Protected: []uint32{child}})
+	if err != nil || len(res.Succeeded) != 0 || !reflect.DeepEqual(res.SkippedSafety, []uint32{child}) {
+		t.Fatalf("protected child: %+v, %v; want the child skipped", res, err)
+	}
+	swapped := port<<8 | port>>8
+	if owners, err := sysprims.ProcessesForPort(sysprims.ProtocolTCP, swapped); err == nil {
+		for _, b := range owners.Bindings {
+			if b.PID != nil && *b.PID == child {
+				t.Fatalf("port %d reported as %d", port, swapped)
+			}
+		}
+	}
+
+	res, err = sysprims.KillByPort(sysprims.ProtocolTCP, port, sysprims.SIGKILL, &sysprims.KillByPortOptions{WaitForFree: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("KillByPort failed: %v", err)
+	}
+	if !reflect.DeepEqual(res.Succeeded, []uint32{child}) || !res.VerifiedFree {
+		t.Errorf("KillByPort = %+v, want child %d signaled and the port verified free", res, child)
+	}
+
+	if _, err := sysprims.KillByPort(sysprims.ProtocolTCP, port, sysprims.SIGKILL, nil); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotFound {
+		t.Errorf("free port: expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestClassifyWarning verifies library warning text maps to stable codes.
+func TestClassifyWarning(t *testing.T) {
+	tests := []struct {
+		message string
+		code    sysprims.WarningCode
+	}{
+		{"Skipped 3 pid entries due to permission errors", sysprims.WarningPermissionDenied},
+		{"Permission denied signaling process group; falling back to pid", sysprims.WarningPermissionDenied},
+		{"Skipped 2 fd entries due to read errors", sysprims.WarningReadError},
+		{"Failed to resolve paths for 4 file fds", sysprims.WarningReadError},
+		{"Skipped 1 socket entries due to unsupported socket kinds", sysprims.WarningNotSupported},
+		{"socket details are not supported on darwin", sysprims.WarningNotSupported},
+		{"Skipped 7 pid entries owned by other users", sysprims.WarningBestEffort},
+		{"Windows PID termination is best-effort without Job Object", sysprims.WarningBestEffort},
+		{"No listening ports found", sysprims.WarningNoResults},
+		{"Terminated via Job Object (spawn_in_group)", sysprims.WarningOther},
+	}
+
+	for _, tt := range tests {
+		w := sysprims.ClassifyWarning(tt.message)
+		if w.Code != tt.code {
+			t.Errorf("ClassifyWarning(%q).Code = %q, expected %q", tt.message, w.Code, tt.code)
+		}
+		if w.Message != tt.message {
+			t.Errorf("ClassifyWarning(%q).Message = %q", tt.message, w.Message)
+		}
+	}
+}
+
+// TestSetLogger verifies that snapshot warnings are logged only while a
+// logger is set, and that results are unchanged.
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	sysprims.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer sysprims.SetLogger(nil)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("ListFds is not supported on windows")
+	}
+	// A netlink socket (AF_NETLINK = 16) cannot be resolved, which warns on
+	// Linux; other platforms warn that socket details are unsupported.
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "s.sock"))
+	if err != nil {
+		t.Skipf("unix listen failed: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	if runtime.GOOS == "linux" {
+		nl, err := syscall.Socket(16, syscall.SOCK_DGRAM, 0)
+		if err != nil {
+			t.Skipf("netlink socket failed: %v", err)
+		}
+		defer func() { _ = syscall.Close(nl) }()
+	}
+
+	self := uint32(os.Getpid())
+	snap, err := sysprims.ListFds(self, nil)
+	if err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	if len(snap.Warnings) == 0 {
+		t.Skip("ListFds returned no warnings to log")
+	}
+
+	var record struct {
+		Level        string   `json:"level"`
+		Op           string   `json:"op"`
+		PID          uint32   `json:"pid"`
+		WarningCodes []string `json:"warning_codes"`
+		Warnings     []string `json:"warnings"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log output is not a single JSON record: %v\n%s", err, buf.String())
+	}
+	if record.Level != "WARN" || record.Op != "ListFds" || record.PID != self {
+		t.Errorf("record level=%q op=%q pid=%d", record.Level, record.Op, record.PID)
+	}
+	if !reflect.DeepEqual(record.Warnings, snap.Warnings) || len(record.WarningCodes) != len(snap.Warnings) {
+		t.Errorf("record warnings=%v codes=%v, snapshot warnings=%v", record.Warnings, record.WarningCodes, snap.Warnings)
+	}
+	for i, code := range record.WarningCodes {
+		if code != string(snap.WarningDetails[i].Code) {
+			t.Errorf("warning_codes[%d] = %q, want %q", i, code, snap.WarningDetails[i].Code)
 		}
-		t.Fatalf("net.Listen failed: %v", err)
 	}
-	defer func() { _ = listener.Close() }()
 
-	addr := listener.Addr().(*net.TCPAddr)
-	port := uint16(addr.Port)
-	pid := uint32(os.Getpid())
+	sysprims.SetLogger(nil)
+	buf.Reset()
+	if _, err := sysprims.ListFds(self, nil); err != nil {
+		t.Fatalf("ListFds failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("logged with nil logger: %s", buf.String())
+	}
+}
 
-	proto := sysprims.ProtocolTCP
-	filter := &sysprims.PortFilter{Protocol: &proto, LocalPort: &port}
+// TestChildHandleLogsOnce verifies a signaled ChildHandle child's warning is
+// logged once, under ChildHandle.Wait, however often Wait is called.
+func TestChildHandleLogsOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh and signals")
+	}
+	var buf bytes.Buffer
+	sysprims.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer sysprims.SetLogger(nil)
 
-	snap, err := sysprims.ListeningPorts(filter)
+	h, err := sysprims.SpawnInGroupHandle(sysprims.SpawnInGroupConfig{Argv: []string{"sh", "-c", "kill -TERM $$"}})
 	if err != nil {
-		// Best-effort: on macOS SIP/TCC or constrained runners, this may fail.
-		if sErr, ok := err.(*sysprims.Error); ok {
-			if sErr.Code == sysprims.ErrPermissionDenied {
-				t.Skipf("ListeningPorts PermissionDenied in this environment: %v", err)
-			}
-			if sErr.Code == sysprims.ErrNotSupported {
-				t.Skipf("ListeningPorts NotSupported in this environment: %v", err)
-			}
-		}
-		t.Fatalf("ListeningPorts failed: %v", err)
+		t.Skipf("SpawnInGroupHandle failed: %v", err)
 	}
-
-	found := false
-	for _, b := range snap.Bindings {
-		if b.LocalPort == port && b.PID != nil && *b.PID == pid {
-			found = true
-			break
+	for i := 0; i < 2; i++ {
+		res, err := h.Wait(5 * time.Second)
+		if err != nil || !res.Signaled || len(res.WarningDetails) != len(res.Warnings) {
+			t.Fatalf("Wait #%d = %+v, %v; want a classified signaled exit", i+1, res, err)
 		}
 	}
+	sysprims.SetLogger(nil)
 
-	if !found {
-		// Best-effort: port-to-PID mapping requires elevated privileges on most platforms.
-		// - macOS: SIP/TCC restrictions
-		// - Linux: /proc/<pid>/fd requires root or same-user
-		// - Windows: netstat access may be limited
-		// CI runners typically don't have these privileges.
-		hasPermissionWarnings := false
-		for _, w := range snap.Warnings {
-			if strings.Contains(w, "permission") || strings.Contains(w, "Permission") {
-				hasPermissionWarnings = true
-				break
-			}
+	var ops []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var record struct {
+			Op string `json:"op"`
 		}
-		if runtime.GOOS == "darwin" || hasPermissionWarnings {
-			t.Logf("Did not find self listener pid=%d port=%d; warnings=%v bindings=%d (best-effort: permission-limited)", pid, port, snap.Warnings, len(snap.Bindings))
-			return
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("bad log record: %v", err)
 		}
-		t.Fatalf("Did not find self listener pid=%d port=%d; warnings=%v bindings=%d", pid, port, snap.Warnings, len(snap.Bindings))
+		ops = append(ops, record.Op)
+	}
+	if !reflect.DeepEqual(ops, []string{"ChildHandle.Wait"}) {
+		t.Errorf("logged ops = %v, want one ChildHandle.Wait", ops)
 	}
 }
 
@@ -485,6 +6377,170 @@ func TestKillGroupNotSupportedOnWindows(t *testing.T) {
 	}
 }
 
+// TestKillGroupJobWindows verifies KillGroup terminates the Job Object of a
+// SpawnInGroup child on Windows, grandchildren included.
+func TestKillGroupJobWindows(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("Skipping Windows-specific test")
+	}
+
+	// cmd starts one ping in the background and runs another, so both are
+	// its children and grandchildren of the test.
+	res, err := sysprims.SpawnInGroup(sysprims.SpawnInGroupConfig{
+		Argv: []string{"cmd", "/c", "start", "/b", "ping", "-n", "60", "127.0.0.1", ">NUL",
+			"&", "ping", "-n", "60", "127.0.0.1", ">NUL"},
+	})
+	if err != nil {
+		t.Fatalf("SpawnInGroup failed: %v", err)
+	}
+	if res.TreeKillReliability != "guaranteed" {
+		_, _ = sysprims.TerminateTree(res.PID, sysprims.TerminateTreeConfig{})
+		t.Skipf("no Job Object: %v", res.Warnings)
+	}
+
+	var ids []sysprims.ProcessIdentity
+	for i := 0; ; i++ {
+		desc, err := sysprims.Descendants(res.PID, 1, nil)
+		if err == nil && desc.TotalFound >= 2 {
+			for _, p := range desc.Levels[0].Processes {
+				if id, err := sysprims.IdentityOf(p.PID); err == nil {
+					ids = append(ids, id)
+				}
+			}
+			break
+		}
+		if i == 100 {
+			_ = sysprims.KillGroup(res.PID, sysprims.SIGKILL)
+			t.Skip("cmd did not start its children")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	var sErr *sysprims.Error
+	if err := sysprims.KillGroup(res.PID, sysprims.SIGINT); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+		t.Errorf("KillGroup(SIGINT) expected ErrNotSupported, got %v", err)
+	}
+	if err := sysprims.KillGroup(res.PID, sysprims.SIGKILL); err != nil {
+		t.Fatalf("KillGroup failed: %v", err)
+	}
+	if err := sysprims.KillGroup(res.PID, sysprims.SIGKILL); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+		t.Errorf("second KillGroup expected ErrNotSupported, got %v", err)
+	}
+	for _, id := range ids {
+		for i := 0; ; i++ {
+			running, err := id.StillRunning()
+			if err == nil && !running {
+				break
+			}
+			if i == 250 {
+				t.Errorf("grandchild %d still running after KillGroup", id.PID)
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
+
+// TestSuspendResumeTree verifies SuspendTree stops a shell and its two
+// children deepest first, ResumeTree continues them root first, and self is
+// skipped for safety.
+func TestSuspendResumeTree(t *testing.T) {
+	var sErr *sysprims.Error
+	if runtime.GOOS == "windows" {
+		if err := sysprims.Suspend(uint32(os.Getpid())); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("Suspend on windows expected ErrNotSupported, got %v", err)
+		}
+		if _, err := sysprims.ResumeTree(uint32(os.Getpid()), nil); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrNotSupported {
+			t.Errorf("ResumeTree on windows expected ErrNotSupported, got %v", err)
+		}
+		return
+	}
+
+	cmd := exec.Command("sh", "-c", "sleep 30 & sleep 30 & wait")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	root := uint32(cmd.Process.Pid)
+	defer func() {
+		_, _ = sysprims.ResumeTree(root, nil)
+		_, _ = sysprims.KillDescendants(root, sysprims.SIGKILL, 0, nil)
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		desc, err := sysprims.Descendants(root, 1, nil)
+		if err == nil && desc.TotalFound == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("children of %d not found: %+v, %v", root, desc, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	// Stops take effect asynchronously, so state polls briefly for stopped
+	// to become want.
+	state := func(pid uint32, want bool) string {
+		var s string
+		for end := time.Now().Add(2 * time.Second); time.Now().Before(end); time.Sleep(10 * time.Millisecond) {
+			s = ""
+			if info, err := sysprims.ProcessGet(pid); err == nil && info.State != nil {
+				s = *info.State
+			}
+			if (s == sysprims.StateStopped) == want {
+				break
+			}
+		}
+		return s
+	}
+
+	suspended, err := sysprims.SuspendTree(root, nil)
+	if err != nil {
+		t.Fatalf("SuspendTree failed: %v", err)
+	}
+	if len(suspended.Succeeded) != 3 || len(suspended.Failed) != 0 || suspended.Succeeded[2] != root {
+		t.Fatalf("SuspendTree = %+v, want 3 PIDs with the root last", suspended)
+	}
+	for _, pid := range suspended.Succeeded {
+		if s := state(pid, true); s != sysprims.StateStopped {
+			t.Errorf("pid %d state after SuspendTree = %q", pid, s)
+		}
+	}
+
+	resumed, err := sysprims.ResumeTree(root, nil)
+	if err != nil {
+		t.Fatalf("ResumeTree failed: %v", err)
+	}
+	if len(resumed.Succeeded) != 3 || resumed.Succeeded[0] != root {
+		t.Fatalf("ResumeTree = %+v, want 3 PIDs with the root first", resumed)
+	}
+	for _, pid := range resumed.Succeeded {
+		if s := state(pid, false); s == sysprims.StateStopped {
+			t.Errorf("pid %d still stopped after ResumeTree", pid)
+		}
+	}
+
+	if err := sysprims.Suspend(root); err != nil {
+		t.Fatalf("Suspend failed: %v", err)
+	}
+	if s := state(root, true); s != sysprims.StateStopped {
+		t.Errorf("root state after Suspend = %q", s)
+	}
+	if err := sysprims.Resume(root); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+
+	one := uint32(1)
+	self, err := sysprims.SuspendTree(uint32(os.Getpid()), &sysprims.DescendantsOptions{MaxLevels: &one, Filter: &sysprims.ProcessFilter{PIDIn: []uint32{root}}})
+	if err != nil {
+		t.Fatalf("SuspendTree(self) failed: %v", err)
+	}
+	defer func() { _ = sysprims.Resume(root) }()
+	if self.SkippedSafety != 1 || len(self.Succeeded) != 1 || self.Succeeded[0] != root {
+		t.Errorf("SuspendTree(self) = %+v, want the child signaled and self skipped", self)
+	}
+}
+
 // TestDefaultTimeoutConfig verifies default config values.
 func TestDefaultTimeoutConfig(t *testing.T) {
 	config := sysprims.DefaultTimeoutConfig()
@@ -523,6 +6579,110 @@ func TestErrorString(t *testing.T) {
 	}
 }
 
+// TestErrorIsTransient verifies errno classification and zero-value defaults.
+func TestErrorIsTransient(t *testing.T) {
+	if (&sysprims.Error{Code: sysprims.ErrSystem}).IsTransient() {
+		t.Error("IsTransient() should be false without Errno")
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX errno values are not used on windows")
+	}
+	tests := []struct {
+		errno     syscall.Errno
+		transient bool
+	}{
+		{syscall.EMFILE, true},
+		{syscall.EAGAIN, true},
+		{syscall.EINTR, true},
+		{syscall.ENOMEM, true},
+		{syscall.EACCES, false},
+		{syscall.ENOENT, false},
+	}
+	for _, tt := range tests {
+		err := &sysprims.Error{Code: sysprims.ErrSystem, Errno: int(tt.errno), OSError: tt.errno.Error()}
+		if got := err.IsTransient(); got != tt.transient {
+			t.Errorf("IsTransient() for %v = %v, expected %v", tt.errno, got, tt.transient)
+		}
+	}
+}
+
+// TestRetry verifies IsRetryable's classification and that Retry retries
+// transient errors with growing backoff but stops at other errors.
+func TestRetry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX errno values are not used on windows")
+	}
+	emfile := &sysprims.Error{Code: sysprims.ErrSystem, Errno: int(syscall.EMFILE), OSError: syscall.EMFILE.Error()}
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{nil, false},
+		{emfile, true},
+		{fmt.Errorf("wrapped: %w", emfile), true},
+		{syscall.EAGAIN, true},
+		{&sysprims.Error{Code: sysprims.ErrSystem, Errno: int(syscall.ENOENT)}, false},
+		{&sysprims.Error{Code: sysprims.ErrSystem}, false},
+		{&sysprims.Error{Code: sysprims.ErrInvalidArgument, Errno: int(syscall.EAGAIN)}, false},
+		{&sysprims.Error{Code: sysprims.ErrNotFound}, false},
+		{errors.New("plain"), false},
+	}
+	for _, tt := range tests {
+		if got := sysprims.IsRetryable(tt.err); got != tt.retryable {
+			t.Errorf("IsRetryable(%v) = %v, expected %v", tt.err, got, tt.retryable)
+		}
+	}
+
+	calls := 0
+	start := time.Now()
+	err := sysprims.Retry(3, 10*time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return emfile
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Retry = %v after %d calls, expected success after 3", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Retry took %v, expected at least 10ms+20ms of backoff", elapsed)
+	}
+
+	calls = 0
+	notFound := &sysprims.Error{Code: sysprims.ErrNotFound}
+	if err := sysprims.Retry(5, 0, func() error { calls++; return notFound }); err != notFound || calls != 1 {
+		t.Errorf("Retry(ErrNotFound) = %v after %d calls, expected one call", err, calls)
+	}
+	calls = 0
+	if err := sysprims.Retry(2, 0, func() error { calls++; return emfile }); err != emfile || calls != 2 {
+		t.Errorf("Retry(EMFILE) = %v after %d calls, expected 2", err, calls)
+	}
+
+	var sErr *sysprims.Error
+	for _, attempts := range []int{0, -1} {
+		if err := sysprims.Retry(attempts, 0, func() error { t.Error("fn called"); return nil }); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+			t.Errorf("Retry(%d attempts) expected ErrInvalidArgument, got %v", attempts, err)
+		}
+	}
+	if err := sysprims.Retry(1, -time.Second, func() error { return nil }); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("Retry(negative backoff) expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestErrorErrnoZeroForNotFound verifies Errno stays unset outside system errors.
+func TestErrorErrnoZeroForNotFound(t *testing.T) {
+	_, err := sysprims.ProcessGet(99999999)
+	var sErr *sysprims.Error
+	if !errors.As(err, &sErr) {
+		t.Skipf("ProcessGet(99999999) unexpectedly returned %v", err)
+	}
+	if sErr.Code == sysprims.ErrNotFound && (sErr.Errno != 0 || sErr.OSError != "") {
+		t.Errorf("ErrNotFound carried errno detail: Errno=%d OSError=%q", sErr.Errno, sErr.OSError)
+	}
+}
+
 // TestErrorCodeString verifies ErrorCode.String() returns meaningful names.
 func TestErrorCodeString(t *testing.T) {
 	tests := []struct {
@@ -554,3 +6714,259 @@ func TestClearError(t *testing.T) {
 	// Should not panic
 	sysprims.ClearError()
 }
+
+// TestSocketsForPID verifies that a listener and an outbound connection held
+// by the test process are reported with their owning fds.
+func TestSocketsForPID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fd listing is not supported on windows")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	snap, err := sysprims.SocketsForPID(uint32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("SocketsForPID failed: %v", err)
+	}
+	if snap.PID != uint32(os.Getpid()) || snap.Warnings == nil {
+		t.Fatalf("unexpected envelope: pid=%d warnings=%v", snap.PID, snap.Warnings)
+	}
+	if runtime.GOOS != "linux" {
+		if len(snap.Warnings) == 0 {
+			t.Error("expected a warning when socket details are unsupported")
+		}
+		return
+	}
+
+	listenPort := uint16(listener.Addr().(*net.TCPAddr).Port)
+	connPort := uint16(conn.LocalAddr().(*net.TCPAddr).Port)
+	var listenFd, connFd *sysprims.FdSocket
+	for i := range snap.Sockets {
+		s := &snap.Sockets[i]
+		if s.Protocol != sysprims.ProtocolTCP {
+			continue
+		}
+		if s.LocalPort == listenPort && s.State != nil && *s.State == "listen" {
+			listenFd = s
+		}
+		if s.LocalPort == connPort && s.RemotePort != nil && *s.RemotePort == listenPort {
+			connFd = s
+		}
+	}
+	if listenFd == nil || connFd == nil {
+		t.Fatalf("listener=%v conn=%v; sockets=%+v warnings=%v", listenFd, connFd, snap.Sockets, snap.Warnings)
+	}
+	if listenFd.Fd == connFd.Fd {
+		t.Errorf("listener and connection share fd %d", listenFd.Fd)
+	}
+}
+
+// TestConnections verifies that both ends of a local TCP connection and its
+// listener are listed and attributed to this process.
+func TestConnections(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("connections are listed on linux only")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	server, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	self := uint32(os.Getpid())
+	listenPort := uint16(listener.Addr().(*net.TCPAddr).Port)
+	clientPort := uint16(conn.LocalAddr().(*net.TCPAddr).Port)
+	tcp := sysprims.ProtocolTCP
+	established := "established"
+
+	snap, err := sysprims.Connections(&sysprims.ConnectionFilter{Protocol: &tcp, LocalPort: &listenPort})
+	if err != nil {
+		t.Fatalf("Connections failed: %v", err)
+	}
+	var sawListener, sawServer bool
+	for _, c := range snap.Connections {
+		if c.PID == nil || *c.PID != self {
+			t.Errorf("connection %+v not attributed to %d", c, self)
+			continue
+		}
+		switch {
+		case c.State != nil && *c.State == "listen":
+			sawListener = true
+		case c.State != nil && *c.State == established && c.RemotePort != nil && *c.RemotePort == clientPort:
+			sawServer = true
+		}
+	}
+	if !sawListener || !sawServer {
+		t.Fatalf("listener=%v server=%v; connections=%+v warnings=%v", sawListener, sawServer, snap.Connections, snap.Warnings)
+	}
+
+	prefix := "127.0.0.0/8"
+	snap, err = sysprims.Connections(&sysprims.ConnectionFilter{State: &established, RemotePort: &listenPort, RemoteAddrPrefix: &prefix})
+	if err != nil {
+		t.Fatalf("Connections(remote) failed: %v", err)
+	}
+	if len(snap.Connections) != 1 || snap.Connections[0].LocalPort != clientPort || snap.Connections[0].PID == nil || *snap.Connections[0].PID != self {
+		t.Errorf("client end: connections=%+v, want local port %d", snap.Connections, clientPort)
+	}
+
+	other := "10.0.0.0/8"
+	snap, err = sysprims.Connections(&sysprims.ConnectionFilter{RemotePort: &listenPort, RemoteAddrPrefix: &other})
+	if err != nil {
+		t.Fatalf("Connections(other prefix) failed: %v", err)
+	}
+	if len(snap.Connections) != 0 {
+		t.Errorf("10.0.0.0/8 matched %+v", snap.Connections)
+	}
+
+	bad := "not-an-address"
+	var sErr *sysprims.Error
+	if _, err := sysprims.Connections(&sysprims.ConnectionFilter{RemoteAddrPrefix: &bad}); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("bad prefix: expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// TestListUnixSockets verifies ListUnixSockets reports the test process's
+// unix stream listener and datagram socket with type, state, and PID, and
+// that PathContains filters by path.
+func TestListUnixSockets(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("unix sockets are listed on linux and macOS only")
+	}
+
+	// Keep paths short: sun_path is limited to about 100 bytes.
+	dir, err := os.MkdirTemp("", "spux")
+	if err != nil {
+		t.Fatalf("MkdirTemp failed: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	streamPath := filepath.Join(dir, "stream.sock")
+	listener, err := net.Listen("unix", streamPath)
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	dgramPath := filepath.Join(dir, "dgram.sock")
+	dgram, err := net.ListenPacket("unixgram", dgramPath)
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	defer func() { _ = dgram.Close() }()
+
+	snap, err := sysprims.ListUnixSockets(&sysprims.UnixSocketFilter{PathContains: &dir})
+	if err != nil {
+		t.Fatalf("ListUnixSockets failed: %v", err)
+	}
+	self := uint32(os.Getpid())
+	byPath := map[string]sysprims.UnixSocket{}
+	for _, s := range snap.Sockets {
+		if !strings.Contains(s.Path, dir) {
+			t.Errorf("socket %q does not match PathContains", s.Path)
+		}
+		byPath[s.Path] = s
+	}
+	if s, ok := byPath[streamPath]; !ok {
+		t.Errorf("listener %s not listed: %+v", streamPath, snap.Sockets)
+	} else if s.Type != "stream" || s.State == nil || *s.State != "listen" || s.PID == nil || *s.PID != self {
+		t.Errorf("listener = %+v", s)
+	}
+	if s, ok := byPath[dgramPath]; !ok {
+		t.Errorf("datagram socket %s not listed: %+v", dgramPath, snap.Sockets)
+	} else if s.Type != "dgram" || s.PID == nil || *s.PID != self {
+		t.Errorf("datagram socket = %+v", s)
+	}
+
+	other := dir + "-missing"
+	snap, err = sysprims.ListUnixSockets(&sysprims.UnixSocketFilter{PathContains: &other})
+	if err != nil {
+		t.Fatalf("ListUnixSockets failed: %v", err)
+	}
+	if len(snap.Sockets) != 0 {
+		t.Errorf("PathContains=%q matched %+v", other, snap.Sockets)
+	}
+}
+
+// TestConnectionSummary verifies a CLOSE_WAIT socket (the peer closed, the
+// test did not) is counted for the test process, per PID and system-wide.
+func TestConnectionSummary(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("connection summaries are linux-only")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("net.Listen failed: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	pid := uint32(os.Getpid())
+	before, err := sysprims.ConnectionSummary(pid)
+	if err != nil {
+		t.Fatalf("ConnectionSummary failed: %v", err)
+	}
+	if before.ByState["established"] < 2 || before.ByState["listen"] < 1 {
+		t.Errorf("before close: by_state = %v, want >= 2 established and a listener", before.ByState)
+	}
+
+	_ = client.Close()
+	var summary *sysprims.ConnSummary
+	for i := 0; i < 50; i++ {
+		if summary, err = sysprims.ConnectionSummary(pid); err != nil {
+			t.Fatalf("ConnectionSummary failed: %v", err)
+		}
+		if summary.ByState["close_wait"] > before.ByState["close_wait"] {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if summary.ByState["close_wait"] <= before.ByState["close_wait"] {
+		t.Fatalf("close_wait not counted: by_state = %v", summary.ByState)
+	}
+	if summary.ByProtocol[sysprims.ProtocolTCP] == 0 || summary.Total < summary.ByProtocol[sysprims.ProtocolTCP] {
+		t.Errorf("by_protocol = %v, total = %d", summary.ByProtocol, summary.Total)
+	}
+
+	all, err := sysprims.ConnectionSummaryAll(&sysprims.ProcessFilter{PIDIn: []uint32{pid}})
+	if err != nil {
+		t.Fatalf("ConnectionSummaryAll failed: %v", err)
+	}
+	self := all.ByPID[pid]
+	if self == nil || self.ByState["close_wait"] < summary.ByState["close_wait"] {
+		t.Errorf("ConnectionSummaryAll by_pid[%d] = %+v, want close_wait counted", pid, self)
+	}
+	if all.Total != self.Total {
+		t.Errorf("filtered total %d != self total %d", all.Total, self.Total)
+	}
+
+	var sErr *sysprims.Error
+	if _, err := sysprims.ConnectionSummary(0); !errors.As(err, &sErr) || sErr.Code != sysprims.ErrInvalidArgument {
+		t.Errorf("ConnectionSummary(0): expected ErrInvalidArgument, got %v", err)
+	}
+}
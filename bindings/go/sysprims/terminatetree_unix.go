@@ -0,0 +1,34 @@
+//go:build !windows
+
+package sysprims
+
+import (
+	"sort"
+	"syscall"
+)
+
+// terminateTreeTargets mirrors the library's choice of group kill: when pid
+// leads a process group other than the caller's, it returns the group's
+// members in ascending order and group=true; otherwise pid alone. Members
+// are looked up in snapshot, or in a fresh one when it is nil.
+func terminateTreeTargets(pid uint32, snapshot *ProcessSnapshot) (targets []uint32, group bool) {
+	pgid, err := syscall.Getpgid(int(pid))
+	if err != nil || pgid != int(pid) || pgid == syscall.Getpgrp() {
+		return []uint32{pid}, false
+	}
+
+	if snapshot == nil {
+		if snapshot, err = ProcessList(nil); err != nil {
+			return []uint32{pid}, true
+		}
+	}
+	targets, _ = membersOf(pid, false, snapshot)
+	for _, t := range targets {
+		if t == pid {
+			return targets, true
+		}
+	}
+	targets = append(targets, pid)
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	return targets, true
+}
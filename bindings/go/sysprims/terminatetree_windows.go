@@ -0,0 +1,7 @@
+package sysprims
+
+// terminateTreeTargets returns pid alone: Windows has no process groups,
+// and the members of a SpawnInGroup Job Object cannot be listed by PID.
+func terminateTreeTargets(pid uint32, snapshot *ProcessSnapshot) (targets []uint32, group bool) {
+	return []uint32{pid}, false
+}
@@ -0,0 +1,73 @@
+package sysprims
+
+import "time"
+
+// ThreadInfo describes a single thread of a process.
+type ThreadInfo struct {
+	// TID is the thread ID.
+	TID uint32 `json:"tid"`
+	// Name is the thread name (may be nil if unavailable).
+	Name *string `json:"name,omitempty"`
+	// State is the thread state, one of the State constants (may be nil if
+	// unavailable).
+	State *string `json:"state,omitempty"`
+	// CPUPercent is the thread's CPU usage, best-effort. On Linux and Windows
+	// this is lifetime usage; on macOS it is the kernel's decayed recent usage.
+	CPUPercent float64 `json:"cpu_percent"`
+	// CPUTimeMS is the cumulative user+system CPU time in milliseconds.
+	CPUTimeMS uint64 `json:"cpu_time_ms"`
+}
+
+// ThreadSnapshot represents a point-in-time listing of a process's threads.
+type ThreadSnapshot struct {
+	Timestamp string       `json:"timestamp"`
+	Platform  string       `json:"platform"`
+	PID       uint32       `json:"pid"`
+	Threads   []ThreadInfo `json:"threads"`
+	Warnings  []string     `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+}
+
+// ListThreads returns a snapshot of the threads of pid.
+//
+// Implemented in the Go bindings:
+// - Linux reads /proc/<pid>/task
+// - macOS uses libproc thread info (same-user processes)
+// - Windows uses a Toolhelp thread snapshot; Name and State are unavailable
+//
+// Best-effort behavior:
+// - Threads that exit or cannot be read mid-listing are skipped with a warning
+// - Fields may be omitted
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to list this process's threads
+//   - [ErrNotSupported]: Thread listing is unavailable on this platform
+func ListThreads(pid uint32) (*ThreadSnapshot, error) {
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
+	}
+
+	threads, warnings, err := listThreads(pid)
+	if err != nil {
+		return nil, err
+	}
+	if threads == nil {
+		threads = []ThreadInfo{}
+	}
+	if warnings == nil {
+		warnings = []string{}
+	}
+
+	return &ThreadSnapshot{
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+		Platform:       Platform(),
+		PID:            pid,
+		Threads:        threads,
+		Warnings:       warnings,
+		WarningDetails: warningDetails("ListThreads", pid, warnings),
+	}, nil
+}
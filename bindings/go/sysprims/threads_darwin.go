@@ -0,0 +1,128 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <mach/thread_info.h>
+#include <string.h>
+#include <sys/proc_info.h>
+
+static int sysprims_go_thread_count(int pid, int *count) {
+	struct proc_taskinfo ti;
+	int n = proc_pidinfo(pid, PROC_PIDTASKINFO, 0, &ti, sizeof(ti));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	if (n < (int)sizeof(ti)) {
+		return EIO;
+	}
+	*count = ti.pti_threadnum;
+	return 0;
+}
+
+static int sysprims_go_thread_ids(int pid, uint64_t *ids, int cap, int *count) {
+#ifdef PROC_PIDLISTTHREADIDS
+	int n = proc_pidinfo(pid, PROC_PIDLISTTHREADIDS, 0, ids, cap * (int)sizeof(uint64_t));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	*count = n / (int)sizeof(uint64_t);
+	return 0;
+#else
+	return ENOTSUP;
+#endif
+}
+
+static int sysprims_go_thread_info(int pid, uint64_t tid, uint64_t *user, uint64_t *system,
+                                   int *usage, int *state, char *name, int name_len) {
+	struct proc_threadinfo ti;
+	int n = proc_pidinfo(pid, PROC_PIDTHREADID64INFO, tid, &ti, sizeof(ti));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	if (n < (int)sizeof(ti)) {
+		return EIO;
+	}
+	*user = ti.pth_user_time;
+	*system = ti.pth_system_time;
+	*usage = ti.pth_cpu_usage;
+	*state = ti.pth_run_state;
+	strlcpy(name, ti.pth_name, name_len);
+	return 0;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// listThreads uses libproc thread info.
+//
+// macOS thread IDs are 64-bit; TID holds the low 32 bits.
+func listThreads(pid uint32) ([]ThreadInfo, []string, error) {
+	var count C.int
+	if rc := C.sysprims_go_thread_count(C.int(pid), &count); rc != 0 {
+		return nil, nil, errnoError(pid, syscall.Errno(rc))
+	}
+
+	// Threads may be created between the two calls; leave headroom.
+	ids := make([]C.uint64_t, int(count)+32)
+	var n C.int
+	rc := C.sysprims_go_thread_ids(C.int(pid), &ids[0], C.int(len(ids)), &n)
+	if rc == C.ENOTSUP {
+		return nil, nil, &Error{Code: ErrNotSupported, Message: "thread listing requires a newer macOS SDK"}
+	}
+	if rc != 0 {
+		return nil, nil, errnoError(pid, syscall.Errno(rc))
+	}
+
+	var threads []ThreadInfo
+	readErrors := 0
+	var nameBuf [64]C.char
+	for _, id := range ids[:n] {
+		var user, system C.uint64_t
+		var usage, state C.int
+		if rc := C.sysprims_go_thread_info(C.int(pid), id, &user, &system, &usage, &state,
+			&nameBuf[0], C.int(len(nameBuf))); rc != 0 {
+			readErrors++
+			continue
+		}
+
+		t := ThreadInfo{
+			TID:        uint32(id),
+			CPUPercent: float64(usage) / float64(C.TH_USAGE_SCALE) * 100,
+			// pth_user_time/pth_system_time are in nanoseconds.
+			CPUTimeMS: (uint64(user) + uint64(system)) / 1_000_000,
+		}
+		if name := C.GoString(&nameBuf[0]); name != "" {
+			t.Name = &name
+		}
+		stateName := darwinThreadStateName(int(state))
+		t.State = &stateName
+		threads = append(threads, t)
+	}
+
+	var warnings []string
+	if readErrors > 0 {
+		warnings = append(warnings, fmt.Sprintf("Skipped %d thread entries due to read errors", readErrors))
+	}
+	return threads, warnings, nil
+}
+
+// darwinThreadStateName maps a TH_STATE_* value to a State constant.
+func darwinThreadStateName(state int) string {
+	switch state {
+	case C.TH_STATE_RUNNING:
+		return StateRunning
+	case C.TH_STATE_WAITING, C.TH_STATE_UNINTERRUPTIBLE:
+		return StateSleeping
+	case C.TH_STATE_STOPPED:
+		return StateStopped
+	default:
+		return StateUnknown
+	}
+}
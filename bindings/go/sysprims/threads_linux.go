@@ -0,0 +1,105 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listThreads reads /proc/<pid>/task.
+func listThreads(pid uint32) ([]ThreadInfo, []string, error) {
+	taskDir := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/task"
+	entries, err := os.ReadDir(taskDir)
+	if err != nil {
+		return nil, nil, procReadError(pid, err)
+	}
+
+	uptime, uptimeErr := readUptimeSeconds()
+	hz := userHZ()
+
+	var threads []ThreadInfo
+	var warnings []string
+	readErrors := 0
+	for _, e := range entries {
+		tid, err := strconv.ParseUint(e.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		path := taskDir + "/" + e.Name() + "/stat"
+		data, err := os.ReadFile(path)
+		if err != nil {
+			readErrors++
+			continue
+		}
+		comm, fields, err := parseProcStat(path, data)
+		if err != nil {
+			readErrors++
+			continue
+		}
+		ticks, err := statCPUTicks(path, fields)
+		if err != nil {
+			readErrors++
+			continue
+		}
+
+		name := comm
+		state := linuxStateName(fields[0])
+		t := ThreadInfo{
+			TID:       uint32(tid),
+			Name:      &name,
+			State:     &state,
+			CPUTimeMS: ticks * 1000 / hz,
+		}
+
+		// fields[19] is starttime in clock ticks since boot.
+		if uptimeErr == nil {
+			if start, err := strconv.ParseUint(fields[19], 10, 64); err == nil {
+				elapsed := uptime - float64(start)/float64(hz)
+				if elapsed > 0 {
+					t.CPUPercent = float64(ticks) / float64(hz) / elapsed * 100
+				}
+			}
+		}
+		threads = append(threads, t)
+	}
+
+	if uptimeErr != nil {
+		warnings = append(warnings, "Failed to read /proc/uptime; cpu_percent unavailable: "+uptimeErr.Error())
+	}
+	if readErrors > 0 {
+		warnings = append(warnings, fmt.Sprintf("Skipped %d thread entries due to read errors", readErrors))
+	}
+	return threads, warnings, nil
+}
+
+// readUptimeSeconds returns the system uptime from /proc/uptime.
+func readUptimeSeconds() (float64, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("malformed /proc/uptime")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+// linuxStateName maps a stat state character to a State constant.
+func linuxStateName(code string) string {
+	switch code {
+	case "R":
+		return StateRunning
+	case "S", "D", "I":
+		return StateSleeping
+	case "T", "t":
+		return StateStopped
+	case "Z", "X":
+		return StateZombie
+	default:
+		return StateUnknown
+	}
+}
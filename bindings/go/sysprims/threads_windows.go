@@ -0,0 +1,116 @@
+//go:build windows
+
+package sysprims
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	th32csSnapThread              = 0x00000004
+	threadQueryLimitedInformation = 0x0800
+	errorNoMoreFiles              = syscall.Errno(18)
+)
+
+var (
+	modKernel32        = syscall.NewLazyDLL("kernel32.dll")
+	procThread32First  = modKernel32.NewProc("Thread32First")
+	procThread32Next   = modKernel32.NewProc("Thread32Next")
+	procOpenThread     = modKernel32.NewProc("OpenThread")
+	procGetThreadTimes = modKernel32.NewProc("GetThreadTimes")
+)
+
+// threadEntry32 mirrors THREADENTRY32.
+type threadEntry32 struct {
+	Size           uint32
+	Usage          uint32
+	ThreadID       uint32
+	OwnerProcessID uint32
+	BasePri        int32
+	DeltaPri       int32
+	Flags          uint32
+}
+
+// listThreads walks a Toolhelp thread snapshot and reads per-thread times.
+func listThreads(pid uint32) ([]ThreadInfo, []string, error) {
+	// Fail early with the usual process errors if pid does not exist.
+	if _, err := cpuTimeNS(pid); err != nil {
+		return nil, nil, err
+	}
+
+	snap, err := syscall.CreateToolhelp32Snapshot(th32csSnapThread, 0)
+	if err != nil {
+		return nil, nil, systemError(err)
+	}
+	defer func() { _ = syscall.CloseHandle(snap) }()
+
+	var entry threadEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	r, _, e := procThread32First.Call(uintptr(snap), uintptr(unsafe.Pointer(&entry)))
+	if r == 0 {
+		if e == errorNoMoreFiles {
+			return nil, nil, nil
+		}
+		return nil, nil, systemError(e)
+	}
+
+	now := time.Now()
+	var threads []ThreadInfo
+	unreadable := 0
+	for {
+		if entry.OwnerProcessID == pid {
+			t := ThreadInfo{TID: entry.ThreadID}
+			if cpuNS, created, err := threadTimes(entry.ThreadID); err == nil {
+				t.CPUTimeMS = cpuNS / 1_000_000
+				if elapsed := now.Sub(created); elapsed > 0 {
+					t.CPUPercent = float64(cpuNS) / float64(elapsed.Nanoseconds()) * 100
+				}
+			} else {
+				unreadable++
+			}
+			threads = append(threads, t)
+		}
+
+		entry.Size = uint32(unsafe.Sizeof(entry))
+		r, _, e = procThread32Next.Call(uintptr(snap), uintptr(unsafe.Pointer(&entry)))
+		if r == 0 {
+			if e != errorNoMoreFiles {
+				return nil, nil, systemError(e)
+			}
+			break
+		}
+	}
+
+	var warnings []string
+	if unreadable > 0 {
+		warnings = append(warnings, fmt.Sprintf("Failed to read CPU times for %d threads", unreadable))
+	}
+	return threads, warnings, nil
+}
+
+// threadTimes returns cumulative kernel+user time in nanoseconds and the
+// creation time of a thread.
+func threadTimes(tid uint32) (uint64, time.Time, error) {
+	h, _, e := procOpenThread.Call(threadQueryLimitedInformation, 0, uintptr(tid))
+	if h == 0 {
+		return 0, time.Time{}, e
+	}
+	defer func() { _ = syscall.CloseHandle(syscall.Handle(h)) }()
+
+	var creation, exit, kernel, user syscall.Filetime
+	r, _, e := procGetThreadTimes.Call(h,
+		uintptr(unsafe.Pointer(&creation)),
+		uintptr(unsafe.Pointer(&exit)),
+		uintptr(unsafe.Pointer(&kernel)),
+		uintptr(unsafe.Pointer(&user)))
+	if r == 0 {
+		return 0, time.Time{}, e
+	}
+
+	// FILETIME durations are in 100ns units.
+	total := (filetimeTicks(kernel) + filetimeTicks(user)) * 100
+	return total, time.Unix(0, creation.Nanoseconds()), nil
+}
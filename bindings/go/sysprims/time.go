@@ -0,0 +1,87 @@
+package sysprims
+
+import "time"
+
+// StartTime returns the process start time.
+//
+// ok is false when StartTimeUnixMS is unavailable.
+func (p *ProcessInfo) StartTime() (t time.Time, ok bool) {
+	if p.StartTimeUnixMS == nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(*p.StartTimeUnixMS)), true
+}
+
+// Elapsed returns the process runtime.
+//
+// ok is false when ElapsedSeconds is unavailable.
+func (p *ProcessInfo) Elapsed() (d time.Duration, ok bool) {
+	if p.ElapsedSeconds == nil {
+		return 0, false
+	}
+	return time.Duration(*p.ElapsedSeconds) * time.Second, true
+}
+
+// Time returns the parsed snapshot Timestamp.
+func (s *ProcessSnapshot) Time() (time.Time, error) {
+	return parseTimestamp(s.Timestamp)
+}
+
+// Time returns the parsed snapshot Timestamp.
+func (s *PortBindingsSnapshot) Time() (time.Time, error) {
+	return parseTimestamp(s.Timestamp)
+}
+
+// Time returns the parsed snapshot Timestamp.
+func (s *FdSnapshot) Time() (time.Time, error) {
+	return parseTimestamp(s.Timestamp)
+}
+
+// Time returns the parsed snapshot Timestamp.
+func (s *ThreadSnapshot) Time() (time.Time, error) {
+	return parseTimestamp(s.Timestamp)
+}
+
+// Time returns the parsed snapshot Timestamp.
+func (s *SocketSnapshot) Time() (time.Time, error) {
+	return parseTimestamp(s.Timestamp)
+}
+
+// Time returns the parsed snapshot Timestamp.
+func (c *ProcessChanges) Time() (time.Time, error) {
+	return parseTimestamp(c.Timestamp)
+}
+
+// Time returns the parsed result Timestamp.
+func (r *WaitPidResult) Time() (time.Time, error) {
+	return parseTimestamp(r.Timestamp)
+}
+
+// Time returns the parsed result Timestamp.
+func (r *DescendantsResult) Time() (time.Time, error) {
+	return parseTimestamp(r.Timestamp)
+}
+
+// Time returns the parsed result Timestamp.
+func (r *TerminateTreeResult) Time() (time.Time, error) {
+	return parseTimestamp(r.Timestamp)
+}
+
+// Time returns the parsed result Timestamp.
+func (r *SpawnInGroupResult) Time() (time.Time, error) {
+	return parseTimestamp(r.Timestamp)
+}
+
+// parseTimestamp parses an RFC 3339 timestamp as emitted by the library.
+//
+// Fractional seconds are optional; the library has emitted both forms.
+func parseTimestamp(ts string) (time.Time, error) {
+	if ts == "" {
+		return time.Time{}, &Error{Code: ErrNotFound, Message: "timestamp not set"}
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, &Error{Code: ErrInternal, Message: "failed to parse timestamp: " + err.Error()}
+	}
+	return t, nil
+}
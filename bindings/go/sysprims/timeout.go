@@ -7,10 +7,17 @@ package sysprims
 import "C"
 import (
 	"encoding/json"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 	"unsafe"
 )
 
+// defaultTerminateTreeGrace is the library's default GraceTimeoutMS.
+const defaultTerminateTreeGrace = 10 * time.Second
+
 // GroupingMode controls process group creation for timeout execution.
 type GroupingMode int32
 
@@ -35,6 +42,27 @@ type TimeoutConfig struct {
 	// PreserveStatus causes the function to return the child's exit code
 	// when the command completes (instead of always returning 0 for success).
 	PreserveStatus bool
+	// RunAsUID and RunAsGID run the command as another user and group, and
+	// SupplementaryGIDs replaces its supplementary groups (emptied when nil
+	// and a RunAs ID is set). Unset IDs keep the caller's. The credentials
+	// are applied in the child before exec, groups first and the UID last,
+	// so the child cannot regain privilege.
+	//
+	// Changing credentials requires an effective UID of 0; requesting it
+	// otherwise fails with ErrPermissionDenied before anything is spawned.
+	// When any of these is set the command is run by the Go bindings rather
+	// than the library, with the same timeout and kill semantics. Not
+	// supported on Windows.
+	RunAsUID          *uint32
+	RunAsGID          *uint32
+	SupplementaryGIDs []uint32
+	// ConsoleCtrl makes the first stage on timeout a console control event
+	// (see [SignalGraceful]) on Windows, where Signal can only be
+	// TerminateProcess; the command is terminated if it is still running
+	// after KillAfter. The command is then run by the Go bindings, in its
+	// own console process group (GroupByDefault) but without a Job Object,
+	// so tree kill is best effort. It is ignored on Unix.
+	ConsoleCtrl bool
 }
 
 // DefaultTimeoutConfig returns sensible defaults for timeout execution.
@@ -61,6 +89,13 @@ type TimeoutResult struct {
 	Status string `json:"status"`
 	// ExitCode is the exit code if the command completed (nil if timed out).
 	ExitCode *int `json:"exit_code,omitempty"`
+	// Signaled is set when the command completed by being terminated by a
+	// signal, which ExitSignal holds; ExitCode is then nil. The library
+	// does not report the signal, so they are only set when the command
+	// runs in the Go bindings (with RunAs fields); otherwise a killed
+	// command has neither ExitCode nor ExitSignal. Unix only.
+	Signaled   bool `json:"signaled,omitempty"`
+	ExitSignal *int `json:"exit_signal,omitempty"`
 	// SignalSent is the signal sent if the command timed out (nil if completed).
 	SignalSent *int `json:"signal_sent,omitempty"`
 	// Escalated indicates whether escalation to SIGKILL occurred (nil if completed).
@@ -85,6 +120,17 @@ type TerminateTreeConfig struct {
 	KillTimeoutMS  *uint64 `json:"kill_timeout_ms,omitempty"`
 	Signal         *int32  `json:"signal,omitempty"`
 	KillSignal     *int32  `json:"kill_signal,omitempty"`
+
+	// DryRun computes Targets without sending any signal or waiting.
+	DryRun bool `json:"-"`
+	// ConsoleCtrl makes the first stage a console control event (see
+	// [SignalGraceful]) on Windows, where Signal can only be
+	// TerminateProcess. If the process is still running after the grace
+	// timeout, or has no console, it is terminated as usual. It is ignored
+	// on Unix and by DryRun.
+	ConsoleCtrl bool `json:"-"`
+	// Policy adds protections to the safety rules; see [SafetyPolicy].
+	Policy *SafetyPolicy `json:"-"`
 }
 
 // TerminateTreeResult is the outcome of a terminate-tree operation.
@@ -101,6 +147,32 @@ type TerminateTreeResult struct {
 	TimedOut            bool     `json:"timed_out"`
 	TreeKillReliability string   `json:"tree_kill_reliability"`
 	Warnings            []string `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+	// Targets lists, in ascending order, the PIDs that were signaled (or,
+	// with DryRun, would be): the members of the process group when group
+	// kill is used, otherwise PID alone.
+	Targets []uint32 `json:"targets,omitempty"`
+	// Processes holds the outcome for each PID in Targets, in the same
+	// order. It is empty with DryRun.
+	Processes []TerminateTreeOutcome `json:"processes,omitempty"`
+	// SkippedPolicy lists the PIDs left alone because a [SafetyPolicy]
+	// protects one of them. A group is signaled as a whole, so it is
+	// either empty or every target.
+	SkippedPolicy []uint32 `json:"skipped_policy,omitempty"`
+}
+
+// TerminateTreeOutcome is the outcome of a [TerminateTree] for one target.
+type TerminateTreeOutcome struct {
+	PID uint32 `json:"pid"`
+	// Exited reports that the process (or a zombie of it) was gone when
+	// TerminateTree returned. A PID taken over by a different process
+	// counts as exited.
+	Exited bool `json:"exited"`
+	// Escalated reports that KillSignal was directed at the process. With a
+	// group kill the signal goes to the whole group, so this is set for
+	// every member, including any that exited during the grace period.
+	Escalated bool `json:"escalated"`
 }
 
 // Completed returns true if the command completed without timing out.
@@ -144,8 +216,22 @@ func (r *TimeoutResult) TimedOut() bool {
 //   - [ErrInvalidArgument]: Invalid command or configuration
 //   - [ErrSpawnFailed]: Failed to spawn the command
 //   - [ErrNotFound]: Command not found
-//   - [ErrPermissionDenied]: Command not executable
+//   - [ErrPermissionDenied]: Command not executable, or RunAs fields set
+//     without the privilege to change credentials
+//   - [ErrNotSupported]: RunAs fields on Windows
 func RunWithTimeout(command string, args []string, timeout time.Duration, config TimeoutConfig) (*TimeoutResult, error) {
+	if hasCredential(config.RunAsUID, config.RunAsGID, config.SupplementaryGIDs) ||
+		config.ConsoleCtrl && runtime.GOOS == "windows" {
+		result, err := runWithTimeoutInGo(command, args, timeout, config)
+		if err != nil {
+			return nil, err
+		}
+		if result.TreeKillReliability != nil {
+			logTreeKillReliability("RunWithTimeout", 0, *result.TreeKillReliability)
+		}
+		return result, nil
+	}
+
 	// Prepare command string
 	cCommand := C.CString(command)
 	defer C.free(unsafe.Pointer(cCommand))
@@ -205,6 +291,9 @@ func RunWithTimeout(command string, args []string, timeout time.Duration, config
 	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &result); err != nil {
 		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
 	}
+	if result.TreeKillReliability != nil {
+		logTreeKillReliability("RunWithTimeout", 0, *result.TreeKillReliability)
+	}
 
 	return &result, nil
 }
@@ -218,31 +307,244 @@ func RunWithTimeout(command string, args []string, timeout time.Duration, config
 //
 // On Unix, if the target PID is a process group leader, sysprims will prefer
 // group kill for better coverage.
+//
+// The result lists the signaled PIDs in Targets, with each one's exit and
+// escalation in Processes. Group members are read before signaling, so a
+// process that joins the group meanwhile is signaled but not listed. On
+// Windows Targets holds PID alone, even when the Job Object of a
+// [SpawnInGroup] child covers more processes.
+//
+// A process that is reaped by another waiter, such as a [ChildHandle],
+// while the library signals or waits on it is reported as Exited rather than
+// as ErrNotFound.
+//
+// With DryRun, the result holds Targets, PGID, TreeKillReliability, and the
+// signals that would be sent; nothing is signaled and Exited is false.
+//
+// When config.Policy or the default [SafetyPolicy] applies, the targets are
+// read from one [ProcessList] snapshot and judged against it. If the policy
+// protects any of them, nothing is signaled (with DryRun too): Targets is
+// empty, SkippedPolicy lists them all, and a warning names the protected
+// PIDs.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0 or > math.MaxInt32, or more targets
+//     than the policy's MaxKillCount would be signaled
+//   - [ErrNotFound]: Process doesn't exist
+//   - [ErrPermissionDenied]: Not permitted to signal this process
+//   - [ErrNotSupported]: The policy sets ProtectOtherUsers and the calling
+//     process's user cannot be resolved
 func TerminateTree(pid uint32, config TerminateTreeConfig) (*TerminateTreeResult, error) {
 	if config.SchemaID == "" {
-		config.SchemaID = "https://schemas.3leaps.dev/sysprims/process/v1.0.0/terminate-tree-config.schema.json"
+		config.SchemaID = SchemaTerminateTreeConfigV1
+	}
+	if err := validatePidList([]uint32{pid}); err != nil {
+		return nil, err
 	}
 
-	configJSON, err := json.Marshal(config)
+	check, err := resolveSafety(config.Policy)
 	if err != nil {
-		return nil, &Error{Code: ErrInternal, Message: "failed to serialize config: " + err.Error()}
+		return nil, err
+	}
+	var snapshot *ProcessSnapshot
+	if check != nil {
+		if snapshot, err = ProcessList(nil); err != nil {
+			return nil, err
+		}
+	}
+	targets, group := terminateTreeTargets(pid, snapshot)
+	if check != nil {
+		if result, err := terminateTreePolicy(pid, config, check, snapshot, targets, group); result != nil || err != nil {
+			return result, err
+		}
+	}
+	if config.DryRun {
+		return terminateTreeDryRun(pid, config, targets, group)
+	}
+	ids := make([]ProcessIdentity, len(targets))
+	idErrs := make([]error, len(targets))
+	existed := false
+	for i, t := range targets {
+		ids[i], idErrs[i] = IdentityOf(t)
+		if t == pid {
+			existed = idErrs[i] == nil
+		}
+	}
+
+	var result *TerminateTreeResult
+	var consoleWarning string
+	if config.ConsoleCtrl && runtime.GOOS == "windows" {
+		var exited bool
+		exited, consoleWarning = terminateTreeConsoleCtrl(pid, &config)
+		if exited {
+			result = newTerminateTreeResult(pid, config, targets, group)
+			result.KillSignal = nil
+			result.Exited = true
+		}
 	}
 
-	configCStr := C.CString(string(configJSON))
-	defer C.free(unsafe.Pointer(configCStr))
+	if result == nil {
+		configJSON, err := json.Marshal(config)
+		if err != nil {
+			return nil, &Error{Code: ErrInternal, Message: "failed to serialize config: " + err.Error()}
+		}
 
-	var resultCStr *C.char
-	if err := callAndCheck(func() C.SysprimsErrorCode {
-		return C.sysprims_terminate_tree(C.uint32_t(pid), configCStr, &resultCStr)
-	}); err != nil {
+		configCStr := C.CString(string(configJSON))
+		defer C.free(unsafe.Pointer(configCStr))
+
+		var resultCStr *C.char
+		if err := callAndCheck(func() C.SysprimsErrorCode {
+			return C.sysprims_terminate_tree(C.uint32_t(pid), configCStr, &resultCStr)
+		}); err != nil {
+			var sErr *Error
+			if !errors.As(err, &sErr) || sErr.Code != ErrNotFound || !existed {
+				return nil, err
+			}
+			// pid existed before the call, so it exited and was reaped by
+			// another waiter (such as a ChildHandle) while the library was
+			// signaling or waiting on it.
+			result = newTerminateTreeResult(pid, config, targets, group)
+			result.KillSignal = nil
+			result.Exited = true
+			result.Warnings = append(result.Warnings, "Process exited and was reaped during termination")
+		} else {
+			defer C.sysprims_free_string(resultCStr)
+			result = &TerminateTreeResult{}
+			if err := json.Unmarshal([]byte(C.GoString(resultCStr)), result); err != nil {
+				return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
+			}
+			groupJobTerminated(pid, result.Warnings)
+		}
+	}
+	if consoleWarning != "" {
+		result.Warnings = append([]string{consoleWarning}, result.Warnings...)
+	}
+	result.WarningDetails = warningDetails("TerminateTree", pid, result.Warnings)
+	logTreeKillReliability("TerminateTree", pid, result.TreeKillReliability)
+
+	if group && result.PGID == nil {
+		// The library fell back to signaling pid alone.
+		for i, t := range targets {
+			if t == pid {
+				targets, ids, idErrs = targets[i:i+1], ids[i:i+1], idErrs[i:i+1]
+				break
+			}
+		}
+	}
+	result.Targets = targets
+	result.Processes = make([]TerminateTreeOutcome, len(targets))
+	for i, t := range targets {
+		result.Processes[i] = TerminateTreeOutcome{
+			PID:       t,
+			Exited:    terminatedExited(t, ids[i], idErrs[i]),
+			Escalated: result.Escalated && (result.PGID != nil || t == pid),
+		}
+	}
+
+	return result, nil
+}
+
+// terminateTreeConsoleCtrl runs the ConsoleCtrl first stage: it sends pid a
+// console control event and waits up to the grace timeout. When the process
+// is still running it zeroes config's grace timeout, so the library
+// terminates it without waiting again.
+func terminateTreeConsoleCtrl(pid uint32, config *TerminateTreeConfig) (exited bool, warning string) {
+	grace := defaultTerminateTreeGrace
+	if config.GraceTimeoutMS != nil {
+		grace = time.Duration(*config.GraceTimeoutMS) * time.Millisecond
+	}
+	if err := signalGraceful(pid); err != nil {
+		return false, "Console control event not sent: " + err.Error()
+	}
+	res, err := WaitPID(pid, grace)
+	var sErr *Error
+	if err == nil && res.Exited || errors.As(err, &sErr) && sErr.Code == ErrNotFound {
+		return true, "Stopped by console control event"
+	}
+	zero := uint64(0)
+	config.GraceTimeoutMS = &zero
+	return false, "Console control event sent; terminating after the grace timeout"
+}
+
+// terminateTreePolicy applies check to the targets, read from snapshot. It
+// returns a result when the policy leaves them alone, and nil to proceed.
+func terminateTreePolicy(pid uint32, config TerminateTreeConfig, check *safetyCheck, snapshot *ProcessSnapshot, targets []uint32, group bool) (*TerminateTreeResult, error) {
+	info := make(map[uint32]*ProcessInfo, len(snapshot.Processes))
+	for i := range snapshot.Processes {
+		info[snapshot.Processes[i].PID] = &snapshot.Processes[i]
+	}
+	if info[pid] == nil {
+		return nil, &Error{Code: ErrNotFound, Message: "process " + strconv.FormatUint(uint64(pid), 10) + " not found"}
+	}
+	var protected []string
+	for _, t := range targets {
+		if check.protects(t, info[t]) {
+			protected = append(protected, strconv.FormatUint(uint64(t), 10))
+		}
+	}
+	if len(protected) == 0 {
+		return nil, check.checkCount(len(targets))
+	}
+
+	result := newTerminateTreeResult(pid, config, []uint32{}, group)
+	result.KillSignal = nil
+	result.SkippedPolicy = targets
+	result.Warnings = append(result.Warnings, "Not terminated: the safety policy protects pid "+strings.Join(protected, ", "))
+	result.WarningDetails = warningDetails("TerminateTree", pid, result.Warnings)
+	return result, nil
+}
+
+// terminateTreeDryRun builds the TerminateTree result for a dry run. Like
+// the library it fails when pid does not exist or cannot be signaled.
+func terminateTreeDryRun(pid uint32, config TerminateTreeConfig, targets []uint32, group bool) (*TerminateTreeResult, error) {
+	ok, err := CanSignal(pid)
+	if err != nil {
 		return nil, err
 	}
-	defer C.sysprims_free_string(resultCStr)
+	if !ok {
+		return nil, &Error{Code: ErrPermissionDenied, Message: "not permitted to signal pid " + strconv.FormatUint(uint64(pid), 10)}
+	}
+	return newTerminateTreeResult(pid, config, targets, group), nil
+}
 
-	var result TerminateTreeResult
-	if err := json.Unmarshal([]byte(C.GoString(resultCStr)), &result); err != nil {
-		return nil, &Error{Code: ErrInternal, Message: "failed to parse response: " + err.Error()}
+// newTerminateTreeResult builds a TerminateTree result the library did not
+// produce, with the signals config selects and nothing yet observed.
+func newTerminateTreeResult(pid uint32, config TerminateTreeConfig, targets []uint32, group bool) *TerminateTreeResult {
+	result := &TerminateTreeResult{
+		SchemaID:            SchemaTerminateTreeResultV1,
+		Timestamp:           time.Now().UTC().Format(time.RFC3339),
+		Platform:            Platform(),
+		PID:                 pid,
+		SignalSent:          SIGTERM,
+		TreeKillReliability: "best_effort",
+		Warnings:            []string{},
+		Targets:             targets,
+	}
+	if config.Signal != nil {
+		result.SignalSent = *config.Signal
+	}
+	kill := int32(SIGKILL)
+	if config.KillSignal != nil {
+		kill = *config.KillSignal
 	}
+	result.KillSignal = &kill
+	if group {
+		pgid := pid
+		result.PGID = &pgid
+		result.TreeKillReliability = "guaranteed"
+	}
+	return result
+}
 
-	return &result, nil
+// terminatedExited reports whether target, whose identity was read before
+// signaling, is gone now.
+func terminatedExited(target uint32, id ProcessIdentity, idErr error) bool {
+	if idErr == nil {
+		running, err := id.StillRunning()
+		return err == nil && !running
+	}
+	_, err := ProcessGet(target)
+	var sErr *Error
+	return errors.As(err, &sErr) && sErr.Code == ErrNotFound
 }
@@ -0,0 +1,12 @@
+package sysprims
+
+// ttyOf returns p.TTY, reading it when the listing did not request it. It
+// returns "" when the process has no controlling terminal or it cannot be
+// read.
+func ttyOf(p *ProcessInfo) string {
+	if p.TTY != nil {
+		return *p.TTY
+	}
+	tty, _ := readTTY(p.PID)
+	return tty
+}
@@ -0,0 +1,54 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <stdlib.h>
+#include <sys/param.h>
+#include <sys/proc_info.h>
+#include <sys/stat.h>
+
+// sysprims_go_tty returns the controlling terminal device of pid; *none is
+// set when it has none.
+static int sysprims_go_tty(int pid, dev_t *dev, int *none) {
+	struct proc_bsdinfo bi;
+	int n = proc_pidinfo(pid, PROC_PIDTBSDINFO, 0, &bi, sizeof(bi));
+	if (n <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	if (n < (int)sizeof(bi)) {
+		return EIO;
+	}
+	*dev = (dev_t)bi.e_tdev;
+	*none = *dev == NODEV;
+	return 0;
+}
+
+static const char *sysprims_go_devname(dev_t dev) {
+	return devname(dev, S_IFCHR);
+}
+*/
+import "C"
+
+import "syscall"
+
+// readTTY returns the controlling terminal of pid as a path relative to
+// /dev, from proc_pidinfo. It returns "" when the process has no controlling
+// terminal.
+func readTTY(pid uint32) (string, error) {
+	var dev C.dev_t
+	var none C.int
+	if rc := C.sysprims_go_tty(C.int(pid), &dev, &none); rc != 0 {
+		return "", errnoError(pid, syscall.Errno(rc))
+	}
+	if none != 0 {
+		return "", nil
+	}
+	name := C.GoString(C.sysprims_go_devname(dev))
+	if name == "" || name == "??" {
+		return "", &Error{Code: ErrSystem, Message: "unknown tty device"}
+	}
+	return name, nil
+}
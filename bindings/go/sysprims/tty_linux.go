@@ -0,0 +1,74 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Character device majors of pseudo-terminal slaves (/dev/pts/N).
+const (
+	ptsMajorFirst = 136
+	ptsMajorLast  = 143
+)
+
+// readTTY returns the controlling terminal of pid as a path relative to
+// /dev, from tty_nr in /proc/<pid>/stat. It returns "" when the process has
+// no controlling terminal.
+func readTTY(pid uint32) (string, error) {
+	path := "/proc/" + strconv.FormatUint(uint64(pid), 10) + "/stat"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", procReadError(pid, err)
+	}
+	_, fields, err := parseProcStat(path, data)
+	if err != nil {
+		return "", err
+	}
+	ttyNr, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return "", &Error{Code: ErrSystem, Message: "malformed tty_nr in " + path}
+	}
+	if ttyNr == 0 {
+		return "", nil
+	}
+
+	// tty_nr packs the device number: minor in bits 0-7 and 20-31, major in
+	// bits 8-19.
+	major := (ttyNr >> 8) & 0xfff
+	minor := (ttyNr & 0xff) | ((ttyNr >> 12) & 0xfff00)
+	if major >= ptsMajorFirst && major <= ptsMajorLast {
+		return "pts/" + strconv.FormatInt((major-ptsMajorFirst)<<8|minor, 10), nil
+	}
+	if name, ok := devNameOf(uint64(major), uint64(minor)); ok {
+		return name, nil
+	}
+	return "", &Error{Code: ErrSystem, Message: "unknown tty device " + strconv.FormatInt(major, 10) + ":" + strconv.FormatInt(minor, 10)}
+}
+
+// devNameOf finds the character device in /dev with the given number.
+func devNameOf(major, minor uint64) (string, bool) {
+	entries, err := os.ReadDir("/dev")
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		if e.Type()&os.ModeCharDevice == 0 {
+			continue
+		}
+		var st syscall.Stat_t
+		if syscall.Stat("/dev/"+e.Name(), &st) != nil {
+			continue
+		}
+		rdev := uint64(st.Rdev)
+		// Linux dev_t: major in bits 8-19 and 32-43, minor in 0-7 and 20-43.
+		m := (rdev>>8)&0xfff | (rdev>>32)&^0xfff
+		n := rdev&0xff | (rdev>>12)&^0xff
+		if m == major && n == minor {
+			return e.Name(), true
+		}
+	}
+	return "", false
+}
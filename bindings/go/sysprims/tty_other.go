@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package sysprims
+
+import "runtime"
+
+// readTTY is not implemented on this platform; Windows processes have no
+// controlling terminal.
+func readTTY(pid uint32) (string, error) {
+	return "", &Error{Code: ErrNotSupported, Message: "controlling terminals are not supported on " + runtime.GOOS}
+}
@@ -0,0 +1,106 @@
+package sysprims
+
+import (
+	"strings"
+	"time"
+)
+
+// UnixSocket is one AF_UNIX socket in a [UnixSocketsSnapshot].
+type UnixSocket struct {
+	// Path is the bound address: a filesystem path or, on Linux, an
+	// abstract name shown with a leading "@". Empty for unbound sockets,
+	// such as the client end of most connections.
+	Path string `json:"path,omitempty"`
+	// Type is "stream", "dgram", or "seqpacket" ("unknown" otherwise).
+	Type string `json:"type"`
+	// State is "listen", "unconnected", "connecting", "connected", or
+	// "disconnecting".
+	State *string `json:"state,omitempty"`
+	// PID is the owning process, when it could be attributed.
+	PID *uint32 `json:"pid,omitempty"`
+	// Inode is the kernel socket inode (Linux only).
+	Inode uint64 `json:"inode,omitempty"`
+}
+
+// UnixSocketsSnapshot represents a point-in-time listing of AF_UNIX sockets.
+type UnixSocketsSnapshot struct {
+	Timestamp string       `json:"timestamp"`
+	Platform  string       `json:"platform"`
+	Sockets   []UnixSocket `json:"sockets"`
+	Warnings  []string     `json:"warnings"`
+	// WarningDetails holds Warnings classified by category, in the same order.
+	WarningDetails []Warning `json:"-"`
+}
+
+// UnixSocketFilter specifies criteria for filtering AF_UNIX sockets. All
+// fields are optional and ANDed together.
+type UnixSocketFilter struct {
+	// PathContains filters by path substring (case-sensitive); unbound
+	// sockets never match.
+	PathContains *string `json:"path_contains,omitempty"`
+}
+
+// matches reports whether s satisfies f.
+func (f *UnixSocketFilter) matches(s *UnixSocket) bool {
+	if f == nil {
+		return true
+	}
+	if f.PathContains != nil && (s.Path == "" || !strings.Contains(s.Path, *f.PathContains)) {
+		return false
+	}
+	return true
+}
+
+// unixSocketType names a SOCK_* socket type; the values are shared by Linux
+// and macOS.
+func unixSocketType(typ uint64) string {
+	switch typ {
+	case 1:
+		return "stream"
+	case 2:
+		return "dgram"
+	case 5:
+		return "seqpacket"
+	default:
+		return "unknown"
+	}
+}
+
+// ListUnixSockets returns a snapshot of AF_UNIX sockets, listening or not,
+// with the owning PID where it can be found.
+//
+// Implemented in the Go bindings: on Linux from /proc/net/unix and the fd
+// tables of /proc/<pid>, on macOS from the socket fds of every readable
+// process (PROC_PIDFDSOCKETINFO).
+//
+// Best-effort behavior:
+// - On Linux, sockets owned by processes whose fds cannot be read have no PID, with a warning
+// - On macOS, sockets of processes that cannot be read are missing, with a warning
+// - A socket shared by several processes is attributed to the lowest PID
+// - Sockets opened or closed during the scan may be missing or unattributed
+// - Windows returns ErrNotSupported
+//
+// # Errors
+//
+//   - [ErrNotSupported]: Unix socket listing is unavailable on this platform
+//   - [ErrSystem]: The socket table could not be read
+func ListUnixSockets(filter *UnixSocketFilter) (*UnixSocketsSnapshot, error) {
+	sockets, warnings, err := listUnixSockets(filter.matches)
+	if err != nil {
+		return nil, err
+	}
+	if sockets == nil {
+		sockets = []UnixSocket{}
+	}
+	if warnings == nil {
+		warnings = []string{}
+	}
+
+	return &UnixSocketsSnapshot{
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+		Platform:       Platform(),
+		Sockets:        sockets,
+		Warnings:       warnings,
+		WarningDetails: warningDetails("ListUnixSockets", 0, warnings),
+	}, nil
+}
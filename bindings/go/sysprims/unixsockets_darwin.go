@@ -0,0 +1,176 @@
+//go:build darwin
+
+package sysprims
+
+/*
+#include <errno.h>
+#include <libproc.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/proc_info.h>
+#include <sys/socket.h>
+#include <sys/un.h>
+
+// Socket state bits from <sys/socketvar.h>, which is not exported to
+// userland.
+#define SYSPRIMS_GO_SS_ISCONNECTED     0x0002
+#define SYSPRIMS_GO_SS_ISCONNECTING    0x0004
+#define SYSPRIMS_GO_SS_ISDISCONNECTING 0x0008
+
+enum {
+	SYSPRIMS_GO_UNIX_UNCONNECTED,
+	SYSPRIMS_GO_UNIX_CONNECTING,
+	SYSPRIMS_GO_UNIX_CONNECTED,
+	SYSPRIMS_GO_UNIX_DISCONNECTING,
+	SYSPRIMS_GO_UNIX_LISTEN,
+};
+
+struct sysprims_go_unix_socket {
+	uint64_t so;
+	int type;
+	int state;
+	char path[sizeof(((struct sockaddr_un *)0)->sun_path) + 1];
+};
+
+// sysprims_go_unix_sockets fills out with up to cap AF_UNIX sockets open in
+// pid, read with PROC_PIDFDSOCKETINFO, and stores the number found in n.
+// Fds closed during the scan are skipped.
+static int sysprims_go_unix_sockets(int pid, struct sysprims_go_unix_socket *out, int cap, int *n) {
+	*n = 0;
+	errno = 0;
+	int bytes = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, NULL, 0);
+	if (bytes <= 0) {
+		return errno == 0 ? ESRCH : errno;
+	}
+	bytes += 32 * (int)sizeof(struct proc_fdinfo);
+	struct proc_fdinfo *fds = malloc(bytes);
+	if (fds == NULL) {
+		return ENOMEM;
+	}
+	errno = 0;
+	bytes = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, fds, bytes);
+	if (bytes <= 0) {
+		free(fds);
+		return errno == 0 ? ESRCH : errno;
+	}
+	int count = bytes / (int)sizeof(struct proc_fdinfo);
+	for (int i = 0; i < count && *n < cap; i++) {
+		if (fds[i].proc_fdtype != PROX_FDTYPE_SOCKET) {
+			continue;
+		}
+		struct socket_fdinfo si;
+		if (proc_pidfdinfo(pid, fds[i].proc_fd, PROC_PIDFDSOCKETINFO, &si, sizeof(si)) < (int)sizeof(si)) {
+			continue;
+		}
+		if (si.psi.soi_kind != SOCKINFO_UN) {
+			continue;
+		}
+		struct sysprims_go_unix_socket *s = &out[(*n)++];
+		memset(s, 0, sizeof(*s));
+		s->so = si.psi.soi_so;
+		s->type = si.psi.soi_type;
+		if (si.psi.soi_options & SO_ACCEPTCONN) {
+			s->state = SYSPRIMS_GO_UNIX_LISTEN;
+		} else if (si.psi.soi_state & SYSPRIMS_GO_SS_ISDISCONNECTING) {
+			s->state = SYSPRIMS_GO_UNIX_DISCONNECTING;
+		} else if (si.psi.soi_state & SYSPRIMS_GO_SS_ISCONNECTED) {
+			s->state = SYSPRIMS_GO_UNIX_CONNECTED;
+		} else if (si.psi.soi_state & SYSPRIMS_GO_SS_ISCONNECTING) {
+			s->state = SYSPRIMS_GO_UNIX_CONNECTING;
+		}
+		memcpy(s->path, si.psi.soi_proto.pri_un.unsi_addr.ua_sun.sun_path, sizeof(s->path) - 1);
+	}
+	free(fds);
+	return 0;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// maxUnixSocketsPerProcess bounds the AF_UNIX sockets read from one process.
+const maxUnixSocketsPerProcess = 4096
+
+// unixSocketStates names the SYSPRIMS_GO_UNIX_* states, in order.
+var unixSocketStates = [...]string{"unconnected", "connecting", "connected", "disconnecting", "listen"}
+
+// listUnixSockets reads the AF_UNIX sockets of every readable process and
+// keeps those accepted by keep. A socket held by several processes is
+// listed once, for the lowest PID.
+func listUnixSockets(keep func(s *UnixSocket) bool) ([]UnixSocket, []string, error) {
+	pids, err := listAllPIDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	buf := C.malloc(C.size_t(maxUnixSocketsPerProcess) * C.size_t(C.sizeof_struct_sysprims_go_unix_socket))
+	if buf == nil {
+		return nil, nil, &Error{Code: ErrSystem, Message: "out of memory listing unix sockets"}
+	}
+	defer C.free(buf)
+
+	var sockets []UnixSocket
+	seen := make(map[uint64]int)
+	var permissionDenied, readErrors int
+	for _, pid := range pids {
+		var n C.int
+		switch rc := syscall.Errno(C.sysprims_go_unix_sockets(C.int(pid), (*C.struct_sysprims_go_unix_socket)(buf), maxUnixSocketsPerProcess, &n)); rc {
+		case 0:
+		case syscall.ESRCH:
+			continue
+		case syscall.EPERM, syscall.EACCES:
+			permissionDenied++
+			continue
+		default:
+			readErrors++
+			continue
+		}
+		for _, cs := range unsafe.Slice((*C.struct_sysprims_go_unix_socket)(buf), int(n)) {
+			owner := uint32(pid)
+			if i, ok := seen[uint64(cs.so)]; ok {
+				if *sockets[i].PID > owner {
+					sockets[i].PID = &owner
+				}
+				continue
+			}
+			state := unixSocketStates[int(cs.state)]
+			s := UnixSocket{
+				Path:  C.GoString(&cs.path[0]),
+				Type:  unixSocketType(uint64(cs._type)),
+				State: &state,
+				PID:   &owner,
+			}
+			if keep(&s) {
+				seen[uint64(cs.so)] = len(sockets)
+				sockets = append(sockets, s)
+			}
+		}
+	}
+
+	var warnings []string
+	if permissionDenied > 0 {
+		warnings = append(warnings, fmt.Sprintf("Skipped %d pid entries due to permission errors", permissionDenied))
+	}
+	if readErrors > 0 {
+		warnings = append(warnings, fmt.Sprintf("Skipped %d pid entries due to read errors", readErrors))
+	}
+	return sockets, warnings, nil
+}
+
+// listAllPIDs returns every PID on the system via proc_listallpids.
+func listAllPIDs() ([]int32, error) {
+	n := C.proc_listallpids(nil, 0)
+	if n <= 0 {
+		return nil, &Error{Code: ErrSystem, Message: "failed to list processes"}
+	}
+	// Processes may start between the two calls; leave headroom.
+	pids := make([]int32, int(n)+64)
+	n = C.proc_listallpids(unsafe.Pointer(&pids[0]), C.int(len(pids)*4))
+	if n <= 0 {
+		return nil, &Error{Code: ErrSystem, Message: "failed to list processes"}
+	}
+	return pids[:n], nil
+}
@@ -0,0 +1,64 @@
+//go:build linux
+
+package sysprims
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// listUnixSockets reads /proc/net/unix, keeps the sockets accepted by keep,
+// and attributes them to PIDs.
+func listUnixSockets(keep func(s *UnixSocket) bool) ([]UnixSocket, []string, error) {
+	const unixPath = "/proc/net/unix"
+	f, err := os.Open(unixPath)
+	if err != nil {
+		return nil, nil, &Error{Code: ErrSystem, Message: fmt.Sprintf("failed to read %s: %v", unixPath, err)}
+	}
+	defer func() { _ = f.Close() }()
+
+	var sockets []UnixSocket
+	var warnings []string
+	malformed := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields, path := splitUnixLine(scanner.Text())
+		if len(fields) < 7 {
+			continue
+		}
+		inode, info, err := parseProcNetUnixFields(fields, path)
+		if err != nil {
+			malformed++
+			continue
+		}
+		typ, err := strconv.ParseUint(fields[4], 16, 16)
+		if err != nil {
+			malformed++
+			continue
+		}
+		s := UnixSocket{Path: path, Type: unixSocketType(typ), State: info.State, Inode: inode}
+		if keep(&s) {
+			sockets = append(sockets, s)
+		}
+	}
+	if malformed > 0 {
+		warnings = append(warnings, fmt.Sprintf("skipped %d malformed entries in %s", malformed, unixPath))
+	}
+
+	wanted := make(map[uint64]*uint32)
+	for i := range sockets {
+		if sockets[i].Inode != 0 {
+			wanted[sockets[i].Inode] = nil
+		}
+	}
+	if len(wanted) > 0 {
+		warnings = append(warnings, mapSocketOwners(wanted)...)
+		for i := range sockets {
+			sockets[i].PID = wanted[sockets[i].Inode]
+		}
+	}
+	return sockets, warnings, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package sysprims
+
+import "runtime"
+
+func listUnixSockets(keep func(s *UnixSocket) bool) ([]UnixSocket, []string, error) {
+	return nil, nil, &Error{Code: ErrNotSupported, Message: "unix socket listing is not supported on " + runtime.GOOS}
+}
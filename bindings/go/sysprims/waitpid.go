@@ -0,0 +1,172 @@
+package sysprims
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+)
+
+// DefaultWaitPollInterval is the poll interval [WaitPIDWithOptions] uses
+// when WaitPidOptions.PollInterval is 0. It matches [WaitPID].
+const DefaultWaitPollInterval = 25 * time.Millisecond
+
+// WaitPidOptions configures [WaitPIDWithOptions].
+type WaitPidOptions struct {
+	// PollInterval is the delay between liveness checks. 0 means
+	// DefaultWaitPollInterval.
+	//
+	// On Unix an exit is noticed up to one interval late, and each check
+	// costs a kill(pid, 0) and a process-state read. Shorter intervals
+	// lower the latency for short-lived processes; longer ones cut the CPU
+	// spent on long waits (a few milliseconds is responsive, a second or
+	// more is cheap when many processes are waited on at once). On Windows
+	// the wait is event-driven and exits are noticed immediately; the
+	// interval only bounds how quickly cancellation is noticed.
+	PollInterval time.Duration
+	// Timeout bounds the wait and yields a TimedOut result. 0 means wait
+	// until the process exits or ctx is done.
+	Timeout time.Duration
+}
+
+// WaitPIDWithOptions is like [WaitPID] with a configurable poll interval
+// and context cancellation.
+//
+// When ctx is done before the process exits or Timeout elapses, the wait
+// stops and ctx.Err() is returned.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pid is 0, or PollInterval or Timeout is negative
+//   - [ErrNotFound]: pid does not exist at time of first check
+//   - [ErrPermissionDenied]: not permitted to query liveness
+//   - ctx.Err(): ctx was done first
+func WaitPIDWithOptions(ctx context.Context, pid uint32, opts WaitPidOptions) (*WaitPidResult, error) {
+	if opts.PollInterval < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "poll interval must be >= 0"}
+	}
+	if opts.Timeout < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "timeout must be >= 0"}
+	}
+	interval := opts.PollInterval
+	if interval == 0 {
+		interval = DefaultWaitPollInterval
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+	// On Windows WaitPID blocks on the process handle, so each step waits
+	// up to one interval; elsewhere each step is a single check.
+	blocking := runtime.GOOS == "windows"
+
+	var last *WaitPidResult
+	for {
+		step := time.Duration(0)
+		if blocking {
+			step = interval
+			if !deadline.IsZero() {
+				step = min(step, time.Until(deadline))
+			}
+		}
+		res, err := WaitPID(pid, max(step, 0))
+		var sErr *Error
+		switch {
+		case err == nil:
+			last = res
+		case last != nil && errors.As(err, &sErr) && sErr.Code == ErrNotFound:
+			// Gone (and reaped) since the previous check.
+			last.Exited = true
+			last.TimedOut = false
+			last.Timestamp = time.Now().UTC().Format(time.RFC3339)
+			return last, nil
+		default:
+			return nil, err
+		}
+		if last.Exited {
+			return last, nil
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			last.TimedOut = true
+			return last, nil
+		}
+		last.TimedOut = false
+
+		if blocking {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		wait := interval
+		if !deadline.IsZero() {
+			wait = min(wait, time.Until(deadline))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// DefaultProcessWaitInterval is the poll interval [WaitForProcess] uses
+// when pollInterval is 0.
+const DefaultProcessWaitInterval = 100 * time.Millisecond
+
+// WaitForProcess polls [ProcessList] until a process matching filter
+// appears, and returns it: the inverse of [WaitPID]. When several match, the
+// one with the lowest PID is returned.
+//
+// The first poll happens immediately, and a last one at the deadline, so a
+// timeout of 0 checks once. pollInterval 0 means DefaultProcessWaitInterval.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: filter is nil or sets no criteria, or is
+//     invalid, or timeout or pollInterval is negative
+//   - [ErrTimeout]: No process matched within timeout
+//   - Errors from [ProcessList]
+func WaitForProcess(filter *ProcessFilter, timeout time.Duration, pollInterval time.Duration) (*ProcessInfo, error) {
+	if filter.isEmpty() {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "wait for process requires a non-empty filter"}
+	}
+	if timeout < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "timeout must be >= 0"}
+	}
+	if pollInterval < 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "poll interval must be >= 0"}
+	}
+	if pollInterval == 0 {
+		pollInterval = DefaultProcessWaitInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		snapshot, err := ProcessList(filter)
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshot.Processes) > 0 {
+			first := &snapshot.Processes[0]
+			for i := range snapshot.Processes {
+				if snapshot.Processes[i].PID < first.PID {
+					first = &snapshot.Processes[i]
+				}
+			}
+			return first, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, &Error{Code: ErrTimeout, Message: "no process matched the filter within " + timeout.String()}
+		}
+		time.Sleep(min(pollInterval, remaining))
+	}
+}
@@ -0,0 +1,83 @@
+package sysprims
+
+import "strings"
+
+// WarningCode is a stable category for a best-effort warning.
+//
+// The library reports warnings as free-form strings; the Go bindings classify
+// them so callers can branch on the category instead of matching text.
+type WarningCode string
+
+const (
+	// WarningPermissionDenied means some entries were skipped or an operation
+	// was degraded because access was denied.
+	WarningPermissionDenied WarningCode = "permission_denied"
+	// WarningReadError means some entries could not be read or parsed.
+	WarningReadError WarningCode = "read_error"
+	// WarningNotSupported means a detail or entry kind is not supported on
+	// this platform.
+	WarningNotSupported WarningCode = "not_supported"
+	// WarningBestEffort means results were limited in scope or a weaker
+	// fallback mechanism was used.
+	WarningBestEffort WarningCode = "best_effort"
+	// WarningNoResults means nothing was found or visible.
+	WarningNoResults WarningCode = "no_results"
+	// WarningOther is any warning that does not fit a more specific category.
+	WarningOther WarningCode = "other"
+)
+
+// Warning is a classified best-effort warning.
+type Warning struct {
+	// Code is the stable warning category.
+	Code WarningCode
+	// Message is the original warning text.
+	Message string
+}
+
+// warningPatterns maps lower-cased message fragments to codes. Order matters:
+// the first match wins, so more specific categories come first.
+var warningPatterns = []struct {
+	fragment string
+	code     WarningCode
+}{
+	{"permission", WarningPermissionDenied},
+	{"not supported", WarningNotSupported},
+	{"unsupported", WarningNotSupported},
+	{"read error", WarningReadError},
+	{"failed to", WarningReadError},
+	{"malformed", WarningReadError},
+	{"unavailable", WarningReadError},
+	{"best-effort", WarningBestEffort},
+	{"owned by other users", WarningBestEffort},
+	{"falling back", WarningBestEffort},
+	{"without grouping", WarningBestEffort},
+	{"process group", WarningBestEffort},
+	{"using pid kill", WarningBestEffort},
+	{"no listening ports found", WarningNoResults},
+	{"no file descriptors visible", WarningNoResults},
+}
+
+// ClassifyWarning returns the typed form of a warning message.
+//
+// Unrecognized messages are classified as [WarningOther].
+func ClassifyWarning(message string) Warning {
+	lower := strings.ToLower(message)
+	for _, p := range warningPatterns {
+		if strings.Contains(lower, p.fragment) {
+			return Warning{Code: p.code, Message: message}
+		}
+	}
+	return Warning{Code: WarningOther, Message: message}
+}
+
+// classifyWarnings returns typed warnings parallel to messages.
+func classifyWarnings(messages []string) []Warning {
+	if len(messages) == 0 {
+		return nil
+	}
+	warnings := make([]Warning, len(messages))
+	for i, m := range messages {
+		warnings[i] = ClassifyWarning(m)
+	}
+	return warnings
+}
@@ -0,0 +1,177 @@
+package sysprims
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// FdCountSample is one fd count reading emitted by [WatchFdCounts].
+type FdCountSample struct {
+	PID uint32 `json:"pid"`
+	// Time is when the count was read.
+	Time time.Time `json:"time"`
+	// Count is the number of open fds (0 in a final sample).
+	Count uint32 `json:"count"`
+	// Exited marks the final sample of a PID that exited.
+	Exited bool `json:"exited,omitempty"`
+	// Err is set on the final sample of a PID that could not be read for
+	// another reason (such as [ErrPermissionDenied]).
+	Err error `json:"-"`
+}
+
+// WatchFdCounts samples the fd counts of pids with [FdCount] every interval
+// until ctx is done, sending one sample per live PID per round. The first
+// round is taken immediately.
+//
+// A PID that exits gets a final sample with Exited set and is no longer
+// watched; a PID that fails for another reason gets a final sample with Err
+// set. The channel is closed when ctx is done or no PIDs remain.
+//
+// The watcher never blocks on a slow consumer: while a PID's sample is
+// waiting to be received, a newer one replaces it. Final samples are never
+// replaced. Samples still pending when ctx is done are discarded.
+//
+// # Errors
+//
+//   - [ErrInvalidArgument]: pids is empty or holds 0, or interval is not
+//     positive
+func WatchFdCounts(ctx context.Context, pids []uint32, interval time.Duration) (<-chan FdCountSample, error) {
+	if err := validatePidList(pids); err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, &Error{Code: ErrInvalidArgument, Message: "interval must be > 0"}
+	}
+
+	live := make([]uint32, 0, len(pids))
+	seen := make(map[uint32]bool, len(pids))
+	for _, pid := range pids {
+		if !seen[pid] {
+			seen[pid] = true
+			live = append(live, pid)
+		}
+	}
+
+	ch := make(chan FdCountSample)
+	go watchFdCounts(ctx, live, interval, ch)
+	return ch, nil
+}
+
+func watchFdCounts(ctx context.Context, live []uint32, interval time.Duration, ch chan<- FdCountSample) {
+	defer close(ch)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// queue holds at most one sample per PID; slot maps a PID to its index.
+	var queue []FdCountSample
+	slot := make(map[uint32]int)
+	sampleRound := func() {
+		kept := live[:0]
+		for _, pid := range live {
+			s := FdCountSample{PID: pid, Time: time.Now()}
+			n, err := FdCount(pid)
+			var sErr *Error
+			switch {
+			case err == nil:
+				s.Count = n
+				kept = append(kept, pid)
+			case errors.As(err, &sErr) && sErr.Code == ErrNotFound:
+				s.Exited = true
+			default:
+				s.Err = err
+			}
+			if i, ok := slot[pid]; ok {
+				queue[i] = s
+			} else {
+				slot[pid] = len(queue)
+				queue = append(queue, s)
+			}
+		}
+		live = kept
+	}
+
+	sampleRound()
+	for len(live) > 0 || len(queue) > 0 {
+		var out chan<- FdCountSample
+		var next FdCountSample
+		if len(queue) > 0 {
+			out = ch
+			next = queue[0]
+		}
+		tick := ticker.C
+		if len(live) == 0 {
+			tick = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			sampleRound()
+		case out <- next:
+			queue = queue[1:]
+			delete(slot, next.PID)
+			for pid, i := range slot {
+				slot[pid] = i - 1
+			}
+		}
+	}
+}
+
+// DetectFdGrowth returns, in ascending order, the PIDs whose fd count never
+// decreased across samples and grew at least minSlope fds per second
+// overall (a least-squares fit over the samples' times).
+//
+// Final samples (Exited or Err set) are ignored, and a PID needs at least two
+// samples with a net increase to qualify.
+func DetectFdGrowth(samples []FdCountSample, minSlope float64) []uint32 {
+	byPID := make(map[uint32][]FdCountSample)
+	for _, s := range samples {
+		if s.Exited || s.Err != nil {
+			continue
+		}
+		byPID[s.PID] = append(byPID[s.PID], s)
+	}
+
+	var growing []uint32
+	for pid, ss := range byPID {
+		if len(ss) < 2 || ss[len(ss)-1].Count <= ss[0].Count {
+			continue
+		}
+		monotonic := true
+		for i := 1; i < len(ss); i++ {
+			if ss[i].Count < ss[i-1].Count {
+				monotonic = false
+				break
+			}
+		}
+		if monotonic && fdSlope(ss) >= minSlope {
+			growing = append(growing, pid)
+		}
+	}
+	sort.Slice(growing, func(i, j int) bool { return growing[i] < growing[j] })
+	return growing
+}
+
+// fdSlope fits count = a + b*t by least squares and returns b in fds per
+// second. Samples taken at the same instant give 0.
+func fdSlope(ss []FdCountSample) float64 {
+	t0 := ss[0].Time
+	var sumT, sumC, sumTT, sumTC float64
+	for _, s := range ss {
+		t := s.Time.Sub(t0).Seconds()
+		c := float64(s.Count)
+		sumT += t
+		sumC += c
+		sumTT += t * t
+		sumTC += t * c
+	}
+	n := float64(len(ss))
+	den := n*sumTT - sumT*sumT
+	if den == 0 {
+		return 0
+	}
+	return (n*sumTC - sumT*sumC) / den
+}
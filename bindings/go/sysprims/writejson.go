@@ -0,0 +1,183 @@
+package sysprims
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// The WriteJSON methods stream a snapshot to a writer in the library's schema
+// shape, one element at a time, so forwarding a large snapshot does not
+// build the whole document in memory. Fields added by the Go bindings (raw
+// state, OOM scores, nice values, memory detail, socket details, fd flags,
+// offsets and deleted status, paging totals) are dropped so the output
+// validates against the snapshot's schema_id. Each document is followed by a
+// newline. To skip decoding entirely, use the Raw variants.
+
+// wireProcessInfo is ProcessInfo in the schema's process_info shape.
+type wireProcessInfo struct {
+	PID             uint32            `json:"pid"`
+	PPID            uint32            `json:"ppid"`
+	Name            string            `json:"name"`
+	User            *string           `json:"user,omitempty"`
+	CPUPercent      float64           `json:"cpu_percent"`
+	MemoryKB        uint64            `json:"memory_kb"`
+	ElapsedSeconds  uint64            `json:"elapsed_seconds"`
+	StartTimeUnixMS *uint64           `json:"start_time_unix_ms,omitempty"`
+	ExePath         *string           `json:"exe_path,omitempty"`
+	State           string            `json:"state"`
+	Cmdline         []string          `json:"cmdline"`
+	Env             map[string]string `json:"env,omitempty"`
+	ThreadCount     *uint32           `json:"thread_count,omitempty"`
+}
+
+// wire returns p in schema shape. Required fields the library always fills
+// but that may be unset on a hand-built ProcessInfo get neutral values.
+func (p *ProcessInfo) wire() *wireProcessInfo {
+	w := &wireProcessInfo{
+		PID:             p.PID,
+		PPID:            p.PPID,
+		Name:            p.Name,
+		User:            p.User,
+		CPUPercent:      p.CPUPercent,
+		MemoryKB:        p.MemoryKB,
+		StartTimeUnixMS: p.StartTimeUnixMS,
+		ExePath:         p.ExePath,
+		State:           StateUnknown,
+		Cmdline:         p.Cmdline,
+		Env:             p.Env,
+		ThreadCount:     p.ThreadCount,
+	}
+	if p.ElapsedSeconds != nil {
+		w.ElapsedSeconds = *p.ElapsedSeconds
+	}
+	if p.State != nil {
+		w.State = *p.State
+	}
+	if w.Cmdline == nil {
+		w.Cmdline = []string{}
+	}
+	return w
+}
+
+// WriteJSON streams s to w as a process snapshot document.
+func (s *ProcessSnapshot) WriteJSON(w io.Writer) error {
+	head := struct {
+		SchemaID  string `json:"schema_id"`
+		Timestamp string `json:"timestamp"`
+	}{s.SchemaID, s.Timestamp}
+	return writeSnapshotJSON(w, head, "processes", len(s.Processes), func(i int) any {
+		return s.Processes[i].wire()
+	})
+}
+
+// WriteJSON streams s to w as an fd snapshot document.
+func (s *FdSnapshot) WriteJSON(w io.Writer) error {
+	head := struct {
+		SchemaID  string   `json:"schema_id"`
+		Timestamp string   `json:"timestamp"`
+		Platform  string   `json:"platform"`
+		Pid       uint32   `json:"pid"`
+		Warnings  []string `json:"warnings"`
+	}{s.SchemaID, s.Timestamp, s.Platform, s.Pid, nonNilStrings(s.Warnings)}
+	return writeSnapshotJSON(w, head, "fds", len(s.Fds), func(i int) any {
+		fd := &s.Fds[i]
+		return struct {
+			Fd   uint32  `json:"fd"`
+			Kind string  `json:"kind"`
+			Path *string `json:"path,omitempty"`
+		}{fd.Fd, fd.Kind, fd.Path}
+	})
+}
+
+// WriteJSON streams s to w as a port bindings document.
+func (s *PortBindingsSnapshot) WriteJSON(w io.Writer) error {
+	head := struct {
+		SchemaID  string   `json:"schema_id"`
+		Timestamp string   `json:"timestamp"`
+		Platform  string   `json:"platform"`
+		Warnings  []string `json:"warnings"`
+	}{s.SchemaID, s.Timestamp, s.Platform, nonNilStrings(s.Warnings)}
+	return writeSnapshotJSON(w, head, "bindings", len(s.Bindings), func(i int) any {
+		b := &s.Bindings[i]
+		out := struct {
+			Protocol  Protocol         `json:"protocol"`
+			LocalAddr *string          `json:"local_addr,omitempty"`
+			LocalPort uint16           `json:"local_port"`
+			State     *string          `json:"state,omitempty"`
+			PID       *uint32          `json:"pid,omitempty"`
+			Process   *wireProcessInfo `json:"process,omitempty"`
+		}{Protocol: b.Protocol, LocalAddr: b.LocalAddr, LocalPort: b.LocalPort, State: b.State, PID: b.PID}
+		if b.Process != nil {
+			out.Process = b.Process.wire()
+		}
+		return out
+	})
+}
+
+// WriteJSON streams s to w. Thread snapshots are produced by the Go bindings
+// and have no library schema; the document has the same fields as
+// json.Marshal(s).
+func (s *ThreadSnapshot) WriteJSON(w io.Writer) error {
+	head := struct {
+		Timestamp string   `json:"timestamp"`
+		Platform  string   `json:"platform"`
+		PID       uint32   `json:"pid"`
+		Warnings  []string `json:"warnings"`
+	}{s.Timestamp, s.Platform, s.PID, nonNilStrings(s.Warnings)}
+	return writeSnapshotJSON(w, head, "threads", len(s.Threads), func(i int) any {
+		return &s.Threads[i]
+	})
+}
+
+// WriteJSON streams s to w. Socket snapshots are produced by the Go bindings
+// and have no library schema; the document has the same fields as
+// json.Marshal(s).
+func (s *SocketSnapshot) WriteJSON(w io.Writer) error {
+	head := struct {
+		Timestamp string   `json:"timestamp"`
+		Platform  string   `json:"platform"`
+		PID       uint32   `json:"pid"`
+		Warnings  []string `json:"warnings"`
+	}{s.Timestamp, s.Platform, s.PID, nonNilStrings(s.Warnings)}
+	return writeSnapshotJSON(w, head, "sockets", len(s.Sockets), func(i int) any {
+		return &s.Sockets[i]
+	})
+}
+
+// writeSnapshotJSON writes head's fields followed by key holding n items.
+//
+// head must marshal to a non-empty JSON object.
+func writeSnapshotJSON(w io.Writer, head any, key string, n int, item func(i int) any) error {
+	headJSON, err := json.Marshal(head)
+	if err != nil {
+		return &Error{Code: ErrInternal, Message: "failed to marshal snapshot: " + err.Error()}
+	}
+	keyJSON, _ := json.Marshal(key)
+
+	bw := bufio.NewWriter(w)
+	_, _ = bw.Write(headJSON[:len(headJSON)-1])
+	_ = bw.WriteByte(',')
+	_, _ = bw.Write(keyJSON)
+	_, _ = bw.WriteString(":[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			_ = bw.WriteByte(',')
+		}
+		data, err := json.Marshal(item(i))
+		if err != nil {
+			return &Error{Code: ErrInternal, Message: "failed to marshal snapshot: " + err.Error()}
+		}
+		_, _ = bw.Write(data)
+	}
+	_, _ = bw.WriteString("]}\n")
+	// bufio.Writer errors are sticky, so Flush reports the first write error.
+	return bw.Flush()
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}